@@ -13,10 +13,24 @@ import (
 	"github.com/usedcvnt/Diplom1Project/backend/internal/handler"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+	"github.com/usedcvnt/Diplom1Project/backend/migrations"
 	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/cache"
 	"github.com/usedcvnt/Diplom1Project/backend/pkg/database"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/mail"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/migrate"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/payment"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/search"
 )
 
+// redisCachePrefix префикс ключей кэша каталога в Redis
+const redisCachePrefix = "tour_agency:cache:"
+
+// redisRateLimitPrefix префикс ключей окон ограничения частоты в Redis
+const redisRateLimitPrefix = "tour_agency:ratelimit:"
+
 func main() {
 	// Загрузка конфигурации
 	cfg, err := config.LoadConfig("configs/config.json")
@@ -37,16 +51,29 @@ func main() {
 	}
 	log.Println("Успешное подключение к базе данных")
 
-	// Проверка наличия таблицы пользователей
-	var tableCount int
-	err = db.Get(&tableCount, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'users'")
+	// Проверка версии схемы; при auto_migrate недостающие миграции применяются
+	migrator, err := migrate.New(db, migrations.Schema())
 	if err != nil {
-		log.Fatalf("Ошибка проверки наличия таблицы пользователей: %s", err.Error())
+		log.Fatalf("Ошибка загрузки миграций: %s", err.Error())
 	}
-
-	if tableCount == 0 {
-		log.Fatalf("Таблица пользователей не найдена в базе данных. Необходимо выполнить миграции.")
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("Ошибка применения миграций: %s", err.Error())
+		}
+		for _, m := range applied {
+			log.Printf("Применена миграция %d_%s", m.Version, m.Name)
+		}
+		for _, set := range migrations.RequiredSeeds {
+			if err := migrate.Seed(context.Background(), db, migrations.Seeds(), set); err != nil {
+				log.Fatalf("Ошибка заполнения начальных данных: %s", err.Error())
+			}
+		}
 	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("Ошибка проверки схемы базы данных: %s. Выполните migrate up", err.Error())
+	}
+	log.Printf("Схема базы данных на версии %d", migrator.Latest())
 
 	// Проверка наличия ролей пользователей
 	var roleCount int
@@ -69,11 +96,74 @@ func main() {
 	// Инициализация менеджера JWT токенов
 	tokenManager := auth.NewJWTManager(cfg.JWT)
 
+	// Инициализация доставки писем
+	mailer, err := mail.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации доставки писем: %s", err.Error())
+	}
+
+	// Инициализация платежного провайдера
+	paymentProvider, err := payment.NewProvider(cfg.Payment)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации платежного провайдера: %s", err.Error())
+	}
+
+	// Инициализация таблицы курсов валют
+	baseCurrency := cfg.Currency.Base
+	if baseCurrency == "" {
+		baseCurrency = service.DefaultCurrency
+	}
+	converter, err := money.NewConverter(baseCurrency, cfg.Currency.Rates)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации курсов валют: %s", err.Error())
+	}
+
+	// Инициализация поискового индекса туров
+	searchIndex, err := search.NewIndex(cfg.Search)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации поискового индекса: %s", err.Error())
+	}
+
+	// Кэш каталога и окна ограничения частоты хранятся в Redis, а при его отсутствии - в памяти процесса
+	var store cache.Cache
+	var limitStore ratelimit.Store
+	if cfg.Redis.Host != "" {
+		redisClient, err := database.NewRedisClient(cfg.Redis)
+		if err != nil {
+			log.Printf("Redis недоступен, используются кэш и лимиты в памяти: %s", err.Error())
+		} else {
+			defer redisClient.Close()
+			store = cache.NewRedisCache(redisClient, redisCachePrefix)
+			limitStore = ratelimit.NewRedisStore(redisClient, redisRateLimitPrefix)
+			log.Println("Успешное подключение к Redis")
+		}
+	}
+	if store == nil {
+		store = cache.NewMemoryCache()
+		limitStore = ratelimit.NewMemoryStore()
+	}
+	caches := cache.NewRegistry(store)
+
 	// Инициализация репозиториев
 	repos := repository.NewRepository(db)
 
 	// Инициализация сервисов
-	services := service.NewService(repos, tokenManager)
+	services, err := service.NewService(repos, tokenManager, mailer, ratelimit.NewLimiter(limitStore), paymentProvider, converter, searchIndex, caches, cfg)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации сервисов: %s", err.Error())
+	}
+
+	// Запуск фонового снятия просроченных удержаний мест
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	holdWorker := service.NewOrderHoldWorker(services.Order, time.Duration(cfg.Orders.ExpirySweepInterval)*time.Second)
+	go holdWorker.Run(workerCtx)
+	scheduleWorker := service.NewTourScheduleWorker(services.TourSchedule, time.Duration(cfg.Schedule.GenerationInterval)*time.Minute)
+	go scheduleWorker.Run(workerCtx)
+	pricingWorker := service.NewPricingWorker(services.PricingRule, time.Duration(cfg.Pricing.RecalculationInterval)*time.Minute)
+	go pricingWorker.Run(workerCtx)
+	searchWorker := service.NewSearchIndexWorker(services.Search, time.Duration(cfg.Search.RebuildInterval)*time.Minute)
+	go searchWorker.Run(workerCtx)
 
 	// Инициализация обработчиков
 	handlers := handler.NewHandler(services, tokenManager)
@@ -83,6 +173,9 @@ func main() {
 
 	// Инициализация HTTP сервера
 	router := handlers.InitRoutes()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Ошибка настройки доверенных прокси: %s", err.Error())
+	}
 	server := &http.Server{
 		Addr:           ":" + cfg.Server.Port,
 		Handler:        router,
@@ -108,6 +201,9 @@ func main() {
 	<-quit
 	log.Println("Завершение работы сервера...")
 
+	// Остановка фоновых обработчиков
+	stopWorkers()
+
 	// Установка таймаута для graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/migrations"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/database"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/migrate"
+)
+
+const usage = `Использование: migrate [флаги] <команда> [аргументы]
+
+Команды:
+  up               применить все непримененные миграции
+  down [N]         откатить N последних миграций (по умолчанию 1)
+  status           показать состояние миграций
+  version          показать текущую и ожидаемую версии схемы
+  create <name>    создать пустую пару файлов миграции в каталоге -dir
+  seed [набор...]  заполнить базу наборами начальных данных (по умолчанию обязательные)
+
+Флаги:
+`
+
+func main() {
+	configPath := flag.String("config", "configs/config.json", "путь к файлу конфигурации")
+	dir := flag.String("dir", "migrations", "каталог исходных файлов миграций для команды create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Создание файлов не требует подключения к базе
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal("Укажите название миграции: migrate create <name>")
+		}
+		up, down, err := migrate.Create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Ошибка создания миграции: %s", err.Error())
+		}
+		fmt.Printf("Созданы %s и %s\n", up, down)
+		return
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %s", err.Error())
+	}
+
+	db, err := database.NewMySQLConnection(cfg.Database.GetDSN())
+	if err != nil {
+		log.Fatalf("Ошибка подключения к базе данных: %s", err.Error())
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.Schema())
+	if err != nil {
+		log.Fatalf("Ошибка загрузки миграций: %s", err.Error())
+	}
+
+	if err := run(context.Background(), db, migrator, args[0], args[1:]); err != nil {
+		log.Fatalf("Ошибка: %s", err.Error())
+	}
+}
+
+// run выполняет команду мигратора
+func run(ctx context.Context, db *sqlx.DB, migrator *migrate.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Применена миграция %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Схема уже на последней версии")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("количество миграций для отката должно быть положительным числом")
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("Откачена миграция %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Нет примененных миграций")
+		}
+		return nil
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ВЕРСИЯ\tНАЗВАНИЕ\tПРИМЕНЕНА")
+		for _, s := range statuses {
+			applied := "нет"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Текущая версия: %d, ожидаемая: %d\n", version, migrator.Latest())
+		return nil
+
+	case "seed":
+		sets := args
+		if len(sets) == 0 {
+			sets = migrations.RequiredSeeds
+		}
+		for _, set := range sets {
+			if err := migrate.Seed(ctx, db, migrations.Seeds(), set); err != nil {
+				return err
+			}
+			fmt.Printf("Применен набор начальных данных %s\n", set)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("неизвестная команда %q, см. migrate -h", command)
+}
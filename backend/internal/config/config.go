@@ -8,10 +8,21 @@ import (
 
 // Config структура для хранения конфигурации приложения
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	JWT      JWTConfig      `json:"jwt"`
-	Redis    RedisConfig    `json:"redis"`
+	Server    ServerConfig    `json:"server"`
+	Database  DatabaseConfig  `json:"database"`
+	JWT       JWTConfig       `json:"jwt"`
+	Redis     RedisConfig     `json:"redis"`
+	Orders    OrdersConfig    `json:"orders"`
+	Payment   PaymentConfig   `json:"payment"`
+	Currency  CurrencyConfig  `json:"currency"`
+	Schedule  ScheduleConfig  `json:"schedule"`
+	Pricing   PricingConfig   `json:"pricing"`
+	Search    SearchConfig    `json:"search"`
+	Cache     CacheConfig     `json:"cache"`
+	Mail      MailConfig      `json:"mail"`
+	Account   AccountConfig   `json:"account"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	TwoFactor TwoFactorConfig `json:"two_factor"`
 }
 
 // ServerConfig настройки HTTP сервера
@@ -19,6 +30,9 @@ type ServerConfig struct {
 	Port         string `json:"port"`
 	ReadTimeout  int    `json:"read_timeout"`
 	WriteTimeout int    `json:"write_timeout"`
+	// TrustedProxies адреса обратных прокси, которым доверяется X-Forwarded-For
+	// при определении IP клиента; пусто - используется адрес соединения
+	TrustedProxies []string `json:"trusted_proxies"`
 }
 
 // DatabaseConfig настройки базы данных
@@ -29,6 +43,9 @@ type DatabaseConfig struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	DBName   string `json:"dbname"`
+	// AutoMigrate применять миграции и обязательные начальные данные при запуске API;
+	// иначе API только проверяет, что схема на ожидаемой версии
+	AutoMigrate bool `json:"auto_migrate"`
 }
 
 // JWTConfig настройки JWT токенов
@@ -36,6 +53,8 @@ type JWTConfig struct {
 	Secret            string `json:"secret"`
 	AccessExpiration  int    `json:"access_expiration"`  // в минутах
 	RefreshExpiration int    `json:"refresh_expiration"` // в часах
+	// ChallengeExpiration срок действия токена подтверждения входа вторым фактором, в минутах
+	ChallengeExpiration int `json:"challenge_expiration"`
 }
 
 // RedisConfig настройки Redis
@@ -46,6 +65,104 @@ type RedisConfig struct {
 	DB       int    `json:"db"`
 }
 
+// OrdersConfig настройки заказов
+type OrdersConfig struct {
+	HoldTTL             int    `json:"hold_ttl"`              // время удержания мест неоплаченным заказом, в минутах
+	ExpirySweepInterval int    `json:"expiry_sweep_interval"` // периодичность снятия просроченных удержаний, в секундах
+	QuoteTTL            int    `json:"quote_ttl"`             // срок действия расчета стоимости, в минутах
+	QuoteSecret         string `json:"quote_secret"`          // ключ подписи расчетов; по умолчанию используется секрет JWT
+}
+
+// PaymentConfig настройки платежного провайдера
+type PaymentConfig struct {
+	Provider      string `json:"provider"`       // идентификатор провайдера, по умолчанию fake
+	WebhookSecret string `json:"webhook_secret"` // секрет для проверки подписи webhook
+}
+
+// CurrencyConfig настройки валют и курсов пересчета
+type CurrencyConfig struct {
+	Base  string             `json:"base"`  // базовая валюта, по умолчанию RUB
+	Rates map[string]float64 `json:"rates"` // количество единиц валюты за одну единицу базовой
+}
+
+// ScheduleConfig настройки генерации дат туров по расписаниям
+type ScheduleConfig struct {
+	HorizonDays        int `json:"horizon_days"`        // на сколько дней вперед создаются даты туров
+	GenerationInterval int `json:"generation_interval"` // периодичность генерации, в минутах
+}
+
+// PricingConfig настройки пересчета модификаторов цены
+type PricingConfig struct {
+	RecalculationInterval int `json:"recalculation_interval"` // периодичность пересчета по правилам, в минутах
+}
+
+// SearchConfig настройки полнотекстового поиска туров
+type SearchConfig struct {
+	Backend         string `json:"backend"`          // реализация индекса, по умолчанию memory
+	RebuildInterval int    `json:"rebuild_interval"` // периодичность полной переиндексации, в минутах
+}
+
+// CacheConfig настройки кэша каталога. Кэш хранится в Redis, а если он не
+// настроен или недоступен - в памяти процесса.
+type CacheConfig struct {
+	TourTTL    int `json:"tour_ttl"`    // срок жизни карточек и дат туров, в секундах
+	HotelTTL   int `json:"hotel_ttl"`   // срок жизни карточек отелей и списков номеров, в секундах
+	CatalogTTL int `json:"catalog_ttl"` // срок жизни справочников стран и городов, в секундах
+	RoleTTL    int `json:"role_ttl"`    // срок жизни ролей и их прав, в секундах
+}
+
+// MailConfig настройки доставки писем
+type MailConfig struct {
+	Driver    string `json:"driver"`     // log, file или smtp; по умолчанию log
+	From      string `json:"from"`       // адрес отправителя
+	OutboxDir string `json:"outbox_dir"` // каталог писем для способа file
+	Host      string `json:"host"`       // SMTP сервер
+	Port      string `json:"port"`       // порт SMTP сервера, по умолчанию 587
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+// AccountConfig настройки подтверждения email и восстановления пароля
+type AccountConfig struct {
+	AppURL          string `json:"app_url"`          // адрес фронтенда для ссылок в письмах
+	VerificationTTL int    `json:"verification_ttl"` // срок действия ссылки подтверждения email, в часах
+	ResetTTL        int    `json:"reset_ttl"`        // срок действия ссылки сброса пароля, в минутах
+}
+
+// RateLimitConfig ограничения частоты запросов. Окна хранятся в Redis,
+// а если он не настроен или недоступен - в памяти процесса.
+type RateLimitConfig struct {
+	Login        RateLimitRule `json:"login"`         // попытки входа с одного IP
+	TwoFactor    RateLimitRule `json:"two_factor"`    // ввод кодов второго фактора с одного IP
+	Register     RateLimitRule `json:"register"`      // регистрации с одного IP
+	AccountEmail RateLimitRule `json:"account_email"` // письма подтверждения и сброса пароля с одного IP
+	Tickets      RateLimitRule `json:"tickets"`       // создание тикетов одним пользователем
+	Orders       RateLimitRule `json:"orders"`        // создание заказов одним пользователем
+	Lockout      LockoutConfig `json:"lockout"`       // защита учетных записей от подбора пароля
+}
+
+// RateLimitRule лимит запросов в скользящем окне
+type RateLimitRule struct {
+	Limit  int `json:"limit"`  // допустимое число запросов в окне; 0 - по умолчанию, -1 - без ограничения
+	Window int `json:"window"` // размер окна, в секундах
+}
+
+// LockoutConfig задержки и временная блокировка после неудачных попыток входа
+type LockoutConfig struct {
+	Window       int `json:"window"`        // окно учета неудачных попыток, в минутах
+	FreeAttempts int `json:"free_attempts"` // неудачи без задержки
+	BaseDelay    int `json:"base_delay"`    // первая задержка, удваивается с каждой неудачей, в секундах
+	MaxDelay     int `json:"max_delay"`     // предел задержки, в секундах
+	MaxFailures  int `json:"max_failures"`  // неудачи в окне до блокировки учетной записи
+	Duration     int `json:"duration"`      // срок блокировки, в минутах
+}
+
+// TwoFactorConfig настройки двухфакторной аутентификации
+type TwoFactorConfig struct {
+	Issuer        string `json:"issuer"`         // название сервиса в приложении-аутентификаторе
+	EncryptionKey string `json:"encryption_key"` // ключ шифрования секретов; по умолчанию используется секрет JWT
+}
+
 // LoadConfig загружает конфигурацию из файла
 func LoadConfig(path string) (*Config, error) {
 	file, err := os.Open(path)
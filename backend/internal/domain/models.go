@@ -1,7 +1,14 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
 )
 
 // Country представляет страну
@@ -21,39 +28,58 @@ type City struct {
 
 // Hotel представляет отель
 type Hotel struct {
-	ID          int64     `db:"id" json:"id"`
-	CityID      int64     `db:"city_id" json:"city_id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	Address     string    `db:"address" json:"address"`
-	Category    int       `db:"category" json:"category"`
-	ImageURL    string    `db:"image_url" json:"image_url"`
-	IsActive    bool      `db:"is_active" json:"is_active"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ID          int64      `db:"id" json:"id"`
+	CityID      int64      `db:"city_id" json:"city_id"`
+	Name        string     `db:"name" json:"name"`
+	Description string     `db:"description" json:"description"`
+	Address     string     `db:"address" json:"address"`
+	Category    int        `db:"category" json:"category"`
+	ImageURL    string     `db:"image_url" json:"image_url"`
+	IsActive    bool       `db:"is_active" json:"is_active"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at" json:"deleted_at,omitempty"` // время мягкого удаления
 }
 
 // Room представляет номер в отеле
 type Room struct {
-	ID          int64     `db:"id" json:"id"`
-	HotelID     int64     `db:"hotel_id" json:"hotel_id"`
-	Description string    `db:"description" json:"description"`
-	Beds        int       `db:"beds" json:"beds"`
-	Price       float64   `db:"price" json:"price"`
-	ImageURL    string    `db:"image_url" json:"image_url"`
-	CreatedAt   time.Time `db:"created_at,omitempty" json:"created_at,omitempty"`
+	ID          int64        `db:"id" json:"id"`
+	HotelID     int64        `db:"hotel_id" json:"hotel_id"`
+	Description string       `db:"description" json:"description"`
+	Beds        int          `db:"beds" json:"beds"`
+	Units       int          `db:"units" json:"units"` // количество номеров этого типа в отеле
+	Price       money.Amount `db:"price" json:"price"`
+	Currency    string       `db:"currency" json:"currency"`
+	ImageURL    string       `db:"image_url" json:"image_url"`
+	CreatedAt   time.Time    `db:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time   `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// RoomNight занятость номеров одного типа в конкретную ночь
+type RoomNight struct {
+	RoomID    int64     `db:"room_id" json:"room_id"`
+	Night     time.Time `db:"night" json:"night"`
+	Booked    int       `db:"booked" json:"booked"`
+	Units     int       `db:"units" json:"units"`
+	Available int       `db:"-" json:"available"`
 }
 
 // Tour представляет тур
 type Tour struct {
-	ID          int64     `db:"id" json:"id"`
-	CityID      int64     `db:"city_id" json:"city_id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	BasePrice   float64   `db:"base_price" json:"base_price"`
-	ImageURL    string    `db:"image_url" json:"image_url"`
-	Duration    int       `db:"duration" json:"duration"`
-	IsActive    bool      `db:"is_active" json:"is_active"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ID          int64        `db:"id" json:"id"`
+	CityID      int64        `db:"city_id" json:"city_id"`
+	Name        string       `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	BasePrice   money.Amount `db:"base_price" json:"base_price"`
+	Currency    string       `db:"currency" json:"currency"`
+	ImageURL    string       `db:"image_url" json:"image_url"`
+	Duration    int          `db:"duration" json:"duration"`
+	IsActive    bool         `db:"is_active" json:"is_active"`
+	Version     int          `db:"version" json:"version"` // увеличивается при каждом изменении тура
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time   `db:"deleted_at" json:"deleted_at,omitempty"`
 
 	// Поля для отображения (не сохраняются в БД) - УДАЛЯЕМ СТАРЫЕ ПОЛЯ
 	// City     string `db:"-" json:"city,omitempty"`
@@ -68,33 +94,464 @@ type Tour struct {
 
 // TourDate представляет доступную дату тура
 type TourDate struct {
-	ID            int64     `db:"id" json:"id"`
-	TourID        int64     `db:"tour_id" json:"tour_id"`
-	StartDate     time.Time `db:"start_date" json:"start_date"`
-	EndDate       time.Time `db:"end_date" json:"end_date"`
-	Availability  int       `db:"availability" json:"availability"`
-	PriceModifier float64   `db:"price_modifier" json:"price_modifier"`
+	ID            int64       `db:"id" json:"id"`
+	TourID        int64       `db:"tour_id" json:"tour_id"`
+	StartDate     time.Time   `db:"start_date" json:"start_date"`
+	EndDate       time.Time   `db:"end_date" json:"end_date"`
+	Capacity      int         `db:"capacity" json:"capacity"` // общее количество мест; по умолчанию равно availability
+	Availability  int         `db:"availability" json:"availability"`
+	BaseModifier  money.Ratio `db:"base_modifier" json:"base_modifier"`   // модификатор, заданный вручную или расписанием
+	PriceModifier money.Ratio `db:"price_modifier" json:"price_modifier"` // итоговый модификатор с учетом правил ценообразования
+	// ScheduleID правило расписания, по которому создана дата; nil для дат, добавленных вручную
+	ScheduleID *int64     `db:"schedule_id" json:"schedule_id,omitempty"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt  *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// Nights количество ночей проживания в номере на эту дату тура
+func (d *TourDate) Nights() int {
+	return len(StayNights(d.StartDate, d.EndDate))
+}
+
+// StayNights возвращает даты ночей проживания с заезда from до выезда to
+func StayNights(from, to time.Time) []time.Time {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	var nights []time.Time
+	for night := from; night.Before(to); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night)
+	}
+	return nights
+}
+
+// TourSchedule правило расписания отправлений тура. По правилу генератор
+// создает даты тура на скользящий горизонт планирования.
+type TourSchedule struct {
+	ID       int64      `db:"id" json:"id"`
+	TourID   int64      `db:"tour_id" json:"tour_id"`
+	Name     string     `db:"name" json:"name"`
+	Weekdays WeekdaySet `db:"weekdays" json:"weekdays"` // дни недели отправления
+	// SeasonStart и SeasonEnd окно сезона (включительно); nil - без ограничения
+	SeasonStart   *time.Time  `db:"season_start" json:"season_start"`
+	SeasonEnd     *time.Time  `db:"season_end" json:"season_end"`
+	BlackoutDates DateList    `db:"blackout_dates" json:"blackout_dates"` // даты без отправлений
+	Capacity      int         `db:"capacity" json:"capacity"`             // количество мест на каждую дату
+	Modifiers     SeasonCurve `db:"price_modifiers" json:"price_modifiers"`
+	IsActive      bool        `db:"is_active" json:"is_active"`
+	CreatedAt     time.Time   `db:"created_at" json:"created_at"`
+}
+
+// Departs проверяет, есть ли по правилу отправление в день date
+func (s *TourSchedule) Departs(date time.Time) bool {
+	if !s.Weekdays.Contains(date.Weekday()) {
+		return false
+	}
+	if s.SeasonStart != nil && dateKey(date) < dateKey(*s.SeasonStart) {
+		return false
+	}
+	if s.SeasonEnd != nil && dateKey(date) > dateKey(*s.SeasonEnd) {
+		return false
+	}
+	return !s.BlackoutDates.Contains(date)
+}
+
+// PricingRuleKind вид правила ценообразования
+type PricingRuleKind string
+
+const (
+	PricingRuleSeason     PricingRuleKind = "season"      // отправление в период сезона
+	PricingRuleWeekend    PricingRuleKind = "weekend"     // отправление в субботу или воскресенье
+	PricingRuleOccupancy  PricingRuleKind = "occupancy"   // остаток мест ниже порога
+	PricingRuleLastMinute PricingRuleKind = "last_minute" // до отправления осталось мало дней
+)
+
+// PricingRule декларативное правило ценообразования. Модификаторы всех
+// подходящих правил перемножаются с базовым модификатором даты тура.
+type PricingRule struct {
+	ID       int64           `db:"id" json:"id"`
+	Name     string          `db:"name" json:"name"`
+	Kind     PricingRuleKind `db:"kind" json:"kind"`
+	Modifier money.Ratio     `db:"modifier" json:"modifier"`
+	// CountryID и CityID ограничивают правило турами страны или города; nil - все туры
+	CountryID         *int64     `db:"country_id" json:"country_id"`
+	CityID            *int64     `db:"city_id" json:"city_id"`
+	SeasonStart       *time.Time `db:"season_start" json:"season_start,omitempty"`             // для season, включительно
+	SeasonEnd         *time.Time `db:"season_end" json:"season_end,omitempty"`                 // для season, включительно
+	AvailabilityBelow *int       `db:"availability_below" json:"availability_below,omitempty"` // для occupancy, остаток мест в процентах вместимости
+	DaysBefore        *int       `db:"days_before" json:"days_before,omitempty"`               // для last_minute
+	IsActive          bool       `db:"is_active" json:"is_active"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PricingRuleMatch правило, сработавшее для даты тура
+type PricingRuleMatch struct {
+	RuleID   int64           `json:"rule_id"`
+	Name     string          `json:"name"`
+	Kind     PricingRuleKind `json:"kind"`
+	Modifier money.Ratio     `json:"modifier"`
+}
+
+// PriceModifierPreview результат расчета модификатора цены для даты тура
+type PriceModifierPreview struct {
+	TourDateID      int64              `json:"tour_date_id"`
+	TourID          int64              `json:"tour_id"`
+	StartDate       time.Time          `json:"start_date"`
+	Capacity        int                `json:"capacity"`
+	Availability    int                `json:"availability"`
+	BaseModifier    money.Ratio        `json:"base_modifier"`
+	CurrentModifier money.Ratio        `json:"current_modifier"`
+	Modifier        money.Ratio        `json:"modifier"` // модификатор после применения правил
+	Rules           []PricingRuleMatch `json:"rules"`
+}
+
+// TourSearchSource текстовые данные тура для полнотекстового индекса
+type TourSearchSource struct {
+	TourID      int64  `db:"tour_id"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	City        string `db:"city"`
+	Country     string `db:"country"`
+	Hotels      string `db:"hotels"` // названия активных отелей города тура через перевод строки
+}
+
+// TourSearchResult тур, найденный полнотекстовым поиском
+type TourSearchResult struct {
+	Tour  *Tour   `json:"tour"`
+	Score float64 `json:"score"`
+	// Highlights фрагменты полей (name, description, city, country, hotels) с выделенными тегом <mark> совпадениями
+	Highlights map[string]string `json:"highlights"`
+}
+
+// WeekdaySet набор дней недели. В JSON представляется массивом номеров дней
+// по ISO 8601 (1 - понедельник, 7 - воскресенье), в БД - битовой маской.
+type WeekdaySet uint8
+
+// isoWeekday номер дня недели по ISO 8601
+func isoWeekday(d time.Weekday) int {
+	if d == time.Sunday {
+		return 7
+	}
+	return int(d)
+}
+
+// Contains проверяет, входит ли день недели в набор
+func (w WeekdaySet) Contains(d time.Weekday) bool {
+	return w&(1<<(isoWeekday(d)-1)) != 0
+}
+
+// MarshalJSON представляет набор массивом номеров дней
+func (w WeekdaySet) MarshalJSON() ([]byte, error) {
+	days := make([]int, 0, 7)
+	for day := 1; day <= 7; day++ {
+		if w&(1<<(day-1)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return json.Marshal(days)
+}
+
+// UnmarshalJSON разбирает массив номеров дней
+func (w *WeekdaySet) UnmarshalJSON(b []byte) error {
+	var days []int
+	if err := json.Unmarshal(b, &days); err != nil {
+		return err
+	}
+
+	var set WeekdaySet
+	for _, day := range days {
+		if day < 1 || day > 7 {
+			return fmt.Errorf("некорректный день недели: %d", day)
+		}
+		set |= 1 << (day - 1)
+	}
+	*w = set
+	return nil
+}
+
+// Value сохраняет набор в TINYINT колонку
+func (w WeekdaySet) Value() (driver.Value, error) {
+	return int64(w), nil
 }
 
-// Role представляет роль пользователя
+// Scan читает набор из TINYINT колонки
+func (w *WeekdaySet) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*w = WeekdaySet(v)
+	case []byte:
+		var n uint8
+		if _, err := fmt.Sscan(string(v), &n); err != nil {
+			return err
+		}
+		*w = WeekdaySet(n)
+	default:
+		return fmt.Errorf("неподдерживаемый тип дней недели: %T", src)
+	}
+	return nil
+}
+
+// dateLayout формат календарной даты
+const dateLayout = "2006-01-02"
+
+// dateKey календарная дата в виде строки, пригодной для сравнения
+func dateKey(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// DateList список календарных дат. В JSON и БД представляется массивом
+// строк в формате YYYY-MM-DD.
+type DateList []time.Time
+
+// Contains проверяет, входит ли календарная дата в список
+func (l DateList) Contains(date time.Time) bool {
+	key := dateKey(date)
+	for _, d := range l {
+		if dateKey(d) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON представляет список массивом дат YYYY-MM-DD
+func (l DateList) MarshalJSON() ([]byte, error) {
+	dates := make([]string, 0, len(l))
+	for _, d := range l {
+		dates = append(dates, dateKey(d))
+	}
+	return json.Marshal(dates)
+}
+
+// UnmarshalJSON разбирает массив дат YYYY-MM-DD
+func (l *DateList) UnmarshalJSON(b []byte) error {
+	var dates []string
+	if err := json.Unmarshal(b, &dates); err != nil {
+		return err
+	}
+
+	list := make(DateList, 0, len(dates))
+	for _, s := range dates {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return fmt.Errorf("некорректная дата %q: %w", s, err)
+		}
+		list = append(list, d)
+	}
+	*l = list
+	return nil
+}
+
+// Value сохраняет список в JSON колонку
+func (l DateList) Value() (driver.Value, error) {
+	return l.MarshalJSON()
+}
+
+// Scan читает список из JSON колонки
+func (l *DateList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return l.UnmarshalJSON(v)
+	case string:
+		return l.UnmarshalJSON([]byte(v))
+	default:
+		return fmt.Errorf("неподдерживаемый тип списка дат: %T", src)
+	}
+}
+
+// SeasonCurve сезонная кривая цен: модификатор цены по номеру месяца (1-12).
+// Для месяцев, отсутствующих в кривой, модификатор равен единице.
+type SeasonCurve map[int]money.Ratio
+
+// At возвращает модификатор цены для даты
+func (c SeasonCurve) At(date time.Time) money.Ratio {
+	if r, ok := c[int(date.Month())]; ok {
+		return r
+	}
+	return money.One
+}
+
+// Value сохраняет кривую в JSON колонку
+func (c SeasonCurve) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan читает кривую из JSON колонки
+func (c *SeasonCurve) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("неподдерживаемый тип сезонной кривой: %T", src)
+	}
+}
+
+// Permission право на действие в системе. Права назначаются ролям,
+// справочник прав хранится в таблице permissions.
+type Permission string
+
+const (
+	PermUsersRead       Permission = "users:read"       // просмотр пользователей
+	PermUsersWrite      Permission = "users:write"      // изменение, удаление и восстановление пользователей
+	PermRolesManage     Permission = "roles:manage"     // управление ролями и назначение ролей пользователям
+	PermToursWrite      Permission = "tours:write"      // туры, даты и расписания
+	PermHotelsWrite     Permission = "hotels:write"     // отели и номера
+	PermPricingWrite    Permission = "pricing:write"    // правила ценообразования
+	PermPromotionsWrite Permission = "promotions:write" // промоакции
+	PermOrdersRead      Permission = "orders:read"      // все заказы и история их статусов
+	PermOrdersWrite     Permission = "orders:write"     // смена статуса заказа
+	PermOrdersRefund    Permission = "orders:refund"    // отмена оплаченного заказа с возвратом средств
+	PermTicketsRead     Permission = "tickets:read"     // все тикеты и ответы в них
+	PermTicketsAssign   Permission = "tickets:assign"   // взятие тикетов в работу и смена статуса
+	PermSystemManage    Permission = "system:manage"    // переиндексация поиска и статистика кэша
+)
+
+// PermissionInfo право из справочника с описанием
+type PermissionInfo struct {
+	Name        Permission `db:"name" json:"name"`
+	Description string     `db:"description" json:"description"`
+}
+
+// Role представляет роль пользователя и назначенные ей права
 type Role struct {
-	ID   int64  `db:"id" json:"id"`
-	Name string `db:"name" json:"name"`
+	ID          int64        `db:"id" json:"id"`
+	Name        string       `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	IsSystem    bool         `db:"is_system" json:"is_system"`   // системную роль нельзя переименовать или удалить
+	IsDefault   bool         `db:"is_default" json:"is_default"` // роль назначается при регистрации
+	Permissions []Permission `db:"-" json:"permissions"`
+}
+
+// RequiresTwoFactor сообщает, что роль привилегированная: при наличии
+// хотя бы одного права ее права действуют только в сессии, подтвержденной
+// вторым фактором
+func (r *Role) RequiresTwoFactor() bool {
+	return len(r.Permissions) > 0
+}
+
+// Can сообщает, есть ли у роли право
+func (r *Role) Can(perm Permission) bool {
+	for _, p := range r.Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
 }
 
 // User представляет пользователя системы
 type User struct {
-	ID        int64     `db:"id" json:"id"`
-	Username  string    `db:"username" json:"username"`
-	Password  string    `db:"password" json:"-"`
-	Email     string    `db:"email" json:"email"`
-	FirstName string    `db:"first_name" json:"first_name"`
-	LastName  string    `db:"last_name" json:"last_name"`
-	FullName  string    `db:"full_name" json:"full_name"`
-	Phone     string    `db:"phone" json:"phone"`
-	BirthDate string    `db:"birth_date" json:"birth_date"`
-	RoleID    int64     `db:"role_id" json:"role_id"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID        int64  `db:"id" json:"id"`
+	Username  string `db:"username" json:"username"`
+	Password  string `db:"password" json:"-"`
+	Email     string `db:"email" json:"email"`
+	FirstName string `db:"first_name" json:"first_name"`
+	LastName  string `db:"last_name" json:"last_name"`
+	FullName  string `db:"full_name" json:"full_name"`
+	Phone     string `db:"phone" json:"phone"`
+	BirthDate string `db:"birth_date" json:"birth_date"`
+	RoleID    int64  `db:"role_id" json:"role_id"`
+	// TokenVersion увеличивается при смене пароля, роли или удалении;
+	// access токены с другой версией считаются отозванными
+	TokenVersion int `db:"token_version" json:"-"`
+	// EmailVerifiedAt время подтверждения email; nil - адрес не подтвержден
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// Role роль с правами; заполняется при проверке токена
+	Role *Role `db:"-" json:"role,omitempty"`
+	// TwoFactorVerified сессия подтверждена вторым фактором; заполняется при проверке токена
+	TwoFactorVerified bool `db:"-" json:"-"`
+}
+
+// Can сообщает, есть ли у пользователя право через его роль.
+// Права привилегированной роли требуют сессии, подтвержденной вторым фактором.
+func (u *User) Can(perm Permission) bool {
+	if u.Role == nil || !u.Role.Can(perm) {
+		return false
+	}
+	return u.TwoFactorVerified || !u.Role.RequiresTwoFactor()
+}
+
+// RefreshToken представляет выданный refresh токен (сессию входа)
+type RefreshToken struct {
+	ID         string     `db:"id" json:"id"` // jti токена
+	UserID     int64      `db:"user_id" json:"user_id"`
+	FamilyID   string     `db:"family_id" json:"family_id"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	ReplacedBy *string    `db:"replaced_by" json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// AccountTokenPurpose назначение одноразового токена учетной записи
+type AccountTokenPurpose string
+
+const (
+	AccountTokenVerifyEmail   AccountTokenPurpose = "verify_email"   // подтверждение email
+	AccountTokenResetPassword AccountTokenPurpose = "reset_password" // сброс пароля
+)
+
+// AccountToken одноразовый токен из письма. Сам токен не хранится,
+// только его SHA-256, поэтому утечка таблицы не дает доступа к учетным записям.
+type AccountToken struct {
+	ID        int64               `db:"id" json:"id"`
+	UserID    int64               `db:"user_id" json:"user_id"`
+	Purpose   AccountTokenPurpose `db:"purpose" json:"purpose"`
+	Email     string              `db:"email" json:"email"` // адрес, на который отправлен токен
+	TokenHash string              `db:"token_hash" json:"-"`
+	ExpiresAt time.Time           `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time          `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time           `db:"created_at" json:"created_at"`
+}
+
+// TwoFactor настройка двухфакторной аутентификации пользователя
+type TwoFactor struct {
+	UserID int64 `db:"user_id" json:"user_id"`
+	// Secret секрет TOTP, зашифрованный ключом приложения
+	Secret      string     `db:"secret" json:"-"`
+	ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmed_at,omitempty"` // nil - подключение не подтверждено
+	LastStep    int64      `db:"last_step" json:"-"`                         // шаг времени последнего принятого кода
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TwoFactorStatus состояние двухфакторной аутентификации для пользователя
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`            // обязательна для роли пользователя
+	RecoveryCodesLeft int  `json:"recovery_codes_left"` // неиспользованные коды восстановления
+}
+
+// TwoFactorEnrollment данные для подключения приложения-аутентификатора.
+// Секрет и коды восстановления показываются только один раз.
+type TwoFactorEnrollment struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"` // содержимое QR-кода
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginResult результат входа по паролю: пара токенов или, если у пользователя
+// включена двухфакторная аутентификация, токен подтверждения, который
+// обменивается на пару токенов по коду
+type LoginResult struct {
+	AccessToken       string
+	RefreshToken      string
+	ChallengeToken    string
+	TwoFactorRequired bool
+	// TwoFactorSetupRequired роль требует двухфакторной аутентификации, но она не подключена;
+	// до подключения права роли не действуют
+	TwoFactorSetupRequired bool
 }
 
 // OrderStatus представляет статус заказа
@@ -108,17 +565,185 @@ const (
 	OrderStatusCompleted OrderStatus = "completed"
 )
 
+// IsValid проверяет, что статус заказа известен системе
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPaid, OrderStatusCancelled, OrderStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// PaymentStatus представляет статус платежа
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "pending"
+	PaymentStatusProcessing PaymentStatus = "processing" // авторизация принята, идет списание и оплата заказа
+	PaymentStatusSucceeded  PaymentStatus = "succeeded"
+	PaymentStatusFailed     PaymentStatus = "failed"
+	PaymentStatusRefunded   PaymentStatus = "refunded"
+)
+
+// Payment представляет платеж по заказу через платежного провайдера
+type Payment struct {
+	ID                int64        `db:"id" json:"id"`
+	OrderID           int64        `db:"order_id" json:"order_id"`
+	Provider          string       `db:"provider" json:"provider"`
+	ProviderPaymentID string       `db:"provider_payment_id" json:"provider_payment_id"`
+	Amount            money.Amount `db:"amount" json:"amount"`
+	Currency          string       `db:"currency" json:"currency"`
+	Status            string       `db:"status" json:"status"`
+	CreatedAt         time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// OrderStatusChange запись истории изменения статуса заказа
+type OrderStatusChange struct {
+	ID         int64   `db:"id" json:"id"`
+	OrderID    int64   `db:"order_id" json:"order_id"`
+	FromStatus *string `db:"from_status" json:"from_status"` // nil для создания заказа
+	ToStatus   string  `db:"to_status" json:"to_status"`
+	// ChangedBy пользователь, изменивший статус; nil для системных изменений
+	ChangedBy         *int64    `db:"changed_by" json:"changed_by"`
+	ChangedByUsername *string   `db:"changed_by_username" json:"changed_by_username,omitempty"`
+	Comment           string    `db:"comment" json:"comment"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+}
+
 // Order представляет заказ
 type Order struct {
-	ID          int64     `db:"id" json:"id"`
-	UserID      int64     `db:"user_id" json:"user_id"`
-	TourID      int64     `db:"tour_id" json:"tour_id"`
-	TourDateID  int64     `db:"tour_date_id" json:"tour_date_id"`
-	RoomID      *int64    `db:"room_id" json:"room_id"`
-	PeopleCount int       `db:"people_count" json:"people_count"`
-	TotalPrice  float64   `db:"total_price" json:"total_price"`
-	Status      string    `db:"status" json:"status"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ID          int64  `db:"id" json:"id"`
+	UserID      int64  `db:"user_id" json:"user_id"`
+	TourID      int64  `db:"tour_id" json:"tour_id"`
+	TourDateID  int64  `db:"tour_date_id" json:"tour_date_id"`
+	RoomID      *int64 `db:"room_id" json:"room_id"`
+	PeopleCount int    `db:"people_count" json:"people_count"`
+	// ChildrenCount сколько из PeopleCount составляют дети
+	ChildrenCount int          `db:"children_count" json:"children_count"`
+	TotalPrice    money.Amount `db:"total_price" json:"total_price"`
+	Currency      string       `db:"currency" json:"currency"`
+	Status        string       `db:"status" json:"status"`
+	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
+	// ExpiresAt до этого момента неоплаченный заказ удерживает места
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	// PriceBreakdown расшифровка стоимости на момент оформления; nil для старых заказов
+	PriceBreakdown *PriceBreakdown `db:"price_breakdown" json:"price_breakdown,omitempty"`
+	// PaymentID платеж, которым оплачен заказ; nil - заказ не оплачивался через провайдера
+	PaymentID *int64 `db:"payment_id" json:"payment_id,omitempty"`
+}
+
+// PriceDiscount скидка, примененная при расчете стоимости заказа
+type PriceDiscount struct {
+	PromotionID int64        `json:"promotion_id"`
+	Code        string       `json:"code,omitempty"` // пусто для автоматических правил
+	Title       string       `json:"title"`
+	Amount      money.Amount `json:"amount"`
+}
+
+// PriceBreakdown расшифровка стоимости заказа, рассчитанной сервером
+type PriceBreakdown struct {
+	Currency       string          `json:"currency"`       // валюта всех сумм расшифровки (валюта тура)
+	BasePrice      money.Amount    `json:"base_price"`     // базовая цена тура за человека
+	PriceModifier  money.Ratio     `json:"price_modifier"` // модификатор цены выбранной даты
+	PeopleCount    int             `json:"people_count"`
+	ChildrenCount  int             `json:"children_count"`
+	TourAmount     money.Amount    `json:"tour_amount"` // base_price * price_modifier * people_count
+	RoomPrice      money.Amount    `json:"room_price"`  // цена номера за ночь в валюте тура
+	RoomNights     int             `json:"room_nights"`
+	RoomAmount     money.Amount    `json:"room_amount"`
+	Discounts      []PriceDiscount `json:"discounts"`
+	DiscountAmount money.Amount    `json:"discount_amount"`
+	Total          money.Amount    `json:"total"`
+}
+
+// Value сохраняет расшифровку стоимости в JSON колонку
+func (b PriceBreakdown) Value() (driver.Value, error) {
+	return json.Marshal(b)
+}
+
+// Scan читает расшифровку стоимости из JSON колонки
+func (b *PriceBreakdown) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("неподдерживаемый тип расшифровки стоимости: %T", src)
+	}
+}
+
+// PriceQuote подписанный расчет стоимости, который клиент передает при оформлении заказа
+type PriceQuote struct {
+	Token      string          `json:"quote_token"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	TotalPrice money.Amount    `json:"total_price"`
+	Currency   string          `json:"currency"`
+	Breakdown  *PriceBreakdown `json:"breakdown"`
+}
+
+// OrderRequest параметры заказа, по которым рассчитывается стоимость
+type OrderRequest struct {
+	UserID        int64
+	TourID        int64
+	TourDateID    int64
+	RoomID        *int64
+	PeopleCount   int
+	ChildrenCount int    // сколько из PeopleCount составляют дети
+	PromoCode     string // необязательный промокод
+}
+
+// PromotionType способ расчета скидки
+type PromotionType string
+
+const (
+	PromotionTypePercent PromotionType = "percent" // процент от стоимости
+	PromotionTypeFixed   PromotionType = "fixed"   // фиксированная сумма
+)
+
+// PromotionTarget часть стоимости, к которой применяется скидка
+type PromotionTarget string
+
+const (
+	PromotionTargetOrder    PromotionTarget = "order"    // вся стоимость заказа
+	PromotionTargetChildren PromotionTarget = "children" // стоимость тура для детей
+)
+
+// Promotion промоакция: скидка по промокоду или автоматическое правило
+// (раннее бронирование, групповая скидка, детский тариф)
+type Promotion struct {
+	ID           int64           `db:"id" json:"id"`
+	Code         *string         `db:"code" json:"code"` // nil - правило применяется без промокода
+	Title        string          `db:"title" json:"title"`
+	Description  string          `db:"description" json:"description"`
+	DiscountType PromotionType   `db:"discount_type" json:"discount_type"`
+	Percent      money.Ratio     `db:"percent" json:"percent"` // размер скидки в процентах для типа percent
+	Amount       money.Amount    `db:"amount" json:"amount"`   // размер скидки для типа fixed (для детского тарифа - за ребенка)
+	Currency     string          `db:"currency" json:"currency"`
+	Target       PromotionTarget `db:"target" json:"target"`
+	TourID       *int64          `db:"tour_id" json:"tour_id"` // nil - действует для всех туров
+	// MinPeople минимальное количество человек в заказе (групповая скидка)
+	MinPeople int `db:"min_people" json:"min_people"`
+	// EarlyBirdDays минимальное количество дней до начала тура (раннее бронирование)
+	EarlyBirdDays  int        `db:"early_bird_days" json:"early_bird_days"`
+	MaxUses        *int       `db:"max_uses" json:"max_uses"`                   // общий лимит применений
+	MaxUsesPerUser *int       `db:"max_uses_per_user" json:"max_uses_per_user"` // лимит применений одним пользователем
+	StartsAt       *time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt         *time.Time `db:"ends_at" json:"ends_at"`
+	IsActive       bool       `db:"is_active" json:"is_active"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PromotionRedemption применение промоакции к заказу
+type PromotionRedemption struct {
+	ID          int64        `db:"id" json:"id"`
+	PromotionID int64        `db:"promotion_id" json:"promotion_id"`
+	OrderID     int64        `db:"order_id" json:"order_id"`
+	UserID      int64        `db:"user_id" json:"user_id"`
+	Amount      money.Amount `db:"amount" json:"amount"`
+	Currency    string       `db:"currency" json:"currency"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
 }
 
 // TicketStatus представляет статус тикета поддержки
@@ -150,12 +775,334 @@ type TicketMessage struct {
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
-// TourFilter содержит параметры для фильтрации туров
+// FieldError ошибка в значении отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError набор ошибок полей запроса
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Add добавляет ошибку поля
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err возвращает ошибку, если есть хотя бы одно некорректное поле, иначе nil
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error перечисляет некорректные поля
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "некорректные параметры запроса: " + strings.Join(parts, "; ")
+}
+
+const (
+	// DefaultPageSize размер страницы по умолчанию
+	DefaultPageSize = 10
+	// MaxPageSize максимальный размер страницы
+	MaxPageSize = 100
+)
+
+// Page параметры постраничного вывода. Нулевые значения заменяются значениями по умолчанию.
+// Без курсора страница выбирается смещением по номеру; с курсором - записи после
+// курсора в порядке времени создания и ID, а общее количество не подсчитывается.
+type Page struct {
+	Number int `json:"page"`
+	Size   int `json:"size"`
+	// Cursor курсор, после которого начинается страница; нулевой курсор - первая страница
+	Cursor *pagination.Cursor `json:"-"`
+}
+
+// Offset возвращает количество пропускаемых записей
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// IsCursor сообщает, что страница выбирается по курсору
+func (p Page) IsCursor() bool {
+	return p.Cursor != nil
+}
+
+// Validate проверяет параметры страницы списка без фильтров
+func (p *Page) Validate() error {
+	var errs ValidationError
+	p.validate(&errs)
+	return errs.Err()
+}
+
+// validate подставляет значения по умолчанию и проверяет границы
+func (p *Page) validate(errs *ValidationError) {
+	if p.Number == 0 {
+		p.Number = 1
+	}
+	if p.Size == 0 {
+		p.Size = DefaultPageSize
+	}
+	if p.Number < 1 {
+		errs.Add("page", "must be at least 1")
+	}
+	if p.Cursor != nil && p.Number > 1 {
+		errs.Add("page", "cannot be combined with cursor")
+	}
+	if p.Size < 1 || p.Size > MaxPageSize {
+		errs.Add("size", fmt.Sprintf("must be between 1 and %d", MaxPageSize))
+	}
+}
+
+// PageInfo сведения о полученной странице списка
+type PageInfo struct {
+	Total      *int   // общее количество записей; подсчитывается только при выборке со смещением
+	NextCursor string // курсор следующей страницы; пустой на последней странице
+}
+
+// SortOrder направление сортировки
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Поля сортировки списка туров
+const (
+	TourSortPrice     = "price"
+	TourSortDuration  = "duration"
+	TourSortName      = "name"
+	TourSortCreatedAt = "created_at"
+)
+
+// TourFilter параметры фильтрации и сортировки списка туров
 type TourFilter struct {
-	CityID    *int     `json:"city_id"`
-	CountryID *int     `json:"country_id"`
-	PriceMin  *float64 `json:"price_min"`
-	PriceMax  *float64 `json:"price_max"`
-	Limit     int      `json:"limit"`
-	Offset    int      `json:"offset"`
+	CityID    *int64
+	CountryID *int64
+	// PriceMin и PriceMax задают границы цены в каждой валюте туров
+	PriceMin        map[string]money.Amount
+	PriceMax        map[string]money.Amount
+	Search          string
+	DurationMin     *int
+	DurationMax     *int
+	StartDateAfter  *time.Time
+	StartDateBefore *time.Time
+	SortBy          string // одно из TourSort*; по умолчанию сначала новые туры
+	SortOrder       SortOrder
+	// IDs ограничивает список турами, найденными полнотекстовым поиском по Search;
+	// при сортировке по умолчанию туры выводятся в порядке релевантности
+	IDs []int64
+	// Deleted выбирает удаленные туры вместо действующих (для администратора)
+	Deleted bool
+	Page
+}
+
+// Validate проверяет фильтр и подставляет значения по умолчанию
+func (f *TourFilter) Validate() error {
+	var errs ValidationError
+	f.Page.validate(&errs)
+
+	if f.CityID != nil && *f.CityID < 1 {
+		errs.Add("cityId", "must be a positive ID")
+	}
+	if f.CountryID != nil && *f.CountryID < 1 {
+		errs.Add("countryId", "must be a positive ID")
+	}
+	for currency, min := range f.PriceMin {
+		if min < 0 {
+			errs.Add("priceMin", "must not be negative")
+			break
+		}
+		if max, ok := f.PriceMax[currency]; ok && max < min {
+			errs.Add("priceMax", "must not be less than priceMin")
+			break
+		}
+	}
+	for _, max := range f.PriceMax {
+		if max <= 0 {
+			errs.Add("priceMax", "must be positive")
+			break
+		}
+	}
+	if f.DurationMin != nil && *f.DurationMin < 1 {
+		errs.Add("durationMin", "must be positive")
+	}
+	if f.DurationMax != nil && *f.DurationMax < 1 {
+		errs.Add("durationMax", "must be positive")
+	}
+	if f.DurationMin != nil && f.DurationMax != nil && *f.DurationMax < *f.DurationMin {
+		errs.Add("durationMax", "must not be less than durationMin")
+	}
+	if f.StartDateAfter != nil && f.StartDateBefore != nil && f.StartDateBefore.Before(*f.StartDateAfter) {
+		errs.Add("startDateBefore", "must not be before startDateAfter")
+	}
+	switch f.SortBy {
+	case "", TourSortPrice, TourSortDuration, TourSortName, TourSortCreatedAt:
+	default:
+		errs.Add("sortBy", "must be one of price, duration, name, created_at")
+	}
+	// Курсор задает позицию по времени создания, поэтому другая сортировка с ним невозможна
+	if f.Cursor != nil && f.SortBy != "" {
+		errs.Add("sortBy", "cannot be combined with cursor")
+	}
+	switch f.SortOrder {
+	case "":
+		f.SortOrder = SortAsc
+	case SortAsc, SortDesc:
+	default:
+		errs.Add("sortOrder", "must be asc or desc")
+	}
+
+	return errs.Err()
+}
+
+// TourDurationFacetBuckets интервалы длительности тура в днях для фасета durations;
+// верхняя граница 0 означает интервал без ограничения сверху
+var TourDurationFacetBuckets = [][2]int{{1, 3}, {4, 7}, {8, 14}, {15, 0}}
+
+// TourFacetOptions параметры расчета фасетов списка туров
+type TourFacetOptions struct {
+	// Currency валюта, в которой выводятся границы ценовых интервалов
+	Currency string
+	// PriceEdges возрастающие границы ценовых интервалов, каждая пересчитана во
+	// все валюты туров; последний интервал не ограничен сверху
+	PriceEdges []map[string]money.Amount
+}
+
+// FacetCount число туров с заданным значением атрибута
+type FacetCount struct {
+	ID    int64  `db:"id" json:"id"`
+	Name  string `db:"name" json:"name"`
+	Count int    `db:"count" json:"count"`
+}
+
+// DurationFacet число туров с длительностью в интервале [Min, Max]
+type DurationFacet struct {
+	Min   int  `json:"min"`
+	Max   *int `json:"max,omitempty"` // не задан для последнего интервала
+	Count int  `json:"count"`
+}
+
+// PriceFacet число туров с ценой в интервале [Min, Max)
+type PriceFacet struct {
+	Min      money.Amount  `json:"min"`
+	Max      *money.Amount `json:"max,omitempty"` // не задан для последнего интервала
+	Currency string        `json:"currency"`
+	Count    int           `json:"count"`
+}
+
+// CategoryFacet число туров, в городе которых есть отель заданной категории
+type CategoryFacet struct {
+	Category int `db:"category" json:"category"`
+	Count    int `db:"count" json:"count"`
+}
+
+// MonthFacet число туров со свободными местами на даты, начинающиеся в месяце
+type MonthFacet struct {
+	Month string `db:"month" json:"month"` // YYYY-MM
+	Count int    `db:"count" json:"count"`
+}
+
+// TourFacets количество туров по значениям атрибутов с учетом текущих фильтров
+type TourFacets struct {
+	Countries  []FacetCount    `json:"countries"`
+	Cities     []FacetCount    `json:"cities"`
+	Durations  []DurationFacet `json:"durations"`
+	Prices     []PriceFacet    `json:"prices"`
+	Categories []CategoryFacet `json:"categories"`
+	Months     []MonthFacet    `json:"months"`
+}
+
+// HotelFilter параметры фильтрации списка отелей
+type HotelFilter struct {
+	CityID      *int64
+	CountryID   *int64
+	CategoryMin *int
+	CategoryMax *int
+	Deleted     bool // удаленные отели вместо действующих
+	Page
+}
+
+// Validate проверяет фильтр и подставляет значения по умолчанию
+func (f *HotelFilter) Validate() error {
+	var errs ValidationError
+	f.Page.validate(&errs)
+
+	if f.CityID != nil && *f.CityID < 1 {
+		errs.Add("city_id", "must be a positive ID")
+	}
+	if f.CountryID != nil && *f.CountryID < 1 {
+		errs.Add("country_id", "must be a positive ID")
+	}
+	if f.CategoryMin != nil && (*f.CategoryMin < 1 || *f.CategoryMin > 5) {
+		errs.Add("category_min", "must be between 1 and 5")
+	}
+	if f.CategoryMax != nil && (*f.CategoryMax < 1 || *f.CategoryMax > 5) {
+		errs.Add("category_max", "must be between 1 and 5")
+	}
+	if f.CategoryMin != nil && f.CategoryMax != nil && *f.CategoryMax < *f.CategoryMin {
+		errs.Add("category_max", "must not be less than category_min")
+	}
+
+	return errs.Err()
+}
+
+// OrderFilter параметры фильтрации списка заказов
+type OrderFilter struct {
+	UserID *int64
+	TourID *int64
+	Status *OrderStatus
+	Page
+}
+
+// Validate проверяет фильтр и подставляет значения по умолчанию
+func (f *OrderFilter) Validate() error {
+	var errs ValidationError
+	f.Page.validate(&errs)
+
+	if f.UserID != nil && *f.UserID < 1 {
+		errs.Add("user_id", "must be a positive ID")
+	}
+	if f.TourID != nil && *f.TourID < 1 {
+		errs.Add("tour_id", "must be a positive ID")
+	}
+	if f.Status != nil && !f.Status.IsValid() {
+		errs.Add("status", "unknown order status")
+	}
+
+	return errs.Err()
+}
+
+// TicketFilter параметры фильтрации списка тикетов поддержки
+type TicketFilter struct {
+	UserID *int64
+	Status *TicketStatus
+	Page
+}
+
+// Validate проверяет фильтр и подставляет значения по умолчанию
+func (f *TicketFilter) Validate() error {
+	var errs ValidationError
+	f.Page.validate(&errs)
+
+	if f.UserID != nil && *f.UserID < 1 {
+		errs.Add("user_id", "must be a positive ID")
+	}
+	if f.Status != nil {
+		switch *f.Status {
+		case TicketStatusOpen, TicketStatusInProgress, TicketStatusClosed:
+		default:
+			errs.Add("status", "unknown ticket status")
+		}
+	}
+
+	return errs.Err()
 }
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+)
+
+// @Summary Get deleted tours (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a paginated list of archived tours, newest first
+// @Tags admin-tours
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of deleted tours, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/deleted [get]
+func (h *Handler) getDeletedTours(c *gin.Context) {
+	p := newQueryParser(c)
+	filter := &domain.TourFilter{Deleted: true, Page: p.Page()}
+	if !p.Check(filter.Validate()) {
+		return
+	}
+
+	tours, info, err := h.services.Tour.List(c.Request.Context(), filter)
+	if err != nil {
+		newListErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse("tours", tours, info))
+}
+
+// @Summary Restore a deleted tour (Admin only)
+// @Security ApiKeyAuth
+// @Description Restore an archived tour together with the dates deleted along with it
+// @Tags admin-tours
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Deleted tour not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/restore [post]
+func (h *Handler) restoreTour(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+
+	if err := h.services.Tour.Restore(c.Request.Context(), id); err != nil {
+		newRestoreErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get deleted tour dates (Admin only)
+// @Security ApiKeyAuth
+// @Description Get archived dates of a tour
+// @Tags admin-tours
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Success 200 {array} domain.TourDate
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/dates/deleted [get]
+func (h *Handler) getDeletedTourDates(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+
+	dates, err := h.services.Tour.ListDeletedTourDates(c.Request.Context(), tourID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dates)
+}
+
+// @Summary Restore a deleted tour date (Admin only)
+// @Security ApiKeyAuth
+// @Description Restore an archived date of a tour
+// @Tags admin-tours
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param dateId path int true "Tour Date ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid tour ID or date ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Deleted tour date not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/dates/{dateId}/restore [post]
+func (h *Handler) restoreTourDate(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+	dateID, err := strconv.ParseInt(c.Param("dateId"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour date ID")
+		return
+	}
+
+	if err := h.services.Tour.RestoreTourDate(c.Request.Context(), tourID, dateID); err != nil {
+		newRestoreErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get deleted hotels (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a paginated list of archived hotels, newest first
+// @Tags admin-hotels
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of deleted hotels, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/hotels/deleted [get]
+func (h *Handler) getDeletedHotels(c *gin.Context) {
+	p := newQueryParser(c)
+	filter := &domain.HotelFilter{Deleted: true, Page: p.Page()}
+	if !p.Check(filter.Validate()) {
+		return
+	}
+
+	hotels, info, err := h.services.Hotel.List(c.Request.Context(), filter)
+	if err != nil {
+		newListErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse("hotels", hotels, info))
+}
+
+// @Summary Restore a deleted hotel (Admin only)
+// @Security ApiKeyAuth
+// @Description Restore an archived hotel together with the rooms deleted along with it
+// @Tags admin-hotels
+// @Produce json
+// @Param id path int true "Hotel ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid hotel ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Deleted hotel not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/hotels/{id}/restore [post]
+func (h *Handler) restoreHotel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid hotel ID")
+		return
+	}
+
+	if err := h.services.Hotel.Restore(c.Request.Context(), id); err != nil {
+		newRestoreErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get deleted hotel rooms (Admin only)
+// @Security ApiKeyAuth
+// @Description Get archived rooms of a hotel
+// @Tags admin-hotels
+// @Produce json
+// @Param id path int true "Hotel ID"
+// @Success 200 {array} domain.Room
+// @Failure 400 {object} ErrorResponse "Invalid hotel ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/hotels/{id}/rooms/deleted [get]
+func (h *Handler) getDeletedRooms(c *gin.Context) {
+	hotelID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid hotel ID")
+		return
+	}
+
+	rooms, err := h.services.Hotel.ListDeletedRooms(c.Request.Context(), hotelID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+// @Summary Restore a deleted room (Admin only)
+// @Security ApiKeyAuth
+// @Description Restore an archived room of a hotel
+// @Tags admin-hotels
+// @Produce json
+// @Param id path int true "Hotel ID"
+// @Param roomId path int true "Room ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid hotel ID or room ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Deleted room not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/hotels/{id}/rooms/{roomId}/restore [post]
+func (h *Handler) restoreRoom(c *gin.Context) {
+	hotelID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid hotel ID")
+		return
+	}
+	roomID, err := strconv.ParseInt(c.Param("roomId"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	if err := h.services.Hotel.RestoreRoom(c.Request.Context(), hotelID, roomID); err != nil {
+		newRestoreErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get deleted users (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a paginated list of archived users, newest first
+// @Tags admin-users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of deleted users, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/deleted [get]
+func (h *Handler) getDeletedUsers(c *gin.Context) {
+	p := newQueryParser(c)
+	page := p.Page()
+	if !p.Check(page.Validate()) {
+		return
+	}
+
+	users, info, err := h.services.User.ListDeleted(c.Request.Context(), page)
+	if err != nil {
+		newListErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse("users", users, info))
+}
+
+// @Summary Restore a deleted user (Admin only)
+// @Security ApiKeyAuth
+// @Description Restore an archived user account; the user has to log in again
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Deleted user not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/users/{id}/restore [post]
+func (h *Handler) restoreUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.services.User.Restore(c.Request.Context(), id); err != nil {
+		newRestoreErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newRestoreErrorResponse отвечает на ошибку восстановления из архива
+func newRestoreErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotDeleted) {
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
@@ -1,13 +1,14 @@
 package handler
 
 import (
-	"bytes"
-	"io"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
 )
 
 // loginInput данные для аутентификации
@@ -32,12 +33,38 @@ type refreshInput struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+// verifyEmailInput данные для подтверждения email
+type verifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// forgotPasswordInput данные для запроса сброса пароля
+type forgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// resetPasswordInput данные для сброса пароля
+type resetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
 // tokenResponse ответ с токенами
 type tokenResponse struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 }
 
+// loginResponse ответ на вход по паролю: токены или токен подтверждения вторым фактором
+type loginResponse struct {
+	AccessToken       string `json:"accessToken,omitempty"`
+	RefreshToken      string `json:"refreshToken,omitempty"`
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+	// TwoFactorSetupRequired права роли не действуют, пока не подключена двухфакторная аутентификация
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired,omitempty"`
+}
+
 // register обработчик регистрации
 func (h *Handler) register(c *gin.Context) {
 	log.Println("[Auth] Получен запрос на register")
@@ -84,21 +111,29 @@ func (h *Handler) login(c *gin.Context) {
 
 	log.Printf("[Auth] Попытка входа для пользователя: %s", input.UsernameOrEmail)
 
-	accessToken, refreshToken, err := h.services.Auth.Login(
+	result, err := h.services.Auth.Login(
 		c.Request.Context(),
 		input.UsernameOrEmail,
 		input.Password,
 	)
 	if err != nil {
 		log.Printf("[Auth] Ошибка аутентификации: %v", err)
+		var limitErr *ratelimit.LimitError
+		if errors.As(err, &limitErr) {
+			abortTooManyRequests(c, limitErr.RetryAfter)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
 	log.Printf("[Auth] Успешная аутентификация для пользователя: %s", input.UsernameOrEmail)
-	c.JSON(http.StatusOK, tokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+	c.JSON(http.StatusOK, loginResponse{
+		AccessToken:            result.AccessToken,
+		RefreshToken:           result.RefreshToken,
+		TwoFactorRequired:      result.TwoFactorRequired,
+		ChallengeToken:         result.ChallengeToken,
+		TwoFactorSetupRequired: result.TwoFactorSetupRequired,
 	})
 }
 
@@ -106,18 +141,6 @@ func (h *Handler) login(c *gin.Context) {
 func (h *Handler) refreshToken(c *gin.Context) {
 	log.Println("[Auth] Получен запрос на обновление токена")
 
-	// Выводим тело запроса
-	requestData, err := c.GetRawData()
-	if err != nil {
-		log.Printf("[Auth] Ошибка чтения тела запроса: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать тело запроса"})
-		return
-	}
-	log.Printf("[Auth] Тело запроса: %s", string(requestData))
-
-	// Восстанавливаем тело запроса для дальнейшей обработки
-	c.Request.Body = io.NopCloser(bytes.NewBuffer(requestData))
-
 	var input refreshInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.Printf("[Auth] Ошибка валидации входных данных: %v", err)
@@ -157,6 +180,119 @@ func (h *Handler) refreshToken(c *gin.Context) {
 	})
 }
 
+// logout обработчик завершения текущей сессии
+func (h *Handler) logout(c *gin.Context) {
+	var input refreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.Auth.Logout(c.Request.Context(), input.RefreshToken); err != nil {
+		log.Printf("[Auth] Ошибка завершения сессии: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// logoutAll обработчик завершения всех сессий текущего пользователя
+func (h *Handler) logoutAll(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Auth.LogoutAll(c.Request.Context(), user.ID); err != nil {
+		log.Printf("[Auth] Ошибка завершения всех сессий: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// verifyEmail обработчик подтверждения email по токену из письма
+func (h *Handler) verifyEmail(c *gin.Context) {
+	var input verifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.Auth.VerifyEmail(c.Request.Context(), input.Token); err != nil {
+		newAccountTokenErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// resendVerification обработчик повторной отправки письма подтверждения email
+func (h *Handler) resendVerification(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Auth.SendEmailVerification(c.Request.Context(), user.ID); err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[Auth] Ошибка отправки письма подтверждения: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// forgotPassword обработчик запроса ссылки сброса пароля. Ответ не зависит
+// от того, зарегистрирован ли адрес.
+func (h *Handler) forgotPassword(c *gin.Context) {
+	var input forgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.Auth.ForgotPassword(c.Request.Context(), input.Email); err != nil {
+		log.Printf("[Auth] Ошибка запроса сброса пароля: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// resetPassword обработчик сброса пароля по токену из письма
+func (h *Handler) resetPassword(c *gin.Context) {
+	var input resetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.Auth.ResetPassword(c.Request.Context(), input.Token, input.Password); err != nil {
+		newAccountTokenErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newAccountTokenErrorResponse сопоставляет ошибки токенов из писем с HTTP статусами
+func newAccountTokenErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidAccountToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("[Auth] Ошибка обработки токена из письма: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
 // authDiagnostic диагностика системы авторизации
 func (h *Handler) authDiagnostic(c *gin.Context) {
 	log.Println("[Auth] Запрос диагностики системы авторизации")
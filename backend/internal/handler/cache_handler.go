@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get catalog cache statistics (Admin only)
+// @Security ApiKeyAuth
+// @Description Hit and miss counters of the tour, hotel, city and country caches since the server start
+// @Tags admin-cache
+// @Produce json
+// @Success 200 {array} cache.Stats
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Router /api/admin/cache/stats [get]
+func (h *Handler) getCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Cache.Stats())
+}
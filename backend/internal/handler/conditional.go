@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// entityTag собирает ETag ответа из идентификаторов и версий сущностей, от
+// которых зависит тело ответа. Last-Modified - самое позднее время изменения
+// добавленных сущностей.
+type entityTag struct {
+	prefix       string
+	hash         hash.Hash64
+	lastModified time.Time
+}
+
+// newEntityTag создает ETag; prefix выводится в теге как есть
+func newEntityTag(prefix string) *entityTag {
+	return &entityTag{prefix: prefix, hash: fnv.New64a()}
+}
+
+// Add добавляет в тег значения, от которых зависит ответ (параметры запроса, итоги)
+func (t *entityTag) Add(parts ...interface{}) *entityTag {
+	for _, part := range parts {
+		fmt.Fprintf(t.hash, "%v|", part)
+	}
+	return t
+}
+
+// Touch добавляет в тег сущность и время ее изменения
+func (t *entityTag) Touch(id int64, updatedAt time.Time) *entityTag {
+	t.Add(id, updatedAt.UnixNano())
+	if updatedAt.After(t.lastModified) {
+		t.lastModified = updatedAt
+	}
+	return t
+}
+
+// AddJSON добавляет в тег данные без версии: справочники и агрегаты
+func (t *entityTag) AddJSON(v interface{}) *entityTag {
+	_ = json.NewEncoder(t.hash).Encode(v)
+	return t
+}
+
+// String возвращает значение заголовка ETag
+func (t *entityTag) String() string {
+	return `"` + t.prefix + strconv.FormatUint(t.hash.Sum64(), 36) + `"`
+}
+
+// tourETag строит ETag тура с датами и отелями. Версия тура вынесена в начало
+// тега, чтобы If-Match проверял только ее: бронирование мест меняет даты тура,
+// но не должно мешать администратору сохранить правку описания.
+func tourETag(tour *domain.Tour, currency string) *entityTag {
+	tag := newEntityTag("v"+strconv.Itoa(tour.Version)+"-").
+		Add(currency).
+		Touch(tour.ID, tour.UpdatedAt)
+	for _, date := range tour.TourDates {
+		tag.Touch(date.ID, date.UpdatedAt)
+	}
+	tag.Add("hotels")
+	for _, hotel := range tour.Hotels {
+		tag.Touch(hotel.ID, hotel.UpdatedAt)
+	}
+	return tag
+}
+
+// tourVersionFromETag извлекает версию тура из ETag, выданного tourETag
+func tourVersionFromETag(value string) (int, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	value = strings.Trim(value, `"`)
+	if !strings.HasPrefix(value, "v") {
+		return 0, false
+	}
+	end := strings.IndexByte(value, '-')
+	if end < 0 {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(value[1:end])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// notModified выставляет ETag и Last-Modified и отвечает 304, если у клиента
+// актуальная копия ответа. Для списков lastModified передается нулевым:
+// удаление элемента не сдвигает время последнего изменения, и сравнение по
+// If-Modified-Since вернуло бы устаревший список.
+func notModified(c *gin.Context, tag *entityTag, lastModified time.Time) bool {
+	etag := tag.String()
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match приоритетнее If-Modified-Since
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		if !etagMatches(header, etag) {
+			return false
+		}
+	} else if header := c.GetHeader("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// etagMatches проверяет, есть ли etag в списке тегов заголовка (слабое сравнение)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
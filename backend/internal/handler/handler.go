@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,8 +11,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
 	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pricing"
 )
 
 // Handler структура для обработки HTTP запросов
@@ -45,9 +49,21 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		// Аутентификация
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", h.register)
-			auth.POST("/login", h.login)
+			auth.POST("/register", h.rateLimit(service.RateLimitRegister, clientIPKey), h.register)
+			auth.POST("/login", h.rateLimit(service.RateLimitLogin, clientIPKey), h.login)
 			auth.POST("/refresh", h.refreshToken)
+			auth.POST("/logout", h.logout)
+			auth.POST("/logout-all", h.authMiddleware(), h.logoutAll)
+			auth.POST("/verify-email", h.verifyEmail)
+			auth.POST("/verify-email/resend", h.authMiddleware(), h.rateLimit(service.RateLimitAccountEmail, clientIPKey), h.resendVerification)
+			auth.POST("/forgot-password", h.rateLimit(service.RateLimitAccountEmail, clientIPKey), h.forgotPassword)
+			auth.POST("/reset-password", h.resetPassword)
+			auth.POST("/2fa", h.rateLimit(service.RateLimitTwoFactor, clientIPKey), h.twoFactorLogin)
+			auth.GET("/2fa", h.authMiddleware(), h.twoFactorStatus)
+			auth.POST("/2fa/enroll", h.authMiddleware(), h.enrollTwoFactor)
+			auth.POST("/2fa/confirm", h.authMiddleware(), h.rateLimit(service.RateLimitTwoFactor, userKey), h.confirmTwoFactor)
+			auth.POST("/2fa/disable", h.authMiddleware(), h.rateLimit(service.RateLimitTwoFactor, userKey), h.disableTwoFactor)
+			auth.POST("/2fa/recovery-codes", h.authMiddleware(), h.rateLimit(service.RateLimitTwoFactor, userKey), h.regenerateRecoveryCodes)
 			auth.GET("/diagnostic", h.authDiagnostic) // Диагностический эндпоинт
 		}
 
@@ -55,6 +71,7 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		tours := api.Group("/tours")
 		{
 			tours.GET("/", h.getAllTours)
+			tours.GET("/search", h.searchTours)
 			tours.GET("/:id", h.getTourByID)
 			tours.GET("/:id/dates", h.getTourDates)
 		}
@@ -71,6 +88,18 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		api.GET("/countries", h.getAllCountries)
 		api.GET("/cities", h.getCitiesByCountry)
 
+		// Callback платежного провайдера; подлинность проверяется по подписи
+		payments := api.Group("/payments")
+		{
+			payments.POST("/webhook", h.paymentWebhook)
+
+			// Имитация оплаты подтверждает платеж без шлюза, поэтому в релизном
+			// режиме не регистрируется и доступна только владельцу заказа
+			if h.services.Payment.SimulationSupported() && gin.Mode() != gin.ReleaseMode {
+				payments.POST("/fake/:intentId/authorize", h.authMiddleware(), h.authorizeFakePayment)
+			}
+		}
+
 		// Маршруты, требующие аутентификации
 		authenticated := api.Group("/")
 		authenticated.Use(h.authMiddleware())
@@ -86,16 +115,18 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			// Заказы
 			orders := authenticated.Group("/orders")
 			{
-				orders.POST("/", h.createOrder)
+				orders.POST("/", h.requireVerifiedEmail(), h.rateLimit(service.RateLimitOrderCreate, userKey), h.createOrder)
+				orders.POST("/quote", h.quoteOrder)
 				orders.GET("/", h.getUserOrders)
 				orders.GET("/:id", h.getOrderByID)
 				orders.DELETE("/:id", h.cancelOrder)
+				orders.POST("/:id/checkout", h.checkoutOrder)
 			}
 
 			// Тикеты тех-поддержки
 			tickets := authenticated.Group("/tickets")
 			{
-				tickets.POST("/", h.createTicket)
+				tickets.POST("/", h.rateLimit(service.RateLimitTicketCreate, userKey), h.createTicket)
 				tickets.GET("/", h.getUserTickets)
 				tickets.GET("/:id", h.getTicketByID)
 				tickets.POST("/:id/messages", h.addTicketMessage)
@@ -104,55 +135,111 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			}
 		}
 
-		// Маршруты для администраторов
+		// Маршруты для администраторов; доступ к каждому разделу определяется правами роли
 		admin := api.Group("/admin")
-		admin.Use(h.authMiddleware(), h.adminMiddleware())
+		admin.Use(h.authMiddleware())
 		{
+			usersRead := h.requirePermission(domain.PermUsersRead)
+			usersWrite := h.requirePermission(domain.PermUsersWrite)
+			toursWrite := h.requirePermission(domain.PermToursWrite)
+			hotelsWrite := h.requirePermission(domain.PermHotelsWrite)
+			pricingWrite := h.requirePermission(domain.PermPricingWrite)
+			promotionsWrite := h.requirePermission(domain.PermPromotionsWrite)
+			ordersRead := h.requirePermission(domain.PermOrdersRead)
+			ordersWrite := h.requirePermission(domain.PermOrdersWrite)
+			ticketsRead := h.requirePermission(domain.PermTicketsRead)
+			ticketsAssign := h.requirePermission(domain.PermTicketsAssign)
+			rolesManage := h.requirePermission(domain.PermRolesManage)
+			systemManage := h.requirePermission(domain.PermSystemManage)
+
 			// Управление пользователями
-			admin.GET("/users", h.getAllUsers)
-			admin.GET("/users/:id", h.getUserByID)
-			admin.PUT("/users/:id", h.updateUser)
-			admin.DELETE("/users/:id", h.deleteUser)
+			admin.GET("/users", usersRead, h.getAllUsers)
+			admin.GET("/users/deleted", usersRead, h.getDeletedUsers)
+			admin.GET("/users/:id", usersRead, h.getUserByID)
+			admin.PUT("/users/:id", usersWrite, h.updateUser)
+			admin.DELETE("/users/:id", usersWrite, h.deleteUser)
+			admin.POST("/users/:id/restore", usersWrite, h.restoreUser)
+
+			// Управление ролями и правами
+			admin.GET("/roles", rolesManage, h.getRoles)
+			admin.POST("/roles", rolesManage, h.createRole)
+			admin.GET("/roles/:id", rolesManage, h.getRoleByID)
+			admin.PUT("/roles/:id", rolesManage, h.updateRole)
+			admin.DELETE("/roles/:id", rolesManage, h.deleteRole)
+			admin.GET("/permissions", rolesManage, h.getPermissions)
 
 			// Управление турами
-			admin.POST("/tours", h.createTour)
-			admin.PUT("/tours/:id", h.updateTour)
-			admin.DELETE("/tours/:id", h.deleteTour)
-			admin.POST("/tours/:id/dates", h.addTourDate)
-			admin.PUT("/tours/:id/dates/:dateId", h.updateTourDate)
-			admin.DELETE("/tours/:id/dates/:dateId", h.deleteTourDate)
+			admin.GET("/tours/deleted", toursWrite, h.getDeletedTours)
+			admin.POST("/tours", toursWrite, h.createTour)
+			admin.PUT("/tours/:id", toursWrite, h.updateTour)
+			admin.DELETE("/tours/:id", toursWrite, h.deleteTour)
+			admin.POST("/tours/:id/restore", toursWrite, h.restoreTour)
+			admin.GET("/tours/:id/dates/deleted", toursWrite, h.getDeletedTourDates)
+			admin.POST("/tours/:id/dates", toursWrite, h.addTourDate)
+			admin.PUT("/tours/:id/dates/:dateId", toursWrite, h.updateTourDate)
+			admin.DELETE("/tours/:id/dates/:dateId", toursWrite, h.deleteTourDate)
+			admin.POST("/tours/:id/dates/:dateId/restore", toursWrite, h.restoreTourDate)
+			admin.GET("/tours/:id/schedules", toursWrite, h.getTourSchedules)
+			admin.POST("/tours/:id/schedules", toursWrite, h.createTourSchedule)
+			admin.POST("/tours/:id/schedules/generate", toursWrite, h.generateTourDates)
+			admin.PUT("/tours/:id/schedules/:scheduleId", toursWrite, h.updateTourSchedule)
+			admin.DELETE("/tours/:id/schedules/:scheduleId", toursWrite, h.deleteTourSchedule)
+			admin.POST("/search/reindex", systemManage, h.reindexSearch)
+			admin.GET("/cache/stats", systemManage, h.getCacheStats)
+
+			// Правила ценообразования
+			admin.GET("/pricing-rules", pricingWrite, h.getPricingRules)
+			admin.POST("/pricing-rules", pricingWrite, h.createPricingRule)
+			admin.GET("/pricing-rules/preview", pricingWrite, h.previewPricingRules)
+			admin.POST("/pricing-rules/apply", pricingWrite, h.applyPricingRules)
+			admin.GET("/pricing-rules/:id", pricingWrite, h.getPricingRuleByID)
+			admin.PUT("/pricing-rules/:id", pricingWrite, h.updatePricingRule)
+			admin.DELETE("/pricing-rules/:id", pricingWrite, h.deletePricingRule)
 
 			// Управление отелями
-			admin.POST("/hotels", h.createHotel)
-			admin.PUT("/hotels/:id", h.updateHotel)
-			admin.DELETE("/hotels/:id", h.deleteHotel)
-			admin.POST("/hotels/:id/rooms", h.addRoom)
-			admin.PUT("/hotels/:id/rooms/:roomId", h.updateRoom)
-			admin.DELETE("/hotels/:id/rooms/:roomId", h.deleteRoom)
-
-			// Управление заказами
-			admin.GET("/orders", h.getAllOrders)
-			admin.PUT("/orders/:id/status", h.updateOrderStatus)
+			admin.GET("/hotels/deleted", hotelsWrite, h.getDeletedHotels)
+			admin.POST("/hotels", hotelsWrite, h.createHotel)
+			admin.PUT("/hotels/:id", hotelsWrite, h.updateHotel)
+			admin.DELETE("/hotels/:id", hotelsWrite, h.deleteHotel)
+			admin.POST("/hotels/:id/restore", hotelsWrite, h.restoreHotel)
+			admin.GET("/hotels/:id/rooms/deleted", hotelsWrite, h.getDeletedRooms)
+			admin.POST("/hotels/:id/rooms", hotelsWrite, h.addRoom)
+			admin.PUT("/hotels/:id/rooms/:roomId", hotelsWrite, h.updateRoom)
+			admin.DELETE("/hotels/:id/rooms/:roomId", hotelsWrite, h.deleteRoom)
+			admin.POST("/hotels/:id/rooms/:roomId/restore", hotelsWrite, h.restoreRoom)
+			admin.GET("/hotels/:id/rooms/:roomId/nights", hotelsWrite, h.getRoomNights)
+
+			// Управление промоакциями
+			admin.GET("/promotions", promotionsWrite, h.getAllPromotions)
+			admin.POST("/promotions", promotionsWrite, h.createPromotion)
+			admin.GET("/promotions/:id", promotionsWrite, h.getPromotionByID)
+			admin.PUT("/promotions/:id", promotionsWrite, h.updatePromotion)
+			admin.DELETE("/promotions/:id", promotionsWrite, h.deletePromotion)
+
+			// Управление заказами; отмена оплаченного заказа дополнительно требует orders:refund
+			admin.GET("/orders", ordersRead, h.getAllOrders)
+			admin.PUT("/orders/:id/status", ordersWrite, h.updateOrderStatus)
+			admin.GET("/orders/:id/history", ordersRead, h.getOrderStatusHistory)
 
 			// Управление тикетами
-			admin.GET("/tickets", h.getAllTickets)
-			admin.PUT("/tickets/:id/status", h.updateTicketStatus)
+			admin.GET("/tickets", ticketsRead, h.getAllTickets)
+			admin.PUT("/tickets/:id/status", ticketsAssign, h.updateTicketStatus)
 		}
 
 		// Маршруты для тех-поддержки
 		support := api.Group("/support")
-		support.Use(h.authMiddleware(), h.supportMiddleware())
+		support.Use(h.authMiddleware(), h.requirePermission(domain.PermTicketsRead))
 		{
 			support.GET("/tickets", h.getAllTickets)
 			support.GET("/tickets/:id", h.getTicketByID)
 			support.POST("/tickets/:id/messages", h.addTicketMessage)
 			support.GET("/tickets/:id/messages", h.getTicketMessages)
-			support.PUT("/tickets/:id/status", h.updateTicketStatus)
+			support.PUT("/tickets/:id/status", h.requirePermission(domain.PermTicketsAssign), h.updateTicketStatus)
 		}
 	}
 
 	// Обработка WebSocket для чата тех-поддержки
-	router.GET("/ws/chat/:ticketId", h.wsTicketChat)
+	router.GET("/ws/chat/:ticketId", h.wsTokenMiddleware(), h.authMiddleware(), h.wsTicketChat)
 
 	return router
 }
@@ -179,152 +266,139 @@ func corsMiddleware() gin.HandlerFunc {
 // @Tags tours
 // @Accept json
 // @Produce json
-// @Param city_id query int false "Filter by city ID"
-// @Param country_id query int false "Filter by country ID"
-// @Param price_min query number false "Minimum price filter"
-// @Param price_max query number false "Maximum price filter"
+// @Param cityId query int false "Filter by city ID"
+// @Param countryId query int false "Filter by country ID"
+// @Param priceMin query number false "Minimum price filter (in the requested currency)"
+// @Param priceMax query number false "Maximum price filter (in the requested currency)"
+// @Param searchQuery query string false "Full-text search in tour, city, country and hotel names and description; typo tolerant"
+// @Param durationMin query int false "Minimum duration in days"
+// @Param durationMax query int false "Maximum duration in days"
+// @Param startDateAfter query string false "Only tours with a date starting on or after (YYYY-MM-DD)"
+// @Param startDateBefore query string false "Only tours with a date starting on or before (YYYY-MM-DD)"
+// @Param sortBy query string false "Sort field: price, duration, name, created_at (default: relevance with searchQuery, otherwise newest first)"
+// @Param sortOrder query string false "Sort order: asc or desc" default(asc)
+// @Param currency query string false "ISO currency code to convert prices to (defaults to each tour's own currency)"
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(10)
-// @Success 200 {object} map[string]interface{} "List of tours and total count"
-// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total, cannot be combined with sortBy)"
+// @Param facets query bool false "Also return counts by country, city, duration, price, hotel category and departure month under the current filters"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 is returned if it is still current"
+// @Success 200 {object} map[string]interface{} "List of tours, total count (offset mode), next_cursor and optional facets"
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Tag computed from the versions of the listed tours"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/tours [get]
 func (h *Handler) getAllTours(c *gin.Context) {
-	// fmt.Println("DEBUG: Entering handler.getAllTours") // Можно добавить лог сюда, если нужно
-	// --- Начало логики из h.List ---
-	var filters = make(map[string]interface{})
-
-	// Обрабатываем ID страны (ожидаем countryId от фронтенда)
-	if countryIDStr := c.Query("countryId"); countryIDStr != "" {
-		countryID, err := strconv.Atoi(countryIDStr)
-		if err == nil && countryID > 0 {
-			filters["country_id"] = countryID // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid countryId parameter")
-			return
-		}
+	// Валюта, в которой клиент хочет видеть цены и задает фильтр по цене
+	currency := c.Query("currency")
+	if currency != "" && !h.resolveCurrency(c, &currency) {
+		return
+	}
+	filterCurrency := currency
+	if filterCurrency == "" {
+		filterCurrency = h.services.Currency.Base()
 	}
 
-	// Обрабатываем ID города (ожидаем cityId от фронтенда)
-	if cityIDStr := c.Query("cityId"); cityIDStr != "" {
-		cityID, err := strconv.Atoi(cityIDStr)
-		if err == nil && cityID > 0 {
-			filters["city_id"] = cityID // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid cityId parameter")
-			return
-		}
+	p := newQueryParser(c)
+	filter := domain.TourFilter{
+		CountryID:       p.ID("countryId"),
+		CityID:          p.ID("cityId"),
+		Search:          strings.TrimSpace(c.Query("searchQuery")),
+		DurationMin:     p.Int("durationMin"),
+		DurationMax:     p.Int("durationMax"),
+		StartDateAfter:  p.Date("startDateAfter"),
+		StartDateBefore: p.Date("startDateBefore"),
+		SortBy:          c.Query("sortBy"),
+		SortOrder:       domain.SortOrder(c.Query("sortOrder")),
+		Page:            p.Page(),
 	}
 
-	// Обрабатываем минимальную цену (ожидаем priceMin от фронтенда)
-	if priceMinStr := c.Query("priceMin"); priceMinStr != "" {
-		priceMin, err := strconv.ParseFloat(priceMinStr, 64)
-		if err == nil && priceMin >= 0 {
-			filters["price_min"] = priceMin // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid priceMin parameter")
-			return
+	// Границы цены пересчитываются во все валюты, чтобы сравнивать туры в их собственной валюте
+	if priceMin := p.Amount("priceMin"); priceMin != nil {
+		bounds, err := h.services.Currency.ConvertToAll(*priceMin, filterCurrency)
+		if err != nil {
+			p.Fail("priceMin", err.Error())
 		}
+		filter.PriceMin = bounds
 	}
-
-	// Обрабатываем максимальную цену (ожидаем priceMax от фронтенда)
-	if priceMaxStr := c.Query("priceMax"); priceMaxStr != "" {
-		priceMax, err := strconv.ParseFloat(priceMaxStr, 64)
-		if err == nil && priceMax > 0 {
-			filters["price_max"] = priceMax // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid priceMax parameter")
-			return
+	if priceMax := p.Amount("priceMax"); priceMax != nil {
+		bounds, err := h.services.Currency.ConvertToAll(*priceMax, filterCurrency)
+		if err != nil {
+			p.Fail("priceMax", err.Error())
+		}
+		filter.PriceMax = bounds
+	}
+	withFacets := p.Bool("facets")
+	var facetOpts *domain.TourFacetOptions
+	if withFacets {
+		facetOpts = &domain.TourFacetOptions{Currency: filterCurrency}
+		for _, edge := range tourPriceFacetEdges {
+			bounds, err := h.services.Currency.ConvertToAll(money.FromMajor(edge), h.services.Currency.Base())
+			if err != nil {
+				newErrorResponse(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			facetOpts.PriceEdges = append(facetOpts.PriceEdges, bounds)
 		}
 	}
-
-	// Добавляем поиск по названию (ожидаем searchQuery от фронтенда)
-	if searchQuery := c.Query("searchQuery"); searchQuery != "" {
-		filters["search_query"] = searchQuery // Ключ для репозитория
+	if !p.Check(filter.Validate()) {
+		return
 	}
 
-	// Обрабатываем минимальную продолжительность (ожидаем durationMin от фронтенда)
-	if durationMinStr := c.Query("durationMin"); durationMinStr != "" {
-		durationMin, err := strconv.Atoi(durationMinStr)
-		if err == nil && durationMin > 0 {
-			filters["duration_min"] = durationMin // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid durationMin parameter")
-			return
-		}
+	tours, info, err := h.services.Tour.List(c.Request.Context(), &filter)
+	if err != nil {
+		newListErrorResponse(c, err)
+		return
 	}
 
-	// Обрабатываем максимальную продолжительность (ожидаем durationMax от фронтенда)
-	if durationMaxStr := c.Query("durationMax"); durationMaxStr != "" {
-		durationMax, err := strconv.Atoi(durationMaxStr)
-		if err == nil && durationMax > 0 {
-			filters["duration_max"] = durationMax // Ключ для репозитория
-		} else {
-			newErrorResponse(c, http.StatusBadRequest, "invalid durationMax parameter")
+	response := listResponse("tours", tours, info)
+	tag := newEntityTag("").Add(c.Request.URL.RawQuery, info.Total, info.NextCursor)
+	for _, tour := range tours {
+		tag.Touch(tour.ID, tour.UpdatedAt).Add(tour.Version)
+	}
+	if withFacets {
+		facets, err := h.services.Tour.Facets(c.Request.Context(), &filter, facetOpts)
+		if err != nil {
+			newListErrorResponse(c, err)
 			return
 		}
+		response["facets"] = facets
+		tag.AddJSON(facets)
 	}
-
-	// Обрабатываем дату начала "после" (ожидаем startDateAfter от фронтенда)
-	if startDateAfter := c.Query("startDateAfter"); startDateAfter != "" {
-		// TODO: Добавить валидацию формата даты, если необходимо
-		filters["start_date_after"] = startDateAfter // Ключ для репозитория
-	}
-
-	// Обрабатываем дату начала "до" (ожидаем startDateBefore от фронтенда)
-	if startDateBefore := c.Query("startDateBefore"); startDateBefore != "" {
-		// TODO: Добавить валидацию формата даты, если необходимо
-		filters["start_date_before"] = startDateBefore // Ключ для репозитория
+	if notModified(c, tag, time.Time{}) {
+		return
 	}
 
-	// Обрабатываем параметры сортировки (ожидаем sortBy, sortOrder от фронтенда)
-	if sortBy := c.Query("sortBy"); sortBy != "" {
-		// Валидация допустимых полей для сортировки
-		allowedSortBy := map[string]string{
-			"price":    "t.base_price",
-			"duration": "t.duration",
-			"name":     "t.name",
-			// Добавьте другие поля при необходимости
-		}
-		if dbField, ok := allowedSortBy[sortBy]; ok {
-			filters["sort_by"] = dbField // Ключ для репозитория
-			sortOrder := c.DefaultQuery("sortOrder", "asc")
-			if sortOrder != "asc" && sortOrder != "desc" {
-				sortOrder = "asc" // По умолчанию asc
+	if currency != "" {
+		for _, tour := range tours {
+			if err := h.services.Currency.ConvertTour(tour, currency); err != nil {
+				newErrorResponse(c, http.StatusInternalServerError, err.Error())
+				return
 			}
-			filters["sort_order"] = sortOrder // Ключ для репозитория
 		}
-		// Если sortBy невалидный, просто игнорируем сортировку
-	}
-	// --- Конец логики из h.List ---
-
-	// Извлекаем параметры пагинации (остается как было)
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	// Получаем туры с учетом ВСЕХ фильтров и пагинации
-	tours, total, err := h.services.Tour.List(c.Request.Context(), filters, page, size)
-	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
 	}
 
-	// Информация о городе/стране теперь автоматически заполняется репозиторием
-	// Старый код обогащения (строки 233-246) удален
-
-	c.JSON(http.StatusOK, gin.H{
-		"tours": tours,
-		"total": total,
-	})
+	c.JSON(http.StatusOK, response)
 }
 
+// tourPriceFacetEdges границы ценовых интервалов фасета prices в основных
+// единицах базовой валюты; в ответе они выводятся в запрошенной валюте
+var tourPriceFacetEdges = []int64{0, 50000, 100000, 200000, 400000}
+
 // @Summary Get tour by ID
 // @Description Get details of a specific tour by its ID
 // @Tags tours
 // @Accept json
 // @Produce json
 // @Param id path int true "Tour ID"
+// @Param currency query string false "ISO currency code to convert the price to"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 is returned if it is still current"
+// @Param If-Modified-Since header string false "Date of a cached copy; used when If-None-Match is absent"
 // @Success 200 {object} domain.Tour
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Tag of the tour with its dates and hotels; pass it in If-Match to update the tour"
+// @Header 200 {string} Last-Modified "Latest change of the tour, its dates or hotels"
 // @Failure 400 {object} ErrorResponse "Invalid tour ID"
 // @Failure 404 {object} ErrorResponse "Tour not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -347,6 +421,23 @@ func (h *Handler) getTourByID(c *gin.Context) {
 	// Информация о городе/стране теперь автоматически заполняется репозиторием
 	// Старый код обогащения (строки 280-291) удален
 
+	currency := c.Query("currency")
+	if currency != "" && !h.resolveCurrency(c, &currency) {
+		return
+	}
+
+	tag := tourETag(tour, currency)
+	if notModified(c, tag, tag.lastModified) {
+		return
+	}
+
+	if currency != "" {
+		if err := h.services.Currency.ConvertTour(tour, currency); err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, tour)
 }
 
@@ -368,83 +459,13 @@ func (h *Handler) getTourDates(c *gin.Context) {
 		return
 	}
 
-	// Получаем информацию о туре для определения продолжительности
-	tour, err := h.services.Tour.GetByID(c.Request.Context(), id)
-	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "не удалось получить информацию о туре")
-		return
-	}
-
-	// Получаем существующие даты тура
+	// Даты создаются заранее генератором расписаний, здесь они только читаются
 	dates, err := h.services.Tour.GetTourDates(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Если дат нет, создаем их
-	if len(dates) == 0 {
-		// Информация о городе/стране уже есть в объекте tour, полученном на строке 315
-		// Старый код обогащения (строки 331-339) удален
-
-		// Определяем длительность тура из свойств тура, по умолчанию 7 дней
-		duration := 7
-		if tour.Duration > 0 {
-			duration = tour.Duration
-		}
-
-		// Добавляем 5 будущих дат для тура (каждые 3 дня, начиная с завтрашнего дня)
-		now := time.Now().AddDate(0, 0, 1) // начиная с завтрашнего дня
-		for i := 0; i < 5; i++ {
-			startDate := now.AddDate(0, 0, i*3) // каждые 3 дня
-			endDate := startDate.AddDate(0, 0, duration)
-
-			// Создаем разное количество мест и разные коэффициенты цены
-			availability := 20 + i*5               // 20, 25, 30, 35, 40 мест
-			priceModifier := 1.0 - float64(i)*0.05 // 1.0, 0.95, 0.90, 0.85, 0.80
-
-			tourDate := &domain.TourDate{
-				TourID:        id,
-				StartDate:     startDate,
-				EndDate:       endDate,
-				Availability:  availability,
-				PriceModifier: priceModifier,
-			}
-
-			dateID, err := h.services.Tour.AddTourDate(c.Request.Context(), tourDate)
-			if err != nil {
-				continue
-			}
-
-			// Добавляем созданную дату в массив для возврата
-			tourDate.ID = dateID
-			dates = append(dates, tourDate)
-		}
-	}
-
-	// Если после попытки создания все еще нет дат, создадим хотя бы одну
-	if len(dates) == 0 {
-		now := time.Now().AddDate(0, 0, 1) // начиная с завтрашнего дня
-		duration := 7
-		if tour.Duration > 0 {
-			duration = tour.Duration
-		}
-
-		tourDate := &domain.TourDate{
-			TourID:        id,
-			StartDate:     now,
-			EndDate:       now.AddDate(0, 0, duration),
-			Availability:  30,
-			PriceModifier: 1.0,
-		}
-
-		dateID, err := h.services.Tour.AddTourDate(c.Request.Context(), tourDate)
-		if err == nil {
-			tourDate.ID = dateID
-			dates = append(dates, tourDate)
-		}
-	}
-
 	c.JSON(http.StatusOK, dates)
 }
 
@@ -454,45 +475,41 @@ func (h *Handler) getTourDates(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param city_id query int false "Filter by city ID"
-// @Param category query int false "Filter by category (stars)"
+// @Param country_id query int false "Filter by country ID"
+// @Param category query int false "Filter by exact category (stars)"
+// @Param category_min query int false "Minimum category (stars)"
+// @Param category_max query int false "Maximum category (stars)"
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(10)
-// @Success 200 {object} map[string]interface{} "List of hotels and total count"
-// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of hotels, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/hotels [get]
 func (h *Handler) getAllHotels(c *gin.Context) {
-	filters := make(map[string]interface{})
-	if cityIDStr := c.Query("city_id"); cityIDStr != "" {
-		cityID, err := strconv.Atoi(cityIDStr)
-		if err != nil {
-			newErrorResponse(c, http.StatusBadRequest, "invalid city_id parameter")
-			return
-		}
-		filters["city_id"] = cityID
+	p := newQueryParser(c)
+	filter := domain.HotelFilter{
+		CityID:      p.ID("city_id"),
+		CountryID:   p.ID("country_id"),
+		CategoryMin: p.Int("category_min"),
+		CategoryMax: p.Int("category_max"),
+		Page:        p.Page(),
 	}
-	if categoryStr := c.Query("category"); categoryStr != "" {
-		category, err := strconv.Atoi(categoryStr)
-		if err != nil {
-			newErrorResponse(c, http.StatusBadRequest, "invalid category parameter")
-			return
-		}
-		filters["category"] = category
+	// Точная категория задает обе границы
+	if category := p.Int("category"); category != nil {
+		filter.CategoryMin, filter.CategoryMax = category, category
+	}
+	if !p.Check(filter.Validate()) {
+		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	hotels, total, err := h.services.Hotel.List(c.Request.Context(), filters, page, size)
+	hotels, info, err := h.services.Hotel.List(c.Request.Context(), &filter)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newListErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"hotels": hotels,
-		"total":  total,
-	})
+	c.JSON(http.StatusOK, listResponse("hotels", hotels, info))
 }
 
 // @Summary Get hotel by ID
@@ -525,13 +542,18 @@ func (h *Handler) getHotelByID(c *gin.Context) {
 }
 
 // @Summary Get hotel rooms
-// @Description Get available rooms for a specific hotel
+// @Description Get rooms for a specific hotel; with startDate and endDate only rooms free for every night of the stay are returned
 // @Tags hotels
 // @Accept json
 // @Produce json
 // @Param id path int true "Hotel ID"
+// @Param startDate query string false "Check-in date (YYYY-MM-DD)"
+// @Param endDate query string false "Check-out date (YYYY-MM-DD)"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 is returned if it is still current"
 // @Success 200 {array} domain.Room
-// @Failure 400 {object} ErrorResponse "Invalid hotel ID"
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Tag computed from the listed rooms and their update times"
+// @Failure 400 {object} ErrorResponse "Invalid hotel ID or dates"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/hotels/{id}/rooms [get]
 func (h *Handler) getHotelRooms(c *gin.Context) {
@@ -542,6 +564,11 @@ func (h *Handler) getHotelRooms(c *gin.Context) {
 		return
 	}
 
+	stay, ok := parseDateRange(c, false)
+	if !ok {
+		return
+	}
+
 	rooms, err := h.services.Hotel.ListRoomsByHotelID(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -561,34 +588,35 @@ func (h *Handler) getHotelRooms(c *gin.Context) {
 		roomTypes := []struct {
 			description string
 			beds        int
-			price       float64
+			price       int64
 			imageURL    string
 		}{
-			{"Стандартный одноместный номер", 1, 3500.0, "/images/rooms/standard_single.jpg"},
-			{"Стандартный двухместный номер с двумя кроватями", 2, 5000.0, "/images/rooms/standard_twin.jpg"},
-			{"Улучшенный двухместный номер с большой кроватью", 2, 5500.0, "/images/rooms/deluxe_double.jpg"},
-			{"Полулюкс с видом на море", 2, 7000.0, "/images/rooms/junior_suite.jpg"},
-			{"Люкс с балконом и джакузи", 2, 9000.0, "/images/rooms/suite.jpg"},
-			{"Семейный номер с тремя кроватями", 3, 8000.0, "/images/rooms/family_room.jpg"},
-			{"Апартаменты с кухней", 4, 10000.0, "/images/rooms/apartment.jpg"},
+			{"Стандартный одноместный номер", 1, 3500, "/images/rooms/standard_single.jpg"},
+			{"Стандартный двухместный номер с двумя кроватями", 2, 5000, "/images/rooms/standard_twin.jpg"},
+			{"Улучшенный двухместный номер с большой кроватью", 2, 5500, "/images/rooms/deluxe_double.jpg"},
+			{"Полулюкс с видом на море", 2, 7000, "/images/rooms/junior_suite.jpg"},
+			{"Люкс с балконом и джакузи", 2, 9000, "/images/rooms/suite.jpg"},
+			{"Семейный номер с тремя кроватями", 3, 8000, "/images/rooms/family_room.jpg"},
+			{"Апартаменты с кухней", 4, 10000, "/images/rooms/apartment.jpg"},
 		}
 
 		// Получаем категорию отеля и корректируем цены
-		priceMultiplier := 1.0
+		priceMultiplier := money.One
 		if hotel.Category > 0 {
-			priceMultiplier = 1.0 + float64(hotel.Category-1)*0.2 // 1.0, 1.2, 1.4, 1.6, 1.8 для категорий 1-5
+			priceMultiplier = money.One + money.Ratio(hotel.Category-1)*money.RatioScale/5 // 1.0, 1.2, 1.4, 1.6, 1.8 для категорий 1-5
 		}
 
 		// Добавляем номера
 		for _, roomType := range roomTypes {
 			// Корректируем цену в зависимости от категории отеля
-			price := roomType.price * priceMultiplier
+			price := money.FromMajor(roomType.price).Mul(priceMultiplier)
 
 			room := &domain.Room{
 				HotelID:     id,
 				Description: roomType.description,
 				Beds:        roomType.beds,
 				Price:       price,
+				Currency:    h.services.Currency.Base(),
 				ImageURL:    roomType.imageURL,
 				CreatedAt:   time.Now(),
 			}
@@ -612,7 +640,8 @@ func (h *Handler) getHotelRooms(c *gin.Context) {
 			HotelID:     id,
 			Description: "Стандартный номер",
 			Beds:        2,
-			Price:       5000.0,
+			Price:       money.FromMajor(5000),
+			Currency:    h.services.Currency.Base(),
 			ImageURL:    "/images/rooms/standard_double.jpg",
 			CreatedAt:   time.Now(),
 		}
@@ -624,9 +653,71 @@ func (h *Handler) getHotelRooms(c *gin.Context) {
 		}
 	}
 
+	// Если указаны даты проживания, оставляем только свободные номера
+	if stay != nil {
+		rooms, err = h.services.Hotel.ListAvailableRooms(c.Request.Context(), id, stay.from, stay.to)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	// Состав списка зависит от занятости, поэтому в тег входят ID всех номеров
+	tag := newEntityTag("").Add(c.Request.URL.RawQuery)
+	for _, room := range rooms {
+		tag.Touch(room.ID, room.UpdatedAt)
+	}
+	if notModified(c, tag, time.Time{}) {
+		return
+	}
+
 	c.JSON(http.StatusOK, rooms)
 }
 
+// dateRange период проживания: с даты заезда from до даты выезда to
+type dateRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// Вспомогательная функция для разбора периода из параметров startDate и endDate.
+// Возвращает nil, если период не задан и не обязателен.
+func parseDateRange(c *gin.Context, required bool) (*dateRange, bool) {
+	startStr, endStr := c.Query("startDate"), c.Query("endDate")
+	if startStr == "" && endStr == "" && !required {
+		return nil, true
+	}
+	if startStr == "" || endStr == "" {
+		newErrorResponse(c, http.StatusBadRequest, "both startDate and endDate are required")
+		return nil, false
+	}
+
+	from, err := parseDateParam(startStr)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid startDate parameter")
+		return nil, false
+	}
+	to, err := parseDateParam(endStr)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid endDate parameter")
+		return nil, false
+	}
+	if !to.After(from) {
+		newErrorResponse(c, http.StatusBadRequest, "endDate must be after startDate")
+		return nil, false
+	}
+
+	return &dateRange{from: from, to: to}, true
+}
+
+// Вспомогательная функция для разбора даты в формате YYYY-MM-DD или RFC3339
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // wsTicketChat обрабатывает WebSocket соединение для чата в тикете
 // func (h *Handler) wsTicketChat(c *gin.Context) {
 // 	// TODO: Implement WebSocket chat logic
@@ -635,7 +726,8 @@ func (h *Handler) getHotelRooms(c *gin.Context) {
 
 // Добавим структуру для ответов с ошибками
 type ErrorResponse struct {
-	Message string `json:"error"`
+	Message string              `json:"error"`
+	Fields  []domain.FieldError `json:"fields,omitempty"` // некорректные параметры запроса
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
@@ -658,6 +750,20 @@ func getUserFromContext(c *gin.Context) (*domain.User, bool) {
 	return user, true
 }
 
+// Вспомогательная функция для проверки кода валюты; пустой код заменяется базовой валютой
+func (h *Handler) resolveCurrency(c *gin.Context, currency *string) bool {
+	if *currency == "" {
+		*currency = h.services.Currency.Base()
+		return true
+	}
+	*currency = strings.ToUpper(*currency)
+	if !h.services.Currency.Supports(*currency) {
+		newErrorResponse(c, http.StatusBadRequest, "unsupported currency: "+*currency)
+		return false
+	}
+	return true
+}
+
 // @Summary Get current user profile
 // @Security ApiKeyAuth
 // @Description Get the profile information of the currently logged-in user
@@ -810,24 +916,81 @@ func (h *Handler) changePassword(c *gin.Context) {
 
 // --- Order handlers ---
 
+type quoteOrderInput struct {
+	TourID        int64  `json:"tour_id" binding:"required"`
+	TourDateID    int64  `json:"tour_date_id" binding:"required"`
+	RoomID        *int64 `json:"room_id"` // Optional room selection
+	PeopleCount   int    `json:"people_count" binding:"required,gt=0"`
+	ChildrenCount int    `json:"children_count" binding:"gte=0,ltfield=PeopleCount"` // How many of people_count are children
+	PromoCode     string `json:"promo_code" binding:"max=32"`                        // Optional promo code
+}
+
+// orderRequest преобразует параметры заказа из запроса в domain.OrderRequest
+func (i quoteOrderInput) orderRequest(userID int64) *domain.OrderRequest {
+	return &domain.OrderRequest{
+		UserID:        userID,
+		TourID:        i.TourID,
+		TourDateID:    i.TourDateID,
+		RoomID:        i.RoomID,
+		PeopleCount:   i.PeopleCount,
+		ChildrenCount: i.ChildrenCount,
+		PromoCode:     i.PromoCode,
+	}
+}
+
 type createOrderInput struct {
-	TourID      int64   `json:"tour_id" binding:"required"`
-	TourDateID  int64   `json:"tour_date_id" binding:"required"`
-	RoomID      *int64  `json:"room_id"` // Optional room selection
-	PeopleCount int     `json:"people_count" binding:"required,gt=0"`
-	TotalPrice  float64 `json:"total_price"`
+	quoteOrderInput
+	QuoteToken string `json:"quote_token" binding:"required"` // Signed quote from POST /api/orders/quote
+}
+
+// @Summary Quote an order price
+// @Security ApiKeyAuth
+// @Description Calculate the order price on the server and return a signed, time-limited quote with the price breakdown
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body quoteOrderInput true "Order details"
+// @Success 200 {object} domain.PriceQuote
+// @Failure 400 {object} ErrorResponse "Invalid input body, or the promo code is invalid or does not apply"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Promo code usage limit reached"
+// @Failure 500 {object} ErrorResponse "Internal server error (e.g., tour not available)"
+// @Router /api/orders/quote [post]
+func (h *Handler) quoteOrder(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input quoteOrderInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body: "+err.Error())
+		return
+	}
+
+	quote, err := h.services.Order.Quote(c.Request.Context(), input.orderRequest(user.ID))
+	if err != nil {
+		newOrderErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
 }
 
 // @Summary Create a new order
 // @Security ApiKeyAuth
-// @Description Create a new tour order for the current user
+// @Description Create a new tour order for the current user; the price is taken from a signed quote and re-checked on the server
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param order body createOrderInput true "Order details"
 // @Success 201 {object} map[string]int64 "Created order ID"
-// @Failure 400 {object} ErrorResponse "Invalid input body"
+// @Failure 400 {object} ErrorResponse "Invalid input body, quote token or promo code"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Email address is not verified"
+// @Failure 404 {object} ErrorResponse "The tour or tour date has been deleted"
+// @Failure 409 {object} ErrorResponse "Not enough seats or free rooms left on the tour date, the quote has expired or does not match the order, or a promotion usage limit is reached"
+// @Failure 429 {object} ErrorResponse "Too many orders created recently; see the Retry-After header"
 // @Failure 500 {object} ErrorResponse "Internal server error (e.g., tour not available)"
 // @Router /api/orders [post]
 func (h *Handler) createOrder(c *gin.Context) {
@@ -842,16 +1005,36 @@ func (h *Handler) createOrder(c *gin.Context) {
 		return
 	}
 
-	orderID, err := h.services.Order.Create(c.Request.Context(), user.ID, input.TourID, input.TourDateID, input.RoomID, input.PeopleCount, input.TotalPrice)
+	orderID, err := h.services.Order.Create(c.Request.Context(), input.orderRequest(user.ID), input.QuoteToken)
 	if err != nil {
-		// TODO: Handle specific service errors (e.g., insufficient availability, invalid IDs)
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newOrderErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"id": orderID})
 }
 
+// newOrderErrorResponse сопоставляет ошибки расчета и оформления заказа с HTTP статусами
+func newOrderErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pricing.ErrInvalidQuote),
+		errors.Is(err, service.ErrPromoCodeInvalid),
+		errors.Is(err, service.ErrPromoCodeNotApplicable):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrInsufficientAvailability),
+		errors.Is(err, repository.ErrRoomUnavailable),
+		errors.Is(err, pricing.ErrQuoteExpired),
+		errors.Is(err, service.ErrQuoteMismatch),
+		errors.Is(err, service.ErrPromotionLimitReached):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrTourNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		// TODO: Handle specific service errors (e.g., invalid IDs)
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
 // @Summary Get user orders
 // @Security ApiKeyAuth
 // @Description Get a list of orders for the currently logged-in user
@@ -882,8 +1065,9 @@ func (h *Handler) getUserOrders(c *gin.Context) {
 			"created_at":  order.CreatedAt,
 			"status":      order.Status,
 			"total_price": order.TotalPrice,
-			"adults":      order.PeopleCount, // Используем PeopleCount как количество взрослых
-			"children":    0,                 // По умолчанию 0 детей
+			"currency":    order.Currency,
+			"adults":      order.PeopleCount - order.ChildrenCount,
+			"children":    order.ChildrenCount,
 		}
 
 		// Получаем информацию о туре (город/страна уже должны быть внутри)
@@ -1011,16 +1195,16 @@ func (h *Handler) cancelOrder(c *gin.Context) {
 		newErrorResponse(c, http.StatusForbidden, "you do not have permission to cancel this order")
 		return
 	}
-	// Optional: Check if order status allows cancellation
-	if order.Status != string(domain.OrderStatusPending) && order.Status != string(domain.OrderStatusConfirmed) {
-		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("order with status '%s' cannot be cancelled", order.Status))
-		return
-	}
 
-	// Service should handle the logic of cancellation (e.g., setting status to 'cancelled')
-	// Using UpdateStatus for cancellation logic as per Service interface
-	err = h.services.Order.UpdateStatus(c.Request.Context(), id, string(domain.OrderStatusCancelled))
+	// Клиент может отменить только неоплаченный заказ; статус проверяется
+	// под блокировкой заказа, чтобы параллельная оплата не проскочила между проверкой и отменой
+	cancellable := []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusConfirmed}
+	err = h.services.Order.UpdateStatusFrom(c.Request.Context(), id, string(domain.OrderStatusCancelled), cancellable, &user.ID, "отменен клиентом")
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidOrderTransition) || errors.Is(err, service.ErrOrderStatusNotAllowed) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1045,6 +1229,7 @@ type createTicketInput struct {
 // @Success 201 {object} map[string]int64 "Created ticket ID"
 // @Failure 400 {object} ErrorResponse "Invalid input body"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 429 {object} ErrorResponse "Too many tickets created recently; see the Retry-After header"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/tickets [post]
 func (h *Handler) createTicket(c *gin.Context) {
@@ -1129,8 +1314,7 @@ func (h *Handler) getTicketByID(c *gin.Context) {
 	}
 
 	// Check if the user owns the ticket OR is support/admin
-	isSupportOrAdmin := user.RoleID == SupportRoleID || user.RoleID == AdminRoleID
-	if ticket.UserID != user.ID && !isSupportOrAdmin {
+	if !canAccessTicket(user, ticket) {
 		newErrorResponse(c, http.StatusForbidden, "you do not have permission to view this ticket")
 		return
 	}
@@ -1184,8 +1368,7 @@ func (h *Handler) addTicketMessage(c *gin.Context) {
 		return
 	}
 
-	isSupportOrAdmin := user.RoleID == SupportRoleID || user.RoleID == AdminRoleID
-	if ticket.UserID != user.ID && !isSupportOrAdmin {
+	if !canAccessTicket(user, ticket) {
 		newErrorResponse(c, http.StatusForbidden, "you do not have permission to add messages to this ticket")
 		return
 	}
@@ -1201,7 +1384,7 @@ func (h *Handler) addTicketMessage(c *gin.Context) {
 	}
 
 	// Optionally, update ticket status to 'in_progress' if added by support?
-	if isSupportOrAdmin && ticket.Status == string(domain.TicketStatusOpen) {
+	if user.Can(domain.PermTicketsAssign) && ticket.Status == string(domain.TicketStatusOpen) {
 		_ = h.services.SupportTicket.UpdateStatus(c.Request.Context(), ticketID, string(domain.TicketStatusInProgress))
 		// Log potential error during status update?
 	}
@@ -1211,11 +1394,14 @@ func (h *Handler) addTicketMessage(c *gin.Context) {
 
 // @Summary Get ticket messages
 // @Security ApiKeyAuth
-// @Description Get all messages for a specific support ticket (checks ownership or support role)
+// @Description Get messages of a support ticket in chronological order (checks ownership or support role). Without page, size or cursor all messages are returned as an array; with any of them the response is {messages, total (offset mode), next_cursor}
 // @Tags tickets, support
 // @Accept json
 // @Produce json
 // @Param id path int true "Ticket ID"
+// @Param page query int false "Page number"
+// @Param size query int false "Page size (max 100)"
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (oldest first, no total)"
 // @Success 200 {array} domain.TicketMessage
 // @Failure 400 {object} ErrorResponse "Invalid ticket ID"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
@@ -1243,19 +1429,36 @@ func (h *Handler) getTicketMessages(c *gin.Context) {
 		newErrorResponse(c, http.StatusNotFound, "ticket not found")
 		return
 	}
-	isSupportOrAdmin := user.RoleID == SupportRoleID || user.RoleID == AdminRoleID
-	if ticket.UserID != user.ID && !isSupportOrAdmin {
+	if !canAccessTicket(user, ticket) {
 		newErrorResponse(c, http.StatusForbidden, "you do not have permission to view messages for this ticket")
 		return
 	}
 
-	messages, err := h.services.SupportTicket.GetMessages(c.Request.Context(), ticketID)
+	// Без параметров пагинации возвращается вся переписка, как до их появления
+	if !hasPageParams(c) {
+		messages, err := h.services.SupportTicket.GetMessages(c.Request.Context(), ticketID)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, messages)
+		return
+	}
+
+	p := newQueryParser(c)
+	page := p.Page()
+	if !p.Check(page.Validate()) {
+		return
+	}
+
+	messages, info, err := h.services.SupportTicket.ListMessages(c.Request.Context(), ticketID, page)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newListErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, messages)
+	c.JSON(http.StatusOK, listResponse("messages", messages, info))
 }
 
 // @Summary Close a support ticket (User only)
@@ -1319,26 +1522,27 @@ func (h *Handler) closeTicket(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(10)
-// @Success 200 {object} map[string]interface{} "List of users and total count"
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of users, total count (offset mode) and next_cursor"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/users [get]
 func (h *Handler) getAllUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	p := newQueryParser(c)
+	page := p.Page()
+	if !p.Check(page.Validate()) {
+		return
+	}
 
-	users, total, err := h.services.User.List(c.Request.Context(), page, size)
+	users, info, err := h.services.User.List(c.Request.Context(), page)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newListErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"total": total,
-	})
+	c.JSON(http.StatusOK, listResponse("users", users, info))
 }
 
 // @Summary Get user by ID (Admin only)
@@ -1392,13 +1596,18 @@ type adminUpdateUserInput struct {
 // @Param id path int true "User ID"
 // @Param user body adminUpdateUserInput true "Updated user data (username, email, first_name, last_name, full_name, phone, role_id)"
 // @Success 200 {string} string "OK"
-// @Failure 400 {object} ErrorResponse "Invalid input body or ID"
+// @Failure 400 {object} ErrorResponse "Invalid input body, ID or role_id"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
-// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 403 {object} ErrorResponse "Forbidden; changing role_id requires roles:manage"
 // @Failure 404 {object} ErrorResponse "User not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/users/{id} [put]
 func (h *Handler) updateUser(c *gin.Context) {
+	actor, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -1438,7 +1647,6 @@ func (h *Handler) updateUser(c *gin.Context) {
 	}
 
 	userToUpdate.Phone = input.Phone
-	userToUpdate.RoleID = input.RoleID
 
 	// Add validation using the standard validator
 	v := validator.New()
@@ -1448,11 +1656,22 @@ func (h *Handler) updateUser(c *gin.Context) {
 		newErrorResponse(c, http.StatusBadRequest, "validation failed: "+err.Error())
 		return
 	}
-	// Add specific range validation for RoleID not covered by struct tags easily
-	err = v.Var(userToUpdate.RoleID, "min=1,max=3") // Assuming roles 1, 2, 3 exist
-	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid role_id (must be between 1 and 3)")
-		return
+
+	// Назначение роли меняет права пользователя, поэтому доступно только управляющим ролями
+	if input.RoleID != userToUpdate.RoleID {
+		if !actor.Can(domain.PermRolesManage) {
+			newErrorResponse(c, http.StatusForbidden, "changing role_id requires the roles:manage permission")
+			return
+		}
+		if _, err := h.services.Role.GetByID(c.Request.Context(), input.RoleID); err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				newErrorResponse(c, http.StatusBadRequest, "invalid role_id: role not found")
+				return
+			}
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		userToUpdate.RoleID = input.RoleID
 	}
 
 	err = h.services.User.Update(c.Request.Context(), userToUpdate)
@@ -1466,7 +1685,7 @@ func (h *Handler) updateUser(c *gin.Context) {
 
 // @Summary Delete user by ID (Admin only)
 // @Security ApiKeyAuth
-// @Description Delete a specific user by ID
+// @Description Archive a user by ID; the account can be restored via /restore
 // @Tags admin-users
 // @Accept json
 // @Produce json
@@ -1518,6 +1737,9 @@ func (h *Handler) createTour(c *gin.Context) {
 		return
 	}
 	input.ID = 0 // Ensure ID is not set by client
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
 
 	// Basic validation example using standard validator
 	v := validator.New()
@@ -1549,12 +1771,15 @@ func (h *Handler) createTour(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Tour ID"
-// @Param tour body domain.Tour true "Updated tour data (ID ignored)"
+// @Param tour body domain.Tour true "Updated tour data (ID and version ignored)"
+// @Param If-Match header string false "ETag from GET /api/tours/{id}; the update is rejected if the tour has changed since"
 // @Success 200 {string} string "OK"
+// @Header 200 {string} ETag "Tag of the updated tour"
 // @Failure 400 {object} ErrorResponse "Invalid input body or ID"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden"
 // @Failure 404 {object} ErrorResponse "Tour not found"
+// @Failure 412 {object} ErrorResponse "The tour was modified after the If-Match version"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/tours/{id} [put]
 func (h *Handler) updateTour(c *gin.Context) {
@@ -1571,6 +1796,20 @@ func (h *Handler) updateTour(c *gin.Context) {
 		return
 	}
 	input.ID = id // Ensure ID is set from path param
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
+
+	// Ожидаемая версия берется только из If-Match, версия в теле запроса игнорируется
+	input.Version = 0
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && ifMatch != "*" {
+		version, ok := tourVersionFromETag(ifMatch)
+		if !ok {
+			newErrorResponse(c, http.StatusPreconditionFailed, "If-Match must be a single ETag of the tour")
+			return
+		}
+		input.Version = version
+	}
 
 	// Basic validation example using standard validator
 	v := validator.New()
@@ -1583,17 +1822,29 @@ func (h *Handler) updateTour(c *gin.Context) {
 
 	err = h.services.Tour.Update(c.Request.Context(), &input)
 	if err != nil {
-		// TODO: Handle not found error specifically
+		if errors.Is(err, repository.ErrTourVersionConflict) {
+			newErrorResponse(c, http.StatusPreconditionFailed, "the tour was modified by someone else, reload it and try again")
+			return
+		}
+		if errors.Is(err, repository.ErrTourNotFound) {
+			newErrorResponse(c, http.StatusNotFound, "tour not found")
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	// Новый ETag позволяет сохранить следующую правку без повторной загрузки тура
+	if tour, err := h.services.Tour.GetByID(c.Request.Context(), id); err == nil {
+		c.Header("ETag", tourETag(tour, "").String())
+	}
+
 	c.Status(http.StatusOK)
 }
 
 // @Summary Delete a tour (Admin only)
 // @Security ApiKeyAuth
-// @Description Delete a tour by its ID
+// @Description Archive a tour and its dates; orders keep their references and the tour can be restored via /restore
 // @Tags admin-tours
 // @Accept json
 // @Produce json
@@ -1652,6 +1903,7 @@ func (h *Handler) addTourDate(c *gin.Context) {
 	}
 	input.TourID = tourID // Ensure TourID is set from path
 	input.ID = 0          // Ensure ID is not set by client
+	input.ScheduleID = nil
 
 	// Basic validation example using standard validator
 	v := validator.New()
@@ -1741,7 +1993,7 @@ func (h *Handler) updateTourDate(c *gin.Context) {
 
 // @Summary Delete a tour date (Admin only)
 // @Security ApiKeyAuth
-// @Description Delete a tour date by its ID
+// @Description Archive a tour date by its ID; it can be restored via /restore
 // @Tags admin-tours
 // @Accept json
 // @Produce json
@@ -1869,7 +2121,7 @@ func (h *Handler) updateHotel(c *gin.Context) {
 
 // @Summary Delete a hotel (Admin only)
 // @Security ApiKeyAuth
-// @Description Delete a hotel by its ID
+// @Description Archive a hotel and its rooms; orders keep their references and the hotel can be restored via /restore
 // @Tags admin-hotels
 // @Accept json
 // @Produce json
@@ -1928,6 +2180,9 @@ func (h *Handler) addRoom(c *gin.Context) {
 	}
 	input.HotelID = hotelID // Ensure HotelID is set from path
 	input.ID = 0            // Ensure ID is not set by client
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
 
 	// Basic validation example using standard validator
 	v := validator.New()
@@ -1987,6 +2242,9 @@ func (h *Handler) updateRoom(c *gin.Context) {
 	}
 	input.ID = roomID // Ensure IDs are set from path params
 	input.HotelID = hotelID
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
 
 	// Basic validation example using standard validator
 	v := validator.New()
@@ -2009,7 +2267,7 @@ func (h *Handler) updateRoom(c *gin.Context) {
 
 // @Summary Delete a room (Admin only)
 // @Security ApiKeyAuth
-// @Description Delete a room by its ID
+// @Description Archive a room by its ID; it can be restored via /restore
 // @Tags admin-hotels
 // @Accept json
 // @Produce json
@@ -2041,6 +2299,42 @@ func (h *Handler) deleteRoom(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Get room allotment by night (Admin only)
+// @Security ApiKeyAuth
+// @Description Get how many units of a room type are booked and still free on each night of the period
+// @Tags admin-hotels
+// @Produce json
+// @Param id path int true "Hotel ID"
+// @Param roomId path int true "Room ID"
+// @Param startDate query string true "First night (YYYY-MM-DD)"
+// @Param endDate query string true "Day after the last night (YYYY-MM-DD)"
+// @Success 200 {array} domain.RoomNight
+// @Failure 400 {object} ErrorResponse "Invalid room ID or dates"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/hotels/{id}/rooms/{roomId}/nights [get]
+func (h *Handler) getRoomNights(c *gin.Context) {
+	roomID, err := strconv.ParseInt(c.Param("roomId"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid room ID")
+		return
+	}
+
+	period, ok := parseDateRange(c, true)
+	if !ok {
+		return
+	}
+
+	nights, err := h.services.Hotel.GetRoomNights(c.Request.Context(), roomID, period.from, period.to)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, nights)
+}
+
 // --- Admin Order Management ---
 
 // @Summary Get all orders (Admin only)
@@ -2050,53 +2344,44 @@ func (h *Handler) deleteRoom(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user_id query int false "Filter by user ID"
+// @Param tour_id query int false "Filter by tour ID"
 // @Param status query string false "Filter by status (e.g., pending, confirmed)"
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(10)
-// @Success 200 {object} map[string]interface{} "List of orders and total count"
-// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of orders, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/orders [get]
 func (h *Handler) getAllOrders(c *gin.Context) {
-	filters := make(map[string]interface{})
-	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		userID, err := strconv.Atoi(userIDStr)
-		if err != nil {
-			newErrorResponse(c, http.StatusBadRequest, "invalid user_id parameter")
-			return
-		}
-		filters["user_id"] = userID
+	p := newQueryParser(c)
+	filter := domain.OrderFilter{
+		UserID: p.ID("user_id"),
+		TourID: p.ID("tour_id"),
+		Page:   p.Page(),
 	}
 	if status := c.Query("status"); status != "" {
-		// Validate status?
-		switch domain.OrderStatus(status) {
-		case domain.OrderStatusPending, domain.OrderStatusConfirmed, domain.OrderStatusPaid, domain.OrderStatusCancelled, domain.OrderStatusCompleted:
-			filters["status"] = status
-		default:
-			newErrorResponse(c, http.StatusBadRequest, "invalid status parameter")
-			return
-		}
+		orderStatus := domain.OrderStatus(status)
+		filter.Status = &orderStatus
+	}
+	if !p.Check(filter.Validate()) {
+		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	orders, total, err := h.services.Order.List(c.Request.Context(), filters, page, size)
+	orders, info, err := h.services.Order.List(c.Request.Context(), &filter)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newListErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"orders": orders,
-		"total":  total,
-	})
+	c.JSON(http.StatusOK, listResponse("orders", orders, info))
 }
 
 type updateOrderStatusInput struct {
-	Status string `json:"status" binding:"required"`
+	Status  string `json:"status" binding:"required"`
+	Comment string `json:"comment"` // Причина изменения, сохраняется в истории заказа
 }
 
 // @Summary Update order status (Admin only)
@@ -2110,11 +2395,17 @@ type updateOrderStatusInput struct {
 // @Success 200 {string} string "OK"
 // @Failure 400 {object} ErrorResponse "Invalid input body, ID, or status value"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
-// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 403 {object} ErrorResponse "Forbidden; cancelling a paid order requires orders:refund"
 // @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed from the current status"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/orders/{id}/status [put]
 func (h *Handler) updateOrderStatus(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -2137,8 +2428,23 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	err = h.services.Order.UpdateStatus(c.Request.Context(), id, input.Status)
+	// Отмена оплаченного заказа означает возврат средств и требует отдельного права;
+	// без него отмена разрешена только из неоплаченных статусов, проверяемых под блокировкой заказа
+	var from []domain.OrderStatus
+	if domain.OrderStatus(input.Status) == domain.OrderStatusCancelled && !user.Can(domain.PermOrdersRefund) {
+		from = []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusConfirmed}
+	}
+
+	err = h.services.Order.UpdateStatusFrom(c.Request.Context(), id, input.Status, from, &user.ID, input.Comment)
 	if err != nil {
+		if errors.Is(err, service.ErrOrderStatusNotAllowed) {
+			newErrorResponse(c, http.StatusForbidden, "cancelling a paid order requires the orders:refund permission")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidOrderTransition) || errors.Is(err, service.ErrOrderHoldExpired) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		// TODO: Handle not found error specifically
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -2147,6 +2453,34 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// @Summary Get order status history (Admin only)
+// @Security ApiKeyAuth
+// @Description Get the chronological list of status changes of an order, including who made each change
+// @Tags admin-orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} domain.OrderStatusChange
+// @Failure 400 {object} ErrorResponse "Invalid order ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Router /api/admin/orders/{id}/history [get]
+func (h *Handler) getOrderStatusHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	history, err := h.services.Order.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "order not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // --- Admin/Support Ticket Management ---
 
 // @Summary Get all tickets (Admin/Support)
@@ -2158,48 +2492,36 @@ func (h *Handler) updateOrderStatus(c *gin.Context) {
 // @Param user_id query int false "Filter by user ID"
 // @Param status query string false "Filter by status (open, in_progress, closed)"
 // @Param page query int false "Page number" default(1)
-// @Param size query int false "Page size" default(10)
-// @Success 200 {object} map[string]interface{} "List of tickets and total count"
-// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Param size query int false "Page size (max 100)" default(10)
+// @Param cursor query string false "Opaque cursor from next_cursor; pass an empty value to start cursor pagination (newest first, no total)"
+// @Success 200 {object} map[string]interface{} "List of tickets, total count (offset mode) and next_cursor"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters, listed in fields"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/admin/tickets [get]
 // @Router /api/support/tickets [get] // Shared endpoint for support
 func (h *Handler) getAllTickets(c *gin.Context) {
-	filters := make(map[string]interface{})
-	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		userID, err := strconv.Atoi(userIDStr)
-		if err != nil {
-			newErrorResponse(c, http.StatusBadRequest, "invalid user_id parameter")
-			return
-		}
-		filters["user_id"] = userID
+	p := newQueryParser(c)
+	filter := domain.TicketFilter{
+		UserID: p.ID("user_id"),
+		Page:   p.Page(),
 	}
 	if status := c.Query("status"); status != "" {
-		// Validate status?
-		switch domain.TicketStatus(status) {
-		case domain.TicketStatusOpen, domain.TicketStatusInProgress, domain.TicketStatusClosed:
-			filters["status"] = status
-		default:
-			newErrorResponse(c, http.StatusBadRequest, "invalid status parameter")
-			return
-		}
+		ticketStatus := domain.TicketStatus(status)
+		filter.Status = &ticketStatus
+	}
+	if !p.Check(filter.Validate()) {
+		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	tickets, total, err := h.services.SupportTicket.List(c.Request.Context(), filters, page, size)
+	tickets, info, err := h.services.SupportTicket.List(c.Request.Context(), &filter)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newListErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"tickets": tickets,
-		"total":   total,
-	})
+	c.JSON(http.StatusOK, listResponse("tickets", tickets, info))
 }
 
 type updateTicketStatusInput struct {
@@ -2268,7 +2590,10 @@ func (h *Handler) updateTicketStatus(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param size query int false "Page size" default(50)
+// @Param If-None-Match header string false "ETag of a cached copy; 304 is returned if it is still current"
 // @Success 200 {object} map[string]interface{} "List of countries and total count"
+// @Success 304 {string} string "Not Modified"
+// @Header 200 {string} ETag "Tag computed from the page content"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/countries [get]
 func (h *Handler) getAllCountries(c *gin.Context) {
@@ -2291,6 +2616,11 @@ func (h *Handler) getAllCountries(c *gin.Context) {
 		return
 	}
 
+	// У справочника стран нет версий, тег строится по содержимому
+	if notModified(c, newEntityTag("").Add(page, size, total).AddJSON(countries), time.Time{}) {
+		return
+	}
+
 	c.JSON(http.StatusOK, map[string]interface{}{
 		"data":  countries,
 		"total": total,
@@ -1,22 +1,17 @@
 package handler
 
 import (
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 )
 
-const (
-	// AdminRoleID ID роли "администратор"
-	AdminRoleID = 1
-	// UserRoleID ID роли "пользователь"
-	UserRoleID = 2
-	// SupportRoleID ID роли "тех-поддержка"
-	SupportRoleID = 3
-)
-
 // authMiddleware middleware для проверки аутентификации
 func (h *Handler) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -51,8 +46,8 @@ func (h *Handler) authMiddleware() gin.HandlerFunc {
 	}
 }
 
-// adminMiddleware middleware для проверки прав администратора
-func (h *Handler) adminMiddleware() gin.HandlerFunc {
+// requirePermission middleware для проверки права роли пользователя
+func (h *Handler) requirePermission(perm domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем пользователя из контекста
 		userAny, exists := c.Get("user")
@@ -68,8 +63,12 @@ func (h *Handler) adminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Проверяем, является ли пользователь администратором
-		if user.RoleID != AdminRoleID {
+		// Проверяем, есть ли право у роли пользователя
+		if !user.Can(perm) {
+			if user.Role != nil && user.Role.Can(perm) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Требуется вход с двухфакторной аутентификацией"})
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
 			return
 		}
@@ -78,29 +77,87 @@ func (h *Handler) adminMiddleware() gin.HandlerFunc {
 	}
 }
 
-// supportMiddleware middleware для проверки прав тех-поддержки
-func (h *Handler) supportMiddleware() gin.HandlerFunc {
+// requireVerifiedEmail middleware для проверки подтверждения email пользователя
+func (h *Handler) requireVerifiedEmail() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Получаем пользователя из контекста
-		userAny, exists := c.Get("user")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+		user, ok := getUserFromContext(c)
+		if !ok {
 			return
 		}
 
-		// Приводим тип пользователя
-		user, ok := userAny.(*domain.User)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения данных пользователя"})
+		if user.EmailVerifiedAt == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Подтвердите адрес электронной почты"})
 			return
 		}
 
-		// Проверяем, является ли пользователь сотрудником тех-поддержки или администратором
-		if user.RoleID != SupportRoleID && user.RoleID != AdminRoleID {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+		c.Next()
+	}
+}
+
+// rateLimit middleware ограничивает частоту действия по ключу, который возвращает key.
+// При недоступности хранилища окон запросы пропускаются.
+func (h *Handler) rateLimit(action string, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := h.services.RateLimit.Allow(c.Request.Context(), action, key(c))
+		if err != nil {
+			log.Printf("[RateLimit] Ошибка проверки лимита %s: %v", action, err)
+			c.Next()
+			return
+		}
+
+		if result.Limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		}
+		if !result.Allowed {
+			abortTooManyRequests(c, result.RetryAfter)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// clientIPKey ключ ограничения частоты по IP клиента
+func clientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// userKey ключ ограничения частоты по пользователю; используется после authMiddleware
+func userKey(c *gin.Context) string {
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*domain.User); ok {
+			return "user:" + strconv.FormatInt(u.ID, 10)
+		}
+	}
+	return clientIPKey(c)
+}
+
+// abortTooManyRequests отвечает 429 с заголовком Retry-After в целых секундах
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много запросов, повторите позже"})
+}
+
+// wsTokenMiddleware переносит токен из query-параметра в заголовок Authorization.
+// Браузерный WebSocket API не позволяет задать заголовки при подключении.
+func (h *Handler) wsTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// canAccessTicket проверяет, может ли пользователь работать с тикетом
+func canAccessTicket(user *domain.User, ticket *domain.SupportTicket) bool {
+	return ticket.UserID == user.ID || user.Can(domain.PermTicketsRead)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/payment"
+)
+
+// paymentSignatureHeader заголовок, в котором провайдер передает подпись webhook
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// maxWebhookBodySize ограничение размера тела webhook
+const maxWebhookBodySize = 64 << 10
+
+// @Summary Start order checkout
+// @Security ApiKeyAuth
+// @Description Create a payment intent at the payment provider for the order total (checks ownership); a pending intent of the order is returned again instead of creating a second one
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 201 {object} payment.Intent
+// @Failure 400 {object} ErrorResponse "Invalid order ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden (not owner)"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Order is already paid or cannot be paid in its current status"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/orders/{id}/checkout [post]
+func (h *Handler) checkoutOrder(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	order, err := h.services.Order.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "order not found")
+		return
+	}
+	if order.UserID != user.ID {
+		newErrorResponse(c, http.StatusForbidden, "you do not have permission to pay for this order")
+		return
+	}
+
+	intent, err := h.services.Payment.StartCheckout(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotPayable) || errors.Is(err, service.ErrOrderHoldExpired) || errors.Is(err, service.ErrOrderAlreadyPaid) || errors.Is(err, service.ErrPaymentInProgress) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, intent)
+}
+
+// @Summary Payment provider webhook
+// @Description Accept a signed event from the payment provider; a successful payment moves the order to paid
+// @Tags payments
+// @Accept json
+// @Param X-Payment-Signature header string true "HMAC signature of the request body"
+// @Success 200 "OK"
+// @Failure 400 {object} ErrorResponse "Invalid body"
+// @Failure 401 {object} ErrorResponse "Invalid signature"
+// @Failure 404 {object} ErrorResponse "Payment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/payments/webhook [post]
+func (h *Handler) paymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	err = h.services.Payment.HandleWebhook(c.Request.Context(), payload, c.GetHeader(paymentSignatureHeader))
+	if err != nil {
+		newPaymentErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Authorize a fake payment
+// @Security ApiKeyAuth
+// @Description Simulate customer approval of the caller's own order at the built-in fake provider; the resulting signed webhook is processed immediately. Available only outside release mode.
+// @Tags payments
+// @Param intentId path string true "Payment intent ID"
+// @Success 200 "OK"
+// @Failure 401 {object} ErrorResponse "Unauthorized or invalid signature"
+// @Failure 404 {object} ErrorResponse "Payment not found or simulation unsupported"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/payments/fake/{intentId}/authorize [post]
+func (h *Handler) authorizeFakePayment(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	err := h.services.Payment.SimulateAuthorization(c.Request.Context(), user.ID, c.Param("intentId"))
+	if err != nil {
+		newPaymentErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// newPaymentErrorResponse сопоставляет ошибки обработки платежа с HTTP статусами
+func newPaymentErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, repository.ErrPaymentNotFound),
+		errors.Is(err, payment.ErrIntentNotFound),
+		errors.Is(err, service.ErrPaymentSimulationUnsupported):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
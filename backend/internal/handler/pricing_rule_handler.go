@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+)
+
+// @Summary Get pricing rules (Admin only)
+// @Security ApiKeyAuth
+// @Description Get all seasonal and dynamic pricing rules
+// @Tags admin-pricing
+// @Produce json
+// @Success 200 {array} domain.PricingRule
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules [get]
+func (h *Handler) getPricingRules(c *gin.Context) {
+	rules, err := h.services.PricingRule.List(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// @Summary Create a pricing rule (Admin only)
+// @Security ApiKeyAuth
+// @Description Create a pricing rule: season (season_start..season_end), weekend departures, occupancy surge (fewer free seats than availability_below percent of capacity) or last-minute discount (days_before). Modifiers of all matching rules are multiplied with the date's base modifier
+// @Tags admin-pricing
+// @Accept json
+// @Produce json
+// @Param rule body domain.PricingRule true "Pricing rule (ID ignored, is_active defaults to true)"
+// @Success 201 {object} map[string]int64 "Created pricing rule ID"
+// @Failure 400 {object} ErrorResponse "Invalid input body"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules [post]
+func (h *Handler) createPricingRule(c *gin.Context) {
+	input := domain.PricingRule{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	input.ID = 0 // Ensure ID is not set by client
+
+	id, err := h.services.PricingRule.Create(c.Request.Context(), &input)
+	if err != nil {
+		newPricingRuleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// @Summary Get a pricing rule (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a pricing rule by ID
+// @Tags admin-pricing
+// @Produce json
+// @Param id path int true "Pricing rule ID"
+// @Success 200 {object} domain.PricingRule
+// @Failure 400 {object} ErrorResponse "Invalid pricing rule ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Pricing rule not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules/{id} [get]
+func (h *Handler) getPricingRuleByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid pricing rule ID")
+		return
+	}
+
+	rule, err := h.services.PricingRule.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newPricingRuleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary Update a pricing rule (Admin only)
+// @Security ApiKeyAuth
+// @Description Replace a pricing rule; stored tour date modifiers change on the next apply
+// @Tags admin-pricing
+// @Accept json
+// @Produce json
+// @Param id path int true "Pricing rule ID"
+// @Param rule body domain.PricingRule true "Updated pricing rule (ID ignored)"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} ErrorResponse "Invalid input body or ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Pricing rule not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules/{id} [put]
+func (h *Handler) updatePricingRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid pricing rule ID")
+		return
+	}
+
+	input := domain.PricingRule{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	input.ID = id
+
+	if err := h.services.PricingRule.Update(c.Request.Context(), &input); err != nil {
+		newPricingRuleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Delete a pricing rule (Admin only)
+// @Security ApiKeyAuth
+// @Description Delete a pricing rule; stored tour date modifiers change on the next apply
+// @Tags admin-pricing
+// @Param id path int true "Pricing rule ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid pricing rule ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Pricing rule not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules/{id} [delete]
+func (h *Handler) deletePricingRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid pricing rule ID")
+		return
+	}
+
+	if err := h.services.PricingRule.Delete(c.Request.Context(), id); err != nil {
+		newPricingRuleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Preview price modifiers (Admin only)
+// @Security ApiKeyAuth
+// @Description Dry run: compute price modifiers for a tour's upcoming dates without saving them, with the rules that matched each date
+// @Tags admin-pricing
+// @Produce json
+// @Param tour_id query int true "Tour ID"
+// @Param include_inactive query bool false "Also evaluate disabled rules"
+// @Success 200 {array} domain.PriceModifierPreview
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules/preview [get]
+func (h *Handler) previewPricingRules(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Query("tour_id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+	includeInactive, _ := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+
+	previews, err := h.services.PricingRule.Preview(c.Request.Context(), tourID, includeInactive)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, previews)
+}
+
+// @Summary Apply pricing rules (Admin only)
+// @Security ApiKeyAuth
+// @Description Recompute and store price modifiers of upcoming tour dates from the active rules. Without tour_id all tours are processed
+// @Tags admin-pricing
+// @Produce json
+// @Param tour_id query int false "Tour ID"
+// @Success 200 {object} map[string]int "Number of updated dates"
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/pricing-rules/apply [post]
+func (h *Handler) applyPricingRules(c *gin.Context) {
+	var tourID *int64
+	if raw := c.Query("tour_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+			return
+		}
+		tourID = &id
+	}
+
+	updated, err := h.services.PricingRule.Apply(c.Request.Context(), tourID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// newPricingRuleErrorResponse сопоставляет ошибки управления правилами ценообразования с HTTP статусами
+func newPricingRuleErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPricingRule):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrPricingRuleNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
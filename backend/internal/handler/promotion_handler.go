@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+)
+
+// @Summary Get all promotions (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a paginated list of promo codes and automatic discount rules
+// @Tags admin-promotions
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} map[string]interface{} "List of promotions and total count"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/promotions [get]
+func (h *Handler) getAllPromotions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+
+	promos, total, err := h.services.Promotion.List(c.Request.Context(), page, size)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"promotions": promos,
+		"total":      total,
+	})
+}
+
+// @Summary Create a promotion (Admin only)
+// @Security ApiKeyAuth
+// @Description Create a promo code (code set) or an automatic discount rule (code null): percent or fixed discount, optionally limited to a tour, group size, early booking or children
+// @Tags admin-promotions
+// @Accept json
+// @Produce json
+// @Param promotion body domain.Promotion true "Promotion data (ID ignored, is_active defaults to true)"
+// @Success 201 {object} map[string]int64 "Created promotion ID"
+// @Failure 400 {object} ErrorResponse "Invalid input body"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Promo code already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/promotions [post]
+func (h *Handler) createPromotion(c *gin.Context) {
+	input := domain.Promotion{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	input.ID = 0 // Ensure ID is not set by client
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
+
+	id, err := h.services.Promotion.Create(c.Request.Context(), &input)
+	if err != nil {
+		newPromotionErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// @Summary Get a promotion (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a promotion by ID
+// @Tags admin-promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} domain.Promotion
+// @Failure 400 {object} ErrorResponse "Invalid promotion ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/promotions/{id} [get]
+func (h *Handler) getPromotionByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	promo, err := h.services.Promotion.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newPromotionErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, promo)
+}
+
+// @Summary Update a promotion (Admin only)
+// @Security ApiKeyAuth
+// @Description Replace an existing promotion; usage already recorded on orders is kept
+// @Tags admin-promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param promotion body domain.Promotion true "Updated promotion data (ID ignored)"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} ErrorResponse "Invalid input body or ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 409 {object} ErrorResponse "Promo code already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/promotions/{id} [put]
+func (h *Handler) updatePromotion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	input := domain.Promotion{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	input.ID = id
+	if !h.resolveCurrency(c, &input.Currency) {
+		return
+	}
+
+	if err := h.services.Promotion.Update(c.Request.Context(), &input); err != nil {
+		newPromotionErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Delete a promotion (Admin only)
+// @Security ApiKeyAuth
+// @Description Delete a promotion; discounts already applied to orders stay in their price breakdown
+// @Tags admin-promotions
+// @Param id path int true "Promotion ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid promotion ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Promotion not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/promotions/{id} [delete]
+func (h *Handler) deletePromotion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	if err := h.services.Promotion.Delete(c.Request.Context(), id); err != nil {
+		newPromotionErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newPromotionErrorResponse сопоставляет ошибки управления промоакциями с HTTP статусами
+func newPromotionErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromotion):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrPromotionNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrPromoCodeTaken):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
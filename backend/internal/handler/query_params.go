@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
+)
+
+// queryParser разбирает параметры строки запроса в типизированные значения.
+// Ошибки не прерывают разбор, а накапливаются, чтобы клиент получил список
+// всех некорректных параметров сразу.
+type queryParser struct {
+	c    *gin.Context
+	errs domain.ValidationError
+}
+
+// newQueryParser создает парсер параметров запроса
+func newQueryParser(c *gin.Context) *queryParser {
+	return &queryParser{c: c}
+}
+
+// ID разбирает идентификатор; nil, если параметр не задан
+func (p *queryParser) ID(name string) *int64 {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		p.errs.Add(name, "must be an integer ID")
+		return nil
+	}
+	return &id
+}
+
+// Int разбирает целое число; nil, если параметр не задан
+func (p *queryParser) Int(name string) *int {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.errs.Add(name, "must be an integer")
+		return nil
+	}
+	return &n
+}
+
+// Bool разбирает логический флаг (true/false/1/0); false, если параметр не задан
+func (p *queryParser) Bool(name string) bool {
+	value := p.c.Query(name)
+	if value == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.errs.Add(name, "must be true or false")
+		return false
+	}
+	return b
+}
+
+// Date разбирает дату в формате YYYY-MM-DD или RFC3339; nil, если параметр не задан
+func (p *queryParser) Date(name string) *time.Time {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	t, err := parseDateParam(value)
+	if err != nil {
+		p.errs.Add(name, "must be a date in YYYY-MM-DD or RFC3339 format")
+		return nil
+	}
+	return &t
+}
+
+// Amount разбирает денежную сумму; nil, если параметр не задан
+func (p *queryParser) Amount(name string) *money.Amount {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	amount, err := money.ParseAmount(value)
+	if err != nil {
+		p.errs.Add(name, "must be a decimal amount")
+		return nil
+	}
+	return &amount
+}
+
+// Page разбирает параметры page, size и cursor. Наличие параметра cursor
+// (в том числе пустого - для первой страницы) включает выборку по курсору.
+func (p *queryParser) Page() domain.Page {
+	var page domain.Page
+	if n := p.Int("page"); n != nil {
+		page.Number = *n
+	}
+	if n := p.Int("size"); n != nil {
+		page.Size = *n
+	}
+	if value, ok := p.c.GetQuery("cursor"); ok {
+		cursor, err := pagination.Decode(value)
+		if err != nil {
+			p.errs.Add("cursor", "is invalid or expired, restart from the first page")
+		} else {
+			page.Cursor = &cursor
+		}
+	}
+	return page
+}
+
+// hasPageParams сообщает, что в запросе задан хотя бы один параметр пагинации
+func hasPageParams(c *gin.Context) bool {
+	for _, name := range []string{"page", "size", "cursor"} {
+		if _, ok := c.GetQuery(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Fail добавляет ошибку параметра, обнаруженную вне парсера
+func (p *queryParser) Fail(name, message string) {
+	p.errs.Add(name, message)
+}
+
+// Check объединяет ошибки разбора с ошибками проверки фильтра и при наличии
+// любых ошибок отвечает 400 со списком полей. Возвращает false, если ответ отправлен.
+func (p *queryParser) Check(validateErr error) bool {
+	var verr *domain.ValidationError
+	if errors.As(validateErr, &verr) {
+		p.errs.Fields = append(p.errs.Fields, verr.Fields...)
+	} else if validateErr != nil {
+		newErrorResponse(p.c, http.StatusInternalServerError, validateErr.Error())
+		return false
+	}
+
+	if err := p.errs.Err(); err != nil {
+		newValidationErrorResponse(p.c, &p.errs)
+		return false
+	}
+	return true
+}
+
+// listResponse формирует ответ со страницей списка: записи под ключом key,
+// общее количество при выборке со смещением и курсор следующей страницы
+// (null на последней странице)
+func listResponse(key string, items interface{}, info *domain.PageInfo) gin.H {
+	response := gin.H{key: items, "next_cursor": nil}
+	if info.Total != nil {
+		response["total"] = *info.Total
+	}
+	if info.NextCursor != "" {
+		response["next_cursor"] = info.NextCursor
+	}
+	return response
+}
+
+// newValidationErrorResponse отвечает 400 со списком некорректных полей
+func newValidationErrorResponse(c *gin.Context, err *domain.ValidationError) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Message: err.Error(), Fields: err.Fields})
+}
+
+// newListErrorResponse сопоставляет ошибки получения списков с HTTP статусами
+func newListErrorResponse(c *gin.Context, err error) {
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		newValidationErrorResponse(c, verr)
+		return
+	}
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+)
+
+// roleInput данные для создания и изменения роли
+type roleInput struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// @Summary Get all roles (Admin only)
+// @Security ApiKeyAuth
+// @Description Get all roles with the permissions granted to each
+// @Tags admin-roles
+// @Produce json
+// @Success 200 {array} domain.Role
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/roles [get]
+func (h *Handler) getRoles(c *gin.Context) {
+	roles, err := h.services.Role.List(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// @Summary Create a role (Admin only)
+// @Security ApiKeyAuth
+// @Description Create a role such as "content manager" or "finance" with a set of permissions from /api/admin/permissions
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Param role body roleInput true "Role name, description and permissions"
+// @Success 201 {object} map[string]int64 "Created role ID"
+// @Failure 400 {object} ErrorResponse "Invalid input body or unknown permission"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 409 {object} ErrorResponse "Role name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/roles [post]
+func (h *Handler) createRole(c *gin.Context) {
+	var input roleInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Role.Create(c.Request.Context(), &domain.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	})
+	if err != nil {
+		newRoleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// @Summary Get a role (Admin only)
+// @Security ApiKeyAuth
+// @Description Get a role with its permissions by ID
+// @Tags admin-roles
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} domain.Role
+// @Failure 400 {object} ErrorResponse "Invalid role ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/roles/{id} [get]
+func (h *Handler) getRoleByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid role ID")
+		return
+	}
+
+	role, err := h.services.Role.GetByID(c.Request.Context(), id)
+	if err != nil {
+		newRoleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// @Summary Update a role (Admin only)
+// @Security ApiKeyAuth
+// @Description Replace the description and permissions of a role; system roles keep their name. Changes apply to users of the role without re-login.
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param role body roleInput true "Role name, description and permissions"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} ErrorResponse "Invalid input body, ID or unknown permission"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Failure 409 {object} ErrorResponse "Role name taken, system role renamed or own roles:manage removed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/roles/{id} [put]
+func (h *Handler) updateRole(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid role ID")
+		return
+	}
+
+	var input roleInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	role := &domain.Role{
+		ID:          id,
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	}
+
+	// Не даем администратору лишить собственную роль управления ролями
+	if id == user.RoleID && !role.Can(domain.PermRolesManage) {
+		newErrorResponse(c, http.StatusConflict, "cannot remove roles:manage from your own role")
+		return
+	}
+
+	if err := h.services.Role.Update(c.Request.Context(), role); err != nil {
+		newRoleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Delete a role (Admin only)
+// @Security ApiKeyAuth
+// @Description Delete a custom role that is not assigned to any user, including archived ones
+// @Tags admin-roles
+// @Param id path int true "Role ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid role ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Failure 409 {object} ErrorResponse "System role or role assigned to users"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/roles/{id} [delete]
+func (h *Handler) deleteRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid role ID")
+		return
+	}
+
+	if err := h.services.Role.Delete(c.Request.Context(), id); err != nil {
+		newRoleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get all permissions (Admin only)
+// @Security ApiKeyAuth
+// @Description Get the catalogue of permissions that can be granted to roles
+// @Tags admin-roles
+// @Produce json
+// @Success 200 {array} domain.PermissionInfo
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/permissions [get]
+func (h *Handler) getPermissions(c *gin.Context) {
+	permissions, err := h.services.Role.ListPermissions(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// newRoleErrorResponse сопоставляет ошибки управления ролями с HTTP статусами
+func newRoleErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRole):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrRoleNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrRoleNameTaken),
+		errors.Is(err, repository.ErrRoleInUse),
+		errors.Is(err, service.ErrSystemRole):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
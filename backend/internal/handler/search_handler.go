@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+)
+
+// @Summary Full-text tour search
+// @Description Search active tours by name, description, city, country and hotel names. Results are ranked by relevance, tolerate typos and Cyrillic/Latin spelling, and include HTML-escaped snippets with matches wrapped in <mark>
+// @Tags tours
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Param currency query string false "ISO currency code to convert prices to"
+// @Success 200 {object} map[string]interface{} "Ranked results with tour, score and highlights"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/tours/search [get]
+func (h *Handler) searchTours(c *gin.Context) {
+	currency := c.Query("currency")
+	if currency != "" && !h.resolveCurrency(c, &currency) {
+		return
+	}
+
+	p := newQueryParser(c)
+	limit := p.Int("limit")
+	if limit != nil && *limit < 1 {
+		p.Fail("limit", "must be positive")
+	}
+	if !p.Check(nil) {
+		return
+	}
+
+	var n int
+	if limit != nil {
+		n = *limit
+	}
+	results, err := h.services.Search.Search(c.Request.Context(), c.Query("q"), n)
+	if err != nil {
+		newSearchErrorResponse(c, err)
+		return
+	}
+
+	if currency != "" {
+		for _, result := range results {
+			if err := h.services.Currency.ConvertTour(result.Tour, currency); err != nil {
+				newErrorResponse(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   len(results),
+	})
+}
+
+// @Summary Rebuild the tour search index (Admin only)
+// @Security ApiKeyAuth
+// @Description Reindex all active tours. The index is also rebuilt periodically in the background
+// @Tags admin-tours
+// @Produce json
+// @Success 200 {object} map[string]int "Number of indexed tours"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/search/reindex [post]
+func (h *Handler) reindexSearch(c *gin.Context) {
+	indexed, err := h.services.Search.Rebuild(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexed": indexed})
+}
+
+// newSearchErrorResponse сопоставляет ошибки поиска с HTTP статусами
+func newSearchErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidSearchQuery) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
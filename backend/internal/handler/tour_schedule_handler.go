@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+)
+
+// @Summary Get tour schedules (Admin only)
+// @Security ApiKeyAuth
+// @Description Get departure rules of a tour
+// @Tags admin-tours
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Success 200 {array} domain.TourSchedule
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/schedules [get]
+func (h *Handler) getTourSchedules(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+
+	schedules, err := h.services.TourSchedule.ListByTourID(c.Request.Context(), tourID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// @Summary Create a tour schedule (Admin only)
+// @Security ApiKeyAuth
+// @Description Create a departure rule: ISO weekdays (1 = Monday), optional season window, blackout dates, capacity per date and a month to price modifier curve. Dates are generated by the background scheduler or on demand
+// @Tags admin-tours
+// @Accept json
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param schedule body domain.TourSchedule true "Schedule data (IDs ignored, is_active defaults to true)"
+// @Success 201 {object} map[string]int64 "Created schedule ID"
+// @Failure 400 {object} ErrorResponse "Invalid input body or ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/schedules [post]
+func (h *Handler) createTourSchedule(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+
+	input := domain.TourSchedule{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body: "+err.Error())
+		return
+	}
+	input.ID = 0
+	input.TourID = tourID
+
+	id, err := h.services.TourSchedule.Create(c.Request.Context(), &input)
+	if err != nil {
+		newTourScheduleErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// @Summary Update a tour schedule (Admin only)
+// @Security ApiKeyAuth
+// @Description Replace a departure rule; dates already generated from it are kept unchanged
+// @Tags admin-tours
+// @Accept json
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Param scheduleId path int true "Schedule ID"
+// @Param schedule body domain.TourSchedule true "Updated schedule data (IDs ignored)"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} ErrorResponse "Invalid input body or ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Schedule not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/schedules/{scheduleId} [put]
+func (h *Handler) updateTourSchedule(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+	scheduleID, err := strconv.ParseInt(c.Param("scheduleId"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid schedule ID")
+		return
+	}
+
+	input := domain.TourSchedule{IsActive: true}
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body: "+err.Error())
+		return
+	}
+	input.ID = scheduleID
+	input.TourID = tourID
+
+	if err := h.services.TourSchedule.Update(c.Request.Context(), &input); err != nil {
+		newTourScheduleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Delete a tour schedule (Admin only)
+// @Security ApiKeyAuth
+// @Description Delete a departure rule; dates already generated from it stay bookable
+// @Tags admin-tours
+// @Param id path int true "Tour ID"
+// @Param scheduleId path int true "Schedule ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Schedule not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/schedules/{scheduleId} [delete]
+func (h *Handler) deleteTourSchedule(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+	scheduleID, err := strconv.ParseInt(c.Param("scheduleId"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid schedule ID")
+		return
+	}
+
+	if err := h.services.TourSchedule.Delete(c.Request.Context(), tourID, scheduleID); err != nil {
+		newTourScheduleErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Generate tour dates (Admin only)
+// @Security ApiKeyAuth
+// @Description Materialise dates from the tour's active schedules up to the planning horizon. Existing dates are left untouched, so the call is idempotent
+// @Tags admin-tours
+// @Produce json
+// @Param id path int true "Tour ID"
+// @Success 200 {object} map[string]int "Number of created dates"
+// @Failure 400 {object} ErrorResponse "Invalid tour ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/admin/tours/{id}/schedules/generate [post]
+func (h *Handler) generateTourDates(c *gin.Context) {
+	tourID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid tour ID")
+		return
+	}
+
+	created, err := h.services.TourSchedule.Generate(c.Request.Context(), &tourID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"created": created})
+}
+
+// newTourScheduleErrorResponse сопоставляет ошибки управления расписаниями с HTTP статусами
+func newTourScheduleErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTourSchedule):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrTourScheduleNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
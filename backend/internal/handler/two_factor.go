@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+)
+
+// twoFactorLoginInput данные для завершения входа вторым фактором
+type twoFactorLoginInput struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"` // код из приложения или код восстановления
+}
+
+// twoFactorCodeInput код из приложения или код восстановления
+type twoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// disableTwoFactorInput данные для отключения двухфакторной аутентификации
+type disableTwoFactorInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// twoFactorLogin обработчик второго шага входа: обмен токена подтверждения и кода на токены
+func (h *Handler) twoFactorLogin(c *gin.Context) {
+	var input twoFactorLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := h.services.Auth.VerifyTwoFactor(c.Request.Context(), input.ChallengeToken, input.Code)
+	if err != nil {
+		log.Printf("[Auth] Ошибка подтверждения входа вторым фактором: %v", err)
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+// twoFactorStatus обработчик получения состояния двухфакторной аутентификации
+func (h *Handler) twoFactorStatus(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.services.TwoFactor.Status(c.Request.Context(), user)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// enrollTwoFactor обработчик начала подключения приложения-аутентификатора
+func (h *Handler) enrollTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.services.TwoFactor.Enroll(c.Request.Context(), user)
+	if err != nil {
+		log.Printf("[Auth] Ошибка подключения двухфакторной аутентификации: %v", err)
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// confirmTwoFactor обработчик включения двухфакторной аутентификации по первому коду.
+// После включения все сессии завершаются и требуется повторный вход.
+func (h *Handler) confirmTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.TwoFactor.Confirm(c.Request.Context(), user.ID, input.Code); err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// disableTwoFactor обработчик отключения двухфакторной аутентификации
+func (h *Handler) disableTwoFactor(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input disableTwoFactorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	if err := h.services.TwoFactor.Disable(c.Request.Context(), user, input.Password, input.Code); err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// regenerateRecoveryCodes обработчик выпуска новых кодов восстановления; старые перестают действовать
+func (h *Handler) regenerateRecoveryCodes(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректные данные: " + err.Error()})
+		return
+	}
+
+	codes, err := h.services.TwoFactor.RegenerateRecoveryCodes(c.Request.Context(), user.ID, input.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// twoFactorErrorResponse отвечает статусом, соответствующим ошибке двухфакторной аутентификации
+func twoFactorErrorResponse(c *gin.Context, err error) {
+	var limitErr *ratelimit.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		abortTooManyRequests(c, limitErr.RetryAfter)
+	case errors.Is(err, service.ErrInvalidTwoFactorCode),
+		errors.Is(err, service.ErrInvalidTwoFactorChallenge),
+		errors.Is(err, service.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -3,12 +3,16 @@ package handler
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	gw "github.com/gorilla/websocket"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/service"
 	pkgwebsocket "github.com/usedcvnt/Diplom1Project/backend/pkg/websocket"
 )
 
@@ -51,13 +55,14 @@ func (h *Handler) wsTicketChat(c *gin.Context) {
 	user := userRaw.(*domain.User)
 
 	// Проверяем права доступа к тикету
-	if user.RoleID != AdminRoleID && user.RoleID != SupportRoleID {
-		// Если это обычный пользователь, проверяем, принадлежит ли ему тикет
-		ticket, err := h.services.SupportTicket.GetByID(c.Request.Context(), ticketID)
-		if err != nil || ticket.UserID != user.ID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет доступа к этому тикету"})
-			return
-		}
+	ticket, err := h.services.SupportTicket.GetByID(c.Request.Context(), ticketID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+		return
+	}
+	if !canAccessTicket(user, ticket) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет доступа к этому тикету"})
+		return
 	}
 
 	// Апгрейд HTTP-соединения до WebSocket
@@ -74,64 +79,190 @@ func (h *Handler) wsTicketChat(c *gin.Context) {
 		Send:     make(chan pkgwebsocket.Message, 256),
 		TicketID: ticketID,
 		UserID:   user.ID,
+		OnMessage: func(client *pkgwebsocket.Client, msg pkgwebsocket.Message) {
+			h.handleTicketMessage(client, msg, user)
+		},
 	}
 
-	// Регистрируем клиента в хабе
+	// Регистрируем клиента в хабе до чтения истории, чтобы не пропустить
+	// сообщения, сохраненные в это время
 	client.Hub.Register <- client
 
+	// История пишется в соединение напрямую, до запуска WritePump: через канал
+	// Send длинная история переполнила бы буфер, и хаб отключил бы клиента
+	if err := h.writeTicketHistory(c.Request.Context(), client); err != nil {
+		log.Printf("[WebSocket] Ошибка отправки истории тикета %d: %v", ticketID, err)
+		client.Hub.Unregister <- client
+		conn.Close()
+		return
+	}
+
 	// Запускаем горутины для чтения и записи сообщений
 	go client.WritePump()
 	go client.ReadPump()
+}
+
+// writeTicketHistory отправляет клиенту сохраненные сообщения тикета
+func (h *Handler) writeTicketHistory(ctx context.Context, client *pkgwebsocket.Client) error {
+	messages, err := h.services.SupportTicket.GetMessages(ctx, client.TicketID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения истории тикета: %w", err)
+	}
+
+	names := h.senderNames(ctx, messages)
+	for _, msg := range messages {
+		err := client.Write(pkgwebsocket.Message{
+			Type:    "history",
+			Content: ticketMessagePayload(msg, names[msg.UserID]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Отправляем историю сообщений
-	messages, err := h.services.SupportTicket.GetMessages(c.Request.Context(), ticketID)
-	if err == nil {
-		for _, msg := range messages {
-			sender, _ := h.services.User.GetByID(c.Request.Context(), msg.UserID)
-			var senderName string
-			if sender != nil {
-				senderName = sender.Username
-			} else {
-				senderName = "Неизвестный пользователь"
-			}
-
-			client.Send <- pkgwebsocket.Message{
-				Type: "history",
-				Content: map[string]interface{}{
-					"id":        msg.ID,
-					"sender":    senderName,
-					"senderId":  msg.UserID,
-					"message":   msg.Message,
-					"timestamp": msg.CreatedAt,
-				},
-			}
+// senderNames загружает имена авторов сообщений, по одному запросу на каждого
+// автора, а не на каждое сообщение
+func (h *Handler) senderNames(ctx context.Context, messages []*domain.TicketMessage) map[int64]string {
+	names := make(map[int64]string)
+	for _, msg := range messages {
+		if _, ok := names[msg.UserID]; ok {
+			continue
+		}
+		names[msg.UserID] = ""
+		if sender, err := h.services.User.GetByID(ctx, msg.UserID); err == nil && sender != nil {
+			names[msg.UserID] = sender.Username
 		}
 	}
+	return names
 }
 
-// handleTicketMessage обрабатывает новое сообщение из WebSocket и сохраняет его в базе данных
-func (h *Handler) handleTicketMessage(message pkgwebsocket.Message, ticketID, userID int64) {
+// handleTicketMessage обрабатывает новое сообщение из WebSocket, сохраняет его
+// в базе данных и только после этого рассылает участникам чата
+func (h *Handler) handleTicketMessage(client *pkgwebsocket.Client, message pkgwebsocket.Message, connected *domain.User) {
+	ctx := context.Background()
+
+	// Пользователь перечитывается на каждое сообщение: после подключения его
+	// могли удалить, заблокировать или сменить ему роль
+	user, err := h.currentWSUser(ctx, connected)
+	if err != nil {
+		log.Printf("[WebSocket] Соединение пользователя ID=%d закрыто: %v", connected.ID, err)
+		sendWSError(client, "сессия недействительна, войдите заново")
+		client.Hub.Unregister <- client
+		return
+	}
+
 	// Проверяем, что это сообщение чата
 	if message.Type != "chat" {
+		sendWSError(client, "неподдерживаемый тип сообщения")
 		return
 	}
 
 	// Получаем содержимое сообщения
 	content, ok := message.Content.(map[string]interface{})
 	if !ok {
+		sendWSError(client, "некорректный формат сообщения")
 		return
 	}
 
 	messageText, ok := content["message"].(string)
+	messageText = strings.TrimSpace(messageText)
 	if !ok || messageText == "" {
+		sendWSError(client, "сообщение не может быть пустым")
+		return
+	}
+
+	// Права проверяются на каждое сообщение: тикет мог быть закрыт после подключения
+	ticket, err := h.services.SupportTicket.GetByID(ctx, client.TicketID)
+	if err != nil {
+		sendWSError(client, "тикет не найден")
+		return
+	}
+	if !canAccessTicket(user, ticket) {
+		sendWSError(client, "у вас нет доступа к этому тикету")
+		return
+	}
+	if ticket.Status == string(domain.TicketStatusClosed) {
+		sendWSError(client, "нельзя отправлять сообщения в закрытый тикет")
 		return
 	}
 
 	// Сохраняем сообщение в базе данных
-	_, err := h.services.SupportTicket.AddMessage(context.Background(), ticketID, userID, messageText)
+	messageID, err := h.services.SupportTicket.AddMessage(ctx, client.TicketID, user.ID, messageText)
+	if err != nil {
+		log.Printf("[WebSocket] Ошибка сохранения сообщения в БД: %v", err)
+		sendWSError(client, "не удалось сохранить сообщение")
+		return
+	}
+
+	// Как и в REST-обработчике, ответ поддержки переводит тикет в работу
+	if user.Can(domain.PermTicketsAssign) && ticket.Status == string(domain.TicketStatusOpen) {
+		_ = h.services.SupportTicket.UpdateStatus(ctx, client.TicketID, string(domain.TicketStatusInProgress))
+	}
+
+	// Перечитываем сообщение, чтобы разослать ID и время из БД
+	saved, err := h.services.SupportTicket.GetMessageByID(ctx, messageID)
+	if err != nil {
+		log.Printf("[WebSocket] Ошибка получения сохраненного сообщения %d: %v", messageID, err)
+		saved = &domain.TicketMessage{
+			ID:        messageID,
+			TicketID:  client.TicketID,
+			UserID:    user.ID,
+			Message:   messageText,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	client.Hub.Broadcast <- pkgwebsocket.BroadcastMessage{
+		Message: pkgwebsocket.Message{
+			Type:    "chat",
+			Content: ticketMessagePayload(saved, user.Username),
+		},
+		TicketID: client.TicketID,
+	}
+}
+
+// currentWSUser возвращает актуальные данные пользователя открытого соединения.
+// Смена пароля или роли, блокировка и удаление увеличивают версию токенов,
+// поэтому расхождение с версией на момент подключения означает отзыв сессии.
+func (h *Handler) currentWSUser(ctx context.Context, connected *domain.User) (*domain.User, error) {
+	user, err := h.services.User.GetByID(ctx, connected.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TokenVersion != connected.TokenVersion {
+		return nil, service.ErrTokenRevoked
+	}
+
+	user.Role, err = h.services.Role.GetByID(ctx, user.RoleID)
 	if err != nil {
-		// Обработка ошибки сохранения сообщения
-		fmt.Printf("Ошибка сохранения сообщения в БД: %v\n", err)
-		// Возможно, стоит отправить сообщение об ошибке обратно клиенту или залогировать
+		return nil, err
 	}
+	user.TwoFactorVerified = connected.TwoFactorVerified
+	return user, nil
+}
+
+// ticketMessagePayload формирует содержимое сообщения чата для клиента;
+// пустое имя означает, что автор не найден
+func ticketMessagePayload(msg *domain.TicketMessage, senderName string) map[string]interface{} {
+	if senderName == "" {
+		senderName = "Неизвестный пользователь"
+	}
+
+	return map[string]interface{}{
+		"id":        msg.ID,
+		"sender":    senderName,
+		"senderId":  msg.UserID,
+		"message":   msg.Message,
+		"timestamp": msg.CreatedAt,
+	}
+}
+
+// sendWSError отправляет ошибку только клиенту, приславшему сообщение
+func sendWSError(client *pkgwebsocket.Client, message string) {
+	client.Hub.SendTo(client, pkgwebsocket.Message{
+		Type:    "error",
+		Content: map[string]interface{}{"message": message},
+	})
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrAccountTokenInvalid токен не найден, уже использован или истек
+var ErrAccountTokenInvalid = errors.New("токен недействителен или истек")
+
+// accountTokenRepository реализация AccountTokenRepository
+type accountTokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountTokenRepository создает новый экземпляр AccountTokenRepository
+func NewAccountTokenRepository(db *sqlx.DB) AccountTokenRepository {
+	return &accountTokenRepository{db: db}
+}
+
+// Create сохраняет выданный токен
+func (r *accountTokenRepository) Create(ctx context.Context, token *domain.AccountToken) error {
+	query := `
+		INSERT INTO account_tokens (user_id, purpose, email, token_hash, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, token.UserID, token.Purpose, token.Email, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении токена: %w", err)
+	}
+
+	token.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении ID токена: %w", err)
+	}
+
+	return nil
+}
+
+// Consume отмечает токен использованным. Строка блокируется до конца
+// транзакции, поэтому параллельные запросы с одним токеном не пройдут оба.
+func (r *accountTokenRepository) Consume(ctx context.Context, purpose domain.AccountTokenPurpose, tokenHash string) (*domain.AccountToken, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	var token domain.AccountToken
+	err = tx.GetContext(ctx, &token, `
+		SELECT id, user_id, purpose, email, token_hash, expires_at, used_at, created_at
+		FROM account_tokens
+		WHERE token_hash = ? AND purpose = ?
+		FOR UPDATE
+	`, tokenHash, purpose)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccountTokenInvalid
+		}
+		return nil, fmt.Errorf("ошибка при получении токена: %w", err)
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrAccountTokenInvalid
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE account_tokens SET used_at = NOW() WHERE id = ?", token.ID); err != nil {
+		return nil, fmt.Errorf("ошибка при использовании токена: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeForUser отзывает неиспользованные токены пользователя с указанным назначением
+func (r *accountTokenRepository) RevokeForUser(ctx context.Context, userID int64, purpose domain.AccountTokenPurpose) error {
+	query := "UPDATE account_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве токенов: %w", err)
+	}
+
+	return nil
+}
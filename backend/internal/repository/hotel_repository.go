@@ -51,9 +51,9 @@ func (r *hotelRepository) Create(ctx context.Context, hotel *domain.Hotel) (int6
 // GetByID получает отель по ID
 func (r *hotelRepository) GetByID(ctx context.Context, id int64) (*domain.Hotel, error) {
 	query := `
-		SELECT id, city_id, name, description, address, category, image_url, is_active, created_at
+		SELECT id, city_id, name, description, address, category, image_url, is_active, created_at, updated_at
 		FROM hotels
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var hotel domain.Hotel
@@ -92,51 +92,55 @@ func (r *hotelRepository) Update(ctx context.Context, hotel *domain.Hotel) error
 	return nil
 }
 
-// Delete удаляет отель по ID
+// Delete мягко удаляет отель вместе с его номерами
 func (r *hotelRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM hotels WHERE id = ?"
-
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
+	if err := softDeleteCascade(ctx, r.db, "hotels", "rooms", "hotel_id", id); err != nil {
 		return fmt.Errorf("ошибка при удалении отеля: %w", err)
 	}
 
 	return nil
 }
 
-// List возвращает список отелей с фильтрацией
-func (r *hotelRepository) List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.Hotel, error) {
-	query := `
-		SELECT h.id, h.city_id, h.name, h.description, h.address, h.category, h.image_url, h.is_active, h.created_at
-		FROM hotels h
-		JOIN cities c ON h.city_id = c.id
-		WHERE h.is_active = true
-	`
+// Restore восстанавливает удаленный отель и номера, удаленные вместе с ним
+func (r *hotelRepository) Restore(ctx context.Context, id int64) error {
+	if err := restoreCascade(ctx, r.db, "hotels", "rooms", "hotel_id", id); err != nil {
+		return fmt.Errorf("ошибка при восстановлении отеля: %w", err)
+	}
+
+	return nil
+}
+
+// hotelListQuery строит общий для List и Count запрос по фильтру отелей
+func hotelListQuery(filter *domain.HotelFilter) *listQuery {
+	q := newListQuery("hotels h").
+		Join("JOIN cities c ON h.city_id = c.id").
+		Where(deletedCondition("h.deleted_at", filter.Deleted))
+	// В архиве выводятся и неактивные отели
+	if !filter.Deleted {
+		q.Where("h.is_active = true")
+	}
 
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if cityID, ok := filters["city_id"]; ok {
-			query += " AND h.city_id = ?"
-			args = append(args, cityID)
-		}
-		if countryID, ok := filters["country_id"]; ok {
-			query += " AND c.country_id = ?"
-			args = append(args, countryID)
-		}
-		if categoryMin, ok := filters["category_min"]; ok {
-			query += " AND h.category >= ?"
-			args = append(args, categoryMin)
-		}
-		if categoryMax, ok := filters["category_max"]; ok {
-			query += " AND h.category <= ?"
-			args = append(args, categoryMax)
-		}
+	if filter.CityID != nil {
+		q.Where("h.city_id = ?", *filter.CityID)
+	}
+	if filter.CountryID != nil {
+		q.Where("c.country_id = ?", *filter.CountryID)
+	}
+	if filter.CategoryMin != nil {
+		q.Where("h.category >= ?", *filter.CategoryMin)
 	}
+	if filter.CategoryMax != nil {
+		q.Where("h.category <= ?", *filter.CategoryMax)
+	}
+
+	return q.OrderBy("h.created_at", domain.SortDesc).OrderBy("h.id", domain.SortDesc).
+		Keyset("h.created_at", "h.id", domain.SortDesc)
+}
 
-	query += " ORDER BY h.created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+// List возвращает страницу списка отелей с фильтрацией
+func (r *hotelRepository) List(ctx context.Context, filter *domain.HotelFilter) ([]*domain.Hotel, error) {
+	query, args := hotelListQuery(filter).Paginate(filter.Page).
+		Select("h.id, h.city_id, h.name, h.description, h.address, h.category, h.image_url, h.is_active, h.created_at, h.updated_at, h.deleted_at")
 
 	var hotels []*domain.Hotel
 	err := r.db.SelectContext(ctx, &hotels, query, args...)
@@ -148,35 +152,8 @@ func (r *hotelRepository) List(ctx context.Context, filters map[string]interface
 }
 
 // Count возвращает количество отелей с учетом фильтрации
-func (r *hotelRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM hotels h
-		JOIN cities c ON h.city_id = c.id
-		WHERE h.is_active = true
-	`
-
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if cityID, ok := filters["city_id"]; ok {
-			query += " AND h.city_id = ?"
-			args = append(args, cityID)
-		}
-		if countryID, ok := filters["country_id"]; ok {
-			query += " AND c.country_id = ?"
-			args = append(args, countryID)
-		}
-		if categoryMin, ok := filters["category_min"]; ok {
-			query += " AND h.category >= ?"
-			args = append(args, categoryMin)
-		}
-		if categoryMax, ok := filters["category_max"]; ok {
-			query += " AND h.category <= ?"
-			args = append(args, categoryMax)
-		}
-	}
+func (r *hotelRepository) Count(ctx context.Context, filter *domain.HotelFilter) (int, error) {
+	query, args := hotelListQuery(filter).Count("COUNT(*)")
 
 	var count int
 	err := r.db.GetContext(ctx, &count, query, args...)
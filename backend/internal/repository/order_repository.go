@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
@@ -43,8 +44,8 @@ func (r *orderRepository) BeginTx(ctx context.Context) (Tx, error) {
 // Create создает новый заказ
 func (r *orderRepository) Create(ctx context.Context, order *domain.Order) (int64, error) {
 	query := `
-		INSERT INTO orders (user_id, tour_id, tour_date_id, room_id, people_count, total_price, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO orders (user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, expires_at, price_breakdown)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(
@@ -55,8 +56,12 @@ func (r *orderRepository) Create(ctx context.Context, order *domain.Order) (int6
 		order.TourDateID,
 		order.RoomID,
 		order.PeopleCount,
+		order.ChildrenCount,
 		order.TotalPrice,
+		order.Currency,
 		order.Status,
+		order.ExpiresAt,
+		order.PriceBreakdown,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании заказа: %w", err)
@@ -73,8 +78,8 @@ func (r *orderRepository) Create(ctx context.Context, order *domain.Order) (int6
 // CreateTx создает новый заказ в рамках транзакции
 func (r *orderRepository) CreateTx(ctx context.Context, tx Tx, order *domain.Order) (int64, error) {
 	query := `
-		INSERT INTO orders (user_id, tour_id, tour_date_id, room_id, people_count, total_price, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO orders (user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, expires_at, price_breakdown)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	sqlxTx := tx.(*sqlxTx)
@@ -87,8 +92,12 @@ func (r *orderRepository) CreateTx(ctx context.Context, tx Tx, order *domain.Ord
 		order.TourDateID,
 		order.RoomID,
 		order.PeopleCount,
+		order.ChildrenCount,
 		order.TotalPrice,
+		order.Currency,
 		order.Status,
+		order.ExpiresAt,
+		order.PriceBreakdown,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании заказа в транзакции: %w", err)
@@ -105,7 +114,7 @@ func (r *orderRepository) CreateTx(ctx context.Context, tx Tx, order *domain.Ord
 // GetByID получает заказ по ID
 func (r *orderRepository) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
 	query := `
-		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, total_price, status, created_at
+		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, created_at, expires_at, price_breakdown, payment_id
 		FROM orders
 		WHERE id = ?
 	`
@@ -119,11 +128,31 @@ func (r *orderRepository) GetByID(ctx context.Context, id int64) (*domain.Order,
 	return &order, nil
 }
 
+// GetByIDForUpdateTx получает заказ по ID с блокировкой строки до конца транзакции
+func (r *orderRepository) GetByIDForUpdateTx(ctx context.Context, tx Tx, id int64) (*domain.Order, error) {
+	query := `
+		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, created_at, expires_at, price_breakdown, payment_id
+		FROM orders
+		WHERE id = ?
+		FOR UPDATE
+	`
+
+	sqlxTx := tx.(*sqlxTx)
+
+	var order domain.Order
+	err := sqlxTx.tx.GetContext(ctx, &order, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заказа в транзакции: %w", err)
+	}
+
+	return &order, nil
+}
+
 // Update обновляет информацию о заказе
 func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error {
 	query := `
 		UPDATE orders
-		SET user_id = ?, tour_id = ?, tour_date_id = ?, room_id = ?, people_count = ?, total_price = ?, status = ?
+		SET user_id = ?, tour_id = ?, tour_date_id = ?, room_id = ?, people_count = ?, children_count = ?, total_price = ?, currency = ?, status = ?
 		WHERE id = ?
 	`
 
@@ -135,7 +164,9 @@ func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error
 		order.TourDateID,
 		order.RoomID,
 		order.PeopleCount,
+		order.ChildrenCount,
 		order.TotalPrice,
+		order.Currency,
 		order.Status,
 		order.ID,
 	)
@@ -161,7 +192,7 @@ func (r *orderRepository) Delete(ctx context.Context, id int64) error {
 // ListByUserID возвращает список заказов пользователя
 func (r *orderRepository) ListByUserID(ctx context.Context, userID int64) ([]*domain.Order, error) {
 	query := `
-		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, total_price, status, created_at
+		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, created_at, expires_at, price_breakdown, payment_id
 		FROM orders
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -176,34 +207,28 @@ func (r *orderRepository) ListByUserID(ctx context.Context, userID int64) ([]*do
 	return orders, nil
 }
 
-// List возвращает список заказов с фильтрацией
-func (r *orderRepository) List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.Order, error) {
-	query := `
-		SELECT id, user_id, tour_id, tour_date_id, room_id, people_count, total_price, status, created_at
-		FROM orders
-		WHERE 1=1
-	`
+// orderListQuery строит общий для List и Count запрос по фильтру заказов
+func orderListQuery(filter *domain.OrderFilter) *listQuery {
+	q := newListQuery("orders")
 
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if userID, ok := filters["user_id"]; ok {
-			query += " AND user_id = ?"
-			args = append(args, userID)
-		}
-		if tourID, ok := filters["tour_id"]; ok {
-			query += " AND tour_id = ?"
-			args = append(args, tourID)
-		}
-		if status, ok := filters["status"]; ok {
-			query += " AND status = ?"
-			args = append(args, status)
-		}
+	if filter.UserID != nil {
+		q.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.TourID != nil {
+		q.Where("tour_id = ?", *filter.TourID)
 	}
+	if filter.Status != nil {
+		q.Where("status = ?", *filter.Status)
+	}
+
+	return q.OrderBy("created_at", domain.SortDesc).OrderBy("id", domain.SortDesc).
+		Keyset("created_at", "id", domain.SortDesc)
+}
 
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+// List возвращает страницу списка заказов с фильтрацией
+func (r *orderRepository) List(ctx context.Context, filter *domain.OrderFilter) ([]*domain.Order, error) {
+	query, args := orderListQuery(filter).Paginate(filter.Page).
+		Select("id, user_id, tour_id, tour_date_id, room_id, people_count, children_count, total_price, currency, status, created_at, expires_at, price_breakdown, payment_id")
 
 	var orders []*domain.Order
 	err := r.db.SelectContext(ctx, &orders, query, args...)
@@ -215,30 +240,8 @@ func (r *orderRepository) List(ctx context.Context, filters map[string]interface
 }
 
 // Count возвращает количество заказов с учетом фильтрации
-func (r *orderRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM orders
-		WHERE 1=1
-	`
-
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if userID, ok := filters["user_id"]; ok {
-			query += " AND user_id = ?"
-			args = append(args, userID)
-		}
-		if tourID, ok := filters["tour_id"]; ok {
-			query += " AND tour_id = ?"
-			args = append(args, tourID)
-		}
-		if status, ok := filters["status"]; ok {
-			query += " AND status = ?"
-			args = append(args, status)
-		}
-	}
+func (r *orderRepository) Count(ctx context.Context, filter *domain.OrderFilter) (int, error) {
+	query, args := orderListQuery(filter).Count("COUNT(*)")
 
 	var count int
 	err := r.db.GetContext(ctx, &count, query, args...)
@@ -261,16 +264,98 @@ func (r *orderRepository) UpdateStatus(ctx context.Context, id int64, status str
 	return nil
 }
 
-// UpdateStatusTx обновляет статус заказа в рамках транзакции
+// UpdateStatusTx обновляет статус заказа в рамках транзакции.
+// Удержание мест имеет смысл только для ожидающего заказа, поэтому при уходе
+// из статуса pending срок удержания сбрасывается.
 func (r *orderRepository) UpdateStatusTx(ctx context.Context, tx Tx, id int64, status string) error {
-	query := "UPDATE orders SET status = ? WHERE id = ?"
+	query := `
+		UPDATE orders
+		SET status = ?, expires_at = IF(? = 'pending', expires_at, NULL)
+		WHERE id = ?
+	`
 
 	sqlxTx := tx.(*sqlxTx)
 
-	_, err := sqlxTx.tx.ExecContext(ctx, query, status, id)
+	_, err := sqlxTx.tx.ExecContext(ctx, query, status, status, id)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении статуса заказа в транзакции: %w", err)
 	}
 
 	return nil
 }
+
+// SetPaymentTx запоминает платеж, которым оплачен заказ, в транзакции смены статуса
+func (r *orderRepository) SetPaymentTx(ctx context.Context, tx Tx, id, paymentID int64) error {
+	query := "UPDATE orders SET payment_id = ? WHERE id = ?"
+
+	sqlxTx := tx.(*sqlxTx)
+
+	_, err := sqlxTx.tx.ExecContext(ctx, query, paymentID, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении платежа заказа: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpiredPending возвращает ID ожидающих заказов, срок удержания которых истек
+func (r *orderRepository) ListExpiredPending(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM orders
+		WHERE status = 'pending' AND expires_at IS NOT NULL AND expires_at <= ?
+		ORDER BY expires_at
+		LIMIT ?
+	`
+
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении просроченных заказов: %w", err)
+	}
+
+	return ids, nil
+}
+
+// AddStatusHistoryTx записывает изменение статуса заказа в рамках транзакции
+func (r *orderRepository) AddStatusHistoryTx(ctx context.Context, tx Tx, change *domain.OrderStatusChange) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, comment)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	sqlxTx := tx.(*sqlxTx)
+
+	_, err := sqlxTx.tx.ExecContext(ctx, query,
+		change.OrderID,
+		change.FromStatus,
+		change.ToStatus,
+		change.ChangedBy,
+		change.Comment,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи истории статуса заказа: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatusHistory возвращает историю изменения статуса заказа в хронологическом порядке
+func (r *orderRepository) ListStatusHistory(ctx context.Context, orderID int64) ([]*domain.OrderStatusChange, error) {
+	query := `
+		SELECT h.id, h.order_id, h.from_status, h.to_status, h.changed_by,
+			u.username AS changed_by_username, h.comment, h.created_at
+		FROM order_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.order_id = ?
+		ORDER BY h.created_at, h.id
+	`
+
+	var history []*domain.OrderStatusChange
+	err := r.db.SelectContext(ctx, &history, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории статусов заказа: %w", err)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrPaymentNotFound возвращается, если платеж не найден
+var ErrPaymentNotFound = errors.New("платеж не найден")
+
+// paymentRepository реализация PaymentRepository
+type paymentRepository struct {
+	db *sqlx.DB
+}
+
+// NewPaymentRepository создает новый экземпляр PaymentRepository
+func NewPaymentRepository(db *sqlx.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// Create сохраняет новый платеж
+func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) (int64, error) {
+	query := `
+		INSERT INTO payments (order_id, provider, provider_payment_id, amount, currency, status)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		payment.OrderID,
+		payment.Provider,
+		payment.ProviderPaymentID,
+		payment.Amount,
+		payment.Currency,
+		payment.Status,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании платежа: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID созданного платежа: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByProviderPaymentID получает платеж по идентификатору у провайдера
+func (r *paymentRepository) GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*domain.Payment, error) {
+	query := `
+		SELECT id, order_id, provider, provider_payment_id, amount, currency, status, created_at, updated_at
+		FROM payments
+		WHERE provider = ? AND provider_payment_id = ?
+	`
+
+	var payment domain.Payment
+	err := r.db.GetContext(ctx, &payment, query, provider, providerPaymentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении платежа: %w", err)
+	}
+
+	return &payment, nil
+}
+
+// ListActiveByOrderID получает ожидающие, обрабатываемые и успешные платежи по заказу, новые первыми
+func (r *paymentRepository) ListActiveByOrderID(ctx context.Context, orderID int64) ([]*domain.Payment, error) {
+	query := `
+		SELECT id, order_id, provider, provider_payment_id, amount, currency, status, created_at, updated_at
+		FROM payments
+		WHERE order_id = ? AND status IN ('pending', 'processing', 'succeeded')
+		ORDER BY id DESC
+	`
+
+	var payments []*domain.Payment
+	if err := r.db.SelectContext(ctx, &payments, query, orderID); err != nil {
+		return nil, fmt.Errorf("ошибка при получении платежей по заказу: %w", err)
+	}
+
+	return payments, nil
+}
+
+// CompareAndSetStatus меняет статус платежа, только если текущий статус равен from.
+// Повторно доставленный webhook не изменит уже обработанный платеж.
+func (r *paymentRepository) CompareAndSetStatus(ctx context.Context, id int64, from, to string) (bool, error) {
+	query := "UPDATE payments SET status = ? WHERE id = ? AND status = ?"
+
+	result, err := r.db.ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении статуса платежа: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetSucceededByOrderIDTx получает успешный платеж по заказу с блокировкой строки.
+// Возвращает nil, если заказ не оплачивался через провайдера.
+func (r *paymentRepository) GetSucceededByOrderIDTx(ctx context.Context, tx Tx, orderID int64) (*domain.Payment, error) {
+	query := `
+		SELECT id, order_id, provider, provider_payment_id, amount, currency, status, created_at, updated_at
+		FROM payments
+		WHERE order_id = ? AND status = 'succeeded'
+		ORDER BY id DESC
+		LIMIT 1
+		FOR UPDATE
+	`
+
+	sqlxTx := tx.(*sqlxTx)
+
+	var payment domain.Payment
+	err := sqlxTx.tx.GetContext(ctx, &payment, query, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении платежа по заказу: %w", err)
+	}
+
+	return &payment, nil
+}
+
+// UpdateStatusTx обновляет статус платежа в рамках транзакции
+func (r *paymentRepository) UpdateStatusTx(ctx context.Context, tx Tx, id int64, status string) error {
+	query := "UPDATE payments SET status = ? WHERE id = ?"
+
+	sqlxTx := tx.(*sqlxTx)
+
+	_, err := sqlxTx.tx.ExecContext(ctx, query, status, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении статуса платежа: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrPricingRuleNotFound возвращается, если правило ценообразования не найдено
+var ErrPricingRuleNotFound = errors.New("правило ценообразования не найдено")
+
+// pricingRuleColumns колонки таблицы pricing_rules в порядке полей domain.PricingRule
+const pricingRuleColumns = `id, name, kind, modifier, country_id, city_id, season_start, season_end,
+		availability_below, days_before, is_active, created_at`
+
+// pricingRuleRepository реализация PricingRuleRepository
+type pricingRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewPricingRuleRepository создает новый экземпляр PricingRuleRepository
+func NewPricingRuleRepository(db *sqlx.DB) PricingRuleRepository {
+	return &pricingRuleRepository{db: db}
+}
+
+// Create создает новое правило ценообразования
+func (r *pricingRuleRepository) Create(ctx context.Context, rule *domain.PricingRule) (int64, error) {
+	query := `
+		INSERT INTO pricing_rules (name, kind, modifier, country_id, city_id, season_start, season_end,
+			availability_below, days_before, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Kind,
+		rule.Modifier,
+		rule.CountryID,
+		rule.CityID,
+		rule.SeasonStart,
+		rule.SeasonEnd,
+		rule.AvailabilityBelow,
+		rule.DaysBefore,
+		rule.IsActive,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании правила ценообразования: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID правила ценообразования: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByID получает правило ценообразования по ID
+func (r *pricingRuleRepository) GetByID(ctx context.Context, id int64) (*domain.PricingRule, error) {
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rules WHERE id = ?"
+
+	var rule domain.PricingRule
+	err := r.db.GetContext(ctx, &rule, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPricingRuleNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении правила ценообразования: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// Update обновляет правило ценообразования
+func (r *pricingRuleRepository) Update(ctx context.Context, rule *domain.PricingRule) error {
+	query := `
+		UPDATE pricing_rules
+		SET name = ?, kind = ?, modifier = ?, country_id = ?, city_id = ?, season_start = ?, season_end = ?,
+			availability_below = ?, days_before = ?, is_active = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Kind,
+		rule.Modifier,
+		rule.CountryID,
+		rule.CityID,
+		rule.SeasonStart,
+		rule.SeasonEnd,
+		rule.AvailabilityBelow,
+		rule.DaysBefore,
+		rule.IsActive,
+		rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении правила ценообразования: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if affected == 0 {
+		// MySQL не считает строку обновленной, если значения не изменились
+		if _, err := r.GetByID(ctx, rule.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete удаляет правило ценообразования
+func (r *pricingRuleRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM pricing_rules WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении правила ценообразования: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if affected == 0 {
+		return ErrPricingRuleNotFound
+	}
+
+	return nil
+}
+
+// List возвращает все правила ценообразования
+func (r *pricingRuleRepository) List(ctx context.Context) ([]*domain.PricingRule, error) {
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rules ORDER BY id"
+
+	var rules []*domain.PricingRule
+	err := r.db.SelectContext(ctx, &rules, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил ценообразования: %w", err)
+	}
+
+	return rules, nil
+}
+
+// ListActive возвращает включенные правила ценообразования
+func (r *pricingRuleRepository) ListActive(ctx context.Context) ([]*domain.PricingRule, error) {
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rules WHERE is_active = true ORDER BY id"
+
+	var rules []*domain.PricingRule
+	err := r.db.SelectContext(ctx, &rules, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении активных правил ценообразования: %w", err)
+	}
+
+	return rules, nil
+}
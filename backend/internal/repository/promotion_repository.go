@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrPromotionNotFound возвращается, если промоакция не найдена
+var ErrPromotionNotFound = errors.New("промоакция не найдена")
+
+// ErrPromoCodeTaken промокод уже занят другой промоакцией
+var ErrPromoCodeTaken = errors.New("промокод уже используется другой промоакцией")
+
+// promotionColumns колонки таблицы promotions в порядке полей domain.Promotion
+const promotionColumns = `id, code, title, description, discount_type, percent, amount, currency, target, tour_id,
+		min_people, early_bird_days, max_uses, max_uses_per_user, starts_at, ends_at, is_active, created_at`
+
+// promotionRepository реализация PromotionRepository
+type promotionRepository struct {
+	db *sqlx.DB
+}
+
+// NewPromotionRepository создает новый экземпляр PromotionRepository
+func NewPromotionRepository(db *sqlx.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+// Create создает новую промоакцию
+func (r *promotionRepository) Create(ctx context.Context, promo *domain.Promotion) (int64, error) {
+	query := `
+		INSERT INTO promotions (code, title, description, discount_type, percent, amount, currency, target, tour_id,
+			min_people, early_bird_days, max_uses, max_uses_per_user, starts_at, ends_at, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		promo.Code,
+		promo.Title,
+		promo.Description,
+		promo.DiscountType,
+		promo.Percent,
+		promo.Amount,
+		promo.Currency,
+		promo.Target,
+		promo.TourID,
+		promo.MinPeople,
+		promo.EarlyBirdDays,
+		promo.MaxUses,
+		promo.MaxUsesPerUser,
+		promo.StartsAt,
+		promo.EndsAt,
+		promo.IsActive,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return 0, ErrPromoCodeTaken
+		}
+		return 0, fmt.Errorf("ошибка при создании промоакции: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID созданной промоакции: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByID получает промоакцию по ID
+func (r *promotionRepository) GetByID(ctx context.Context, id int64) (*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE id = ?"
+
+	var promo domain.Promotion
+	err := r.db.GetContext(ctx, &promo, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении промоакции: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// GetByCode получает промоакцию по промокоду
+func (r *promotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE code = ?"
+
+	var promo domain.Promotion
+	err := r.db.GetContext(ctx, &promo, query, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении промоакции по коду: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// Update обновляет промоакцию
+func (r *promotionRepository) Update(ctx context.Context, promo *domain.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET code = ?, title = ?, description = ?, discount_type = ?, percent = ?, amount = ?, currency = ?, target = ?,
+			tour_id = ?, min_people = ?, early_bird_days = ?, max_uses = ?, max_uses_per_user = ?,
+			starts_at = ?, ends_at = ?, is_active = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		promo.Code,
+		promo.Title,
+		promo.Description,
+		promo.DiscountType,
+		promo.Percent,
+		promo.Amount,
+		promo.Currency,
+		promo.Target,
+		promo.TourID,
+		promo.MinPeople,
+		promo.EarlyBirdDays,
+		promo.MaxUses,
+		promo.MaxUsesPerUser,
+		promo.StartsAt,
+		promo.EndsAt,
+		promo.IsActive,
+		promo.ID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return ErrPromoCodeTaken
+		}
+		return fmt.Errorf("ошибка при обновлении промоакции: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if affected == 0 {
+		// MySQL не считает строку обновленной, если значения не изменились
+		if _, err := r.GetByID(ctx, promo.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete удаляет промоакцию вместе с историей ее применений
+func (r *promotionRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении промоакции: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if affected == 0 {
+		return ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// List возвращает список промоакций
+func (r *promotionRepository) List(ctx context.Context, offset, limit int) ([]*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+
+	var promos []*domain.Promotion
+	err := r.db.SelectContext(ctx, &promos, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка промоакций: %w", err)
+	}
+
+	return promos, nil
+}
+
+// Count возвращает общее количество промоакций
+func (r *promotionRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM promotions")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете промоакций: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListAutomatic возвращает активные правила без промокода, действующие
+// в момент now и применимые к туру tourID
+func (r *promotionRepository) ListAutomatic(ctx context.Context, tourID int64, now time.Time) ([]*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + `
+		FROM promotions
+		WHERE code IS NULL AND is_active = true
+			AND (tour_id IS NULL OR tour_id = ?)
+			AND (starts_at IS NULL OR starts_at <= ?)
+			AND (ends_at IS NULL OR ends_at > ?)
+		ORDER BY id
+	`
+
+	var promos []*domain.Promotion
+	err := r.db.SelectContext(ctx, &promos, query, tourID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил скидок: %w", err)
+	}
+
+	return promos, nil
+}
+
+// CountRedemptions возвращает количество применений промоакции по неотмененным
+// заказам; если задан userID - только заказами этого пользователя
+func (r *promotionRepository) CountRedemptions(ctx context.Context, promotionID int64, userID *int64) (int, error) {
+	query, args := redemptionCountQuery(promotionID, userID)
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете применений промоакции: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetByIDForUpdateTx получает промоакцию с блокировкой строки до конца транзакции,
+// чтобы параллельные заказы не превысили лимит применений
+func (r *promotionRepository) GetByIDForUpdateTx(ctx context.Context, tx Tx, id int64) (*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE id = ? FOR UPDATE"
+
+	sqlxTx := tx.(*sqlxTx)
+
+	var promo domain.Promotion
+	err := sqlxTx.tx.GetContext(ctx, &promo, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("ошибка при блокировке промоакции: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// CountRedemptionsTx подсчитывает применения промоакции в рамках транзакции
+func (r *promotionRepository) CountRedemptionsTx(ctx context.Context, tx Tx, promotionID int64, userID *int64) (int, error) {
+	query, args := redemptionCountQuery(promotionID, userID)
+
+	sqlxTx := tx.(*sqlxTx)
+
+	var count int
+	if err := sqlxTx.tx.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете применений промоакции: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddRedemptionTx сохраняет применение промоакции к заказу в рамках транзакции
+func (r *promotionRepository) AddRedemptionTx(ctx context.Context, tx Tx, redemption *domain.PromotionRedemption) error {
+	query := `
+		INSERT INTO promotion_redemptions (promotion_id, order_id, user_id, amount, currency)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	sqlxTx := tx.(*sqlxTx)
+
+	_, err := sqlxTx.tx.ExecContext(ctx, query,
+		redemption.PromotionID,
+		redemption.OrderID,
+		redemption.UserID,
+		redemption.Amount,
+		redemption.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении применения промоакции: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRedemptionsByOrderTx удаляет применения промоакций заказом в рамках
+// транзакции отмены, возвращая их в лимиты промоакций
+func (r *promotionRepository) DeleteRedemptionsByOrderTx(ctx context.Context, tx Tx, orderID int64) error {
+	query := "DELETE FROM promotion_redemptions WHERE order_id = ?"
+
+	sqlxTx := tx.(*sqlxTx)
+
+	if _, err := sqlxTx.tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("ошибка при удалении применений промоакций: %w", err)
+	}
+
+	return nil
+}
+
+// redemptionCountQuery строит запрос подсчета применений промоакции.
+// Применения отмененных заказов удаляются при отмене; условие на статус
+// страхует от заказов, отмененных в обход смены статуса.
+func redemptionCountQuery(promotionID int64, userID *int64) (string, []interface{}) {
+	query := `
+		SELECT COUNT(*)
+		FROM promotion_redemptions pr
+		JOIN orders o ON o.id = pr.order_id
+		WHERE pr.promotion_id = ? AND o.status <> 'cancelled'
+	`
+	args := []interface{}{promotionID}
+
+	if userID != nil {
+		query += " AND pr.user_id = ?"
+		args = append(args, *userID)
+	}
+
+	return query, args
+}
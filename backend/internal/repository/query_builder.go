@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// listQuery собирает JOIN, WHERE, ORDER BY и LIMIT списочного запроса.
+// Один и тот же построитель формирует запрос страницы и запрос подсчета,
+// поэтому условия фильтрации в них всегда совпадают.
+type listQuery struct {
+	from       string
+	joins      []string
+	conditions []string
+	args       []interface{}
+	groupBy    []string
+	orderBy    []string
+	page       *domain.Page
+	keyset     *keyset
+}
+
+// keyset колонки курсора и направление обхода списка
+type keyset struct {
+	createdAt string
+	id        string
+	order     domain.SortOrder
+}
+
+// newListQuery создает построитель для выборки из from (таблица с псевдонимом и обязательные JOIN)
+func newListQuery(from string) *listQuery {
+	return &listQuery{from: from}
+}
+
+// Join добавляет JOIN; повторное добавление того же выражения игнорируется
+func (q *listQuery) Join(clause string) *listQuery {
+	for _, j := range q.joins {
+		if j == clause {
+			return q
+		}
+	}
+	q.joins = append(q.joins, clause)
+	return q
+}
+
+// Where добавляет условие, объединяемое с остальными через AND
+func (q *listQuery) Where(condition string, args ...interface{}) *listQuery {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// GroupBy добавляет выражение группировки запроса страницы
+func (q *listQuery) GroupBy(expr string) *listQuery {
+	q.groupBy = append(q.groupBy, expr)
+	return q
+}
+
+// OrderBy добавляет выражение сортировки. Выражение подставляется в запрос
+// как есть, поэтому должно браться только из списка допустимых колонок.
+func (q *listQuery) OrderBy(expr string, order domain.SortOrder) *listQuery {
+	if order == domain.SortDesc {
+		expr += " DESC"
+	} else {
+		expr += " ASC"
+	}
+	q.orderBy = append(q.orderBy, expr)
+	return q
+}
+
+// Keyset задает колонки курсора и направление обхода списка. При выборке по
+// курсору страница сортируется только по ним, а выражения OrderBy не применяются.
+func (q *listQuery) Keyset(createdAt, id string, order domain.SortOrder) *listQuery {
+	q.keyset = &keyset{createdAt: createdAt, id: id, order: order}
+	return q
+}
+
+// Paginate ограничивает запрос страницы заданной страницей. При выборке по
+// курсору запрашивается на одну запись больше, чтобы определить, есть ли
+// следующая страница (см. pagination.Trim).
+func (q *listQuery) Paginate(page domain.Page) *listQuery {
+	q.page = &page
+	return q
+}
+
+// Select возвращает запрос страницы с указанными колонками; columnArgs -
+// параметры выражений в списке колонок
+func (q *listQuery) Select(columns string, columnArgs ...interface{}) (string, []interface{}) {
+	conditions := q.conditions
+	args := append(append([]interface{}{}, columnArgs...), q.args...)
+	orderBy := q.orderBy
+	cursorMode := q.page != nil && q.page.IsCursor() && q.keyset != nil
+
+	if cursorMode {
+		k := q.keyset
+		cmp, dir := ">", " ASC"
+		if k.order == domain.SortDesc {
+			cmp, dir = "<", " DESC"
+		}
+		if cursor := q.page.Cursor; !cursor.IsZero() {
+			conditions = append(append([]string{}, conditions...),
+				"("+k.createdAt+" "+cmp+" ? OR ("+k.createdAt+" = ? AND "+k.id+" "+cmp+" ?))")
+			args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+		}
+		orderBy = []string{k.createdAt + dir, k.id + dir}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	sb.WriteString(columns)
+	q.writeFromWhere(&sb, conditions)
+
+	if len(q.groupBy) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(q.groupBy, ", "))
+	}
+	if len(orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(orderBy, ", "))
+	}
+	switch {
+	case cursorMode:
+		sb.WriteString(" LIMIT ?")
+		args = append(args, q.page.Size+1)
+	case q.page != nil:
+		sb.WriteString(" LIMIT ? OFFSET ?")
+		args = append(args, q.page.Size, q.page.Offset())
+	}
+
+	return sb.String(), args
+}
+
+// Count возвращает запрос подсчета с тем же набором условий; expr - выражение
+// подсчета, например COUNT(*) или COUNT(DISTINCT t.id)
+func (q *listQuery) Count(expr string) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	sb.WriteString(expr)
+	q.writeFromWhere(&sb, q.conditions)
+
+	return sb.String(), append([]interface{}{}, q.args...)
+}
+
+// writeFromWhere записывает FROM, JOIN и WHERE части запроса
+func (q *listQuery) writeFromWhere(sb *strings.Builder, conditions []string) {
+	sb.WriteString(" FROM ")
+	sb.WriteString(q.from)
+	for _, j := range q.joins {
+		sb.WriteString(" ")
+		sb.WriteString(j)
+	}
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(conditions, " AND "))
+	}
+}
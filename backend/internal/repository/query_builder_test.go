@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
+)
+
+func TestListQuerySelectOffset(t *testing.T) {
+	q := newListQuery("tours t").
+		Join("JOIN cities c ON c.id = t.city_id").
+		Join("JOIN cities c ON c.id = t.city_id"). // повторный JOIN не дублируется
+		Where("t.deleted_at IS NULL").
+		Where("c.country_id = ?", int64(3)).
+		Where("t.base_price BETWEEN ? AND ?", "100.00", "500.00").
+		OrderBy("t.base_price", domain.SortDesc).
+		OrderBy("t.id", domain.SortAsc).
+		Paginate(domain.Page{Number: 3, Size: 20})
+
+	query, args := q.Select("t.id, t.name")
+
+	wantQuery := "SELECT t.id, t.name FROM tours t JOIN cities c ON c.id = t.city_id" +
+		" WHERE t.deleted_at IS NULL AND c.country_id = ? AND t.base_price BETWEEN ? AND ?" +
+		" ORDER BY t.base_price DESC, t.id ASC LIMIT ? OFFSET ?"
+	if query != wantQuery {
+		t.Errorf("запрос:\n%s\nожидался:\n%s", query, wantQuery)
+	}
+	wantArgs := []interface{}{int64(3), "100.00", "500.00", 20, 40}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("параметры: %v, ожидались %v", args, wantArgs)
+	}
+}
+
+// TestListQueryCountMatchesSelect проверяет, что запрос подсчета использует
+// те же JOIN и условия, но без сортировки, группировки и LIMIT
+func TestListQueryCountMatchesSelect(t *testing.T) {
+	q := newListQuery("orders o").
+		Join("JOIN users u ON u.id = o.user_id").
+		Where("o.status = ?", "paid").
+		GroupBy("o.id").
+		OrderBy("o.created_at", domain.SortDesc).
+		Paginate(domain.Page{Number: 1, Size: 10})
+
+	query, args := q.Count("COUNT(DISTINCT o.id)")
+
+	wantQuery := "SELECT COUNT(DISTINCT o.id) FROM orders o JOIN users u ON u.id = o.user_id WHERE o.status = ?"
+	if query != wantQuery {
+		t.Errorf("запрос:\n%s\nожидался:\n%s", query, wantQuery)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"paid"}) {
+		t.Errorf("параметры: %v", args)
+	}
+}
+
+// TestListQueryColumnArgsPrecedeConditions проверяет порядок параметров:
+// параметры колонок стоят в запросе раньше параметров WHERE
+func TestListQueryColumnArgsPrecedeConditions(t *testing.T) {
+	q := newListQuery("tours t").Where("t.city_id = ?", int64(5))
+
+	query, args := q.Select("t.id, CASE WHEN t.currency = ? THEN 1 ELSE 0 END", "RUB")
+
+	wantQuery := "SELECT t.id, CASE WHEN t.currency = ? THEN 1 ELSE 0 END FROM tours t WHERE t.city_id = ?"
+	if query != wantQuery {
+		t.Errorf("запрос:\n%s\nожидался:\n%s", query, wantQuery)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"RUB", int64(5)}) {
+		t.Errorf("параметры: %v", args)
+	}
+
+	// Построитель можно использовать повторно: Select не изменяет его условия
+	if _, again := q.Select("t.id"); !reflect.DeepEqual(again, []interface{}{int64(5)}) {
+		t.Errorf("параметры повторного запроса: %v", again)
+	}
+}
+
+// TestListQuerySelectKeyset проверяет запрос страницы по курсору: условие
+// продолжения после курсора, сортировка только по колонкам курсора и запрос
+// на одну запись больше размера страницы
+func TestListQuerySelectKeyset(t *testing.T) {
+	createdAt := time.Date(2024, 5, 17, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		order     domain.SortOrder
+		cursor    pagination.Cursor
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:   "первая страница по убыванию",
+			order:  domain.SortDesc,
+			cursor: pagination.Cursor{},
+			wantQuery: "SELECT o.id FROM orders o WHERE o.user_id = ?" +
+				" ORDER BY o.created_at DESC, o.id DESC LIMIT ?",
+			wantArgs: []interface{}{int64(9), 11},
+		},
+		{
+			name:   "следующая страница по убыванию",
+			order:  domain.SortDesc,
+			cursor: pagination.Cursor{CreatedAt: createdAt, ID: 120},
+			wantQuery: "SELECT o.id FROM orders o WHERE o.user_id = ?" +
+				" AND (o.created_at < ? OR (o.created_at = ? AND o.id < ?))" +
+				" ORDER BY o.created_at DESC, o.id DESC LIMIT ?",
+			wantArgs: []interface{}{int64(9), createdAt, createdAt, int64(120), 11},
+		},
+		{
+			name:   "следующая страница по возрастанию",
+			order:  domain.SortAsc,
+			cursor: pagination.Cursor{CreatedAt: createdAt, ID: 120},
+			wantQuery: "SELECT o.id FROM orders o WHERE o.user_id = ?" +
+				" AND (o.created_at > ? OR (o.created_at = ? AND o.id > ?))" +
+				" ORDER BY o.created_at ASC, o.id ASC LIMIT ?",
+			wantArgs: []interface{}{int64(9), createdAt, createdAt, int64(120), 11},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := tc.cursor
+			q := newListQuery("orders o").
+				Where("o.user_id = ?", int64(9)).
+				OrderBy("o.total_price", domain.SortAsc). // при выборке по курсору не применяется
+				Keyset("o.created_at", "o.id", tc.order).
+				Paginate(domain.Page{Number: 1, Size: 10, Cursor: &cursor})
+
+			query, args := q.Select("o.id")
+			if query != tc.wantQuery {
+				t.Errorf("запрос:\n%s\nожидался:\n%s", query, tc.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tc.wantArgs) {
+				t.Errorf("параметры: %v, ожидались %v", args, tc.wantArgs)
+			}
+
+			// Подсчет не зависит от курсора
+			if count, _ := q.Count("COUNT(*)"); count != "SELECT COUNT(*) FROM orders o WHERE o.user_id = ?" {
+				t.Errorf("запрос подсчета: %s", count)
+			}
+		})
+	}
+}
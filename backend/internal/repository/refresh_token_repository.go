@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrRefreshTokenNotFound возвращается, если refresh токен отсутствует в реестре
+var ErrRefreshTokenNotFound = errors.New("refresh токен не найден")
+
+// refreshTokenRepository реализация RefreshTokenRepository
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewRefreshTokenRepository создает новый экземпляр RefreshTokenRepository
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create сохраняет выданный refresh токен
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.UserID, token.FamilyID, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении refresh токена: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID получает refresh токен по jti
+func (r *refreshTokenRepository) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE id = ?
+	`
+
+	var token domain.RefreshToken
+	err := r.db.GetContext(ctx, &token, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении refresh токена: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Rotate отзывает токен oldID и сохраняет next в рамках одной транзакции.
+// Возвращает false, если oldID уже был отозван (например, параллельным запросом).
+func (r *refreshTokenRepository) Rotate(ctx context.Context, oldID string, next *domain.RefreshToken) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW(), replaced_by = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, next.ID, oldID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при отзыве refresh токена: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при отзыве refresh токена: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, family_id, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, next.ID, next.UserID, next.FamilyID, next.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при сохранении нового refresh токена: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return true, nil
+}
+
+// RevokeFamily отзывает все активные токены семейства
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве семейства refresh токенов: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser отзывает все активные токены пользователя
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве refresh токенов пользователя: %w", err)
+	}
+
+	return nil
+}
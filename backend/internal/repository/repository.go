@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
 )
 
 // Tx представляет интерфейс транзакции
@@ -23,6 +25,14 @@ type Repository struct {
 	SupportTicket SupportTicketRepository
 	City          CityRepository
 	Country       CountryRepository
+	RefreshToken  RefreshTokenRepository
+	Payment       PaymentRepository
+	Promotion     PromotionRepository
+	TourSchedule  TourScheduleRepository
+	PricingRule   PricingRuleRepository
+	Role          RoleRepository
+	AccountToken  AccountTokenRepository
+	TwoFactor     TwoFactorRepository
 }
 
 // NewRepository создает новый экземпляр Repository
@@ -36,6 +46,14 @@ func NewRepository(db *sqlx.DB) *Repository {
 		SupportTicket: NewSupportTicketRepository(db),
 		City:          NewCityRepository(db),
 		Country:       NewCountryRepository(db),
+		RefreshToken:  NewRefreshTokenRepository(db),
+		Payment:       NewPaymentRepository(db),
+		Promotion:     NewPromotionRepository(db),
+		TourSchedule:  NewTourScheduleRepository(db),
+		PricingRule:   NewPricingRuleRepository(db),
+		Role:          NewRoleRepository(db),
+		AccountToken:  NewAccountTokenRepository(db),
+		TwoFactor:     NewTwoFactorRepository(db),
 	}
 }
 
@@ -46,9 +64,55 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
+	IncrementTokenVersion(ctx context.Context, id int64) error
+	MarkEmailVerified(ctx context.Context, id int64) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, offset, limit int) ([]*domain.User, error)
-	Count(ctx context.Context) (int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, page domain.Page, deleted bool) ([]*domain.User, error)
+	Count(ctx context.Context, deleted bool) (int, error)
+}
+
+// RoleRepository интерфейс для работы с ролями и их правами
+type RoleRepository interface {
+	List(ctx context.Context) ([]*domain.Role, error)
+	GetByID(ctx context.Context, id int64) (*domain.Role, error)
+	GetDefault(ctx context.Context) (*domain.Role, error)
+	Create(ctx context.Context, role *domain.Role) (int64, error)
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, id int64) error
+	ListPermissions(ctx context.Context) ([]*domain.PermissionInfo, error)
+}
+
+// RefreshTokenRepository интерфейс для реестра выданных refresh токенов
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	GetByID(ctx context.Context, id string) (*domain.RefreshToken, error)
+	Rotate(ctx context.Context, oldID string, next *domain.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+// AccountTokenRepository интерфейс для работы с одноразовыми токенами из писем
+type AccountTokenRepository interface {
+	Create(ctx context.Context, token *domain.AccountToken) error
+	// Consume отмечает действующий токен использованным и возвращает его
+	Consume(ctx context.Context, purpose domain.AccountTokenPurpose, tokenHash string) (*domain.AccountToken, error)
+	// RevokeForUser отзывает неиспользованные токены пользователя с указанным назначением
+	RevokeForUser(ctx context.Context, userID int64, purpose domain.AccountTokenPurpose) error
+}
+
+// TwoFactorRepository интерфейс для работы с двухфакторной аутентификацией
+type TwoFactorRepository interface {
+	GetByUserID(ctx context.Context, userID int64) (*domain.TwoFactor, error)
+	// SavePending сохраняет неподтвержденный секрет и коды восстановления
+	SavePending(ctx context.Context, userID int64, secret string, codeHashes []string) error
+	Confirm(ctx context.Context, userID int64, step int64) error
+	// UseStep принимает код, только если его шаг времени новее последнего принятого
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	Delete(ctx context.Context, userID int64) error
 }
 
 // TourRepository интерфейс для работы с турами
@@ -57,15 +121,24 @@ type TourRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Tour, error)
 	Update(ctx context.Context, tour *domain.Tour) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.Tour, error)
-	Count(ctx context.Context, filters map[string]interface{}) (int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, filter *domain.TourFilter) ([]*domain.Tour, error)
+	Count(ctx context.Context, filter *domain.TourFilter) (int, error)
+	Facets(ctx context.Context, filter *domain.TourFilter, opts *domain.TourFacetOptions) (*domain.TourFacets, error)
 	AddTourDate(ctx context.Context, tourDate *domain.TourDate) (int64, error)
 	GetTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error)
 	GetTourDateByID(ctx context.Context, id int64) (*domain.TourDate, error)
+	GetTourDateByIDWithDeleted(ctx context.Context, id int64) (*domain.TourDate, error) // В том числе удаленную; для уже оформленных заказов
 	UpdateTourDate(ctx context.Context, tourDate *domain.TourDate) error
 	DeleteTourDate(ctx context.Context, id int64) error
+	ListDeletedTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error)
+	RestoreTourDate(ctx context.Context, tourID, id int64) error
+	ListUpcomingTourIDs(ctx context.Context, from time.Time) ([]int64, error)
+	UpdatePriceModifier(ctx context.Context, tourDateID int64, modifier money.Ratio) error
+	ListForSearch(ctx context.Context, tourID *int64) ([]*domain.TourSearchSource, error) // Тексты активных туров для поискового индекса
 	// Транзакционные методы
-	UpdateTourDateAvailabilityTx(ctx context.Context, tx Tx, tourDateID int64, availability int) error
+	ReserveSeatsTx(ctx context.Context, tx Tx, tourDateID int64, count int) error
+	ReleaseSeatsTx(ctx context.Context, tx Tx, tourDateID int64, count int) error
 }
 
 // HotelRepository интерфейс для работы с отелями
@@ -74,8 +147,9 @@ type HotelRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Hotel, error)
 	Update(ctx context.Context, hotel *domain.Hotel) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.Hotel, error)
-	Count(ctx context.Context, filters map[string]interface{}) (int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, filter *domain.HotelFilter) ([]*domain.Hotel, error)
+	Count(ctx context.Context, filter *domain.HotelFilter) (int, error)
 }
 
 // RoomRepository интерфейс для работы с номерами отелей
@@ -84,7 +158,16 @@ type RoomRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Room, error)
 	Update(ctx context.Context, room *domain.Room) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, hotelID, id int64) error
 	ListByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error)
+	ListDeletedByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error)
+	// Занятость номеров по ночам; период [from, to) - с даты заезда до даты выезда
+	ListAvailableByHotelID(ctx context.Context, hotelID int64, from, to time.Time) ([]*domain.Room, error)
+	IsAvailable(ctx context.Context, roomID int64, from, to time.Time) (bool, error)
+	ListNights(ctx context.Context, roomID int64, from, to time.Time) ([]*domain.RoomNight, error)
+	// Транзакционные методы
+	ReserveNightsTx(ctx context.Context, tx Tx, roomID int64, from, to time.Time) error
+	ReleaseNightsTx(ctx context.Context, tx Tx, roomID int64, from, to time.Time) error
 }
 
 // OrderRepository интерфейс для работы с заказами
@@ -94,13 +177,74 @@ type OrderRepository interface {
 	Update(ctx context.Context, order *domain.Order) error
 	Delete(ctx context.Context, id int64) error
 	ListByUserID(ctx context.Context, userID int64) ([]*domain.Order, error)
-	List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.Order, error)
-	Count(ctx context.Context, filters map[string]interface{}) (int, error)
+	List(ctx context.Context, filter *domain.OrderFilter) ([]*domain.Order, error)
+	Count(ctx context.Context, filter *domain.OrderFilter) (int, error)
 	UpdateStatus(ctx context.Context, id int64, status string) error
+	ListExpiredPending(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	ListStatusHistory(ctx context.Context, orderID int64) ([]*domain.OrderStatusChange, error)
 	// Транзакционные методы
 	BeginTx(ctx context.Context) (Tx, error)
 	CreateTx(ctx context.Context, tx Tx, order *domain.Order) (int64, error)
+	GetByIDForUpdateTx(ctx context.Context, tx Tx, id int64) (*domain.Order, error)
 	UpdateStatusTx(ctx context.Context, tx Tx, id int64, status string) error
+	// SetPaymentTx запоминает платеж, которым оплачен заказ
+	SetPaymentTx(ctx context.Context, tx Tx, id, paymentID int64) error
+	AddStatusHistoryTx(ctx context.Context, tx Tx, change *domain.OrderStatusChange) error
+}
+
+// PaymentRepository интерфейс для работы с платежами по заказам
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.Payment) (int64, error)
+	GetByProviderPaymentID(ctx context.Context, provider, providerPaymentID string) (*domain.Payment, error)
+	// ListActiveByOrderID возвращает ожидающие, обрабатываемые и успешные платежи по заказу, новые первыми
+	ListActiveByOrderID(ctx context.Context, orderID int64) ([]*domain.Payment, error)
+	// CompareAndSetStatus меняет статус, только если текущий статус равен from
+	CompareAndSetStatus(ctx context.Context, id int64, from, to string) (bool, error)
+	// Транзакционные методы
+	GetSucceededByOrderIDTx(ctx context.Context, tx Tx, orderID int64) (*domain.Payment, error)
+	UpdateStatusTx(ctx context.Context, tx Tx, id int64, status string) error
+}
+
+// PromotionRepository интерфейс для работы с промоакциями и их применениями
+type PromotionRepository interface {
+	Create(ctx context.Context, promo *domain.Promotion) (int64, error)
+	GetByID(ctx context.Context, id int64) (*domain.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	Update(ctx context.Context, promo *domain.Promotion) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, offset, limit int) ([]*domain.Promotion, error)
+	Count(ctx context.Context) (int, error)
+	ListAutomatic(ctx context.Context, tourID int64, now time.Time) ([]*domain.Promotion, error)
+	// CountRedemptions считает применения по неотмененным заказам; userID ограничивает подсчет пользователем
+	CountRedemptions(ctx context.Context, promotionID int64, userID *int64) (int, error)
+	// Транзакционные методы
+	GetByIDForUpdateTx(ctx context.Context, tx Tx, id int64) (*domain.Promotion, error)
+	CountRedemptionsTx(ctx context.Context, tx Tx, promotionID int64, userID *int64) (int, error)
+	AddRedemptionTx(ctx context.Context, tx Tx, redemption *domain.PromotionRedemption) error
+	// DeleteRedemptionsByOrderTx удаляет применения промоакций отмененным заказом
+	DeleteRedemptionsByOrderTx(ctx context.Context, tx Tx, orderID int64) error
+}
+
+// TourScheduleRepository интерфейс для работы с правилами расписания туров
+type TourScheduleRepository interface {
+	Create(ctx context.Context, schedule *domain.TourSchedule) (int64, error)
+	GetByID(ctx context.Context, id int64) (*domain.TourSchedule, error)
+	Update(ctx context.Context, schedule *domain.TourSchedule) error
+	Delete(ctx context.Context, tourID, id int64) error
+	ListByTourID(ctx context.Context, tourID int64) ([]*domain.TourSchedule, error)
+	ListActive(ctx context.Context) ([]*domain.TourSchedule, error)
+	// InsertDates добавляет даты, пропуская уже существующие; возвращает количество добавленных
+	InsertDates(ctx context.Context, dates []*domain.TourDate) (int, error)
+}
+
+// PricingRuleRepository интерфейс для работы с правилами ценообразования
+type PricingRuleRepository interface {
+	Create(ctx context.Context, rule *domain.PricingRule) (int64, error)
+	GetByID(ctx context.Context, id int64) (*domain.PricingRule, error)
+	Update(ctx context.Context, rule *domain.PricingRule) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*domain.PricingRule, error)
+	ListActive(ctx context.Context) ([]*domain.PricingRule, error)
 }
 
 // SupportTicketRepository интерфейс для работы с тикетами тех-поддержки
@@ -110,11 +254,14 @@ type SupportTicketRepository interface {
 	Update(ctx context.Context, ticket *domain.SupportTicket) error
 	Delete(ctx context.Context, id int64) error
 	ListByUserID(ctx context.Context, userID int64) ([]*domain.SupportTicket, error)
-	List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.SupportTicket, error)
-	Count(ctx context.Context, filters map[string]interface{}) (int, error)
+	List(ctx context.Context, filter *domain.TicketFilter) ([]*domain.SupportTicket, error)
+	Count(ctx context.Context, filter *domain.TicketFilter) (int, error)
 	UpdateStatus(ctx context.Context, id int64, status string) error
 	AddMessage(ctx context.Context, message *domain.TicketMessage) (int64, error)
 	GetMessages(ctx context.Context, ticketID int64) ([]*domain.TicketMessage, error)
+	ListMessages(ctx context.Context, ticketID int64, page domain.Page) ([]*domain.TicketMessage, error)
+	CountMessages(ctx context.Context, ticketID int64) (int, error)
+	GetMessageByID(ctx context.Context, id int64) (*domain.TicketMessage, error)
 }
 
 // CityRepository интерфейс для работы с городами
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrRoleNotFound возвращается, если роль не найдена
+var ErrRoleNotFound = errors.New("роль не найдена")
+
+// ErrRoleNameTaken роль с таким названием уже существует
+var ErrRoleNameTaken = errors.New("роль с таким названием уже существует")
+
+// ErrRoleInUse роль назначена пользователям и не может быть удалена
+var ErrRoleInUse = errors.New("роль назначена пользователям")
+
+// roleColumns колонки таблицы roles в порядке полей domain.Role
+const roleColumns = "id, name, description, is_system, is_default"
+
+// roleRepository реализация RoleRepository
+type roleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository создает новый экземпляр RoleRepository
+func NewRoleRepository(db *sqlx.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// List возвращает все роли с их правами
+func (r *roleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	var roles []*domain.Role
+	err := r.db.SelectContext(ctx, &roles, "SELECT "+roleColumns+" FROM roles ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка ролей: %w", err)
+	}
+
+	var grants []struct {
+		RoleID     int64             `db:"role_id"`
+		Permission domain.Permission `db:"permission"`
+	}
+	err = r.db.SelectContext(ctx, &grants, "SELECT role_id, permission FROM role_permissions ORDER BY role_id, permission")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении прав ролей: %w", err)
+	}
+
+	byID := make(map[int64]*domain.Role, len(roles))
+	for _, role := range roles {
+		role.Permissions = []domain.Permission{}
+		byID[role.ID] = role
+	}
+	for _, grant := range grants {
+		if role, ok := byID[grant.RoleID]; ok {
+			role.Permissions = append(role.Permissions, grant.Permission)
+		}
+	}
+
+	return roles, nil
+}
+
+// GetByID получает роль по ID вместе с ее правами
+func (r *roleRepository) GetByID(ctx context.Context, id int64) (*domain.Role, error) {
+	return r.get(ctx, "id = ?", id)
+}
+
+// GetDefault получает роль, назначаемую при регистрации
+func (r *roleRepository) GetDefault(ctx context.Context) (*domain.Role, error) {
+	return r.get(ctx, "is_default = TRUE ORDER BY id LIMIT 1")
+}
+
+// get получает роль по условию вместе с ее правами
+func (r *roleRepository) get(ctx context.Context, condition string, args ...interface{}) (*domain.Role, error) {
+	var role domain.Role
+	err := r.db.GetContext(ctx, &role, "SELECT "+roleColumns+" FROM roles WHERE "+condition, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении роли: %w", err)
+	}
+
+	role.Permissions = []domain.Permission{}
+	err = r.db.SelectContext(ctx, &role.Permissions,
+		"SELECT permission FROM role_permissions WHERE role_id = ? ORDER BY permission", role.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении прав роли: %w", err)
+	}
+
+	return &role, nil
+}
+
+// Create создает роль с правами
+func (r *roleRepository) Create(ctx context.Context, role *domain.Role) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO roles (name, description) VALUES (?, ?)", role.Name, role.Description)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return 0, ErrRoleNameTaken
+		}
+		return 0, fmt.Errorf("ошибка при создании роли: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID созданной роли: %w", err)
+	}
+
+	if err := replaceRolePermissions(ctx, tx, id, role.Permissions); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update обновляет название, описание и права роли
+func (r *roleRepository) Update(ctx context.Context, role *domain.Role) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM roles WHERE id = ?", role.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении роли: %w", err)
+	}
+	if count == 0 {
+		return ErrRoleNotFound
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE roles SET name = ?, description = ? WHERE id = ?", role.Name, role.Description, role.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return ErrRoleNameTaken
+		}
+		return fmt.Errorf("ошибка при обновлении роли: %w", err)
+	}
+
+	if err := replaceRolePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete удаляет роль, не назначенную ни одному пользователю, в том числе удаленному
+func (r *roleRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Внешний ключ users.role_id не даст удалить роль, назначенную между проверкой и удалением
+	var users int
+	err = tx.GetContext(ctx, &users, "SELECT COUNT(*) FROM users WHERE role_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении роли: %w", err)
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM roles WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении роли: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении роли: %w", err)
+	}
+	if affected == 0 {
+		return ErrRoleNotFound
+	}
+
+	return tx.Commit()
+}
+
+// ListPermissions возвращает справочник прав
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]*domain.PermissionInfo, error) {
+	var permissions []*domain.PermissionInfo
+	err := r.db.SelectContext(ctx, &permissions, "SELECT name, description FROM permissions ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении справочника прав: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// replaceRolePermissions заменяет права роли переданным набором
+func replaceRolePermissions(ctx context.Context, tx *sqlx.Tx, roleID int64, permissions []domain.Permission) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role_id = ?", roleID); err != nil {
+		return fmt.Errorf("ошибка при обновлении прав роли: %w", err)
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(permissions))
+	args := make([]interface{}, 0, len(permissions)*2)
+	for i, perm := range permissions {
+		placeholders[i] = "(?, ?)"
+		args = append(args, roleID, perm)
+	}
+	query := "INSERT INTO role_permissions (role_id, permission) VALUES " + strings.Join(placeholders, ", ")
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("ошибка при обновлении прав роли: %w", err)
+	}
+
+	return nil
+}
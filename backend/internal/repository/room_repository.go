@@ -2,12 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 )
 
+// ErrRoomUnavailable все номера выбранного типа заняты хотя бы в одну из ночей
+var ErrRoomUnavailable = errors.New("выбранный номер недоступен на даты тура")
+
+// nightLayout формат даты ночи в таблице room_nights
+const nightLayout = "2006-01-02"
+
 // roomRepository реализация RoomRepository
 type roomRepository struct {
 	db *sqlx.DB
@@ -21,8 +30,8 @@ func NewRoomRepository(db *sqlx.DB) RoomRepository {
 // Create создает новый номер отеля
 func (r *roomRepository) Create(ctx context.Context, room *domain.Room) (int64, error) {
 	query := `
-		INSERT INTO rooms (hotel_id, description, beds, price, image_url)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO rooms (hotel_id, description, beds, units, price, currency, image_url)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(
@@ -31,7 +40,9 @@ func (r *roomRepository) Create(ctx context.Context, room *domain.Room) (int64,
 		room.HotelID,
 		room.Description,
 		room.Beds,
+		room.Units,
 		room.Price,
+		room.Currency,
 		room.ImageURL,
 	)
 	if err != nil {
@@ -49,9 +60,9 @@ func (r *roomRepository) Create(ctx context.Context, room *domain.Room) (int64,
 // GetByID получает номер отеля по ID
 func (r *roomRepository) GetByID(ctx context.Context, id int64) (*domain.Room, error) {
 	query := `
-		SELECT id, hotel_id, description, beds, price, image_url
+		SELECT id, hotel_id, description, beds, units, price, currency, image_url, updated_at
 		FROM rooms
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var room domain.Room
@@ -67,7 +78,7 @@ func (r *roomRepository) GetByID(ctx context.Context, id int64) (*domain.Room, e
 func (r *roomRepository) Update(ctx context.Context, room *domain.Room) error {
 	query := `
 		UPDATE rooms
-		SET hotel_id = ?, description = ?, beds = ?, price = ?, image_url = ?
+		SET hotel_id = ?, description = ?, beds = ?, units = ?, price = ?, currency = ?, image_url = ?
 		WHERE id = ?
 	`
 
@@ -77,7 +88,9 @@ func (r *roomRepository) Update(ctx context.Context, room *domain.Room) error {
 		room.HotelID,
 		room.Description,
 		room.Beds,
+		room.Units,
 		room.Price,
+		room.Currency,
 		room.ImageURL,
 		room.ID,
 	)
@@ -88,9 +101,9 @@ func (r *roomRepository) Update(ctx context.Context, room *domain.Room) error {
 	return nil
 }
 
-// Delete удаляет номер отеля по ID
+// Delete мягко удаляет номер отеля по ID
 func (r *roomRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM rooms WHERE id = ?"
+	query := "UPDATE rooms SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
 
 	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
@@ -100,12 +113,42 @@ func (r *roomRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// ListDeletedByHotelID возвращает удаленные номера отеля
+func (r *roomRepository) ListDeletedByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error) {
+	query := `
+		SELECT id, hotel_id, description, beds, units, price, currency, image_url, updated_at, deleted_at
+		FROM rooms
+		WHERE hotel_id = ? AND deleted_at IS NOT NULL
+		ORDER BY price
+	`
+
+	var rooms []*domain.Room
+	err := r.db.SelectContext(ctx, &rooms, query, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении удаленных номеров: %w", err)
+	}
+
+	return rooms, nil
+}
+
+// Restore восстанавливает удаленный номер отеля
+func (r *roomRepository) Restore(ctx context.Context, hotelID, id int64) error {
+	query := "UPDATE rooms SET deleted_at = NULL WHERE id = ? AND hotel_id = ? AND deleted_at IS NOT NULL"
+
+	result, err := r.db.ExecContext(ctx, query, id, hotelID)
+	if err := checkRestored(result, err); err != nil {
+		return fmt.Errorf("ошибка при восстановлении номера: %w", err)
+	}
+
+	return nil
+}
+
 // ListByHotelID возвращает список номеров отеля
 func (r *roomRepository) ListByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error) {
 	query := `
-		SELECT id, hotel_id, description, beds, price, image_url
+		SELECT id, hotel_id, description, beds, units, price, currency, image_url, updated_at
 		FROM rooms
-		WHERE hotel_id = ?
+		WHERE hotel_id = ? AND deleted_at IS NULL
 		ORDER BY price
 	`
 
@@ -117,3 +160,156 @@ func (r *roomRepository) ListByHotelID(ctx context.Context, hotelID int64) ([]*d
 
 	return rooms, nil
 }
+
+// ListAvailableByHotelID возвращает номера отеля, у которых есть свободный
+// номер на каждую ночь периода [from, to)
+func (r *roomRepository) ListAvailableByHotelID(ctx context.Context, hotelID int64, from, to time.Time) ([]*domain.Room, error) {
+	query := `
+		SELECT r.id, r.hotel_id, r.description, r.beds, r.units, r.price, r.currency, r.image_url, r.updated_at
+		FROM rooms r
+		WHERE r.hotel_id = ? AND r.units > 0 AND r.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM room_nights rn
+				WHERE rn.room_id = r.id AND rn.night >= ? AND rn.night < ? AND rn.booked >= r.units
+			)
+		ORDER BY r.price
+	`
+
+	var rooms []*domain.Room
+	err := r.db.SelectContext(ctx, &rooms, query, hotelID, from.Format(nightLayout), to.Format(nightLayout))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка свободных номеров: %w", err)
+	}
+
+	return rooms, nil
+}
+
+// IsAvailable проверяет, что номер свободен на каждую ночь периода [from, to)
+func (r *roomRepository) IsAvailable(ctx context.Context, roomID int64, from, to time.Time) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM rooms r
+		WHERE r.id = ? AND r.units > 0 AND r.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM room_nights rn
+				WHERE rn.room_id = r.id AND rn.night >= ? AND rn.night < ? AND rn.booked >= r.units
+			)
+	`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, roomID, from.Format(nightLayout), to.Format(nightLayout))
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке доступности номера: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ListNights возвращает занятость номера по ночам периода [from, to);
+// ночи без бронирований возвращаются с нулевой занятостью
+func (r *roomRepository) ListNights(ctx context.Context, roomID int64, from, to time.Time) ([]*domain.RoomNight, error) {
+	var units int
+	if err := r.db.GetContext(ctx, &units, "SELECT units FROM rooms WHERE id = ?", roomID); err != nil {
+		return nil, fmt.Errorf("ошибка при получении номера: %w", err)
+	}
+
+	query := `
+		SELECT room_id, night, booked
+		FROM room_nights
+		WHERE room_id = ? AND night >= ? AND night < ?
+	`
+
+	var booked []*domain.RoomNight
+	err := r.db.SelectContext(ctx, &booked, query, roomID, from.Format(nightLayout), to.Format(nightLayout))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении занятости номера: %w", err)
+	}
+
+	bookedByNight := make(map[string]int, len(booked))
+	for _, n := range booked {
+		bookedByNight[n.Night.Format(nightLayout)] = n.Booked
+	}
+
+	nights := domain.StayNights(from, to)
+	result := make([]*domain.RoomNight, 0, len(nights))
+	for _, night := range nights {
+		n := &domain.RoomNight{
+			RoomID: roomID,
+			Night:  night,
+			Booked: bookedByNight[night.Format(nightLayout)],
+			Units:  units,
+		}
+		n.Available = units - n.Booked
+		if n.Available < 0 {
+			n.Available = 0
+		}
+		result = append(result, n)
+	}
+
+	return result, nil
+}
+
+// ReserveNightsTx занимает один номер на каждую ночь периода [from, to).
+// Возвращает ErrRoomUnavailable, если хотя бы в одну ночь свободных номеров нет.
+func (r *roomRepository) ReserveNightsTx(ctx context.Context, tx Tx, roomID int64, from, to time.Time) error {
+	nights := domain.StayNights(from, to)
+	if len(nights) == 0 {
+		return nil
+	}
+
+	sqlxTx := tx.(*sqlxTx)
+
+	// Блокируем номер, чтобы параллельные бронирования проверяли занятость по очереди
+	var units int
+	err := sqlxTx.tx.GetContext(ctx, &units, "SELECT units FROM rooms WHERE id = ? FOR UPDATE", roomID)
+	if err != nil {
+		return fmt.Errorf("ошибка при блокировке номера: %w", err)
+	}
+
+	var maxBooked int
+	err = sqlxTx.tx.GetContext(ctx, &maxBooked, `
+		SELECT COALESCE(MAX(booked), 0)
+		FROM room_nights
+		WHERE room_id = ? AND night >= ? AND night < ?
+	`, roomID, from.Format(nightLayout), to.Format(nightLayout))
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке занятости номера: %w", err)
+	}
+	if maxBooked >= units {
+		return ErrRoomUnavailable
+	}
+
+	placeholders := make([]string, 0, len(nights))
+	args := make([]interface{}, 0, len(nights)*2)
+	for _, night := range nights {
+		placeholders = append(placeholders, "(?, ?, 1)")
+		args = append(args, roomID, night.Format(nightLayout))
+	}
+
+	query := "INSERT INTO room_nights (room_id, night, booked) VALUES " + strings.Join(placeholders, ", ") +
+		" ON DUPLICATE KEY UPDATE booked = booked + 1"
+
+	if _, err = sqlxTx.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("ошибка при бронировании ночей номера: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseNightsTx освобождает один номер на каждую ночь периода [from, to)
+func (r *roomRepository) ReleaseNightsTx(ctx context.Context, tx Tx, roomID int64, from, to time.Time) error {
+	query := `
+		UPDATE room_nights
+		SET booked = booked - 1
+		WHERE room_id = ? AND night >= ? AND night < ? AND booked > 0
+	`
+
+	sqlxTx := tx.(*sqlxTx)
+
+	_, err := sqlxTx.tx.ExecContext(ctx, query, roomID, from.Format(nightLayout), to.Format(nightLayout))
+	if err != nil {
+		return fmt.Errorf("ошибка при освобождении ночей номера: %w", err)
+	}
+
+	return nil
+}
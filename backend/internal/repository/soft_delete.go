@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Удаление туров, дат туров, отелей, номеров и пользователей мягкое: строка
+// получает время удаления в deleted_at и пропадает из выборок, но остается в
+// базе. Заказы продолжают ссылаться на архивные строки, и их история не теряется.
+
+// ErrNotDeleted запись для восстановления не найдена среди удаленных
+var ErrNotDeleted = errors.New("запись не найдена среди удаленных")
+
+// deletedCondition условие выборки действующих (deleted = false) или удаленных строк
+func deletedCondition(column string, deleted bool) string {
+	if deleted {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
+// checkRestored проверяет результат запроса восстановления: если строка не
+// была удалена или не существует, возвращает ErrNotDeleted
+func checkRestored(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotDeleted
+	}
+
+	return nil
+}
+
+// softDeleteCascade помечает удаленной строку parent и ее действующие дочерние
+// строки child тем же временем удаления. По совпадению времени restoreCascade
+// возвращает только их, а не строки, удаленные раньше отдельно.
+func softDeleteCascade(ctx context.Context, db *sqlx.DB, parent, child, foreignKey string, id int64) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE " + parent + " SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	query = "UPDATE " + child + " c JOIN " + parent + " p ON p.id = c." + foreignKey + `
+		SET c.deleted_at = p.deleted_at
+		WHERE p.id = ? AND c.deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// restoreCascade восстанавливает строку parent вместе с дочерними строками,
+// удаленными вместе с ней
+func restoreCascade(ctx context.Context, db *sqlx.DB, parent, child, foreignKey string, id int64) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE " + child + " c JOIN " + parent + " p ON p.id = c." + foreignKey + `
+		SET c.deleted_at = NULL
+		WHERE p.id = ? AND c.deleted_at = p.deleted_at`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	query = "UPDATE " + parent + " SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+	result, err := tx.ExecContext(ctx, query, id)
+	if err := checkRestored(result, err); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -132,30 +132,24 @@ func (r *supportTicketRepository) ListByUserID(ctx context.Context, userID int64
 	return tickets, nil
 }
 
-// List возвращает список тикетов поддержки с фильтрацией
-func (r *supportTicketRepository) List(ctx context.Context, filters map[string]interface{}, offset, limit int) ([]*domain.SupportTicket, error) {
-	query := `
-		SELECT id, user_id, subject, status, created_at
-		FROM support_tickets
-		WHERE 1=1
-	`
+// ticketListQuery строит общий для List и Count запрос по фильтру тикетов
+func ticketListQuery(filter *domain.TicketFilter) *listQuery {
+	q := newListQuery("support_tickets")
 
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if userID, ok := filters["user_id"]; ok {
-			query += " AND user_id = ?"
-			args = append(args, userID)
-		}
-		if status, ok := filters["status"]; ok {
-			query += " AND status = ?"
-			args = append(args, status)
-		}
+	if filter.UserID != nil {
+		q.Where("user_id = ?", *filter.UserID)
 	}
+	if filter.Status != nil {
+		q.Where("status = ?", *filter.Status)
+	}
+
+	return q.OrderBy("created_at", domain.SortDesc).OrderBy("id", domain.SortDesc).
+		Keyset("created_at", "id", domain.SortDesc)
+}
 
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+// List возвращает страницу списка тикетов поддержки с фильтрацией
+func (r *supportTicketRepository) List(ctx context.Context, filter *domain.TicketFilter) ([]*domain.SupportTicket, error) {
+	query, args := ticketListQuery(filter).Paginate(filter.Page).Select("id, user_id, subject, status, created_at")
 
 	var tickets []*domain.SupportTicket
 	err := r.db.SelectContext(ctx, &tickets, query, args...)
@@ -167,26 +161,8 @@ func (r *supportTicketRepository) List(ctx context.Context, filters map[string]i
 }
 
 // Count возвращает количество тикетов поддержки с учетом фильтрации
-func (r *supportTicketRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM support_tickets
-		WHERE 1=1
-	`
-
-	var args []interface{}
-
-	// Обработка фильтров
-	if filters != nil {
-		if userID, ok := filters["user_id"]; ok {
-			query += " AND user_id = ?"
-			args = append(args, userID)
-		}
-		if status, ok := filters["status"]; ok {
-			query += " AND status = ?"
-			args = append(args, status)
-		}
-	}
+func (r *supportTicketRepository) Count(ctx context.Context, filter *domain.TicketFilter) (int, error) {
+	query, args := ticketListQuery(filter).Count("COUNT(*)")
 
 	var count int
 	err := r.db.GetContext(ctx, &count, query, args...)
@@ -252,3 +228,55 @@ func (r *supportTicketRepository) GetMessages(ctx context.Context, ticketID int6
 
 	return messages, nil
 }
+
+// ticketMessagesQuery строит запрос сообщений тикета в хронологическом порядке
+func ticketMessagesQuery(ticketID int64) *listQuery {
+	return newListQuery("ticket_messages").
+		Where("ticket_id = ?", ticketID).
+		OrderBy("created_at", domain.SortAsc).
+		OrderBy("id", domain.SortAsc).
+		Keyset("created_at", "id", domain.SortAsc)
+}
+
+// ListMessages возвращает страницу сообщений тикета в хронологическом порядке
+func (r *supportTicketRepository) ListMessages(ctx context.Context, ticketID int64, page domain.Page) ([]*domain.TicketMessage, error) {
+	query, args := ticketMessagesQuery(ticketID).Paginate(page).Select("id, ticket_id, user_id, message, created_at")
+
+	var messages []*domain.TicketMessage
+	err := r.db.SelectContext(ctx, &messages, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сообщений тикета: %w", err)
+	}
+
+	return messages, nil
+}
+
+// CountMessages возвращает количество сообщений тикета
+func (r *supportTicketRepository) CountMessages(ctx context.Context, ticketID int64) (int, error) {
+	query, args := ticketMessagesQuery(ticketID).Count("COUNT(*)")
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете сообщений тикета: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetMessageByID получает сообщение тикета по ID
+func (r *supportTicketRepository) GetMessageByID(ctx context.Context, id int64) (*domain.TicketMessage, error) {
+	query := `
+		SELECT id, ticket_id, user_id, message, created_at
+		FROM ticket_messages
+		WHERE id = ?
+	`
+
+	var message domain.TicketMessage
+	err := r.db.GetContext(ctx, &message, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сообщения тикета: %w", err)
+	}
+
+	return &message, nil
+}
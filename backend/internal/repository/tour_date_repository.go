@@ -31,7 +31,7 @@ func (r *tourDateRepository) GetByTourID(ctx context.Context, tourID int64) ([]*
 	query := `
 		SELECT id, tour_id, start_date, end_date, availability, price_modifier
 		FROM tour_dates
-		WHERE tour_id = ? AND start_date >= ?
+		WHERE tour_id = ? AND start_date >= ? AND deleted_at IS NULL
 		ORDER BY start_date
 	`
 
@@ -102,9 +102,9 @@ func (r *tourDateRepository) Update(ctx context.Context, tourDate *domain.TourDa
 	return nil
 }
 
-// Delete удаляет дату тура
+// Delete мягко удаляет дату тура
 func (r *tourDateRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM tour_dates WHERE id = ?"
+	query := "UPDATE tour_dates SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
 
 	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
@@ -3,13 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
 )
 
+// ErrTourNotFound тур не существует или удален
+var ErrTourNotFound = errors.New("тур не найден")
+
+// ErrInsufficientAvailability недостаточно свободных мест на дату тура
+var ErrInsufficientAvailability = errors.New("недостаточно свободных мест на выбранную дату")
+
+// ErrTourVersionConflict тур изменен после того, как клиент получил его версию
+var ErrTourVersionConflict = errors.New("тур был изменен другим пользователем")
+
 // tourRepository реализация TourRepository из repository.go
 type tourRepository struct {
 	db *sqlx.DB
@@ -23,8 +37,8 @@ func NewTourRepository(db *sqlx.DB) TourRepository {
 // Create создает новый тур
 func (r *tourRepository) Create(ctx context.Context, tour *domain.Tour) (int64, error) {
 	query := `
-		INSERT INTO tours (city_id, name, description, base_price, image_url, duration, is_active)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO tours (city_id, name, description, base_price, currency, image_url, duration, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(
@@ -34,6 +48,7 @@ func (r *tourRepository) Create(ctx context.Context, tour *domain.Tour) (int64,
 		tour.Name,
 		tour.Description,
 		tour.BasePrice,
+		tour.Currency,
 		tour.ImageURL,
 		tour.Duration,
 		tour.IsActive,
@@ -58,7 +73,7 @@ func (r *tourRepository) GetByID(ctx context.Context, id int64) (*domain.Tour, e
 	// Обновленный запрос с псевдонимами для вложенных структур
 	query := `
 		SELECT
-			t.id, t.city_id, t.name, t.description, t.base_price, t.image_url, t.duration, t.is_active, t.created_at,
+			t.id, t.city_id, t.name, t.description, t.base_price, t.currency, t.image_url, t.duration, t.is_active, t.version, t.created_at, t.updated_at,
 			c.id AS "city.id",
 			c.name AS "city.name",
 			co.id AS "city.country.id",
@@ -67,14 +82,14 @@ func (r *tourRepository) GetByID(ctx context.Context, id int64) (*domain.Tour, e
 		FROM tours t
 		LEFT JOIN cities c ON t.city_id = c.id        -- Используем LEFT JOIN на случай, если город не указан
 		LEFT JOIN countries co ON c.country_id = co.id -- Используем LEFT JOIN на случай, если страна не указана
-		WHERE t.id = ?
+		WHERE t.id = ? AND t.deleted_at IS NULL
 	`
 
 	var tour domain.Tour // Сканируем напрямую в основную структуру
 	err := r.db.GetContext(ctx, &tour, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("тур с ID %d не найден", id)
+			return nil, fmt.Errorf("%w: ID %d", ErrTourNotFound, id)
 		}
 		return nil, fmt.Errorf("ошибка при поиске тура: %w", err)
 	}
@@ -106,9 +121,9 @@ func (r *tourRepository) GetByID(ctx context.Context, id int64) (*domain.Tour, e
 // getHotelsByCityID вспомогательный метод для получения отелей по ID города
 func (r *tourRepository) getHotelsByCityID(ctx context.Context, cityID int64) ([]*domain.Hotel, error) {
 	query := `
-		SELECT id, city_id, name, description, address, category, image_url, is_active, created_at
+		SELECT id, city_id, name, description, address, category, image_url, is_active, created_at, updated_at
 		FROM hotels
-		WHERE city_id = ? AND is_active = true
+		WHERE city_id = ? AND is_active = true AND deleted_at IS NULL
 	`
 	var hotels []*domain.Hotel
 	err := r.db.SelectContext(ctx, &hotels, query, cityID)
@@ -122,259 +137,404 @@ func (r *tourRepository) getHotelsByCityID(ctx context.Context, cityID int64) ([
 	return hotels, nil
 }
 
-// Update обновляет информацию о туре
+// priceBoundCondition строит условие фильтра по цене тура. Граница задана в
+// каждой валюте - тур сравнивается с границей в своей валюте, а туры в валютах
+// без границы не проходят фильтр.
+func priceBoundCondition(op string, bounds map[string]money.Amount) (string, []interface{}) {
+	currencies := make([]string, 0, len(bounds))
+	for currency := range bounds {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var sb strings.Builder
+	args := make([]interface{}, 0, 2*len(bounds))
+	sb.WriteString("t.base_price " + op + " CASE t.currency")
+	for _, currency := range currencies {
+		sb.WriteString(" WHEN ? THEN ?")
+		args = append(args, currency, bounds[currency])
+	}
+	sb.WriteString(" ELSE NULL END")
+
+	return sb.String(), args
+}
+
+// Update обновляет информацию о туре и увеличивает его версию. Если в tour.Version
+// задана ожидаемая версия, тур обновляется только при совпадении с текущей,
+// иначе возвращается ErrTourVersionConflict. Для удаленного или несуществующего
+// тура возвращается ErrTourNotFound.
 func (r *tourRepository) Update(ctx context.Context, tour *domain.Tour) error {
 	query := `
 		UPDATE tours 
-		SET city_id = ?, name = ?, description = ?, base_price = ?, image_url = ?, duration = ?, is_active = ?
-		WHERE id = ?
+		SET city_id = ?, name = ?, description = ?, base_price = ?, currency = ?, image_url = ?, duration = ?, is_active = ?,
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL
 	`
-
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
+	args := []interface{}{
 		tour.CityID,
 		tour.Name,
 		tour.Description,
 		tour.BasePrice,
+		tour.Currency,
 		tour.ImageURL,
 		tour.Duration,
 		tour.IsActive,
 		tour.ID,
-	)
+	}
+	if tour.Version > 0 {
+		query += " AND version = ?"
+		args = append(args, tour.Version)
+	}
 
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении тура: %w", err)
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении тура: %w", err)
+	}
+	if affected == 0 {
+		if tour.Version == 0 {
+			return fmt.Errorf("%w: ID %d", ErrTourNotFound, tour.ID)
+		}
+		// Версия не совпала или тура нет
+		var current int
+		if err := r.db.GetContext(ctx, &current, "SELECT version FROM tours WHERE id = ? AND deleted_at IS NULL", tour.ID); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: ID %d", ErrTourNotFound, tour.ID)
+			}
+			return fmt.Errorf("ошибка при проверке версии тура: %w", err)
+		}
+		return ErrTourVersionConflict
+	}
+	if tour.Version > 0 {
+		tour.Version++
+	}
+
 	return nil
 }
 
-// Delete удаляет тур по ID
+// Delete мягко удаляет тур вместе с его датами
 func (r *tourRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM tours WHERE id = ?"
-
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
+	if err := softDeleteCascade(ctx, r.db, "tours", "tour_dates", "tour_id", id); err != nil {
 		return fmt.Errorf("ошибка при удалении тура: %w", err)
 	}
 
 	return nil
 }
 
-// List возвращает список туров с фильтрацией и информацией о городе/стране
-func (r *tourRepository) List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Tour, error) {
-	fmt.Printf("DEBUG: Received filters in Repository List: %#v\n", filters) // Добавляем логирование полученных фильтров
-	offset := (page - 1) * size
-	limit := size
+// Restore восстанавливает удаленный тур и даты, удаленные вместе с ним
+func (r *tourRepository) Restore(ctx context.Context, id int64) error {
+	if err := restoreCascade(ctx, r.db, "tours", "tour_dates", "tour_id", id); err != nil {
+		return fmt.Errorf("ошибка при восстановлении тура: %w", err)
+	}
 
-	selectFields := `
-		DISTINCT -- Используем DISTINCT, если будет JOIN с tour_dates
-		t.id, t.city_id, t.name, t.description, t.base_price, t.image_url, t.duration, t.is_active, t.created_at,
-		c.id AS "city.id",
-		c.name AS "city.name",
-		co.id AS "city.country.id",
-		co.name AS "city.country.name",
-		co.code AS "city.country.code"
-	`
-	fromClause := `
-		FROM tours t
-		LEFT JOIN cities c ON t.city_id = c.id
-		LEFT JOIN countries co ON c.country_id = co.id
-	`
-	joinClause := "" // Инициализируем пустой JOIN
+	return nil
+}
 
-	var conditions []string
-	var args []interface{}
-	needsDateJoin := false // Флаг для определения необходимости JOIN с tour_dates
+// tourSortColumns колонки сортировки списка туров
+var tourSortColumns = map[string]string{
+	domain.TourSortPrice:     "t.base_price",
+	domain.TourSortDuration:  "t.duration",
+	domain.TourSortName:      "t.name",
+	domain.TourSortCreatedAt: "t.created_at",
+}
 
-	// Активные туры по умолчанию
-	conditions = append(conditions, "t.is_active = true")
+// tourFilterQuery строит запрос по условиям фильтра туров без сортировки;
+// используется списком, подсчетом и фасетами
+func tourFilterQuery(filter *domain.TourFilter) *listQuery {
+	q := newListQuery("tours t").
+		Join("LEFT JOIN cities c ON t.city_id = c.id").
+		Join("LEFT JOIN countries co ON c.country_id = co.id").
+		Where(deletedCondition("t.deleted_at", filter.Deleted))
+	// В архиве выводятся и неактивные туры
+	if !filter.Deleted {
+		q.Where("t.is_active = true")
+	}
 
-	// Обработка фильтров
-	if filters != nil {
-		if cityID, ok := filters["city_id"]; ok {
-			conditions = append(conditions, "t.city_id = ?")
-			args = append(args, cityID)
-		}
-		if countryID, ok := filters["country_id"]; ok {
-			conditions = append(conditions, "c.country_id = ?")
-			args = append(args, countryID)
-		}
-		if priceMin, ok := filters["price_min"]; ok {
-			conditions = append(conditions, "t.base_price >= ?")
-			args = append(args, priceMin)
-		}
-		if priceMax, ok := filters["price_max"]; ok {
-			conditions = append(conditions, "t.base_price <= ?")
-			args = append(args, priceMax)
-		}
-		// Обновленный поиск по названию и описанию (регистронезависимый)
-		if searchQuery, ok := filters["search_query"]; ok && searchQuery.(string) != "" {
-			searchPattern := "%" + strings.ToLower(searchQuery.(string)) + "%"
-			conditions = append(conditions, "(LOWER(t.name) LIKE ? OR LOWER(t.description) LIKE ?)")
-			args = append(args, searchPattern, searchPattern)
-		}
-		// Фильтр по продолжительности
-		if durationMin, ok := filters["duration_min"]; ok {
-			conditions = append(conditions, "t.duration >= ?")
-			args = append(args, durationMin)
-		}
-		if durationMax, ok := filters["duration_max"]; ok {
-			conditions = append(conditions, "t.duration <= ?")
-			args = append(args, durationMax)
-		}
-		// Фильтры по дате
-		if startDateAfter, ok := filters["start_date_after"]; ok {
-			conditions = append(conditions, "td.start_date >= ?")
-			args = append(args, startDateAfter)
-			needsDateJoin = true
+	if filter.CityID != nil {
+		q.Where("t.city_id = ?", *filter.CityID)
+	}
+	if filter.CountryID != nil {
+		q.Where("c.country_id = ?", *filter.CountryID)
+	}
+	if len(filter.PriceMin) > 0 {
+		condition, args := priceBoundCondition(">=", filter.PriceMin)
+		q.Where(condition, args...)
+	}
+	if len(filter.PriceMax) > 0 {
+		condition, args := priceBoundCondition("<=", filter.PriceMax)
+		q.Where(condition, args...)
+	}
+	// Результаты полнотекстового поиска задаются списком ID; без него
+	// выполняется простой регистронезависимый поиск по названию и описанию
+	if filter.IDs != nil {
+		q.Where(idListCondition("t.id", filter.IDs))
+	} else if filter.Search != "" {
+		pattern := "%" + strings.ToLower(filter.Search) + "%"
+		q.Where("(LOWER(t.name) LIKE ? OR LOWER(t.description) LIKE ?)", pattern, pattern)
+	}
+	if filter.DurationMin != nil {
+		q.Where("t.duration >= ?", *filter.DurationMin)
+	}
+	if filter.DurationMax != nil {
+		q.Where("t.duration <= ?", *filter.DurationMax)
+	}
+	// Тур подходит, если у него есть хотя бы одна дата в заданном интервале
+	if filter.StartDateAfter != nil || filter.StartDateBefore != nil {
+		dateQuery := "EXISTS (SELECT 1 FROM tour_dates td WHERE td.tour_id = t.id AND td.deleted_at IS NULL"
+		var args []interface{}
+		if filter.StartDateAfter != nil {
+			dateQuery += " AND td.start_date >= ?"
+			args = append(args, *filter.StartDateAfter)
 		}
-		if startDateBefore, ok := filters["start_date_before"]; ok {
-			conditions = append(conditions, "td.start_date <= ?")
-			args = append(args, startDateBefore)
-			needsDateJoin = true
+		if filter.StartDateBefore != nil {
+			dateQuery += " AND td.start_date <= ?"
+			args = append(args, *filter.StartDateBefore)
 		}
+		q.Where(dateQuery+")", args...)
 	}
 
-	// Добавляем JOIN с tour_dates, если необходимо
-	if needsDateJoin {
-		joinClause = " LEFT JOIN tour_dates td ON t.id = td.tour_id "
-	}
+	return q
+}
 
-	// Формируем WHERE clause
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+// tourListQuery строит общий для List и Count запрос по фильтру туров
+func tourListQuery(filter *domain.TourFilter) *listQuery {
+	q := tourFilterQuery(filter)
+	if column, ok := tourSortColumns[filter.SortBy]; ok {
+		q.OrderBy(column, filter.SortOrder)
+	} else if len(filter.IDs) > 0 {
+		// Порядок релевантности, в котором ID вернул поисковый индекс
+		q.OrderBy("FIELD(t.id, "+idList(filter.IDs)+")", domain.SortAsc)
+	} else {
+		q.OrderBy("t.created_at", domain.SortDesc)
 	}
+	// Дополнительная сортировка делает порядок страниц детерминированным
+	q.OrderBy("t.id", domain.SortDesc)
+	// При выборке по курсору туры идут от новых к старым
+	q.Keyset("t.created_at", "t.id", domain.SortDesc)
 
-	// Формируем ORDER BY clause
-	orderByClause := " ORDER BY t.created_at DESC" // Сортировка по умолчанию
-	if sortBy, ok := filters["sort_by"].(string); ok {
-		if sortOrder, ok := filters["sort_order"].(string); ok {
-			// Проверяем, что sortOrder 'asc' или 'desc' (уже сделано в хендлере, но для безопасности)
-			if sortOrder == "asc" || sortOrder == "desc" {
-				// sortBy уже содержит проверенное имя поля из хендлера (e.g., "t.base_price")
-				orderByClause = fmt.Sprintf(" ORDER BY %s %s", sortBy, strings.ToUpper(sortOrder))
-			}
-		}
+	return q
+}
+
+// idList возвращает ID через запятую. ID подставляются в запрос напрямую:
+// это целые числа, и число параметров запроса не растет с выборкой.
+func idList(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
 	}
+	return strings.Join(parts, ", ")
+}
 
-	// Формируем LIMIT и OFFSET
-	limitOffsetClause := " LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+// idListCondition возвращает условие принадлежности колонки списку ID;
+// пустой список не пропускает ни одной строки
+func idListCondition(column string, ids []int64) string {
+	if len(ids) == 0 {
+		return "FALSE"
+	}
+	return column + " IN (" + idList(ids) + ")"
+}
 
-	// Собираем полный запрос
-	query := "SELECT " + selectFields + fromClause + joinClause + whereClause + orderByClause + limitOffsetClause
+// List возвращает страницу списка туров с информацией о городе и стране
+func (r *tourRepository) List(ctx context.Context, filter *domain.TourFilter) ([]*domain.Tour, error) {
+	query, args := tourListQuery(filter).Paginate(filter.Page).Select(`
+		t.id, t.city_id, t.name, t.description, t.base_price, t.currency, t.image_url, t.duration, t.is_active, t.version, t.created_at, t.updated_at,
+		t.deleted_at,
+		c.id AS "city.id",
+		c.name AS "city.name",
+		co.id AS "city.country.id",
+		co.name AS "city.country.name",
+		co.code AS "city.country.code"`)
 
-	var tours []*domain.Tour // Сканируем напрямую в слайс domain.Tour
+	var tours []*domain.Tour
 	err := r.db.SelectContext(ctx, &tours, query, args...)
 	if err != nil {
-		// Добавим вывод самого запроса и аргументов для отладки
-		fmt.Printf("DEBUG: Error executing List query: %v\nQuery: %s\nArgs: %v\n", err, query, args)
 		return nil, fmt.Errorf("ошибка при поиске туров: %w", err)
 	}
 
-	// Даты и отели для списка по-прежнему не загружаем для производительности
-
+	// Даты и отели для списка не загружаются для производительности
 	return tours, nil
 }
 
-// Count возвращает количество туров с учетом фильтрации
-func (r *tourRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	fmt.Printf("DEBUG: Received filters in Repository Count: %#v\n", filters) // Добавляем логирование полученных фильтров
-	selectClause := "SELECT COUNT(DISTINCT t.id)"                             // Считаем уникальные ID туров
-	fromClause := `
-		FROM tours t
-		LEFT JOIN cities c ON t.city_id = c.id -- Используем LEFT JOIN для консистентности с List
-		LEFT JOIN countries co ON c.country_id = co.id -- Добавляем JOIN с countries для фильтрации по country_id
-	`
-	joinClause := "" // Инициализируем пустой JOIN для дат
+// Count возвращает количество туров, подходящих под фильтр
+func (r *tourRepository) Count(ctx context.Context, filter *domain.TourFilter) (int, error) {
+	query, args := tourListQuery(filter).Count("COUNT(*)")
 
-	var conditions []string
-	var args []interface{}
-	needsDateJoin := false // Флаг для определения необходимости JOIN с tour_dates
+	var count int
+	err := r.db.GetContext(ctx, &count, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете туров: %w", err)
+	}
 
-	// Активные туры по умолчанию
-	conditions = append(conditions, "t.is_active = true")
+	return count, nil
+}
 
-	// Обработка фильтров (аналогично List)
-	if filters != nil {
-		if cityID, ok := filters["city_id"]; ok {
-			conditions = append(conditions, "t.city_id = ?")
-			args = append(args, cityID)
-		}
-		if countryID, ok := filters["country_id"]; ok {
-			// Условие теперь применяется к co (countries)
-			conditions = append(conditions, "co.id = ?") // Используем co.id после JOIN с countries
-			args = append(args, countryID)
-		}
-		if priceMin, ok := filters["price_min"]; ok {
-			conditions = append(conditions, "t.base_price >= ?")
-			args = append(args, priceMin)
-		}
-		if priceMax, ok := filters["price_max"]; ok {
-			conditions = append(conditions, "t.base_price <= ?")
-			args = append(args, priceMax)
-		}
-		// Обновленный поиск по названию и описанию (регистронезависимый)
-		if searchQuery, ok := filters["search_query"]; ok && searchQuery.(string) != "" {
-			searchPattern := "%" + strings.ToLower(searchQuery.(string)) + "%"
-			conditions = append(conditions, "(LOWER(t.name) LIKE ? OR LOWER(t.description) LIKE ?)")
-			args = append(args, searchPattern, searchPattern)
-		}
-		// Фильтр по продолжительности
-		if durationMin, ok := filters["duration_min"]; ok {
-			conditions = append(conditions, "t.duration >= ?")
-			args = append(args, durationMin)
-		}
-		if durationMax, ok := filters["duration_max"]; ok {
-			conditions = append(conditions, "t.duration <= ?")
-			args = append(args, durationMax)
+// Facets подсчитывает туры, подходящие под фильтр, по странам, городам,
+// интервалам длительности и цены, категориям отелей и месяцам дат
+func (r *tourRepository) Facets(ctx context.Context, filter *domain.TourFilter, opts *domain.TourFacetOptions) (*domain.TourFacets, error) {
+	facets := &domain.TourFacets{
+		Countries:  []domain.FacetCount{},
+		Cities:     []domain.FacetCount{},
+		Categories: []domain.CategoryFacet{},
+		Months:     []domain.MonthFacet{},
+	}
+
+	query, args := tourFilterQuery(filter).
+		Where("co.id IS NOT NULL").
+		GroupBy("co.id, co.name").
+		OrderBy("count", domain.SortDesc).
+		OrderBy("co.name", domain.SortAsc).
+		Select("co.id, co.name, COUNT(*) AS count")
+	if err := r.db.SelectContext(ctx, &facets.Countries, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по странам: %w", err)
+	}
+
+	query, args = tourFilterQuery(filter).
+		Where("c.id IS NOT NULL").
+		GroupBy("c.id, c.name").
+		OrderBy("count", domain.SortDesc).
+		OrderBy("c.name", domain.SortAsc).
+		Select("c.id, c.name, COUNT(*) AS count")
+	if err := r.db.SelectContext(ctx, &facets.Cities, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по городам: %w", err)
+	}
+
+	durations, err := r.durationFacets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	facets.Durations = durations
+
+	prices, err := r.priceFacets(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	facets.Prices = prices
+
+	// Тур учитывается в категории, если в его городе есть активный отель этой категории
+	query, args = tourFilterQuery(filter).
+		Join("JOIN hotels h ON h.city_id = t.city_id AND h.is_active = true AND h.deleted_at IS NULL").
+		GroupBy("h.category").
+		OrderBy("h.category", domain.SortAsc).
+		Select("h.category, COUNT(DISTINCT t.id) AS count")
+	if err := r.db.SelectContext(ctx, &facets.Categories, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по категориям отелей: %w", err)
+	}
+
+	// Месяцы предстоящих дат со свободными местами в пределах фильтра по датам
+	q := tourFilterQuery(filter).
+		Join("JOIN tour_dates fd ON fd.tour_id = t.id AND fd.deleted_at IS NULL").
+		Where("fd.availability > 0").
+		Where("fd.start_date >= CURDATE()")
+	if filter.StartDateAfter != nil {
+		q.Where("fd.start_date >= ?", *filter.StartDateAfter)
+	}
+	if filter.StartDateBefore != nil {
+		q.Where("fd.start_date <= ?", *filter.StartDateBefore)
+	}
+	query, args = q.
+		GroupBy("month").
+		OrderBy("month", domain.SortAsc).
+		Select("DATE_FORMAT(fd.start_date, '%Y-%m') AS month, COUNT(DISTINCT t.id) AS count")
+	if err := r.db.SelectContext(ctx, &facets.Months, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по месяцам: %w", err)
+	}
+
+	return facets, nil
+}
+
+// durationFacets подсчитывает туры по интервалам длительности одним запросом
+func (r *tourRepository) durationFacets(ctx context.Context, filter *domain.TourFilter) ([]domain.DurationFacet, error) {
+	buckets := domain.TourDurationFacetBuckets
+	columns := make([]string, len(buckets))
+	var columnArgs []interface{}
+	for i, b := range buckets {
+		if b[1] > 0 {
+			columns[i] = "COALESCE(SUM(t.duration BETWEEN ? AND ?), 0)"
+			columnArgs = append(columnArgs, b[0], b[1])
+		} else {
+			columns[i] = "COALESCE(SUM(t.duration >= ?), 0)"
+			columnArgs = append(columnArgs, b[0])
 		}
-		// Фильтры по дате
-		if startDateAfter, ok := filters["start_date_after"]; ok {
-			conditions = append(conditions, "td.start_date >= ?")
-			args = append(args, startDateAfter)
-			needsDateJoin = true
+	}
+
+	counts, err := r.bucketCounts(ctx, filter, columns, columnArgs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по длительности: %w", err)
+	}
+
+	facets := make([]domain.DurationFacet, len(buckets))
+	for i, b := range buckets {
+		facets[i] = domain.DurationFacet{Min: b[0], Count: counts[i]}
+		if b[1] > 0 {
+			max := b[1]
+			facets[i].Max = &max
 		}
-		if startDateBefore, ok := filters["start_date_before"]; ok {
-			conditions = append(conditions, "td.start_date <= ?")
-			args = append(args, startDateBefore)
-			needsDateJoin = true
+	}
+	return facets, nil
+}
+
+// priceFacets подсчитывает туры по ценовым интервалам одним запросом. Цена
+// тура сравнивается с границами, пересчитанными в валюту тура.
+func (r *tourRepository) priceFacets(ctx context.Context, filter *domain.TourFilter, opts *domain.TourFacetOptions) ([]domain.PriceFacet, error) {
+	if opts == nil || len(opts.PriceEdges) == 0 {
+		return []domain.PriceFacet{}, nil
+	}
+
+	edges := opts.PriceEdges
+	columns := make([]string, len(edges))
+	var columnArgs []interface{}
+	for i := range edges {
+		condition, args := priceBoundCondition(">=", edges[i])
+		if i+1 < len(edges) {
+			upper, upperArgs := priceBoundCondition("<", edges[i+1])
+			condition += " AND " + upper
+			args = append(args, upperArgs...)
 		}
+		columns[i] = "COALESCE(SUM(" + condition + "), 0)"
+		columnArgs = append(columnArgs, args...)
 	}
 
-	// Добавляем JOIN с tour_dates, если необходимо
-	if needsDateJoin {
-		joinClause = " LEFT JOIN tour_dates td ON t.id = td.tour_id "
+	counts, err := r.bucketCounts(ctx, filter, columns, columnArgs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете туров по цене: %w", err)
 	}
 
-	// Формируем WHERE clause
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	facets := make([]domain.PriceFacet, len(edges))
+	for i := range edges {
+		facets[i] = domain.PriceFacet{Min: edges[i][opts.Currency], Currency: opts.Currency, Count: counts[i]}
+		if i+1 < len(edges) {
+			max := edges[i+1][opts.Currency]
+			facets[i].Max = &max
+		}
 	}
+	return facets, nil
+}
 
-	// Собираем полный запрос
-	query := selectClause + fromClause + joinClause + whereClause
+// bucketCounts выполняет запрос с колонками-счетчиками по фильтру туров
+func (r *tourRepository) bucketCounts(ctx context.Context, filter *domain.TourFilter, columns []string, columnArgs []interface{}) ([]int, error) {
+	query, args := tourFilterQuery(filter).Select(strings.Join(columns, ", "), columnArgs...)
 
-	var count int
-	err := r.db.GetContext(ctx, &count, query, args...)
-	if err != nil {
-		// Добавим вывод самого запроса и аргументов для отладки
-		fmt.Printf("DEBUG: Error executing Count query: %v\nQuery: %s\nArgs: %v\n", err, query, args)
-		return 0, fmt.Errorf("ошибка при подсчете туров: %w", err)
+	counts := make([]int, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, err
 	}
 
-	return count, nil
+	return counts, nil
 }
 
 // AddTourDate добавляет дату проведения тура
 func (r *tourRepository) AddTourDate(ctx context.Context, tourDate *domain.TourDate) (int64, error) {
 	query := `
-		INSERT INTO tour_dates (tour_id, start_date, end_date, availability)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO tour_dates (tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier, schedule_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(
@@ -383,7 +543,11 @@ func (r *tourRepository) AddTourDate(ctx context.Context, tourDate *domain.TourD
 		tourDate.TourID,
 		tourDate.StartDate,
 		tourDate.EndDate,
+		tourDate.Capacity,
 		tourDate.Availability,
+		tourDate.BaseModifier,
+		tourDate.PriceModifier,
+		tourDate.ScheduleID,
 	)
 
 	if err != nil {
@@ -401,9 +565,10 @@ func (r *tourRepository) AddTourDate(ctx context.Context, tourDate *domain.TourD
 // GetTourDates возвращает список доступных дат тура
 func (r *tourRepository) GetTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error) {
 	query := `
-		SELECT id, tour_id, start_date, end_date, availability, price_modifier
+		SELECT id, tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier, schedule_id, updated_at
 		FROM tour_dates
-		WHERE tour_id = ?
+		WHERE tour_id = ? AND deleted_at IS NULL
+		ORDER BY start_date
 	`
 
 	var tourDates []*domain.TourDate
@@ -417,11 +582,25 @@ func (r *tourRepository) GetTourDates(ctx context.Context, tourID int64) ([]*dom
 
 // GetTourDateByID возвращает дату тура по ID
 func (r *tourRepository) GetTourDateByID(ctx context.Context, id int64) (*domain.TourDate, error) {
+	return r.getTourDate(ctx, id, false)
+}
+
+// GetTourDateByIDWithDeleted возвращает дату тура по ID, в том числе удаленную.
+// Нужна для заказов, оформленных до удаления даты.
+func (r *tourRepository) GetTourDateByIDWithDeleted(ctx context.Context, id int64) (*domain.TourDate, error) {
+	return r.getTourDate(ctx, id, true)
+}
+
+// getTourDate возвращает дату тура по ID; withDeleted включает в поиск удаленные даты
+func (r *tourRepository) getTourDate(ctx context.Context, id int64, withDeleted bool) (*domain.TourDate, error) {
 	query := `
-		SELECT id, tour_id, start_date, end_date, availability, price_modifier
+		SELECT id, tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier, schedule_id,
+			updated_at, deleted_at
 		FROM tour_dates
-		WHERE id = ?
-	`
+		WHERE id = ?`
+	if !withDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	var tourDate domain.TourDate
 	err := r.db.GetContext(ctx, &tourDate, query, id)
@@ -439,7 +618,7 @@ func (r *tourRepository) GetTourDateByID(ctx context.Context, id int64) (*domain
 func (r *tourRepository) UpdateTourDate(ctx context.Context, tourDate *domain.TourDate) error {
 	query := `
 		UPDATE tour_dates 
-		SET tour_id = ?, start_date = ?, end_date = ?, availability = ?, price_modifier = ?
+		SET tour_id = ?, start_date = ?, end_date = ?, capacity = ?, availability = ?, base_modifier = ?, price_modifier = ?
 		WHERE id = ?
 	`
 
@@ -449,7 +628,9 @@ func (r *tourRepository) UpdateTourDate(ctx context.Context, tourDate *domain.To
 		tourDate.TourID,
 		tourDate.StartDate,
 		tourDate.EndDate,
+		tourDate.Capacity,
 		tourDate.Availability,
+		tourDate.BaseModifier,
 		tourDate.PriceModifier,
 		tourDate.ID,
 	)
@@ -461,33 +642,134 @@ func (r *tourRepository) UpdateTourDate(ctx context.Context, tourDate *domain.To
 	return nil
 }
 
-// UpdateTourDateAvailabilityTx обновляет доступность мест для даты тура в рамках транзакции
-func (r *tourRepository) UpdateTourDateAvailabilityTx(ctx context.Context, tx Tx, tourDateID int64, availability int) error {
+// ListUpcomingTourIDs возвращает ID туров, у которых есть даты с началом не раньше from
+func (r *tourRepository) ListUpcomingTourIDs(ctx context.Context, from time.Time) ([]int64, error) {
 	query := `
-		UPDATE tour_dates 
-		SET availability = ?
-		WHERE id = ?
+		SELECT DISTINCT td.tour_id
+		FROM tour_dates td
+		JOIN tours t ON t.id = td.tour_id AND t.deleted_at IS NULL
+		WHERE td.start_date >= ? AND td.deleted_at IS NULL
+		ORDER BY td.tour_id
+	`
+
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, query, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении туров с предстоящими датами: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListForSearch возвращает тексты активных туров для полнотекстового индекса;
+// tourID ограничивает выборку одним туром
+func (r *tourRepository) ListForSearch(ctx context.Context, tourID *int64) ([]*domain.TourSearchSource, error) {
+	query := `
+		SELECT
+			t.id AS tour_id, t.name, COALESCE(t.description, '') AS description,
+			COALESCE(c.name, '') AS city,
+			COALESCE(co.name, '') AS country,
+			COALESCE((
+				SELECT GROUP_CONCAT(h.name ORDER BY h.name SEPARATOR '\n')
+				FROM hotels h
+				WHERE h.city_id = t.city_id AND h.is_active = true AND h.deleted_at IS NULL
+			), '') AS hotels
+		FROM tours t
+		LEFT JOIN cities c ON t.city_id = c.id
+		LEFT JOIN countries co ON c.country_id = co.id
+		WHERE t.is_active = true AND t.deleted_at IS NULL
+	`
+	var args []interface{}
+	if tourID != nil {
+		query += " AND t.id = ?"
+		args = append(args, *tourID)
+	}
+
+	var sources []*domain.TourSearchSource
+	err := r.db.SelectContext(ctx, &sources, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении туров для поискового индекса: %w", err)
+	}
+
+	return sources, nil
+}
+
+// UpdatePriceModifier сохраняет итоговый модификатор цены даты тура
+func (r *tourRepository) UpdatePriceModifier(ctx context.Context, tourDateID int64, modifier money.Ratio) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE tour_dates SET price_modifier = ? WHERE id = ?", modifier, tourDateID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении модификатора цены даты тура: %w", err)
+	}
+
+	return nil
+}
+
+// ReserveSeatsTx атомарно уменьшает количество свободных мест даты тура в рамках транзакции.
+// Обновление относительное и защищено условием availability >= count, поэтому
+// параллельные бронирования не могут увести остаток ниже нуля. Для удаленной даты
+// или удаленного тура возвращается ErrTourNotFound.
+func (r *tourRepository) ReserveSeatsTx(ctx context.Context, tx Tx, tourDateID int64, count int) error {
+	query := `
+		UPDATE tour_dates td
+		JOIN tours t ON t.id = td.tour_id
+		SET td.availability = td.availability - ?
+		WHERE td.id = ? AND td.availability >= ? AND td.deleted_at IS NULL AND t.deleted_at IS NULL
 	`
 
 	sqlxTx := tx.(*sqlxTx)
 
-	_, err := sqlxTx.tx.ExecContext(
-		ctx,
-		query,
-		availability,
-		tourDateID,
-	)
+	result, err := sqlxTx.tx.ExecContext(ctx, query, count, tourDateID, count)
+	if err != nil {
+		return fmt.Errorf("ошибка при резервировании мест даты тура в транзакции: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при резервировании мест даты тура в транзакции: %w", err)
+	}
+	if affected == 0 {
+		// Строка не обновлена: либо не хватило мест, либо дата или тур удалены
+		var availability int
+		err := sqlxTx.tx.GetContext(ctx, &availability, `
+			SELECT td.availability
+			FROM tour_dates td
+			JOIN tours t ON t.id = td.tour_id
+			WHERE td.id = ? AND td.deleted_at IS NULL AND t.deleted_at IS NULL
+			FOR UPDATE
+		`, tourDateID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: дата тура ID %d", ErrTourNotFound, tourDateID)
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при резервировании мест даты тура в транзакции: %w", err)
+		}
+		return ErrInsufficientAvailability
+	}
+
+	return nil
+}
+
+// ReleaseSeatsTx возвращает места в дату тура в рамках транзакции
+func (r *tourRepository) ReleaseSeatsTx(ctx context.Context, tx Tx, tourDateID int64, count int) error {
+	query := `
+		UPDATE tour_dates
+		SET availability = availability + ?
+		WHERE id = ?
+	`
+
+	sqlxTx := tx.(*sqlxTx)
 
+	_, err := sqlxTx.tx.ExecContext(ctx, query, count, tourDateID)
 	if err != nil {
-		return fmt.Errorf("ошибка при обновлении доступности мест даты тура в транзакции: %w", err)
+		return fmt.Errorf("ошибка при возврате мест даты тура в транзакции: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteTourDate удаляет дату тура по ID
+// DeleteTourDate мягко удаляет дату тура по ID
 func (r *tourRepository) DeleteTourDate(ctx context.Context, id int64) error {
-	query := "DELETE FROM tour_dates WHERE id = ?"
+	query := "UPDATE tour_dates SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
 
 	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
@@ -496,3 +778,34 @@ func (r *tourRepository) DeleteTourDate(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// ListDeletedTourDates возвращает удаленные даты тура
+func (r *tourRepository) ListDeletedTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error) {
+	query := `
+		SELECT id, tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier, schedule_id,
+			updated_at, deleted_at
+		FROM tour_dates
+		WHERE tour_id = ? AND deleted_at IS NOT NULL
+		ORDER BY start_date
+	`
+
+	var tourDates []*domain.TourDate
+	err := r.db.SelectContext(ctx, &tourDates, query, tourID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении удаленных дат тура: %w", err)
+	}
+
+	return tourDates, nil
+}
+
+// RestoreTourDate восстанавливает удаленную дату тура
+func (r *tourRepository) RestoreTourDate(ctx context.Context, tourID, id int64) error {
+	query := "UPDATE tour_dates SET deleted_at = NULL WHERE id = ? AND tour_id = ? AND deleted_at IS NOT NULL"
+
+	result, err := r.db.ExecContext(ctx, query, id, tourID)
+	if err := checkRestored(result, err); err != nil {
+		return fmt.Errorf("ошибка при восстановлении даты тура: %w", err)
+	}
+
+	return nil
+}
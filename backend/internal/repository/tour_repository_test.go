@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/migrations"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/database"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/migrate"
+)
+
+// testDB подключается к MySQL из TEST_MYSQL_DSN и применяет миграции.
+// Без переменной тест пропускается; база должна быть отдельной, тестовой.
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN не задан")
+	}
+
+	db, err := database.NewMySQLConnection(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, migrations.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// testCountrySeq порядковый номер тестовой страны в процессе; из него строится код
+var testCountrySeq int32
+
+// createTestCountry создает тестовую страну с уникальным кодом из трех символов
+// (Z и номер в base36) и удаляет ее по окончании теста. Код, занятый параллельным
+// процессом или оставшийся от прерванного запуска, пропускается.
+func createTestCountry(t *testing.T, db *sqlx.DB) int64 {
+	t.Helper()
+	const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	for attempt := 0; attempt < len(digits)*len(digits); attempt++ {
+		n := int(atomic.AddInt32(&testCountrySeq, 1)) % (len(digits) * len(digits))
+		code := string([]byte{'Z', digits[n/len(digits)], digits[n%len(digits)]})
+
+		res, err := db.Exec("INSERT IGNORE INTO countries (name, code) VALUES (?, ?)", "Тестовая страна", code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			continue // код уже занят
+		}
+
+		countryID, _ := res.LastInsertId()
+		t.Cleanup(func() {
+			if _, err := db.Exec("DELETE FROM countries WHERE id = ?", countryID); err != nil {
+				t.Errorf("не удалось удалить тестовую страну %d: %v", countryID, err)
+			}
+		})
+		return countryID
+	}
+
+	t.Fatal("все коды тестовых стран заняты")
+	return 0
+}
+
+// createTestTourDate создает страну, город, тур и дату с capacity местами;
+// все удаляется каскадно вместе со страной по окончании теста
+func createTestTourDate(t *testing.T, db *sqlx.DB, capacity int) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	countryID := createTestCountry(t, db)
+
+	res, err := db.ExecContext(ctx, "INSERT INTO cities (country_id, name) VALUES (?, ?)", countryID, "Тестовый город")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cityID, _ := res.LastInsertId()
+
+	res, err = db.ExecContext(ctx, "INSERT INTO tours (city_id, name, base_price, duration) VALUES (?, ?, ?, ?)", cityID, "Тестовый тур", "10000.00", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tourID, _ := res.LastInsertId()
+
+	start := time.Now().AddDate(0, 1, 0)
+	res, err = db.ExecContext(ctx, "INSERT INTO tour_dates (tour_id, start_date, end_date, capacity, availability) VALUES (?, ?, ?, ?, ?)",
+		tourID, start, start.AddDate(0, 0, 6), capacity, capacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tourDateID, _ := res.LastInsertId()
+	return tourDateID
+}
+
+// TestReserveSeatsTxConcurrent проверяет, что условное резервирование не продает
+// больше мест, чем есть, при параллельных транзакциях
+func TestReserveSeatsTxConcurrent(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	const capacity, clients = 5, 40
+	tourDateID := createTestTourDate(t, db, capacity)
+	tours := NewTourRepository(db)
+	orders := NewOrderRepository(db)
+
+	var mu sync.Mutex
+	succeeded := 0
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			tx, err := orders.BeginTx(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			err = tours.ReserveSeatsTx(ctx, tx, tourDateID, 1)
+			if err != nil {
+				tx.Rollback()
+				if !errors.Is(err, ErrInsufficientAvailability) {
+					t.Errorf("неожиданная ошибка: %v", err)
+				}
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				t.Error(err)
+				return
+			}
+
+			mu.Lock()
+			succeeded++
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != capacity {
+		t.Errorf("успешных резервирований: %d, ожидалось %d", succeeded, capacity)
+	}
+
+	var availability int
+	if err := db.GetContext(ctx, &availability, "SELECT availability FROM tour_dates WHERE id = ?", tourDateID); err != nil {
+		t.Fatal(err)
+	}
+	if availability != 0 {
+		t.Errorf("остаток мест: %d, ожидался 0", availability)
+	}
+}
+
+// TestDeletedTourIsNotUpdatedOrBooked проверяет, что мягко удаленные тур и дата
+// не изменяются и не бронируются, а вызовы возвращают ErrTourNotFound
+func TestDeletedTourIsNotUpdatedOrBooked(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	tourDateID := createTestTourDate(t, db, 5)
+	tours := NewTourRepository(db)
+	orders := NewOrderRepository(db)
+
+	var tourID int64
+	if err := db.GetContext(ctx, &tourID, "SELECT tour_id FROM tour_dates WHERE id = ?", tourDateID); err != nil {
+		t.Fatal(err)
+	}
+	tour, err := tours.GetByID(ctx, tourID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tours.Delete(ctx, tourID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []int{0, tour.Version} {
+		tour.Version = version
+		if err := tours.Update(ctx, tour); !errors.Is(err, ErrTourNotFound) {
+			t.Errorf("Update удаленного тура (версия %d): %v, ожидалась ErrTourNotFound", version, err)
+		}
+	}
+
+	tx, err := orders.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := tours.ReserveSeatsTx(ctx, tx, tourDateID, 1); !errors.Is(err, ErrTourNotFound) {
+		t.Errorf("ReserveSeatsTx для удаленного тура: %v, ожидалась ErrTourNotFound", err)
+	}
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrTourScheduleNotFound возвращается, если правило расписания не найдено
+var ErrTourScheduleNotFound = errors.New("правило расписания не найдено")
+
+// tourScheduleColumns колонки таблицы tour_schedules в порядке полей domain.TourSchedule
+const tourScheduleColumns = `id, tour_id, name, weekdays, season_start, season_end, blackout_dates, capacity,
+		price_modifiers, is_active, created_at`
+
+// tourScheduleRepository реализация TourScheduleRepository
+type tourScheduleRepository struct {
+	db *sqlx.DB
+}
+
+// NewTourScheduleRepository создает новый экземпляр TourScheduleRepository
+func NewTourScheduleRepository(db *sqlx.DB) TourScheduleRepository {
+	return &tourScheduleRepository{db: db}
+}
+
+// Create создает новое правило расписания
+func (r *tourScheduleRepository) Create(ctx context.Context, schedule *domain.TourSchedule) (int64, error) {
+	query := `
+		INSERT INTO tour_schedules (tour_id, name, weekdays, season_start, season_end, blackout_dates, capacity,
+			price_modifiers, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		schedule.TourID,
+		schedule.Name,
+		schedule.Weekdays,
+		schedule.SeasonStart,
+		schedule.SeasonEnd,
+		schedule.BlackoutDates,
+		schedule.Capacity,
+		schedule.Modifiers,
+		schedule.IsActive,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании правила расписания: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID правила расписания: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByID получает правило расписания по ID
+func (r *tourScheduleRepository) GetByID(ctx context.Context, id int64) (*domain.TourSchedule, error) {
+	query := "SELECT " + tourScheduleColumns + " FROM tour_schedules WHERE id = ?"
+
+	var schedule domain.TourSchedule
+	err := r.db.GetContext(ctx, &schedule, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTourScheduleNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении правила расписания: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// Update обновляет правило расписания. Уже созданные по правилу даты не изменяются.
+func (r *tourScheduleRepository) Update(ctx context.Context, schedule *domain.TourSchedule) error {
+	query := `
+		UPDATE tour_schedules
+		SET name = ?, weekdays = ?, season_start = ?, season_end = ?, blackout_dates = ?, capacity = ?,
+			price_modifiers = ?, is_active = ?
+		WHERE id = ? AND tour_id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		schedule.Name,
+		schedule.Weekdays,
+		schedule.SeasonStart,
+		schedule.SeasonEnd,
+		schedule.BlackoutDates,
+		schedule.Capacity,
+		schedule.Modifiers,
+		schedule.IsActive,
+		schedule.ID,
+		schedule.TourID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении правила расписания: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if affected == 0 {
+		// MySQL не считает строку обновленной, если значения не изменились
+		existing, err := r.GetByID(ctx, schedule.ID)
+		if err != nil {
+			return err
+		}
+		if existing.TourID != schedule.TourID {
+			return ErrTourScheduleNotFound
+		}
+	}
+
+	return nil
+}
+
+// Delete удаляет правило расписания. Созданные по нему даты остаются без привязки к правилу.
+func (r *tourScheduleRepository) Delete(ctx context.Context, tourID, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tour_schedules WHERE id = ? AND tour_id = ?", id, tourID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении правила расписания: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if affected == 0 {
+		return ErrTourScheduleNotFound
+	}
+
+	return nil
+}
+
+// ListByTourID возвращает правила расписания тура
+func (r *tourScheduleRepository) ListByTourID(ctx context.Context, tourID int64) ([]*domain.TourSchedule, error) {
+	query := "SELECT " + tourScheduleColumns + " FROM tour_schedules WHERE tour_id = ? ORDER BY id"
+
+	var schedules []*domain.TourSchedule
+	err := r.db.SelectContext(ctx, &schedules, query, tourID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил расписания тура: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ListActive возвращает активные правила расписания всех активных туров
+func (r *tourScheduleRepository) ListActive(ctx context.Context) ([]*domain.TourSchedule, error) {
+	query := `
+		SELECT s.id, s.tour_id, s.name, s.weekdays, s.season_start, s.season_end, s.blackout_dates, s.capacity,
+			s.price_modifiers, s.is_active, s.created_at
+		FROM tour_schedules s
+		JOIN tours t ON t.id = s.tour_id
+		WHERE s.is_active = true AND t.is_active = true AND t.deleted_at IS NULL
+		ORDER BY s.id
+	`
+
+	var schedules []*domain.TourSchedule
+	err := r.db.SelectContext(ctx, &schedules, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении активных правил расписания: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// InsertDates добавляет даты тура, созданные по правилу расписания. Даты, которые
+// у тура уже есть (по дню начала), пропускаются без изменений, поэтому повторная
+// генерация не затрагивает забронированные места и ручные правки.
+// Возвращает количество добавленных дат.
+func (r *tourScheduleRepository) InsertDates(ctx context.Context, dates []*domain.TourDate) (int, error) {
+	if len(dates) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, 0, len(dates))
+	args := make([]interface{}, 0, len(dates)*8)
+	for _, d := range dates {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, d.TourID, d.StartDate, d.EndDate, d.Capacity, d.Availability, d.BaseModifier, d.PriceModifier, d.ScheduleID)
+	}
+
+	query := `
+		INSERT IGNORE INTO tour_dates (tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier,
+			schedule_id)
+		VALUES ` + strings.Join(placeholders, ", ")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении дат тура по расписанию: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении количества добавленных дат: %w", err)
+	}
+
+	return int(affected), nil
+}
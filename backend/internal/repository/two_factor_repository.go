@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// ErrTwoFactorNotFound двухфакторная аутентификация пользователя не настроена
+var ErrTwoFactorNotFound = errors.New("двухфакторная аутентификация не настроена")
+
+// ErrTwoFactorConfirmed двухфакторная аутентификация уже подтверждена и не может быть перезаписана
+var ErrTwoFactorConfirmed = errors.New("двухфакторная аутентификация уже подключена")
+
+// twoFactorRepository реализация TwoFactorRepository
+type twoFactorRepository struct {
+	db *sqlx.DB
+}
+
+// NewTwoFactorRepository создает новый экземпляр TwoFactorRepository
+func NewTwoFactorRepository(db *sqlx.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// GetByUserID возвращает настройку двухфакторной аутентификации пользователя
+func (r *twoFactorRepository) GetByUserID(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	query := "SELECT user_id, secret, confirmed_at, last_step, created_at FROM two_factor WHERE user_id = ?"
+
+	var tf domain.TwoFactor
+	if err := r.db.GetContext(ctx, &tf, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTwoFactorNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении настройки двухфакторной аутентификации: %w", err)
+	}
+
+	return &tf, nil
+}
+
+// SavePending сохраняет неподтвержденный секрет и коды восстановления,
+// заменяя предыдущую незавершенную попытку подключения
+func (r *twoFactorRepository) SavePending(ctx context.Context, userID int64, secret string, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	var confirmed sql.NullTime
+	err = tx.GetContext(ctx, &confirmed, "SELECT confirmed_at FROM two_factor WHERE user_id = ? FOR UPDATE", userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("ошибка при получении настройки двухфакторной аутентификации: %w", err)
+	case confirmed.Valid:
+		return ErrTwoFactorConfirmed
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO two_factor (user_id, secret, confirmed_at, last_step)
+		VALUES (?, ?, NULL, 0)
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), confirmed_at = NULL, last_step = 0, created_at = CURRENT_TIMESTAMP
+	`, userID, secret)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении секрета: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Confirm подтверждает подключение и запоминает шаг принятого кода
+func (r *twoFactorRepository) Confirm(ctx context.Context, userID int64, step int64) error {
+	query := "UPDATE two_factor SET confirmed_at = NOW(), last_step = ? WHERE user_id = ? AND confirmed_at IS NULL"
+
+	result, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при подтверждении двухфакторной аутентификации: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if rows == 0 {
+		return ErrTwoFactorNotFound
+	}
+
+	return nil
+}
+
+// UseStep принимает код с шагом step, только если он новее последнего
+// принятого. Проверка и запись выполняются одним запросом, поэтому один
+// код не пройдет дважды даже при параллельных запросах.
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := "UPDATE two_factor SET last_step = ? WHERE user_id = ? AND last_step < ?"
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при использовании кода: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// UseRecoveryCode отмечает код восстановления использованным; false - код не найден или уже использован
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := "UPDATE recovery_codes SET used_at = NOW() WHERE user_id = ? AND code_hash = ? AND used_at IS NULL"
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при использовании кода восстановления: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// CountRecoveryCodes возвращает количество неиспользованных кодов восстановления
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	query := "SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL"
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете кодов восстановления: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новыми
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete отключает двухфакторную аутентификацию и удаляет коды восстановления
+func (r *twoFactorRepository) Delete(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("ошибка при удалении кодов восстановления: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM two_factor WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("ошибка при отключении двухфакторной аутентификации: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// replaceRecoveryCodes удаляет коды восстановления пользователя и сохраняет новые в транзакции tx
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("ошибка при удалении кодов восстановления: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hash); err != nil {
+			return fmt.Errorf("ошибка при сохранении кода восстановления: %w", err)
+		}
+	}
+
+	return nil
+}
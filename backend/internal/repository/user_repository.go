@@ -72,9 +72,9 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) (int64,
 // GetByID получает пользователя по ID
 func (r *userRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, created_at
+		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, token_version, email_verified_at, created_at
 		FROM users
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var user domain.User
@@ -92,9 +92,9 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*domain.User, e
 // GetByUsername получает пользователя по имени пользователя
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, created_at
+		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, token_version, email_verified_at, created_at
 		FROM users
-		WHERE username = ?
+		WHERE username = ? AND deleted_at IS NULL
 	`
 
 	log.Printf("[UserRepository] Поиск пользователя по username: %s", username)
@@ -117,9 +117,9 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*d
 // GetByEmail получает пользователя по email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, created_at
+		SELECT id, username, password, email, first_name, last_name, full_name, phone, role_id, token_version, email_verified_at, created_at
 		FROM users
-		WHERE email = ?
+		WHERE email = ? AND deleted_at IS NULL
 	`
 
 	log.Printf("[UserRepository] Поиск пользователя по email: %s", email)
@@ -139,7 +139,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 	return &user, nil
 }
 
-// Update обновляет данные пользователя
+// Update обновляет данные пользователя. Смена email снимает его подтверждение;
+// MySQL присваивает значения слева направо, поэтому сравнение идет со старым адресом.
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 	// Проверяем, нужно ли обновлять пароль
 	var query string
@@ -149,10 +150,12 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 		// Если пароль предоставлен, обновляем его тоже
 		query = `
 			UPDATE users
-			SET username = ?, email = ?, password = ?, first_name = ?, last_name = ?, full_name = ?, phone = ?, role_id = ?
+			SET email_verified_at = IF(email = ?, email_verified_at, NULL),
+				username = ?, email = ?, password = ?, first_name = ?, last_name = ?, full_name = ?, phone = ?, role_id = ?
 			WHERE id = ?
 		`
 		args = []interface{}{
+			user.Email,
 			user.Username,
 			user.Email,
 			user.Password,
@@ -169,10 +172,12 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 		// Если пароль пустой, не обновляем его
 		query = `
 			UPDATE users
-			SET username = ?, email = ?, first_name = ?, last_name = ?, full_name = ?, phone = ?, role_id = ?
+			SET email_verified_at = IF(email = ?, email_verified_at, NULL),
+				username = ?, email = ?, first_name = ?, last_name = ?, full_name = ?, phone = ?, role_id = ?
 			WHERE id = ?
 		`
 		args = []interface{}{
+			user.Email,
 			user.Username,
 			user.Email,
 			user.FirstName,
@@ -197,9 +202,35 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
-// Delete удаляет пользователя
+// IncrementTokenVersion увеличивает версию токенов пользователя,
+// делая недействительными все ранее выданные access токены
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id int64) error {
+	query := "UPDATE users SET token_version = token_version + 1 WHERE id = ?"
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to increment token version: %w", err)
+	}
+
+	log.Printf("[UserRepository] Версия токенов пользователя ID=%d увеличена", id)
+	return nil
+}
+
+// MarkEmailVerified отмечает email пользователя подтвержденным
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	query := "UPDATE users SET email_verified_at = CURRENT_TIMESTAMP WHERE id = ? AND email_verified_at IS NULL"
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	return nil
+}
+
+// Delete мягко удаляет пользователя: заказы и тикеты продолжают на него ссылаться
 func (r *userRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM users WHERE id = ?"
+	query := "UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
 
 	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
@@ -209,17 +240,36 @@ func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// List возвращает список пользователей с пагинацией
-func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*domain.User, error) {
-	query := `
-		SELECT id, username, email, first_name, last_name, full_name, phone, role_id, created_at
-		FROM users
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`
+// Restore восстанавливает удаленного пользователя
+func (r *userRepository) Restore(ctx context.Context, id int64) error {
+	query := "UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL"
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err := checkRestored(result, err); err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	return nil
+}
+
+// userListQuery строит общий для List и Count запрос по действующим или удаленным пользователям
+func userListQuery(deleted bool) *listQuery {
+	return newListQuery("users").
+		Where(deletedCondition("deleted_at", deleted)).
+		OrderBy("created_at", domain.SortDesc).
+		OrderBy("id", domain.SortDesc).
+		Keyset("created_at", "id", domain.SortDesc)
+}
+
+// List возвращает страницу списка пользователей, от новых к старым;
+// deleted выбирает удаленных пользователей вместо действующих
+func (r *userRepository) List(ctx context.Context, page domain.Page, deleted bool) ([]*domain.User, error) {
+	query, args := userListQuery(deleted).
+		Paginate(page).
+		Select("id, username, email, first_name, last_name, full_name, phone, role_id, email_verified_at, created_at, deleted_at")
 
 	var users []*domain.User
-	err := r.db.SelectContext(ctx, &users, query, limit, offset)
+	err := r.db.SelectContext(ctx, &users, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users list: %w", err)
 	}
@@ -227,12 +277,12 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*domain
 	return users, nil
 }
 
-// Count возвращает общее количество пользователей
-func (r *userRepository) Count(ctx context.Context) (int, error) {
-	query := "SELECT COUNT(*) FROM users"
+// Count возвращает количество действующих или удаленных пользователей
+func (r *userRepository) Count(ctx context.Context, deleted bool) (int, error) {
+	query, args := userListQuery(deleted).Count("COUNT(*)")
 
 	var count int
-	err := r.db.GetContext(ctx, &count, query)
+	err := r.db.GetContext(ctx, &count, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
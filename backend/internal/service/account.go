@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/mail"
+)
+
+const (
+	// DefaultVerificationTTL срок действия ссылки подтверждения email по умолчанию
+	DefaultVerificationTTL = 48 * time.Hour
+	// DefaultResetTTL срок действия ссылки сброса пароля по умолчанию
+	DefaultResetTTL = time.Hour
+)
+
+// accountLinks формирует ссылки из писем и задает срок их действия
+type accountLinks struct {
+	appURL          string
+	verificationTTL time.Duration
+	resetTTL        time.Duration
+}
+
+// newAccountLinks применяет значения по умолчанию к настройкам из конфигурации
+func newAccountLinks(cfg config.AccountConfig) accountLinks {
+	links := accountLinks{
+		appURL:          strings.TrimRight(cfg.AppURL, "/"),
+		verificationTTL: time.Duration(cfg.VerificationTTL) * time.Hour,
+		resetTTL:        time.Duration(cfg.ResetTTL) * time.Minute,
+	}
+	if links.verificationTTL <= 0 {
+		links.verificationTTL = DefaultVerificationTTL
+	}
+	if links.resetTTL <= 0 {
+		links.resetTTL = DefaultResetTTL
+	}
+	return links
+}
+
+// url возвращает ссылку на страницу фронтенда с токеном
+func (l accountLinks) url(page, token string) string {
+	return l.appURL + "/" + page + "?token=" + url.QueryEscape(token)
+}
+
+// SendEmailVerification повторно отправляет письмо подтверждения email;
+// ссылки из предыдущих писем перестают действовать
+func (s *AuthServiceImpl) SendEmailVerification(ctx context.Context, userID int64) error {
+	user, err := s.repos.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendEmailVerification(ctx, user)
+}
+
+// VerifyEmail подтверждает email по токену из письма. Токен, отправленный
+// на адрес, который пользователь с тех пор сменил, не подтверждает новый адрес.
+func (s *AuthServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := s.consumeAccountToken(ctx, domain.AccountTokenVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repos.GetByID(ctx, stored.UserID)
+	if err != nil || user.Email != stored.Email {
+		return ErrInvalidAccountToken
+	}
+
+	log.Printf("[AuthService] Email пользователя ID=%d подтвержден", user.ID)
+	return s.repos.MarkEmailVerified(ctx, user.ID)
+}
+
+// ForgotPassword отправляет ссылку сброса пароля. Для незарегистрированного
+// адреса ошибка не возвращается, чтобы по ответу нельзя было проверить наличие учетной записи.
+func (s *AuthServiceImpl) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repos.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		log.Printf("[AuthService] Запрошен сброс пароля для незарегистрированного адреса")
+		return nil
+	}
+
+	token, err := s.issueAccountToken(ctx, user, domain.AccountTokenResetPassword, s.links.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Восстановление пароля",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Для вашей учетной записи запрошен сброс пароля. Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %s и может быть использована один раз. "+
+			"Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n",
+			user.Username, s.links.url("reset-password", token), formatTTL(s.links.resetTTL)),
+	})
+	if err != nil {
+		log.Printf("[AuthService] Ошибка отправки письма сброса пароля пользователю ID=%d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword задает новый пароль по токену из письма и завершает все сессии.
+// Переход по ссылке доказывает владение адресом, поэтому email считается подтвержденным.
+// Ссылка, отправленная на адрес, который пользователь с тех пор сменил, недействительна:
+// иначе владелец прежнего ящика мог бы захватить учетную запись.
+func (s *AuthServiceImpl) ResetPassword(ctx context.Context, token, newPassword string) error {
+	stored, err := s.consumeAccountToken(ctx, domain.AccountTokenResetPassword, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repos.GetByID(ctx, stored.UserID)
+	if err != nil || user.Email != stored.Email {
+		return ErrInvalidAccountToken
+	}
+
+	hashedPassword, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	if err := s.repos.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.repos.MarkEmailVerified(ctx, user.ID); err != nil {
+		return err
+	}
+
+	log.Printf("[AuthService] Пароль пользователя ID=%d сброшен, сессии завершаются", user.ID)
+	if err := s.repos.IncrementTokenVersion(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, user.ID)
+}
+
+// sendEmailVerification выпускает токен подтверждения и отправляет письмо со ссылкой
+func (s *AuthServiceImpl) sendEmailVerification(ctx context.Context, user *domain.User) error {
+	token, err := s.issueAccountToken(ctx, user, domain.AccountTokenVerifyEmail, s.links.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Подтверждение адреса электронной почты",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Чтобы подтвердить адрес и получить возможность бронировать туры, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %s. Если вы не регистрировались, просто проигнорируйте это письмо.\n",
+			user.Username, s.links.url("verify-email", token), formatTTL(s.links.verificationTTL)),
+	})
+}
+
+// issueAccountToken отзывает прежние токены пользователя с тем же назначением
+// и выпускает новый; возвращает токен для ссылки
+func (s *AuthServiceImpl) issueAccountToken(ctx context.Context, user *domain.User, purpose domain.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	if err := s.accountTokens.RevokeForUser(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, hash, err := auth.NewSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.accountTokens.Create(ctx, &domain.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeAccountToken проверяет токен из письма и отмечает его использованным
+func (s *AuthServiceImpl) consumeAccountToken(ctx context.Context, purpose domain.AccountTokenPurpose, token string) (*domain.AccountToken, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidAccountToken
+	}
+
+	stored, err := s.accountTokens.Consume(ctx, purpose, auth.HashSecretToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrAccountTokenInvalid) {
+			return nil, ErrInvalidAccountToken
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+// formatTTL записывает срок действия ссылки для текста письма
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d ч.", int(ttl/time.Hour))
+	}
+	return fmt.Sprintf("%d мин.", int(ttl/time.Minute))
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+)
+
+// memAccountUsers хранит одного пользователя
+type memAccountUsers struct {
+	repository.UserRepository
+	user domain.User
+}
+
+func (r *memAccountUsers) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	u := r.user
+	return &u, nil
+}
+
+func (r *memAccountUsers) Update(ctx context.Context, user *domain.User) error {
+	r.user = *user
+	return nil
+}
+
+func (r *memAccountUsers) MarkEmailVerified(ctx context.Context, id int64) error {
+	now := time.Now()
+	r.user.EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *memAccountUsers) IncrementTokenVersion(ctx context.Context, id int64) error {
+	r.user.TokenVersion++
+	return nil
+}
+
+// memAccountTokens хранит токены из писем по хешу
+type memAccountTokens struct {
+	repository.AccountTokenRepository
+	tokens map[string]*domain.AccountToken
+}
+
+func (r *memAccountTokens) Consume(ctx context.Context, purpose domain.AccountTokenPurpose, tokenHash string) (*domain.AccountToken, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.Purpose != purpose || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, repository.ErrAccountTokenInvalid
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	c := *t
+	return &c, nil
+}
+
+// newTestAccountService возвращает сервис с пользователем user@example.com и
+// токеном сброса пароля, отправленным на адрес sentTo
+func newTestAccountService(t *testing.T, sentTo string) (*AuthServiceImpl, *memAccountUsers, *memSessions, string) {
+	t.Helper()
+
+	hash, err := auth.HashPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memAccountUsers{user: domain.User{ID: 1, Email: "user@example.com", Password: hash}}
+
+	token, tokenHash, err := auth.NewSecretToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := &memAccountTokens{tokens: map[string]*domain.AccountToken{
+		tokenHash: {
+			UserID:    1,
+			Purpose:   domain.AccountTokenResetPassword,
+			Email:     sentTo,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}}
+
+	sessions := &memSessions{}
+	svc := &AuthServiceImpl{
+		repos:         users,
+		accountTokens: tokens,
+		refreshTokens: memSessionTokens{memSessions: sessions},
+	}
+	return svc, users, sessions, token
+}
+
+func TestResetPassword(t *testing.T) {
+	svc, users, sessions, token := newTestAccountService(t, "user@example.com")
+
+	if err := svc.ResetPassword(context.Background(), token, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if !auth.CheckPassword("new-password", users.user.Password) {
+		t.Error("пароль не изменен")
+	}
+	if users.user.EmailVerifiedAt == nil {
+		t.Error("email не подтвержден переходом по ссылке")
+	}
+	if users.user.TokenVersion != 1 || sessions.revoked != 1 {
+		t.Errorf("сессии не завершены: версия токенов %d, отзывов %d", users.user.TokenVersion, sessions.revoked)
+	}
+
+	// Ссылка одноразовая
+	if err := svc.ResetPassword(context.Background(), token, "another-password"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Errorf("повторное использование ссылки: %v", err)
+	}
+}
+
+// TestResetPasswordRejectsTokenForReplacedEmail проверяет, что ссылка, отправленная
+// на прежний адрес, не сбрасывает пароль после смены email
+func TestResetPasswordRejectsTokenForReplacedEmail(t *testing.T) {
+	svc, users, sessions, token := newTestAccountService(t, "old@example.com")
+
+	if err := svc.ResetPassword(context.Background(), token, "new-password"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Fatalf("сброс по ссылке на прежний адрес: %v, ожидалась ErrInvalidAccountToken", err)
+	}
+	if !auth.CheckPassword("old-password", users.user.Password) {
+		t.Error("пароль изменен по ссылке на прежний адрес")
+	}
+	if users.user.EmailVerifiedAt != nil || users.user.TokenVersion != 0 || sessions.revoked != 0 {
+		t.Error("учетная запись изменена по ссылке на прежний адрес")
+	}
+}
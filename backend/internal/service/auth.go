@@ -5,23 +5,43 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
 	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/mail"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
 )
 
 // AuthServiceImpl реализация сервиса аутентификации
 type AuthServiceImpl struct {
-	repos        repository.UserRepository
-	tokenManager auth.TokenManager
+	repos         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	accountTokens repository.AccountTokenRepository
+	roles         RoleService
+	twoFactor     TwoFactorService
+	tokenManager  auth.TokenManager
+	mailer        mail.Mailer
+	links         accountLinks
+	limiter       *ratelimit.Limiter
+	lockout       ratelimit.LockoutPolicy
 }
 
 // NewAuthService создает новый сервис аутентификации
-func NewAuthService(repos repository.UserRepository, tokenManager auth.TokenManager) AuthService {
+func NewAuthService(repos repository.UserRepository, refreshTokens repository.RefreshTokenRepository, accountTokens repository.AccountTokenRepository, roles RoleService, twoFactor TwoFactorService, tokenManager auth.TokenManager, mailer mail.Mailer, cfg config.AccountConfig, limiter *ratelimit.Limiter, lockout ratelimit.LockoutPolicy) AuthService {
 	return &AuthServiceImpl{
-		repos:        repos,
-		tokenManager: tokenManager,
+		repos:         repos,
+		refreshTokens: refreshTokens,
+		accountTokens: accountTokens,
+		roles:         roles,
+		twoFactor:     twoFactor,
+		tokenManager:  tokenManager,
+		mailer:        mailer,
+		links:         newAccountLinks(cfg),
+		limiter:       limiter,
+		lockout:       lockout,
 	}
 }
 
@@ -57,6 +77,12 @@ func (s *AuthServiceImpl) Register(ctx context.Context, username, email, passwor
 		fullName = strings.TrimSpace(firstName + " " + lastName)
 	}
 
+	defaultRole, err := s.roles.GetDefault(ctx)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка получения роли по умолчанию: %v", err)
+		return 0, err
+	}
+
 	user := &domain.User{
 		Username:  username,
 		Email:     email,
@@ -65,7 +91,7 @@ func (s *AuthServiceImpl) Register(ctx context.Context, username, email, passwor
 		LastName:  lastName,
 		FullName:  fullName,
 		Phone:     phone,
-		RoleID:    2, // Предполагается, что ID роли обычного пользователя = 2
+		RoleID:    defaultRole.ID,
 	}
 
 	id, err := s.repos.Create(ctx, user)
@@ -75,11 +101,20 @@ func (s *AuthServiceImpl) Register(ctx context.Context, username, email, passwor
 	}
 
 	log.Printf("[AuthService] Пользователь успешно создан: %s (ID: %d)", username, id)
+
+	// Письмо можно запросить повторно, поэтому ошибка доставки не отменяет регистрацию
+	user.ID = id
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		log.Printf("[AuthService] Ошибка отправки письма подтверждения пользователю ID=%d: %v", id, err)
+	}
+
 	return id, nil
 }
 
-// Login аутентифицирует пользователя и выдает токены
-func (s *AuthServiceImpl) Login(ctx context.Context, usernameOrEmail, password string) (string, string, error) {
+// Login аутентифицирует пользователя по паролю и выдает токены. Если у пользователя
+// включена двухфакторная аутентификация, вместо токенов выдается токен подтверждения,
+// который обменивается на токены в VerifyTwoFactor.
+func (s *AuthServiceImpl) Login(ctx context.Context, usernameOrEmail, password string) (*domain.LoginResult, error) {
 	log.Printf("[AuthService] Вызов Login для пользователя: %s", usernameOrEmail)
 
 	var user *domain.User
@@ -97,74 +132,104 @@ func (s *AuthServiceImpl) Login(ctx context.Context, usernameOrEmail, password s
 	}
 
 	if err != nil {
-		log.Printf("[AuthService] Ошибка поиска пользователя: %v", err)
-		return "", "", ErrInvalidCredentials
+		user = nil
 	}
 
-	if user == nil {
-		log.Printf("[AuthService] Пользователь не найден: %s", usernameOrEmail)
-		return "", "", ErrInvalidCredentials
+	// Неудачи учитываются и для несуществующих учетных записей,
+	// чтобы по ответам нельзя было определить, зарегистрирован ли пользователь
+	lockoutKey := "login:" + strings.ToLower(strings.TrimSpace(usernameOrEmail))
+	if user != nil {
+		lockoutKey = fmt.Sprintf("user:%d", user.ID)
+	}
+	if err := s.checkLockout(ctx, lockoutKey); err != nil {
+		return nil, err
 	}
 
-	// Безопасно выводим часть хеша пароля
-	passwordPreview := "пустой"
-	if len(user.Password) > 0 {
-		if len(user.Password) > 10 {
-			passwordPreview = user.Password[:10] + "..."
-		} else {
-			passwordPreview = user.Password
+	if user == nil || !auth.CheckPassword(password, user.Password) {
+		log.Printf("[AuthService] Неудачная попытка входа: %s", usernameOrEmail)
+		if err := s.limiter.RecordFailure(ctx, s.lockout, lockoutKey); err != nil {
+			log.Printf("[AuthService] Ошибка учета неудачной попытки входа: %v", err)
 		}
+		return nil, ErrInvalidCredentials
 	}
 
-	log.Printf("[AuthService] Пользователь найден: ID=%d, Username=%s, PasswordHash=%s",
-		user.ID, user.Username, passwordPreview)
-
-	// Проверяем пароль
-	if !auth.CheckPassword(password, user.Password) {
-		log.Printf("[AuthService] Неверный пароль для пользователя: %s (длина введенного пароля: %d)",
-			usernameOrEmail, len(password))
-		return "", "", ErrInvalidCredentials
+	if err := s.limiter.ResetFailures(ctx, lockoutKey); err != nil {
+		log.Printf("[AuthService] Ошибка сброса неудачных попыток входа: %v", err)
 	}
 
 	log.Printf("[AuthService] Успешная аутентификация пользователя: %s (ID: %d)", usernameOrEmail, user.ID)
 
 	// Определяем роль пользователя для токена
-	var roleName string
-	switch user.RoleID {
-	case 1:
-		roleName = "admin"
-	case 2:
-		roleName = "user"
-	case 3:
-		roleName = "support"
-	default:
-		roleName = "user"
-	}
-
-	log.Printf("[AuthService] Роль пользователя: %s", roleName)
-
-	// Генерируем access token
-	accessToken, err := s.tokenManager.GenerateAccessToken(user.ID, roleName)
+	role, err := s.roles.GetByID(ctx, user.RoleID)
 	if err != nil {
-		log.Printf("[AuthService] Ошибка генерации access token: %v", err)
+		log.Printf("[AuthService] Ошибка получения роли пользователя: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[AuthService] Роль пользователя: %s", role.Name)
+
+	twoFactorEnabled, err := s.twoFactor.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка проверки двухфакторной аутентификации: %v", err)
+		return nil, err
+	}
+	if twoFactorEnabled {
+		challengeToken, err := s.tokenManager.GenerateChallengeToken(user.ID, user.TokenVersion)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[AuthService] Пользователю ID=%d требуется код второго фактора", user.ID)
+		return &domain.LoginResult{ChallengeToken: challengeToken, TwoFactorRequired: true}, nil
+	}
+
+	accessToken, refreshToken, err := s.issueSession(ctx, user, role, false)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[AuthService] Токены успешно сгенерированы для пользователя: %s", usernameOrEmail)
+	return &domain.LoginResult{
+		AccessToken:            accessToken,
+		RefreshToken:           refreshToken,
+		TwoFactorSetupRequired: role.RequiresTwoFactor(),
+	}, nil
+}
+
+// VerifyTwoFactor обменивает токен подтверждения входа и код второго фактора
+// на access и refresh токены подтвержденной сессии
+func (s *AuthServiceImpl) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, error) {
+	claims, err := s.tokenManager.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return "", "", ErrInvalidTwoFactorChallenge
+	}
+
+	user, err := s.repos.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return "", "", ErrInvalidTwoFactorChallenge
+	}
+	// После смены пароля незавершенный вход недействителен
+	if claims.Version != user.TokenVersion {
+		return "", "", ErrInvalidTwoFactorChallenge
+	}
+
+	if err := s.twoFactor.Verify(ctx, user.ID, code); err != nil {
+		log.Printf("[AuthService] Неверный код второго фактора пользователя ID=%d: %v", user.ID, err)
 		return "", "", err
 	}
 
-	// Генерируем refresh token
-	refreshToken, err := s.tokenManager.GenerateRefreshToken(user.ID)
+	role, err := s.roles.GetByID(ctx, user.RoleID)
 	if err != nil {
-		log.Printf("[AuthService] Ошибка генерации refresh token: %v", err)
 		return "", "", err
 	}
 
-	log.Printf("[AuthService] Токены успешно сгенерированы для пользователя: %s", usernameOrEmail)
-	return accessToken, refreshToken, nil
+	log.Printf("[AuthService] Вход пользователя ID=%d подтвержден вторым фактором", user.ID)
+	return s.issueSession(ctx, user, role, true)
 }
 
 // ValidateToken проверяет токен и возвращает пользователя
 func (s *AuthServiceImpl) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
-	// Парсим токен
-	claims, err := s.tokenManager.ParseToken(token)
+	// Парсим токен; refresh токен не может использоваться для доступа к API
+	claims, err := s.tokenManager.ParseAccessToken(token)
 	if err != nil {
 		return nil, err
 	}
@@ -175,68 +240,193 @@ func (s *AuthServiceImpl) ValidateToken(ctx context.Context, token string) (*dom
 		return nil, err
 	}
 
+	// Токен, выданный до смены пароля или роли, больше недействителен.
+	// Права берутся из текущей роли, поэтому их изменение не требует перевыпуска токенов.
+	if claims.Version != user.TokenVersion {
+		log.Printf("[AuthService] Отозванный access токен пользователя ID=%d", user.ID)
+		return nil, ErrTokenRevoked
+	}
+
+	user.Role, err = s.roles.GetByID(ctx, user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorVerified = claims.MFA
+
 	return user, nil
 }
 
-// RefreshToken обновляет токены доступа
+// RefreshToken обновляет токены доступа с ротацией refresh токена.
+// Повторное предъявление уже замененного токена считается кражей
+// и приводит к отзыву всего семейства.
 func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
 	log.Println("[AuthService] Вызов RefreshToken")
 
 	// Парсим refresh токен
-	log.Println("[AuthService] Парсинг refresh токена")
-	claims, err := s.tokenManager.ParseToken(refreshToken)
+	claims, err := s.tokenManager.ParseRefreshToken(refreshToken)
 	if err != nil {
 		log.Printf("[AuthService] Ошибка парсинга токена: %v", err)
-		return "", "", err
+		return "", "", ErrInvalidRefreshToken
 	}
 
-	log.Printf("[AuthService] Токен успешно распарсен, userID: %d", claims.UserID)
+	// Сверяем токен с реестром
+	stored, err := s.refreshTokens.GetByID(ctx, claims.Id)
+	if err != nil {
+		log.Printf("[AuthService] Refresh токен не найден в реестре: %v", err)
+		return "", "", ErrInvalidRefreshToken
+	}
+	if stored.UserID != claims.UserID || stored.FamilyID != claims.Family {
+		log.Printf("[AuthService] Данные refresh токена не совпадают с реестром")
+		return "", "", ErrInvalidRefreshToken
+	}
+	if stored.RevokedAt != nil {
+		return "", "", s.handleRefreshReuse(ctx, stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return "", "", ErrInvalidRefreshToken
+	}
 
 	// Получаем пользователя из БД
-	log.Printf("[AuthService] Получение пользователя по ID: %d", claims.UserID)
 	user, err := s.repos.GetByID(ctx, claims.UserID)
 	if err != nil {
 		log.Printf("[AuthService] Ошибка получения пользователя: %v", err)
-		return "", "", err
+		return "", "", ErrInvalidRefreshToken
 	}
 
 	log.Printf("[AuthService] Пользователь найден: %s (ID: %d)", user.Username, user.ID)
 
-	// Определяем роль пользователя для токена
-	var roleName string
-	switch user.RoleID {
-	case 1:
-		roleName = "admin"
-	case 2:
-		roleName = "user"
-	case 3:
-		roleName = "support"
-	default:
-		roleName = "user"
-	}
-
-	log.Printf("[AuthService] Роль пользователя: %s", roleName)
-
-	// Генерируем новый access token
-	log.Println("[AuthService] Генерация нового access token")
-	newAccessToken, err := s.tokenManager.GenerateAccessToken(user.ID, roleName)
+	// Выпускаем замену в том же семействе
+	nextID, err := auth.NewTokenID()
 	if err != nil {
-		log.Printf("[AuthService] Ошибка генерации access token: %v", err)
 		return "", "", err
 	}
-
-	// Генерируем новый refresh token
-	log.Println("[AuthService] Генерация нового refresh token")
-	newRefreshToken, err := s.tokenManager.GenerateRefreshToken(user.ID)
+	newRefreshToken, expiresAt, err := s.tokenManager.GenerateRefreshToken(user.ID, nextID, stored.FamilyID, claims.MFA)
 	if err != nil {
 		log.Printf("[AuthService] Ошибка генерации refresh token: %v", err)
 		return "", "", err
 	}
 
+	rotated, err := s.refreshTokens.Rotate(ctx, stored.ID, &domain.RefreshToken{
+		ID:        nextID,
+		UserID:    user.ID,
+		FamilyID:  stored.FamilyID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		// Токен был отозван между чтением и ротацией — параллельное использование
+		return "", "", s.handleRefreshReuse(ctx, stored)
+	}
+
+	role, err := s.roles.GetByID(ctx, user.RoleID)
+	if err != nil {
+		return "", "", err
+	}
+
+	newAccessToken, err := s.tokenManager.GenerateAccessToken(user.ID, role.Name, user.TokenVersion, claims.MFA)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка генерации access token: %v", err)
+		return "", "", err
+	}
+
 	log.Println("[AuthService] Токены успешно обновлены")
 	return newAccessToken, newRefreshToken, nil
 }
 
+// Logout отзывает семейство, к которому относится refresh токен
+func (s *AuthServiceImpl) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.tokenManager.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	stored, err := s.refreshTokens.GetByID(ctx, claims.Id)
+	if err != nil || stored.UserID != claims.UserID {
+		return ErrInvalidRefreshToken
+	}
+
+	log.Printf("[AuthService] Завершение сессии пользователя ID=%d", stored.UserID)
+	return s.refreshTokens.RevokeFamily(ctx, stored.FamilyID)
+}
+
+// LogoutAll отзывает все refresh токены пользователя
+func (s *AuthServiceImpl) LogoutAll(ctx context.Context, userID int64) error {
+	log.Printf("[AuthService] Завершение всех сессий пользователя ID=%d", userID)
+	return s.refreshTokens.RevokeAllForUser(ctx, userID)
+}
+
+// issueSession выпускает access токен и refresh токен в новом семействе (новая сессия);
+// mfa - вход подтвержден вторым фактором
+func (s *AuthServiceImpl) issueSession(ctx context.Context, user *domain.User, role *domain.Role, mfa bool) (string, string, error) {
+	accessToken, err := s.tokenManager.GenerateAccessToken(user.ID, role.Name, user.TokenVersion, mfa)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка генерации access token: %v", err)
+		return "", "", err
+	}
+
+	familyID, err := auth.NewTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := s.issueRefreshToken(ctx, user.ID, familyID, mfa)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка генерации refresh token: %v", err)
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// issueRefreshToken выпускает refresh токен в семействе и регистрирует его
+func (s *AuthServiceImpl) issueRefreshToken(ctx context.Context, userID int64, familyID string, mfa bool) (string, error) {
+	tokenID, err := auth.NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	token, expiresAt, err := s.tokenManager.GenerateRefreshToken(userID, tokenID, familyID, mfa)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.refreshTokens.Create(ctx, &domain.RefreshToken{
+		ID:        tokenID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// checkLockout возвращает *ratelimit.LimitError, если попытку входа нужно отложить.
+// При недоступности хранилища окон вход не блокируется.
+func (s *AuthServiceImpl) checkLockout(ctx context.Context, key string) error {
+	result, err := s.limiter.CheckLockout(ctx, s.lockout, key)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка проверки блокировки входа: %v", err)
+		return nil
+	}
+	if !result.Allowed {
+		log.Printf("[AuthService] Попытка входа отложена на %s: %s", result.RetryAfter.Round(time.Second), key)
+	}
+	return result.Err()
+}
+
+// handleRefreshReuse отзывает семейство при повторном использовании токена
+func (s *AuthServiceImpl) handleRefreshReuse(ctx context.Context, stored *domain.RefreshToken) error {
+	log.Printf("[AuthService] Повторное использование refresh токена, отзыв семейства пользователя ID=%d", stored.UserID)
+	if err := s.refreshTokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		log.Printf("[AuthService] Ошибка отзыва семейства токенов: %v", err)
+	}
+	return ErrRefreshTokenReused
+}
+
 // ChangePassword изменяет пароль пользователя
 func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error {
 	// Получаем пользователя из БД
@@ -258,5 +448,13 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID int64, oldP
 
 	// Обновляем пароль в базе данных
 	user.Password = hashedPassword
-	return s.repos.Update(ctx, user)
+	if err := s.repos.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Старый пароль мог быть скомпрометирован: отзываем все токены
+	if err := s.repos.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, userID)
 }
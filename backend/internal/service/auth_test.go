@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+)
+
+// memRefreshTokens реестр refresh токенов в памяти
+type memRefreshTokens struct {
+	repository.RefreshTokenRepository
+	tokens map[string]*domain.RefreshToken
+}
+
+func (r *memRefreshTokens) Create(ctx context.Context, token *domain.RefreshToken) error {
+	t := *token
+	r.tokens[t.ID] = &t
+	return nil
+}
+
+func (r *memRefreshTokens) GetByID(ctx context.Context, id string) (*domain.RefreshToken, error) {
+	t, ok := r.tokens[id]
+	if !ok {
+		return nil, errors.New("refresh токен не найден")
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *memRefreshTokens) Rotate(ctx context.Context, oldID string, next *domain.RefreshToken) (bool, error) {
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedBy = &next.ID
+	return true, r.Create(ctx, next)
+}
+
+func (r *memRefreshTokens) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// memRoles отдает одну роль для любого ID
+type memRoles struct {
+	RoleService
+}
+
+func (memRoles) GetByID(ctx context.Context, id int64) (*domain.Role, error) {
+	return &domain.Role{ID: id, Name: "user"}, nil
+}
+
+// newTestSession возвращает сервис и refresh токен новой сессии пользователя 1
+func newTestSession(t *testing.T) (*AuthServiceImpl, *memRefreshTokens, string) {
+	t.Helper()
+
+	tokens := &memRefreshTokens{tokens: map[string]*domain.RefreshToken{}}
+	svc := &AuthServiceImpl{
+		repos:         &memAccountUsers{user: domain.User{ID: 1, Email: "user@example.com"}},
+		refreshTokens: tokens,
+		roles:         memRoles{},
+		tokenManager:  auth.NewJWTManager(config.JWTConfig{Secret: "test-secret", AccessExpiration: 15, RefreshExpiration: 24}),
+	}
+
+	_, refresh, err := svc.issueSession(context.Background(), &domain.User{ID: 1}, &domain.Role{Name: "user"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc, tokens, refresh
+}
+
+// refreshTokenID возвращает jti refresh токена
+func refreshTokenID(t *testing.T, svc *AuthServiceImpl, token string) string {
+	t.Helper()
+	claims, err := svc.tokenManager.ParseRefreshToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims.Id
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	svc, tokens, first := newTestSession(t)
+
+	access, second, err := svc.RefreshToken(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if access == "" {
+		t.Error("access токен не выпущен")
+	}
+
+	firstID, secondID := refreshTokenID(t, svc, first), refreshTokenID(t, svc, second)
+	if firstID == secondID {
+		t.Fatal("при ротации выпущен токен с тем же jti")
+	}
+	old, next := tokens.tokens[firstID], tokens.tokens[secondID]
+	if next == nil || next.FamilyID != old.FamilyID || next.RevokedAt != nil {
+		t.Fatalf("новый токен не записан в семейство: %+v", next)
+	}
+	if old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != secondID {
+		t.Errorf("замененный токен не отозван: %+v", old)
+	}
+}
+
+// TestRefreshTokenReuseRevokesFamily проверяет, что повторное предъявление
+// замененного токена отзывает и выпущенную ему замену
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	svc, _, first := newTestSession(t)
+
+	_, second, err := svc.RefreshToken(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := svc.RefreshToken(context.Background(), first); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("повторное использование: %v, ожидалась ErrRefreshTokenReused", err)
+	}
+	if _, _, err := svc.RefreshToken(context.Background(), second); err == nil {
+		t.Error("токен из отозванного семейства принят")
+	}
+}
+
+func TestRefreshTokenRejectedAfterLogout(t *testing.T) {
+	svc, _, first := newTestSession(t)
+
+	_, second, err := svc.RefreshToken(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Logout(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+
+	if access, refresh, err := svc.RefreshToken(context.Background(), second); err == nil || access != "" || refresh != "" {
+		t.Errorf("токен принят после выхода: %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/cache"
+)
+
+// Сроки жизни кэша каталога, если они не заданы в конфигурации
+const (
+	DefaultTourCacheTTL    = time.Minute
+	DefaultHotelCacheTTL   = 5 * time.Minute
+	DefaultCatalogCacheTTL = time.Hour
+	DefaultRoleCacheTTL    = time.Minute
+)
+
+// Группы ключей кэша
+const (
+	tourCacheNamespace    = "tours"
+	hotelCacheNamespace   = "hotels"
+	cityCacheNamespace    = "cities"
+	countryCacheNamespace = "countries"
+	roleCacheNamespace    = "roles"
+)
+
+// cacheKey собирает ключ кэша из частей
+func cacheKey(parts ...interface{}) string {
+	strs := make([]string, len(parts))
+	for i, part := range parts {
+		strs[i] = fmt.Sprint(part)
+	}
+	return strings.Join(strs, ":")
+}
+
+// cachedTourService кэширует карточку тура и его даты. Количество свободных
+// мест и модификаторы цены меняются заказами и фоновыми пересчетами в обход
+// сервиса, поэтому срок жизни записей туров короткий; цена заказа всегда
+// рассчитывается по данным из БД.
+type cachedTourService struct {
+	TourService
+	tours *cache.Namespace
+}
+
+// NewCachedTourService оборачивает сервис туров кэшем
+func NewCachedTourService(next TourService, tours *cache.Namespace) TourService {
+	return &cachedTourService{TourService: next, tours: tours}
+}
+
+// GetByID получает тур по ID из кэша
+func (s *cachedTourService) GetByID(ctx context.Context, id int64) (*domain.Tour, error) {
+	return cache.Fetch(ctx, s.tours, cacheKey(id), func() (*domain.Tour, error) {
+		return s.TourService.GetByID(ctx, id)
+	})
+}
+
+// GetTourDates возвращает даты тура из кэша
+func (s *cachedTourService) GetTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error) {
+	return cache.Fetch(ctx, s.tours, cacheKey(tourID, "dates"), func() ([]*domain.TourDate, error) {
+		return s.TourService.GetTourDates(ctx, tourID)
+	})
+}
+
+// Update обновляет тур и сбрасывает его кэш
+func (s *cachedTourService) Update(ctx context.Context, tour *domain.Tour) error {
+	if err := s.TourService.Update(ctx, tour); err != nil {
+		return err
+	}
+	s.invalidate(ctx, tour.ID)
+	return nil
+}
+
+// Delete удаляет тур и сбрасывает его кэш
+func (s *cachedTourService) Delete(ctx context.Context, id int64) error {
+	if err := s.TourService.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id)
+	return nil
+}
+
+// AddTourDate добавляет дату тура и сбрасывает кэш тура
+func (s *cachedTourService) AddTourDate(ctx context.Context, tourDate *domain.TourDate) (int64, error) {
+	id, err := s.TourService.AddTourDate(ctx, tourDate)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidate(ctx, tourDate.TourID)
+	return id, nil
+}
+
+// UpdateTourDate обновляет дату тура и сбрасывает кэш тура
+func (s *cachedTourService) UpdateTourDate(ctx context.Context, tourDate *domain.TourDate) error {
+	if err := s.TourService.UpdateTourDate(ctx, tourDate); err != nil {
+		return err
+	}
+	s.invalidate(ctx, tourDate.TourID)
+	return nil
+}
+
+// DeleteTourDate удаляет дату тура. Тур даты заранее неизвестен, поэтому сбрасывается кэш всех туров.
+func (s *cachedTourService) DeleteTourDate(ctx context.Context, id int64) error {
+	if err := s.TourService.DeleteTourDate(ctx, id); err != nil {
+		return err
+	}
+	s.tours.InvalidateAll(ctx)
+	return nil
+}
+
+// Restore восстанавливает тур и сбрасывает его кэш
+func (s *cachedTourService) Restore(ctx context.Context, id int64) error {
+	if err := s.TourService.Restore(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id)
+	return nil
+}
+
+// RestoreTourDate восстанавливает дату тура и сбрасывает кэш тура
+func (s *cachedTourService) RestoreTourDate(ctx context.Context, tourID, id int64) error {
+	if err := s.TourService.RestoreTourDate(ctx, tourID, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, tourID)
+	return nil
+}
+
+// invalidate сбрасывает карточку и даты тура
+func (s *cachedTourService) invalidate(ctx context.Context, tourID int64) {
+	s.tours.Invalidate(ctx, cacheKey(tourID), cacheKey(tourID, "dates"))
+}
+
+// cachedHotelService кэширует карточки отелей и списки номеров. Отели входят
+// в карточку тура, поэтому изменения отелей сбрасывают и кэш туров.
+type cachedHotelService struct {
+	HotelService
+	hotels *cache.Namespace
+	tours  *cache.Namespace
+}
+
+// NewCachedHotelService оборачивает сервис отелей кэшем
+func NewCachedHotelService(next HotelService, hotels, tours *cache.Namespace) HotelService {
+	return &cachedHotelService{HotelService: next, hotels: hotels, tours: tours}
+}
+
+// GetByID получает отель по ID из кэша
+func (s *cachedHotelService) GetByID(ctx context.Context, id int64) (*domain.Hotel, error) {
+	return cache.Fetch(ctx, s.hotels, cacheKey(id), func() (*domain.Hotel, error) {
+		return s.HotelService.GetByID(ctx, id)
+	})
+}
+
+// ListRoomsByHotelID возвращает номера отеля из кэша
+func (s *cachedHotelService) ListRoomsByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error) {
+	return cache.Fetch(ctx, s.hotels, cacheKey(hotelID, "rooms"), func() ([]*domain.Room, error) {
+		return s.HotelService.ListRoomsByHotelID(ctx, hotelID)
+	})
+}
+
+// Create создает отель и сбрасывает кэш туров, в карточках которых выводятся отели города
+func (s *cachedHotelService) Create(ctx context.Context, hotel *domain.Hotel) (int64, error) {
+	id, err := s.HotelService.Create(ctx, hotel)
+	if err != nil {
+		return 0, err
+	}
+	s.tours.InvalidateAll(ctx)
+	return id, nil
+}
+
+// Update обновляет отель и сбрасывает его кэш
+func (s *cachedHotelService) Update(ctx context.Context, hotel *domain.Hotel) error {
+	if err := s.HotelService.Update(ctx, hotel); err != nil {
+		return err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(hotel.ID))
+	s.tours.InvalidateAll(ctx)
+	return nil
+}
+
+// Delete удаляет отель и сбрасывает его кэш
+func (s *cachedHotelService) Delete(ctx context.Context, id int64) error {
+	if err := s.HotelService.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(id), cacheKey(id, "rooms"))
+	s.tours.InvalidateAll(ctx)
+	return nil
+}
+
+// Restore восстанавливает отель и сбрасывает его кэш
+func (s *cachedHotelService) Restore(ctx context.Context, id int64) error {
+	if err := s.HotelService.Restore(ctx, id); err != nil {
+		return err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(id), cacheKey(id, "rooms"))
+	s.tours.InvalidateAll(ctx)
+	return nil
+}
+
+// AddRoom добавляет номер и сбрасывает список номеров отеля
+func (s *cachedHotelService) AddRoom(ctx context.Context, room *domain.Room) (int64, error) {
+	id, err := s.HotelService.AddRoom(ctx, room)
+	if err != nil {
+		return 0, err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(room.HotelID, "rooms"))
+	return id, nil
+}
+
+// UpdateRoom обновляет номер и сбрасывает список номеров отеля
+func (s *cachedHotelService) UpdateRoom(ctx context.Context, room *domain.Room) error {
+	if err := s.HotelService.UpdateRoom(ctx, room); err != nil {
+		return err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(room.HotelID, "rooms"))
+	return nil
+}
+
+// DeleteRoom удаляет номер. Отель номера заранее неизвестен, поэтому сбрасывается кэш всех отелей.
+func (s *cachedHotelService) DeleteRoom(ctx context.Context, id int64) error {
+	if err := s.HotelService.DeleteRoom(ctx, id); err != nil {
+		return err
+	}
+	s.hotels.InvalidateAll(ctx)
+	return nil
+}
+
+// RestoreRoom восстанавливает номер и сбрасывает список номеров отеля
+func (s *cachedHotelService) RestoreRoom(ctx context.Context, hotelID, id int64) error {
+	if err := s.HotelService.RestoreRoom(ctx, hotelID, id); err != nil {
+		return err
+	}
+	s.hotels.Invalidate(ctx, cacheKey(hotelID, "rooms"))
+	return nil
+}
+
+// cachedCityService кэширует справочник городов. Справочник меняется только
+// миграциями, поэтому записи живут до истечения срока.
+type cachedCityService struct {
+	CityService
+	cities *cache.Namespace
+}
+
+// NewCachedCityService оборачивает сервис городов кэшем
+func NewCachedCityService(next CityService, cities *cache.Namespace) CityService {
+	return &cachedCityService{CityService: next, cities: cities}
+}
+
+// GetByID получает город по ID из кэша
+func (s *cachedCityService) GetByID(ctx context.Context, id int64) (*domain.City, error) {
+	return cache.Fetch(ctx, s.cities, cacheKey(id), func() (*domain.City, error) {
+		return s.CityService.GetByID(ctx, id)
+	})
+}
+
+// cityPage страница списка городов в кэше
+type cityPage struct {
+	Cities []*domain.City `json:"cities"`
+	Total  int            `json:"total"`
+}
+
+// List возвращает страницу списка городов из кэша
+func (s *cachedCityService) List(ctx context.Context, page, size int) ([]*domain.City, int, error) {
+	result, err := cache.Fetch(ctx, s.cities, cacheKey("page", page, size), func() (cityPage, error) {
+		cities, total, err := s.CityService.List(ctx, page, size)
+		return cityPage{Cities: cities, Total: total}, err
+	})
+	return result.Cities, result.Total, err
+}
+
+// ListByCountryID возвращает города страны из кэша
+func (s *cachedCityService) ListByCountryID(ctx context.Context, countryID int64) ([]*domain.City, error) {
+	return cache.Fetch(ctx, s.cities, cacheKey("country", countryID), func() ([]*domain.City, error) {
+		return s.CityService.ListByCountryID(ctx, countryID)
+	})
+}
+
+// cachedCountryService кэширует справочник стран
+type cachedCountryService struct {
+	CountryService
+	countries *cache.Namespace
+}
+
+// NewCachedCountryService оборачивает сервис стран кэшем
+func NewCachedCountryService(next CountryService, countries *cache.Namespace) CountryService {
+	return &cachedCountryService{CountryService: next, countries: countries}
+}
+
+// GetByID получает страну по ID из кэша
+func (s *cachedCountryService) GetByID(ctx context.Context, id int64) (*domain.Country, error) {
+	return cache.Fetch(ctx, s.countries, cacheKey(id), func() (*domain.Country, error) {
+		return s.CountryService.GetByID(ctx, id)
+	})
+}
+
+// countryPage страница списка стран в кэше
+type countryPage struct {
+	Countries []*domain.Country `json:"countries"`
+	Total     int               `json:"total"`
+}
+
+// List возвращает страницу списка стран из кэша
+func (s *cachedCountryService) List(ctx context.Context, page, size int) ([]*domain.Country, int, error) {
+	result, err := cache.Fetch(ctx, s.countries, cacheKey("page", page, size), func() (countryPage, error) {
+		countries, total, err := s.CountryService.List(ctx, page, size)
+		return countryPage{Countries: countries, Total: total}, err
+	})
+	return result.Countries, result.Total, err
+}
+
+// cachedRoleService кэширует роли с правами, которые читаются при каждом
+// запросе с проверкой доступа. Изменения ролей сбрасывают кэш сразу,
+// на других экземплярах API без Redis они вступают в силу по сроку жизни.
+type cachedRoleService struct {
+	RoleService
+	roles *cache.Namespace
+}
+
+// NewCachedRoleService оборачивает сервис ролей кэшем
+func NewCachedRoleService(next RoleService, roles *cache.Namespace) RoleService {
+	return &cachedRoleService{RoleService: next, roles: roles}
+}
+
+// GetByID получает роль с правами из кэша
+func (s *cachedRoleService) GetByID(ctx context.Context, id int64) (*domain.Role, error) {
+	return cache.Fetch(ctx, s.roles, cacheKey(id), func() (*domain.Role, error) {
+		return s.RoleService.GetByID(ctx, id)
+	})
+}
+
+// Update обновляет роль и сбрасывает ее кэш
+func (s *cachedRoleService) Update(ctx context.Context, role *domain.Role) error {
+	if err := s.RoleService.Update(ctx, role); err != nil {
+		return err
+	}
+	s.roles.Invalidate(ctx, cacheKey(role.ID))
+	return nil
+}
+
+// Delete удаляет роль и сбрасывает ее кэш
+func (s *cachedRoleService) Delete(ctx context.Context, id int64) error {
+	if err := s.RoleService.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.roles.Invalidate(ctx, cacheKey(id))
+	return nil
+}
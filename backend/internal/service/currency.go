@@ -0,0 +1,49 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// DefaultCurrency базовая валюта, если она не задана в конфигурации
+const DefaultCurrency = "RUB"
+
+// CurrencyServiceImpl реализация сервиса пересчета цен между валютами
+type CurrencyServiceImpl struct {
+	converter *money.Converter
+}
+
+// NewCurrencyService создает новый сервис валют
+func NewCurrencyService(converter *money.Converter) CurrencyService {
+	return &CurrencyServiceImpl{converter: converter}
+}
+
+// Supports проверяет, что валюта есть в таблице курсов
+func (s *CurrencyServiceImpl) Supports(currency string) bool {
+	return s.converter.Supports(currency)
+}
+
+// Base возвращает базовую валюту
+func (s *CurrencyServiceImpl) Base() string {
+	return s.converter.Base()
+}
+
+// ConvertTour пересчитывает цену тура в валюту currency
+func (s *CurrencyServiceImpl) ConvertTour(tour *domain.Tour, currency string) error {
+	price, err := s.converter.Convert(money.New(tour.BasePrice, tour.Currency), currency)
+	if err != nil {
+		return err
+	}
+
+	tour.BasePrice = price.Amount
+	tour.Currency = price.Currency
+	return nil
+}
+
+// ConvertToAll пересчитывает сумму во все поддерживаемые валюты; используется
+// для фильтрации по цене среди туров в разных валютах
+func (s *CurrencyServiceImpl) ConvertToAll(amount money.Amount, currency string) (map[string]money.Amount, error) {
+	return s.converter.ConvertToAll(money.New(amount, strings.ToUpper(currency)))
+}
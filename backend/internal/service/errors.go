@@ -5,5 +5,90 @@ import "errors"
 // ErrInvalidCredentials ошибка неверных учетных данных
 var ErrInvalidCredentials = errors.New("invalid credentials")
 
+// ErrInvalidRefreshToken refresh токен не найден в реестре, отозван или истек
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// ErrRefreshTokenReused повторное использование уже замененного refresh токена
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// ErrTokenRevoked access токен выдан до смены пароля или роли пользователя
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// ErrInvalidOrderTransition переход между статусами заказа не разрешен
+var ErrInvalidOrderTransition = errors.New("invalid order status transition")
+
+// ErrOrderStatusNotAllowed переход разрешен автоматом, но не из текущего статуса
+// заказа для этого инициатора (например, клиент не может отменить оплаченный заказ)
+var ErrOrderStatusNotAllowed = errors.New("order status does not allow this change")
+
+// ErrOrderHoldExpired срок удержания мест по заказу истек
+var ErrOrderHoldExpired = errors.New("order hold has expired")
+
+// ErrQuoteMismatch расчет стоимости выдан на другие параметры заказа или цена изменилась
+var ErrQuoteMismatch = errors.New("price quote does not match the order")
+
+// ErrOrderNotPayable заказ находится в статусе, в котором его нельзя оплатить
+var ErrOrderNotPayable = errors.New("order cannot be paid in its current status")
+
+// ErrOrderAlreadyPaid по заказу уже есть успешный платеж
+var ErrOrderAlreadyPaid = errors.New("order is already paid")
+
+// ErrPaymentInProgress платеж по заказу уже авторизован и обрабатывается
+var ErrPaymentInProgress = errors.New("payment for the order is being processed")
+
+// ErrPaymentSimulationUnsupported текущий платежный провайдер не поддерживает имитацию оплаты
+var ErrPaymentSimulationUnsupported = errors.New("payment simulation is not supported by the provider")
+
+// ErrInvalidPromotion параметры промоакции заданы некорректно
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+// ErrInvalidTourSchedule параметры правила расписания тура заданы некорректно
+var ErrInvalidTourSchedule = errors.New("invalid tour schedule")
+
+// ErrInvalidPricingRule параметры правила ценообразования заданы некорректно
+var ErrInvalidPricingRule = errors.New("invalid pricing rule")
+
+// ErrInvalidSearchQuery поисковый запрос пуст или задан некорректно
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// ErrPromoCodeInvalid промокод не найден, отключен или срок его действия истек
+var ErrPromoCodeInvalid = errors.New("promo code is invalid or has expired")
+
+// ErrPromoCodeNotApplicable условия промокода не выполняются для заказа
+var ErrPromoCodeNotApplicable = errors.New("promo code does not apply to this order")
+
+// ErrPromotionLimitReached исчерпан общий лимит применений промоакции или лимит для пользователя
+var ErrPromotionLimitReached = errors.New("promotion usage limit reached")
+
+// ErrInvalidRole название или права роли заданы некорректно
+var ErrInvalidRole = errors.New("invalid role")
+
+// ErrSystemRole системную роль нельзя переименовать или удалить
+var ErrSystemRole = errors.New("system role cannot be renamed or deleted")
+
+// ErrInvalidAccountToken токен из письма не найден, уже использован или истек
+var ErrInvalidAccountToken = errors.New("invalid or expired token")
+
+// ErrEmailAlreadyVerified email пользователя уже подтвержден
+var ErrEmailAlreadyVerified = errors.New("email is already verified")
+
+// ErrEmailNotVerified действие доступно только после подтверждения email
+var ErrEmailNotVerified = errors.New("email is not verified")
+
+// ErrInvalidTwoFactorCode код из приложения или код восстановления неверен либо уже использован
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// ErrInvalidTwoFactorChallenge токен подтверждения входа недействителен или истек
+var ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor challenge")
+
+// ErrTwoFactorAlreadyEnabled двухфакторная аутентификация уже включена
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// ErrTwoFactorNotEnabled двухфакторная аутентификация не включена или подключение не начато
+var ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+
+// ErrTwoFactorRequired роль пользователя требует двухфакторной аутентификации, ее нельзя отключить
+var ErrTwoFactorRequired = errors.New("two-factor authentication is required for this role")
+
 // TODO: Добавить другие специфичные ошибки сервиса
 // например, ErrNotFound, ErrValidation, ErrForbidden и т.д.
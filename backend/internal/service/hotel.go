@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
 )
 
 // HotelServiceImpl реализация сервиса для работы с отелями
@@ -41,25 +44,34 @@ func (s *HotelServiceImpl) Delete(ctx context.Context, id int64) error {
 	return s.hotelRepo.Delete(ctx, id)
 }
 
+// Restore восстанавливает удаленный отель вместе с номерами, удаленными с ним
+func (s *HotelServiceImpl) Restore(ctx context.Context, id int64) error {
+	return s.hotelRepo.Restore(ctx, id)
+}
+
 // List возвращает список отелей с фильтрацией
-func (s *HotelServiceImpl) List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Hotel, int, error) {
-	hotels, err := s.hotelRepo.List(ctx, filters, page, size)
-	if err != nil {
-		return nil, 0, err
+func (s *HotelServiceImpl) List(ctx context.Context, filter *domain.HotelFilter) ([]*domain.Hotel, *domain.PageInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	// Предполагаем, что репозиторий может вернуть общее количество отелей
-	// Если нет, можно добавить отдельный метод для получения количества
-	totalCount, err := s.hotelRepo.Count(ctx, filters)
+	hotels, err := s.hotelRepo.List(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	return hotels, totalCount, nil
+	return pageResult(filter.Page, hotels, func(h *domain.Hotel) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: h.CreatedAt, ID: h.ID}
+	}, func() (int, error) {
+		return s.hotelRepo.Count(ctx, filter)
+	})
 }
 
 // AddRoom добавляет новый номер в отель
 func (s *HotelServiceImpl) AddRoom(ctx context.Context, room *domain.Room) (int64, error) {
+	if room.Units <= 0 {
+		room.Units = 1
+	}
 	return s.roomRepo.Create(ctx, room)
 }
 
@@ -70,6 +82,9 @@ func (s *HotelServiceImpl) GetRoomByID(ctx context.Context, id int64) (*domain.R
 
 // UpdateRoom обновляет данные номера
 func (s *HotelServiceImpl) UpdateRoom(ctx context.Context, room *domain.Room) error {
+	if room.Units <= 0 {
+		room.Units = 1
+	}
 	return s.roomRepo.Update(ctx, room)
 }
 
@@ -78,7 +93,34 @@ func (s *HotelServiceImpl) DeleteRoom(ctx context.Context, id int64) error {
 	return s.roomRepo.Delete(ctx, id)
 }
 
+// RestoreRoom восстанавливает удаленный номер отеля
+func (s *HotelServiceImpl) RestoreRoom(ctx context.Context, hotelID, id int64) error {
+	return s.roomRepo.Restore(ctx, hotelID, id)
+}
+
+// ListDeletedRooms возвращает удаленные номера отеля
+func (s *HotelServiceImpl) ListDeletedRooms(ctx context.Context, hotelID int64) ([]*domain.Room, error) {
+	return s.roomRepo.ListDeletedByHotelID(ctx, hotelID)
+}
+
 // ListRoomsByHotelID возвращает список номеров в отеле
 func (s *HotelServiceImpl) ListRoomsByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error) {
 	return s.roomRepo.ListByHotelID(ctx, hotelID)
 }
+
+// ListAvailableRooms возвращает номера отеля, свободные на все ночи
+// с даты заезда from до даты выезда to
+func (s *HotelServiceImpl) ListAvailableRooms(ctx context.Context, hotelID int64, from, to time.Time) ([]*domain.Room, error) {
+	if !to.After(from) {
+		return nil, errors.New("дата выезда должна быть позже даты заезда")
+	}
+	return s.roomRepo.ListAvailableByHotelID(ctx, hotelID, from, to)
+}
+
+// GetRoomNights возвращает занятость номера по ночам периода
+func (s *HotelServiceImpl) GetRoomNights(ctx context.Context, roomID int64, from, to time.Time) ([]*domain.RoomNight, error) {
+	if !to.After(from) {
+		return nil, errors.New("дата выезда должна быть позже даты заезда")
+	}
+	return s.roomRepo.ListNights(ctx, roomID, from, to)
+}
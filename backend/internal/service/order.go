@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pricing"
 )
 
 // OrderServiceImpl реализация сервиса для работы с заказами
@@ -15,22 +20,88 @@ type OrderServiceImpl struct {
 	tourRepo  repository.TourRepository
 	userRepo  repository.UserRepository
 	roomRepo  repository.RoomRepository
+	promoRepo repository.PromotionRepository
+	quotes    pricing.QuoteSigner
+	currency  *money.Converter
+	holdTTL   time.Duration
+	refunder  OrderRefunder
+	notifier  OrderNotifier
 }
 
+// DefaultOrderHoldTTL время удержания мест, если оно не задано в конфигурации
+const DefaultOrderHoldTTL = 30 * time.Minute
+
+// DefaultQuoteTTL срок действия расчета стоимости, если он не задан в конфигурации
+const DefaultQuoteTTL = 15 * time.Minute
+
+// expireHoldsBatchSize максимальное число заказов, снимаемых за один проход
+const expireHoldsBatchSize = 100
+
 // NewOrderService создает новый сервис для работы с заказами
-func NewOrderService(orderRepo repository.OrderRepository, tourRepo repository.TourRepository, userRepo repository.UserRepository, roomRepo repository.RoomRepository) OrderService {
+func NewOrderService(orderRepo repository.OrderRepository, tourRepo repository.TourRepository, userRepo repository.UserRepository, roomRepo repository.RoomRepository, promoRepo repository.PromotionRepository, quotes pricing.QuoteSigner, currency *money.Converter, holdTTL time.Duration, refunder OrderRefunder) OrderService {
+	if holdTTL <= 0 {
+		holdTTL = DefaultOrderHoldTTL
+	}
+	if refunder == nil {
+		refunder = manualRefunder{}
+	}
+
 	return &OrderServiceImpl{
 		orderRepo: orderRepo,
 		tourRepo:  tourRepo,
 		userRepo:  userRepo,
 		roomRepo:  roomRepo,
+		promoRepo: promoRepo,
+		quotes:    quotes,
+		currency:  currency,
+		holdTTL:   holdTTL,
+		refunder:  refunder,
+		notifier:  logNotifier{},
 	}
 }
 
-// Create создает новый заказ
-func (s *OrderServiceImpl) Create(ctx context.Context, userID, tourID, tourDateID int64, roomID *int64, peopleCount int, totalPrice float64) (int64, error) {
+// Quote рассчитывает стоимость заказа с учетом скидок и возвращает подписанный
+// расчет, который необходимо передать при оформлении заказа
+func (s *OrderServiceImpl) Quote(ctx context.Context, req *domain.OrderRequest) (*domain.PriceQuote, error) {
+	req.PromoCode = normalizePromoCode(req.PromoCode)
+	if _, err := s.checkOrderRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.CalculatePrice(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при расчете стоимости: %w", err)
+	}
+
+	token, expiresAt, err := s.quotes.Sign(pricing.QuoteClaims{
+		UserID:        req.UserID,
+		TourID:        req.TourID,
+		TourDateID:    req.TourDateID,
+		RoomID:        req.RoomID,
+		PeopleCount:   req.PeopleCount,
+		ChildrenCount: req.ChildrenCount,
+		PromoCode:     req.PromoCode,
+		Total:         breakdown.Total,
+		Currency:      breakdown.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PriceQuote{
+		Token:      token,
+		ExpiresAt:  expiresAt,
+		TotalPrice: breakdown.Total,
+		Currency:   breakdown.Currency,
+		Breakdown:  breakdown,
+	}, nil
+}
+
+// Create создает новый заказ по подписанному расчету стоимости quoteToken.
+// Стоимость пересчитывается на сервере и должна совпадать с расчетом.
+func (s *OrderServiceImpl) Create(ctx context.Context, req *domain.OrderRequest, quoteToken string) (int64, error) {
 	// Проверка существования пользователя
-	user, err := s.userRepo.GetByID(ctx, userID)
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при проверке пользователя: %w", err)
 	}
@@ -38,75 +109,48 @@ func (s *OrderServiceImpl) Create(ctx context.Context, userID, tourID, tourDateI
 		return 0, errors.New("пользователь не найден")
 	}
 
-	// Проверка существования тура
-	tour, err := s.tourRepo.GetByID(ctx, tourID)
+	// Проверка расчета стоимости: он должен быть выдан этому пользователю
+	// на те же параметры заказа
+	req.PromoCode = normalizePromoCode(req.PromoCode)
+	quote, err := s.quotes.Parse(quoteToken)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка при проверке тура: %w", err)
+		return 0, err
 	}
-	if tour == nil {
-		return 0, errors.New("тур не найден")
+	if quote.UserID != req.UserID || quote.TourID != req.TourID || quote.TourDateID != req.TourDateID ||
+		quote.PeopleCount != req.PeopleCount || quote.ChildrenCount != req.ChildrenCount ||
+		quote.PromoCode != req.PromoCode || !sameRoom(quote.RoomID, req.RoomID) {
+		return 0, ErrQuoteMismatch
 	}
 
-	// Получение даты тура
-	var tourDate *domain.TourDate
-	tourDates, err := s.tourRepo.GetTourDates(ctx, tourID)
+	tourDate, err := s.checkOrderRequest(ctx, req)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка при получении дат тура: %w", err)
-	}
-
-	// Ищем нужную дату среди доступных дат тура
-	for _, td := range tourDates {
-		if td.ID == tourDateID {
-			tourDate = td
-			break
-		}
-	}
-
-	if tourDate == nil {
-		return 0, errors.New("выбранная дата тура не найдена")
-	}
-
-	// Проверка доступности мест
-	if tourDate.Availability < peopleCount {
-		return 0, fmt.Errorf("недостаточно свободных мест на выбранную дату (доступно: %d, запрошено: %d)", tourDate.Availability, peopleCount)
+		return 0, err
 	}
 
-	// Если указан ID номера, проверяем его существование
-	if roomID != nil {
-		room, err := s.roomRepo.GetByID(ctx, *roomID)
-		if err != nil {
-			return 0, fmt.Errorf("ошибка при проверке номера: %w", err)
-		}
-		if room == nil {
-			return 0, errors.New("выбранный номер не найден")
-		}
-
-		// Проверка вместимости номера
-		if room.Beds < peopleCount {
-			return 0, fmt.Errorf("выбранный номер вмещает максимум %d человек", room.Beds)
-		}
+	// Стоимость пересчитывается: цены и скидки могли измениться после выдачи расчета
+	breakdown, err := s.CalculatePrice(ctx, req)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при расчете стоимости: %w", err)
 	}
-
-	// Используем переданную цену, если она указана, иначе рассчитываем
-	price := totalPrice
-	if price <= 0 {
-		// Подсчет общей стоимости
-		calculatedPrice, err := s.CalculatePrice(ctx, tourID, tourDateID, roomID, peopleCount)
-		if err != nil {
-			return 0, fmt.Errorf("ошибка при расчете стоимости: %w", err)
-		}
-		price = calculatedPrice
+	if breakdown.Total != quote.Total || breakdown.Currency != quote.Currency {
+		return 0, fmt.Errorf("%w: стоимость изменилась с %s %s на %s %s", ErrQuoteMismatch,
+			quote.Total, quote.Currency, breakdown.Total, breakdown.Currency)
 	}
 
-	// Создаем заказ
+	// Создаем заказ; места удерживаются за ним до истечения срока оплаты
+	expiresAt := time.Now().Add(s.holdTTL)
 	order := &domain.Order{
-		UserID:      userID,
-		TourID:      tourID,
-		TourDateID:  tourDateID,
-		RoomID:      roomID,
-		PeopleCount: peopleCount,
-		TotalPrice:  price,
-		Status:      string(domain.OrderStatusPending),
+		UserID:         req.UserID,
+		TourID:         req.TourID,
+		TourDateID:     req.TourDateID,
+		RoomID:         req.RoomID,
+		PeopleCount:    req.PeopleCount,
+		ChildrenCount:  req.ChildrenCount,
+		TotalPrice:     breakdown.Total,
+		Currency:       breakdown.Currency,
+		Status:         string(domain.OrderStatusPending),
+		ExpiresAt:      &expiresAt,
+		PriceBreakdown: breakdown,
 	}
 
 	// Начинаем транзакцию
@@ -120,17 +164,44 @@ func (s *OrderServiceImpl) Create(ctx context.Context, userID, tourID, tourDateI
 		}
 	}()
 
+	// Резервируем места; при нехватке мест обновление не затрагивает строку
+	if err = s.tourRepo.ReserveSeatsTx(ctx, tx, req.TourDateID, req.PeopleCount); err != nil {
+		if errors.Is(err, repository.ErrInsufficientAvailability) || errors.Is(err, repository.ErrTourNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("ошибка при резервировании мест: %w", err)
+	}
+
+	// Бронируем номер на все ночи тура
+	if req.RoomID != nil {
+		if err = s.roomRepo.ReserveNightsTx(ctx, tx, *req.RoomID, tourDate.StartDate, tourDate.EndDate); err != nil {
+			if errors.Is(err, repository.ErrRoomUnavailable) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("ошибка при бронировании номера: %w", err)
+		}
+	}
+
 	// Создаем заказ
 	orderID, err := s.orderRepo.CreateTx(ctx, tx, order)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании заказа: %w", err)
 	}
 
-	// Уменьшаем доступность мест для выбранной даты тура
-	updatedAvailability := tourDate.Availability - peopleCount
-	err = s.tourRepo.UpdateTourDateAvailabilityTx(ctx, tx, tourDateID, updatedAvailability)
+	// Фиксируем примененные скидки с проверкой лимитов под блокировкой промоакций
+	if err = s.redeemDiscountsTx(ctx, tx, orderID, req.UserID, breakdown); err != nil {
+		return 0, err
+	}
+
+	// Фиксируем начальный статус в истории
+	err = s.orderRepo.AddStatusHistoryTx(ctx, tx, &domain.OrderStatusChange{
+		OrderID:   orderID,
+		ToStatus:  order.Status,
+		ChangedBy: &req.UserID,
+		Comment:   "заказ создан",
+	})
 	if err != nil {
-		return 0, fmt.Errorf("ошибка при обновлении доступности мест: %w", err)
+		return 0, err
 	}
 
 	// Коммитим транзакцию
@@ -162,142 +233,526 @@ func (s *OrderServiceImpl) ListByUserID(ctx context.Context, userID int64) ([]*d
 }
 
 // List возвращает список заказов с фильтрацией
-func (s *OrderServiceImpl) List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Order, int, error) {
-	orders, err := s.orderRepo.List(ctx, filters, page, size)
-	if err != nil {
-		return nil, 0, err
+func (s *OrderServiceImpl) List(ctx context.Context, filter *domain.OrderFilter) ([]*domain.Order, *domain.PageInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	totalCount, err := s.orderRepo.Count(ctx, filters)
+	orders, err := s.orderRepo.List(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	return orders, totalCount, nil
+	return pageResult(filter.Page, orders, func(o *domain.Order) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+	}, func() (int, error) {
+		return s.orderRepo.Count(ctx, filter)
+	})
 }
 
-// UpdateStatus обновляет статус заказа
-func (s *OrderServiceImpl) UpdateStatus(ctx context.Context, id int64, status string) error {
+// UpdateStatus переводит заказ в новый статус согласно конечному автомату.
+// changedBy - пользователь, инициировавший изменение (nil для системных изменений).
+func (s *OrderServiceImpl) UpdateStatus(ctx context.Context, id int64, status string, changedBy *int64, comment string) error {
 	// Валидация статуса
-	if status != string(domain.OrderStatusPending) &&
-		status != string(domain.OrderStatusConfirmed) &&
-		status != string(domain.OrderStatusPaid) &&
-		status != string(domain.OrderStatusCancelled) &&
-		status != string(domain.OrderStatusCompleted) {
+	if !domain.OrderStatus(status).IsValid() {
+		return errors.New("недопустимый статус заказа")
+	}
+
+	_, err := s.transition(ctx, id, domain.OrderStatus(status), changedBy, comment, transitionOptions{})
+	return err
+}
+
+// UpdateStatusFrom переводит заказ в новый статус, только если текущий статус
+// входит в from. Статус проверяется под блокировкой заказа, поэтому параллельная
+// оплата не может проскочить между проверкой и сменой статуса. Иначе
+// возвращается ErrOrderStatusNotAllowed.
+func (s *OrderServiceImpl) UpdateStatusFrom(ctx context.Context, id int64, status string, from []domain.OrderStatus, changedBy *int64, comment string) error {
+	if !domain.OrderStatus(status).IsValid() {
 		return errors.New("недопустимый статус заказа")
 	}
 
-	// Получаем текущий заказ
-	order, err := s.orderRepo.GetByID(ctx, id)
+	_, err := s.transition(ctx, id, domain.OrderStatus(status), changedBy, comment, transitionOptions{from: from})
+	return err
+}
+
+// MarkPaid переводит заказ в статус paid после успешной оплаты платежом paymentID
+// и запоминает этот платеж в той же транзакции. В отличие от UpdateStatus
+// повторный перевод не считается успехом: второй платеж по уже оплаченному
+// заказу должен быть возвращен.
+func (s *OrderServiceImpl) MarkPaid(ctx context.Context, id, paymentID int64, comment string) error {
+	setPayment := func(tx repository.Tx, order *domain.Order) error {
+		return s.orderRepo.SetPaymentTx(ctx, tx, order.ID, paymentID)
+	}
+	changed, err := s.transition(ctx, id, domain.OrderStatusPaid, nil, comment, transitionOptions{apply: setPayment})
 	if err != nil {
-		return fmt.Errorf("ошибка при получении заказа: %w", err)
+		return err
 	}
-	if order == nil {
-		return errors.New("заказ не найден")
+	if !changed {
+		return ErrOrderAlreadyPaid
 	}
+	return nil
+}
 
-	// Если статус не меняется - возвращаем успех
-	if order.Status == status {
-		return nil
+// GetStatusHistory возвращает историю изменения статуса заказа
+func (s *OrderServiceImpl) GetStatusHistory(ctx context.Context, orderID int64) ([]*domain.OrderStatusChange, error) {
+	if _, err := s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, err
 	}
 
-	// Проверяем, можно ли изменить статус
-	if (order.Status == string(domain.OrderStatusCancelled) || order.Status == string(domain.OrderStatusCompleted)) &&
-		(status != string(domain.OrderStatusCancelled) && status != string(domain.OrderStatusCompleted)) {
-		return errors.New("невозможно изменить статус завершенного или отмененного заказа")
+	return s.orderRepo.ListStatusHistory(ctx, orderID)
+}
+
+// ExpireHolds отменяет ожидающие заказы с истекшим сроком удержания и
+// возвращает места в даты туров. Возвращает количество отмененных заказов.
+func (s *OrderServiceImpl) ExpireHolds(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	ids, err := s.orderRepo.ListExpiredPending(ctx, now, expireHoldsBatchSize)
+	if err != nil {
+		return 0, err
 	}
 
-	// Если заказ отменяется, увеличиваем доступность мест для выбранной даты тура
-	if status == string(domain.OrderStatusCancelled) &&
-		(order.Status == string(domain.OrderStatusPending) || order.Status == string(domain.OrderStatusConfirmed)) {
+	// Заказ мог быть оплачен после выборки, поэтому условие истечения
+	// повторно проверяется под блокировкой строки
+	expired := func(order *domain.Order) bool {
+		return order.Status == string(domain.OrderStatusPending) &&
+			order.ExpiresAt != nil && !order.ExpiresAt.After(now)
+	}
 
-		// Начинаем транзакцию
-		tx, err := s.orderRepo.BeginTx(ctx)
+	count := 0
+	for _, id := range ids {
+		changed, err := s.transition(ctx, id, domain.OrderStatusCancelled, nil, "истек срок удержания мест", transitionOptions{skip: expired})
 		if err != nil {
-			return fmt.Errorf("ошибка при начале транзакции: %w", err)
+			log.Printf("[OrderService] Не удалось снять удержание заказа %d: %v", id, err)
+			continue
 		}
-		defer func() {
-			if err != nil {
-				tx.Rollback()
-			}
-		}()
-
-		// Обновляем статус заказа
-		if err = s.orderRepo.UpdateStatusTx(ctx, tx, id, status); err != nil {
-			return fmt.Errorf("ошибка при обновлении статуса заказа: %w", err)
+		if changed {
+			count++
 		}
+	}
+
+	return count, nil
+}
+
+// transitionOptions условия и дополнительные действия перехода, которые
+// проверяются и выполняются под блокировкой заказа
+type transitionOptions struct {
+	// from допустимые исходные статусы; пусто - любой, разрешенный автоматом
+	from []domain.OrderStatus
+	// skip если возвращает true для заблокированного заказа, статус не меняется
+	skip func(order *domain.Order) bool
+	// apply выполняется в транзакции перехода после смены статуса
+	apply func(tx repository.Tx, order *domain.Order) error
+}
 
-		// Получаем текущую доступность мест
-		tourDate, err := s.tourRepo.GetTourDateByID(ctx, order.TourDateID)
+// transition выполняет переход заказа в статус to в одной транзакции вместе с
+// побочными действиями перехода и записью в историю. Условия из opts
+// проверяются для заблокированного заказа. Возвращает true, если статус был изменен.
+func (s *OrderServiceImpl) transition(ctx context.Context, id int64, to domain.OrderStatus, changedBy *int64, comment string, opts transitionOptions) (changed bool, err error) {
+	// Начинаем транзакцию: статус заказа, остаток мест и история меняются вместе
+	tx, err := s.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("ошибка при получении даты тура: %w", err)
+			tx.Rollback()
+		}
+	}()
+
+	// Блокируем заказ, чтобы параллельная отмена не вернула места дважды
+	order, err := s.orderRepo.GetByIDForUpdateTx(ctx, tx, id)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении заказа: %w", err)
+	}
+
+	from := domain.OrderStatus(order.Status)
+
+	// Если статус не меняется или переход пропускается - возвращаем успех
+	if from == to || (opts.skip != nil && opts.skip(order)) {
+		return false, tx.Commit()
+	}
+
+	t, err := lookupOrderTransition(from, to)
+	if err != nil {
+		return false, err
+	}
+	if len(opts.from) > 0 && !containsOrderStatus(opts.from, from) {
+		return false, fmt.Errorf("%w: %s", ErrOrderStatusNotAllowed, from)
+	}
+	if t.guard != nil {
+		if err = t.guard(order, time.Now()); err != nil {
+			return false, err
 		}
+	}
 
-		// Увеличиваем доступность мест
-		updatedAvailability := tourDate.Availability + order.PeopleCount
-		if err = s.tourRepo.UpdateTourDateAvailabilityTx(ctx, tx, order.TourDateID, updatedAvailability); err != nil {
-			return fmt.Errorf("ошибка при обновлении доступности мест: %w", err)
+	// Обновляем статус заказа
+	if err = s.orderRepo.UpdateStatusTx(ctx, tx, id, string(to)); err != nil {
+		return false, fmt.Errorf("ошибка при обновлении статуса заказа: %w", err)
+	}
+
+	if opts.apply != nil {
+		if err = opts.apply(tx, order); err != nil {
+			return false, err
 		}
+	}
 
-		// Коммитим транзакцию
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	notify := false
+	for _, effect := range t.effects {
+		switch effect {
+		case effectReleaseSeats:
+			if err = s.tourRepo.ReleaseSeatsTx(ctx, tx, order.TourDateID, order.PeopleCount); err != nil {
+				return false, fmt.Errorf("ошибка при обновлении доступности мест: %w", err)
+			}
+		case effectReleaseRoom:
+			if err = s.releaseRoomTx(ctx, tx, order); err != nil {
+				return false, fmt.Errorf("ошибка при освобождении номера: %w", err)
+			}
+		case effectReleaseDiscounts:
+			if err = s.releaseDiscountsTx(ctx, tx, order); err != nil {
+				return false, fmt.Errorf("ошибка при возврате применений скидок: %w", err)
+			}
+		case effectRefund:
+			if err = s.refunder.RefundOrder(ctx, tx, order); err != nil {
+				return false, fmt.Errorf("ошибка при возврате оплаты: %w", err)
+			}
+		case effectNotify:
+			notify = true
 		}
+	}
+
+	fromStatus := string(from)
+	err = s.orderRepo.AddStatusHistoryTx(ctx, tx, &domain.OrderStatusChange{
+		OrderID:    id,
+		FromStatus: &fromStatus,
+		ToStatus:   string(to),
+		ChangedBy:  changedBy,
+		Comment:    comment,
+	})
+	if err != nil {
+		return false, err
+	}
 
+	// Коммитим транзакцию
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	// Уведомление отправляется только после фиксации перехода
+	if notify {
+		order.Status = string(to)
+		s.notifier.OrderStatusChanged(ctx, order, from, to)
+	}
+
+	return true, nil
+}
+
+// releaseRoomTx освобождает ночи номера, забронированные заказом
+func (s *OrderServiceImpl) releaseRoomTx(ctx context.Context, tx repository.Tx, order *domain.Order) error {
+	if order.RoomID == nil {
 		return nil
 	}
 
-	// В остальных случаях просто обновляем статус
-	return s.orderRepo.UpdateStatus(ctx, id, status)
+	// Дата могла быть удалена после оформления заказа
+	tourDate, err := s.tourRepo.GetTourDateByIDWithDeleted(ctx, order.TourDateID)
+	if err != nil {
+		return err
+	}
+
+	return s.roomRepo.ReleaseNightsTx(ctx, tx, *order.RoomID, tourDate.StartDate, tourDate.EndDate)
 }
 
-// CalculatePrice рассчитывает стоимость заказа
-func (s *OrderServiceImpl) CalculatePrice(ctx context.Context, tourID, tourDateID int64, roomID *int64, peopleCount int) (float64, error) {
-	// Получение базовой цены тура
-	tour, err := s.tourRepo.GetByID(ctx, tourID)
+// releaseDiscountsTx удаляет применения скидок отменяемого заказа, чтобы
+// промоакции с лимитом снова были доступны
+func (s *OrderServiceImpl) releaseDiscountsTx(ctx context.Context, tx repository.Tx, order *domain.Order) error {
+	if s.promoRepo == nil {
+		return nil
+	}
+	return s.promoRepo.DeleteRedemptionsByOrderTx(ctx, tx, order.ID)
+}
+
+// checkOrderRequest проверяет существование тура, даты и номера, а также наличие
+// свободных мест и номеров. Возвращает выбранную дату тура.
+func (s *OrderServiceImpl) checkOrderRequest(ctx context.Context, req *domain.OrderRequest) (*domain.TourDate, error) {
+	// В заказе должен быть хотя бы один взрослый
+	if req.ChildrenCount < 0 || req.ChildrenCount >= req.PeopleCount {
+		return nil, errors.New("количество детей должно быть меньше количества человек в заказе")
+	}
+
+	// Проверка существования тура
+	tour, err := s.tourRepo.GetByID(ctx, req.TourID)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("ошибка при проверке тура: %w", err)
+	}
+	if tour == nil {
+		return nil, errors.New("тур не найден")
+	}
+
+	tourDate, err := s.findTourDate(ctx, req.TourID, req.TourDateID)
+	if err != nil {
+		return nil, err
+	}
+	if tourDate == nil {
+		return nil, errors.New("выбранная дата тура не найдена")
+	}
+
+	// Предварительная проверка доступности мест; окончательная проверка
+	// выполняется атомарно при резервировании внутри транзакции
+	if tourDate.Availability < req.PeopleCount {
+		return nil, fmt.Errorf("%w (доступно: %d, запрошено: %d)", repository.ErrInsufficientAvailability, tourDate.Availability, req.PeopleCount)
+	}
+
+	// Если указан ID номера, проверяем его существование
+	if req.RoomID != nil {
+		room, err := s.roomRepo.GetByID(ctx, *req.RoomID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при проверке номера: %w", err)
+		}
+		if room == nil {
+			return nil, errors.New("выбранный номер не найден")
+		}
+
+		// Проверка вместимости номера
+		if room.Beds < req.PeopleCount {
+			return nil, fmt.Errorf("выбранный номер вмещает максимум %d человек", room.Beds)
+		}
+
+		// Предварительная проверка свободных номеров на ночи тура; окончательная
+		// проверка выполняется при бронировании ночей внутри транзакции
+		available, err := s.roomRepo.IsAvailable(ctx, room.ID, tourDate.StartDate, tourDate.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			return nil, repository.ErrRoomUnavailable
+		}
 	}
 
-	// Получение даты тура
-	var tourDate *domain.TourDate
+	return tourDate, nil
+}
+
+// findTourDate ищет дату среди дат тура; возвращает nil, если дата не найдена
+func (s *OrderServiceImpl) findTourDate(ctx context.Context, tourID, tourDateID int64) (*domain.TourDate, error) {
 	tourDates, err := s.tourRepo.GetTourDates(ctx, tourID)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("ошибка при получении дат тура: %w", err)
 	}
 
-	// Ищем нужную дату среди доступных дат тура
 	for _, td := range tourDates {
 		if td.ID == tourDateID {
-			tourDate = td
-			break
+			return td, nil
 		}
 	}
 
+	return nil, nil
+}
+
+// CalculatePrice рассчитывает стоимость заказа с расшифровкой по составляющим
+// и примененным скидкам
+func (s *OrderServiceImpl) CalculatePrice(ctx context.Context, req *domain.OrderRequest) (*domain.PriceBreakdown, error) {
+	// Получение базовой цены тура
+	tour, err := s.tourRepo.GetByID(ctx, req.TourID)
+	if err != nil {
+		return nil, err
+	}
+
+	tourDate, err := s.findTourDate(ctx, req.TourID, req.TourDateID)
+	if err != nil {
+		return nil, err
+	}
 	if tourDate == nil {
-		return 0, errors.New("tour date not found")
+		return nil, errors.New("tour date not found")
 	}
 
 	// Базовая стоимость = базовая цена тура * модификатор даты * количество человек
-	basePrice := tour.BasePrice * tourDate.PriceModifier * float64(peopleCount)
+	// Модификатор применяется к цене за человека, чтобы округление не зависело
+	// от количества человек
+	breakdown := &domain.PriceBreakdown{
+		Currency:      tour.Currency,
+		BasePrice:     tour.BasePrice,
+		PriceModifier: tourDate.PriceModifier,
+		PeopleCount:   req.PeopleCount,
+		ChildrenCount: req.ChildrenCount,
+		TourAmount:    tour.BasePrice.Mul(tourDate.PriceModifier).Times(req.PeopleCount),
+		Discounts:     []domain.PriceDiscount{},
+	}
 
 	// Если выбран номер, добавляем его стоимость
-	var roomPrice float64 = 0
-	if roomID != nil && s.roomRepo != nil {
+	if req.RoomID != nil && s.roomRepo != nil {
 		// Получаем информацию о комнате
-		room, err := s.roomRepo.GetByID(ctx, *roomID)
+		room, err := s.roomRepo.GetByID(ctx, *req.RoomID)
 		if err != nil {
-			return 0, err
+			return nil, err
+		}
+
+		// Цена номера пересчитывается в валюту тура
+		roomPrice, err := s.currency.Convert(money.New(room.Price, room.Currency), tour.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при пересчете цены номера: %w", err)
 		}
 
-		// Вычисляем продолжительность пребывания на основе дат тура
-		// Количество ночей всегда на 1 меньше, чем количество дней
-		duration := tour.Duration
-		nights := duration - 1 // Исправлено: количество ночей на 1 меньше, чем дней
+		// Ночи считаются по выбранной дате тура - ровно те, что занимает бронирование номера
+		breakdown.RoomPrice = roomPrice.Amount
+		breakdown.RoomNights = tourDate.Nights()
+		breakdown.RoomAmount = roomPrice.Amount.Times(breakdown.RoomNights)
+	}
+
+	if err := s.applyDiscounts(ctx, req, tourDate, breakdown); err != nil {
+		return nil, err
+	}
 
-		// Добавляем стоимость номера за весь период пребывания (ночи)
-		roomPrice = room.Price * float64(nights)
+	for _, d := range breakdown.Discounts {
+		breakdown.DiscountAmount += d.Amount
 	}
+	breakdown.Total = breakdown.TourAmount + breakdown.RoomAmount - breakdown.DiscountAmount
 
-	return basePrice + roomPrice, nil
+	return breakdown, nil
+}
+
+// applyDiscounts добавляет в расшифровку скидки автоматических правил, условия
+// которых выполняются, и скидку по промокоду. Скидки суммируются, но в сумме
+// не превышают стоимость заказа.
+func (s *OrderServiceImpl) applyDiscounts(ctx context.Context, req *domain.OrderRequest, tourDate *domain.TourDate, breakdown *domain.PriceBreakdown) error {
+	if s.promoRepo == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	promos, err := s.promoRepo.ListAutomatic(ctx, req.TourID, now)
+	if err != nil {
+		return err
+	}
+	// Правила с исчерпанным лимитом просто не применяются
+	candidates := make([]*domain.Promotion, 0, len(promos)+1)
+	for _, promo := range promos {
+		if !promotionApplies(promo, req, tourDate, now) {
+			continue
+		}
+		reached, err := s.promotionLimitReached(ctx, promo, req.UserID)
+		if err != nil {
+			return err
+		}
+		if !reached {
+			candidates = append(candidates, promo)
+		}
+	}
+
+	// Промокод, в отличие от правил, должен примениться, иначе расчет отклоняется
+	if req.PromoCode != "" {
+		promo, err := s.promoRepo.GetByCode(ctx, req.PromoCode)
+		if err != nil {
+			if errors.Is(err, repository.ErrPromotionNotFound) {
+				return ErrPromoCodeInvalid
+			}
+			return err
+		}
+		if !promotionActive(promo, now) {
+			return ErrPromoCodeInvalid
+		}
+		if !promotionApplies(promo, req, tourDate, now) {
+			return ErrPromoCodeNotApplicable
+		}
+		reached, err := s.promotionLimitReached(ctx, promo, req.UserID)
+		if err != nil {
+			return err
+		}
+		if reached {
+			return ErrPromotionLimitReached
+		}
+		candidates = append(candidates, promo)
+	}
+
+	remaining := breakdown.TourAmount + breakdown.RoomAmount
+	for _, promo := range candidates {
+		amount, err := promotionDiscount(promo, req, breakdown, s.currency)
+		if err != nil {
+			return err
+		}
+		if amount > remaining {
+			amount = remaining
+		}
+		if amount <= 0 {
+			continue
+		}
+		remaining -= amount
+
+		discount := domain.PriceDiscount{
+			PromotionID: promo.ID,
+			Title:       promo.Title,
+			Amount:      amount,
+		}
+		if promo.Code != nil {
+			discount.Code = *promo.Code
+		}
+		breakdown.Discounts = append(breakdown.Discounts, discount)
+	}
+
+	return nil
+}
+
+// promotionLimitReached проверяет, исчерпаны ли лимиты применения промоакции
+func (s *OrderServiceImpl) promotionLimitReached(ctx context.Context, promo *domain.Promotion, userID int64) (bool, error) {
+	if promo.MaxUses == nil && promo.MaxUsesPerUser == nil {
+		return false, nil
+	}
+
+	total, perUser := 0, 0
+	var err error
+	if promo.MaxUses != nil {
+		if total, err = s.promoRepo.CountRedemptions(ctx, promo.ID, nil); err != nil {
+			return false, err
+		}
+	}
+	if promo.MaxUsesPerUser != nil {
+		if perUser, err = s.promoRepo.CountRedemptions(ctx, promo.ID, &userID); err != nil {
+			return false, err
+		}
+	}
+
+	return promotionLimitReached(promo, total, perUser), nil
+}
+
+// redeemDiscountsTx сохраняет применения скидок заказа. Лимиты повторно
+// проверяются под блокировкой промоакции, чтобы параллельные заказы их не превысили.
+func (s *OrderServiceImpl) redeemDiscountsTx(ctx context.Context, tx repository.Tx, orderID, userID int64, breakdown *domain.PriceBreakdown) error {
+	for _, d := range breakdown.Discounts {
+		promo, err := s.promoRepo.GetByIDForUpdateTx(ctx, tx, d.PromotionID)
+		if err != nil {
+			return err
+		}
+
+		total, err := s.promoRepo.CountRedemptionsTx(ctx, tx, promo.ID, nil)
+		if err != nil {
+			return err
+		}
+		perUser, err := s.promoRepo.CountRedemptionsTx(ctx, tx, promo.ID, &userID)
+		if err != nil {
+			return err
+		}
+		if promotionLimitReached(promo, total, perUser) {
+			return fmt.Errorf("%w: %s", ErrPromotionLimitReached, promo.Title)
+		}
+
+		err = s.promoRepo.AddRedemptionTx(ctx, tx, &domain.PromotionRedemption{
+			PromotionID: promo.ID,
+			OrderID:     orderID,
+			UserID:      userID,
+			Amount:      d.Amount,
+			Currency:    breakdown.Currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameRoom сравнивает необязательные идентификаторы номеров
+func sameRoom(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
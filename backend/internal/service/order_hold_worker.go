@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultOrderHoldSweepInterval периодичность снятия удержаний, если она не задана в конфигурации
+const DefaultOrderHoldSweepInterval = time.Minute
+
+// OrderHoldWorker фоновый обработчик, отменяющий неоплаченные заказы с истекшим удержанием мест
+type OrderHoldWorker struct {
+	orders   OrderService
+	interval time.Duration
+}
+
+// NewOrderHoldWorker создает новый обработчик удержаний
+func NewOrderHoldWorker(orders OrderService, interval time.Duration) *OrderHoldWorker {
+	if interval <= 0 {
+		interval = DefaultOrderHoldSweepInterval
+	}
+
+	return &OrderHoldWorker{
+		orders:   orders,
+		interval: interval,
+	}
+}
+
+// Run периодически снимает просроченные удержания до отмены контекста
+func (w *OrderHoldWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("[OrderHoldWorker] Запущен, интервал проверки: %s", w.interval)
+
+	for {
+		w.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[OrderHoldWorker] Остановлен")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep выполняет один проход по просроченным заказам
+func (w *OrderHoldWorker) sweep(ctx context.Context) {
+	count, err := w.orders.ExpireHolds(ctx)
+	if err != nil {
+		log.Printf("[OrderHoldWorker] Ошибка при снятии удержаний: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("[OrderHoldWorker] Отменено просроченных заказов: %d", count)
+	}
+}
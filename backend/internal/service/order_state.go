@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+)
+
+// orderEffect побочное действие, выполняемое при переходе заказа между статусами
+type orderEffect int
+
+const (
+	effectReleaseSeats     orderEffect = iota // вернуть места в дату тура
+	effectReleaseRoom                         // освободить забронированные ночи номера
+	effectReleaseDiscounts                    // вернуть применения промоакций в их лимиты
+	effectRefund                              // инициировать возврат оплаты
+	effectNotify                              // уведомить клиента после фиксации перехода
+)
+
+// orderTransition описание разрешенного перехода между статусами заказа
+type orderTransition struct {
+	// guard дополнительное условие перехода; nil - переход безусловный
+	guard   func(order *domain.Order, now time.Time) error
+	effects []orderEffect
+}
+
+// orderTransitions конечный автомат статусов заказа. Переходы, которых нет
+// в таблице, запрещены; cancelled и completed - конечные статусы.
+var orderTransitions = map[domain.OrderStatus]map[domain.OrderStatus]orderTransition{
+	domain.OrderStatusPending: {
+		domain.OrderStatusConfirmed: {guard: holdActive, effects: []orderEffect{effectNotify}},
+		domain.OrderStatusPaid:      {guard: holdActive, effects: []orderEffect{effectNotify}},
+		domain.OrderStatusCancelled: {effects: []orderEffect{effectReleaseSeats, effectReleaseRoom, effectReleaseDiscounts, effectNotify}},
+	},
+	domain.OrderStatusConfirmed: {
+		domain.OrderStatusPaid:      {effects: []orderEffect{effectNotify}},
+		domain.OrderStatusCancelled: {effects: []orderEffect{effectReleaseSeats, effectReleaseRoom, effectReleaseDiscounts, effectNotify}},
+	},
+	domain.OrderStatusPaid: {
+		domain.OrderStatusCompleted: {effects: []orderEffect{effectNotify}},
+		domain.OrderStatusCancelled: {effects: []orderEffect{effectReleaseSeats, effectReleaseRoom, effectReleaseDiscounts, effectRefund, effectNotify}},
+	},
+}
+
+// holdActive запрещает подтверждать заказ, удержание мест которого уже истекло
+func holdActive(order *domain.Order, now time.Time) error {
+	if order.ExpiresAt != nil && !order.ExpiresAt.After(now) {
+		return ErrOrderHoldExpired
+	}
+	return nil
+}
+
+// lookupOrderTransition возвращает описание перехода или ErrInvalidOrderTransition
+func lookupOrderTransition(from, to domain.OrderStatus) (orderTransition, error) {
+	t, ok := orderTransitions[from][to]
+	if !ok {
+		return orderTransition{}, fmt.Errorf("%w: %s -> %s", ErrInvalidOrderTransition, from, to)
+	}
+	return t, nil
+}
+
+// containsOrderStatus проверяет, входит ли статус в список
+func containsOrderStatus(statuses []domain.OrderStatus, status domain.OrderStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderRefunder инициирует возврат средств по отменяемому оплаченному заказу
+// в рамках транзакции смены статуса
+type OrderRefunder interface {
+	RefundOrder(ctx context.Context, tx repository.Tx, order *domain.Order) error
+}
+
+// OrderNotifier уведомляет клиента об изменении статуса заказа
+type OrderNotifier interface {
+	OrderStatusChanged(ctx context.Context, order *domain.Order, from, to domain.OrderStatus)
+}
+
+// manualRefunder используется для заказов, оплаченных вне платежного шлюза:
+// возврат выполняется вручную, сервис только фиксирует необходимость
+type manualRefunder struct{}
+
+func (manualRefunder) RefundOrder(ctx context.Context, tx repository.Tx, order *domain.Order) error {
+	log.Printf("[OrderService] Заказ %d отменен после оплаты, требуется возврат %s %s", order.ID, order.TotalPrice, order.Currency)
+	return nil
+}
+
+// logNotifier записывает уведомления в лог
+type logNotifier struct{}
+
+func (logNotifier) OrderStatusChanged(ctx context.Context, order *domain.Order, from, to domain.OrderStatus) {
+	log.Printf("[OrderService] Уведомление пользователю %d: заказ %d переведен из %s в %s", order.UserID, order.ID, from, to)
+}
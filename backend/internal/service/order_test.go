@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pricing"
+)
+
+// memTx транзакция в памяти: при откате отменяет выполненные в ней изменения
+type memTx struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+func (tx *memTx) onRollback(f func()) {
+	tx.mu.Lock()
+	tx.undo = append(tx.undo, f)
+	tx.mu.Unlock()
+}
+
+func (tx *memTx) Commit() error {
+	tx.mu.Lock()
+	tx.undo = nil
+	tx.mu.Unlock()
+	return nil
+}
+
+func (tx *memTx) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+	return nil
+}
+
+// memTourRepo хранит один тур с одной датой. ReserveSeatsTx повторяет
+// условное обновление репозитория: места списываются, только если их хватает.
+type memTourRepo struct {
+	repository.TourRepository
+	mu       sync.Mutex
+	tour     domain.Tour
+	date     domain.TourDate
+	negative bool // остаток мест хотя бы раз уходил ниже нуля
+}
+
+func (r *memTourRepo) GetByID(ctx context.Context, id int64) (*domain.Tour, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.tour
+	return &t, nil
+}
+
+func (r *memTourRepo) GetTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := r.date
+	return []*domain.TourDate{&d}, nil
+}
+
+func (r *memTourRepo) ReserveSeatsTx(ctx context.Context, tx repository.Tx, tourDateID int64, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.date.Availability < count {
+		return repository.ErrInsufficientAvailability
+	}
+	r.date.Availability -= count
+	if r.date.Availability < 0 {
+		r.negative = true
+	}
+	tx.(*memTx).onRollback(func() {
+		r.mu.Lock()
+		r.date.Availability += count
+		r.mu.Unlock()
+	})
+	return nil
+}
+
+func (r *memTourRepo) ReleaseSeatsTx(ctx context.Context, tx repository.Tx, tourDateID int64, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.date.Availability += count
+	tx.(*memTx).onRollback(func() {
+		r.mu.Lock()
+		r.date.Availability -= count
+		r.mu.Unlock()
+	})
+	return nil
+}
+
+func (r *memTourRepo) availability() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.date.Availability
+}
+
+// memOrderStore сохраняет созданные заказы в памяти
+type memOrderStore struct {
+	repository.OrderRepository
+	created int64
+}
+
+func (r *memOrderStore) BeginTx(ctx context.Context) (repository.Tx, error) {
+	return &memTx{}, nil
+}
+
+func (r *memOrderStore) CreateTx(ctx context.Context, tx repository.Tx, order *domain.Order) (int64, error) {
+	id := atomic.AddInt64(&r.created, 1)
+	tx.(*memTx).onRollback(func() { atomic.AddInt64(&r.created, -1) })
+	return id, nil
+}
+
+func (r *memOrderStore) AddStatusHistoryTx(ctx context.Context, tx repository.Tx, change *domain.OrderStatusChange) error {
+	return nil
+}
+
+// memUserRepo считает существующим любого пользователя
+type memUserRepo struct {
+	repository.UserRepository
+}
+
+func (memUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return &domain.User{ID: id}, nil
+}
+
+func newTestOrderService(capacity int) (*OrderServiceImpl, *memTourRepo, *memOrderStore) {
+	tours := &memTourRepo{
+		tour: domain.Tour{ID: 1, BasePrice: money.Amount(1000000), Currency: "RUB", Duration: 7, IsActive: true},
+		date: domain.TourDate{
+			ID:            1,
+			TourID:        1,
+			StartDate:     time.Now().AddDate(0, 1, 0),
+			EndDate:       time.Now().AddDate(0, 1, 6),
+			Capacity:      capacity,
+			Availability:  capacity,
+			BaseModifier:  money.One,
+			PriceModifier: money.One,
+		},
+	}
+	orders := &memOrderStore{}
+	quotes := pricing.NewJWTQuoteSigner("secret", time.Minute)
+	svc := NewOrderService(orders, tours, memUserRepo{}, nil, nil, quotes, nil, time.Minute, nil).(*OrderServiceImpl)
+	return svc, tours, orders
+}
+
+// TestCreateOrderConcurrentBookings проверяет, что параллельные заказы на одну
+// дату не продают больше мест, чем есть
+func TestCreateOrderConcurrentBookings(t *testing.T) {
+	cases := []struct {
+		name        string
+		capacity    int
+		peopleCount int
+		clients     int
+	}{
+		{name: "по одному месту", capacity: 5, peopleCount: 1, clients: 50},
+		{name: "по два места", capacity: 5, peopleCount: 2, clients: 20},
+		{name: "мест больше, чем клиентов", capacity: 30, peopleCount: 1, clients: 20},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, tours, orders := newTestOrderService(tc.capacity)
+
+			// Расчеты выдаются заранее, пока места еще есть
+			requests := make([]*domain.OrderRequest, tc.clients)
+			tokens := make([]string, tc.clients)
+			for i := range requests {
+				requests[i] = &domain.OrderRequest{UserID: int64(i + 1), TourID: 1, TourDateID: 1, PeopleCount: tc.peopleCount}
+				quote, err := svc.Quote(ctx, requests[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				tokens[i] = quote.Token
+			}
+
+			var succeeded, rejected int64
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for i := range requests {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-start
+					_, err := svc.Create(ctx, requests[i], tokens[i])
+					switch {
+					case err == nil:
+						atomic.AddInt64(&succeeded, 1)
+					case errors.Is(err, repository.ErrInsufficientAvailability):
+						atomic.AddInt64(&rejected, 1)
+					default:
+						t.Errorf("неожиданная ошибка: %v", err)
+					}
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+
+			wantSucceeded := tc.capacity / tc.peopleCount
+			if wantSucceeded > tc.clients {
+				wantSucceeded = tc.clients
+			}
+			if int(succeeded) != wantSucceeded {
+				t.Errorf("успешных заказов: %d, ожидалось %d", succeeded, wantSucceeded)
+			}
+			if int(succeeded+rejected) != tc.clients {
+				t.Errorf("обработано заказов: %d из %d", succeeded+rejected, tc.clients)
+			}
+			if tours.negative {
+				t.Error("остаток мест уходил ниже нуля")
+			}
+			if got, want := tours.availability(), tc.capacity-wantSucceeded*tc.peopleCount; got != want {
+				t.Errorf("остаток мест: %d, ожидалось %d", got, want)
+			}
+			if int(orders.created) != wantSucceeded {
+				t.Errorf("сохранено заказов: %d, ожидалось %d", orders.created, wantSucceeded)
+			}
+		})
+	}
+}
+
+// memRoomRepo отдает один номер
+type memRoomRepo struct {
+	repository.RoomRepository
+	room domain.Room
+}
+
+func (r memRoomRepo) GetByID(ctx context.Context, id int64) (*domain.Room, error) {
+	room := r.room
+	return &room, nil
+}
+
+// TestCalculatePriceRoomNightsFromTourDate проверяет, что номер оплачивается
+// за ночи выбранной даты, а не за продолжительность из карточки тура
+func TestCalculatePriceRoomNightsFromTourDate(t *testing.T) {
+	svc, tours, _ := newTestOrderService(10)
+	svc.roomRepo = memRoomRepo{room: domain.Room{ID: 1, Price: money.Amount(500000), Currency: "RUB"}}
+	tours.date.StartDate = time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC)
+	tours.date.EndDate = time.Date(2026, 11, 4, 10, 0, 0, 0, time.UTC)
+
+	roomID := int64(1)
+	breakdown, err := svc.CalculatePrice(context.Background(), &domain.OrderRequest{
+		UserID: 1, TourID: 1, TourDateID: 1, PeopleCount: 2, RoomID: &roomID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := len(domain.StayNights(tours.date.StartDate, tours.date.EndDate)); breakdown.RoomNights != want || want != 3 {
+		t.Errorf("ночей: %d, ожидалось %d (резервируется %d)", breakdown.RoomNights, 3, want)
+	}
+	if want := money.Amount(1500000); breakdown.RoomAmount != want {
+		t.Errorf("стоимость номера: %d, ожидалось %d", breakdown.RoomAmount, want)
+	}
+}
+
+// memCancelOrders хранит один заказ для проверки смены статуса
+type memCancelOrders struct {
+	repository.OrderRepository
+	order      domain.Order
+	historyErr error // ошибка записи истории - откатывает переход
+}
+
+func (r *memCancelOrders) BeginTx(ctx context.Context) (repository.Tx, error) {
+	return &memTx{}, nil
+}
+
+func (r *memCancelOrders) GetByIDForUpdateTx(ctx context.Context, tx repository.Tx, id int64) (*domain.Order, error) {
+	order := r.order
+	return &order, nil
+}
+
+func (r *memCancelOrders) UpdateStatusTx(ctx context.Context, tx repository.Tx, id int64, status string) error {
+	prev := r.order.Status
+	r.order.Status = status
+	tx.(*memTx).onRollback(func() { r.order.Status = prev })
+	return nil
+}
+
+func (r *memCancelOrders) AddStatusHistoryTx(ctx context.Context, tx repository.Tx, change *domain.OrderStatusChange) error {
+	return r.historyErr
+}
+
+// memRedemptions хранит применения промоакций по заказам
+type memRedemptions struct {
+	repository.PromotionRepository
+	byOrder map[int64][]int64 // ID заказа -> ID промоакций
+}
+
+func (r *memRedemptions) DeleteRedemptionsByOrderTx(ctx context.Context, tx repository.Tx, orderID int64) error {
+	removed, ok := r.byOrder[orderID]
+	delete(r.byOrder, orderID)
+	if ok {
+		tx.(*memTx).onRollback(func() { r.byOrder[orderID] = removed })
+	}
+	return nil
+}
+
+// TestCancelOrderReleasesRedemptions проверяет, что отмена заказа возвращает
+// применения скидок в лимиты промоакций в той же транзакции
+func TestCancelOrderReleasesRedemptions(t *testing.T) {
+	for _, status := range []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusConfirmed, domain.OrderStatusPaid} {
+		t.Run(string(status), func(t *testing.T) {
+			svc, tours, _ := newTestOrderService(10)
+			orders := &memCancelOrders{order: domain.Order{ID: 7, UserID: 1, TourDateID: 1, PeopleCount: 2, Status: string(status)}}
+			promos := &memRedemptions{byOrder: map[int64][]int64{7: {1, 2}, 8: {1}}}
+			svc.orderRepo = orders
+			svc.promoRepo = promos
+
+			if err := svc.UpdateStatus(context.Background(), 7, string(domain.OrderStatusCancelled), nil, "отмена"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := promos.byOrder[7]; ok {
+				t.Error("применения скидок отмененного заказа не удалены")
+			}
+			if len(promos.byOrder[8]) != 1 {
+				t.Error("удалены применения другого заказа")
+			}
+			if got := tours.availability(); got != 12 {
+				t.Errorf("остаток мест: %d, ожидалось 12", got)
+			}
+		})
+	}
+}
+
+// TestUpdateStatusFromChecksLockedStatus проверяет, что допустимые исходные
+// статусы сверяются с заблокированным заказом: заказ, оплаченный после того,
+// как клиент увидел его неоплаченным, не отменяется без возврата
+func TestUpdateStatusFromChecksLockedStatus(t *testing.T) {
+	unpaid := []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusConfirmed}
+
+	cases := []struct {
+		status  domain.OrderStatus
+		wantErr error
+		want    domain.OrderStatus
+		seats   int
+	}{
+		{domain.OrderStatusPending, nil, domain.OrderStatusCancelled, 12},
+		{domain.OrderStatusConfirmed, nil, domain.OrderStatusCancelled, 12},
+		{domain.OrderStatusPaid, ErrOrderStatusNotAllowed, domain.OrderStatusPaid, 10},
+		{domain.OrderStatusCompleted, ErrInvalidOrderTransition, domain.OrderStatusCompleted, 10},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.status), func(t *testing.T) {
+			svc, tours, _ := newTestOrderService(10)
+			orders := &memCancelOrders{order: domain.Order{ID: 7, UserID: 1, TourDateID: 1, PeopleCount: 2, Status: string(tc.status)}}
+			svc.orderRepo = orders
+			svc.promoRepo = &memRedemptions{byOrder: map[int64][]int64{}}
+
+			err := svc.UpdateStatusFrom(context.Background(), 7, string(domain.OrderStatusCancelled), unpaid, nil, "отменен клиентом")
+			if tc.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ошибка: %v, ожидалась %v", err, tc.wantErr)
+			}
+			if orders.order.Status != string(tc.want) {
+				t.Errorf("статус: %s, ожидался %s", orders.order.Status, tc.want)
+			}
+			if got := tours.availability(); got != tc.seats {
+				t.Errorf("остаток мест: %d, ожидалось %d", got, tc.seats)
+			}
+		})
+	}
+}
+
+func TestCancelOrderKeepsRedemptionsOnRollback(t *testing.T) {
+	svc, _, _ := newTestOrderService(10)
+	orders := &memCancelOrders{
+		order:      domain.Order{ID: 7, UserID: 1, TourDateID: 1, PeopleCount: 1, Status: string(domain.OrderStatusPending)},
+		historyErr: errors.New("история недоступна"),
+	}
+	promos := &memRedemptions{byOrder: map[int64][]int64{7: {1}}}
+	svc.orderRepo = orders
+	svc.promoRepo = promos
+
+	if err := svc.UpdateStatus(context.Background(), 7, string(domain.OrderStatusCancelled), nil, "отмена"); err == nil {
+		t.Fatal("ожидалась ошибка записи истории")
+	}
+	if orders.order.Status != string(domain.OrderStatusPending) {
+		t.Errorf("статус после отката: %s", orders.order.Status)
+	}
+	if len(promos.byOrder[7]) != 1 {
+		t.Error("применения скидок удалены, хотя отмена откатилась")
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
+)
+
+// pageResult завершает выборку страницы. При выборке по курсору отбрасывает
+// лишнюю запись и возвращает курсор следующей страницы, при выборке со
+// смещением подсчитывает общее количество записей.
+func pageResult[T any](page domain.Page, items []T, key func(T) pagination.Cursor, count func() (int, error)) ([]T, *domain.PageInfo, error) {
+	if page.IsCursor() {
+		items, next := pagination.Trim(items, page.Size, key)
+		return items, &domain.PageInfo{NextCursor: next}, nil
+	}
+
+	total, err := count()
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, &domain.PageInfo{Total: &total}, nil
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/payment"
+)
+
+// PaymentServiceImpl реализация сервиса приема оплаты заказов
+type PaymentServiceImpl struct {
+	paymentRepo repository.PaymentRepository
+	orderRepo   repository.OrderRepository
+	orders      OrderService
+	provider    payment.PaymentProvider
+}
+
+// NewPaymentService создает новый сервис платежей
+func NewPaymentService(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository, orders OrderService, provider payment.PaymentProvider) PaymentService {
+	return &PaymentServiceImpl{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		orders:      orders,
+		provider:    provider,
+	}
+}
+
+// StartCheckout создает у провайдера платежное намерение на сумму заказа
+// и сохраняет ожидающий платеж
+func (s *PaymentServiceImpl) StartCheckout(ctx context.Context, orderID int64) (*payment.Intent, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.OrderStatus(order.Status)
+	if status != domain.OrderStatusPending && status != domain.OrderStatusConfirmed {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotPayable, status)
+	}
+	if status == domain.OrderStatusPending {
+		if err := holdActive(order, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Повторный запрос оплаты не должен создавать второе намерение, иначе заказ
+	// можно оплатить дважды
+	active, err := s.paymentRepo.ListActiveByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range active {
+		switch domain.PaymentStatus(p.Status) {
+		case domain.PaymentStatusSucceeded:
+			return nil, ErrOrderAlreadyPaid
+		case domain.PaymentStatusProcessing:
+			return nil, ErrPaymentInProgress
+		}
+	}
+	for _, p := range active {
+		intent, err := s.reusableIntent(ctx, order, p)
+		if err != nil {
+			return nil, err
+		}
+		if intent != nil {
+			return intent, nil
+		}
+	}
+
+	// Оплата принимается в валюте заказа
+	intent, err := s.provider.CreateIntent(ctx, money.New(order.TotalPrice, order.Currency), fmt.Sprintf("order-%d", order.ID))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании платежа у провайдера: %w", err)
+	}
+
+	_, err = s.paymentRepo.Create(ctx, &domain.Payment{
+		OrderID:           order.ID,
+		Provider:          s.provider.Name(),
+		ProviderPaymentID: intent.ID,
+		Amount:            intent.Amount,
+		Currency:          intent.Currency,
+		Status:            string(domain.PaymentStatusPending),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return intent, nil
+}
+
+// reusableIntent возвращает намерение ожидающего платежа, если по нему еще можно
+// оплатить заказ. Платеж на другую сумму или неизвестный провайдеру отменяется.
+func (s *PaymentServiceImpl) reusableIntent(ctx context.Context, order *domain.Order, p *domain.Payment) (*payment.Intent, error) {
+	if p.Provider == s.provider.Name() && p.Amount == order.TotalPrice && p.Currency == order.Currency {
+		intent, err := s.provider.GetIntent(ctx, p.ProviderPaymentID)
+		if err == nil {
+			return intent, nil
+		}
+		if !errors.Is(err, payment.ErrIntentNotFound) {
+			return nil, fmt.Errorf("ошибка при получении платежа у провайдера: %w", err)
+		}
+	}
+
+	log.Printf("[PaymentService] Ожидающий платеж %s по заказу %d устарел и отменяется", p.ProviderPaymentID, order.ID)
+	_, err := s.paymentRepo.CompareAndSetStatus(ctx, p.ID, string(domain.PaymentStatusPending), string(domain.PaymentStatusFailed))
+	return nil, err
+}
+
+// HandleWebhook проверяет подпись события провайдера и применяет его к платежу.
+// Повторная доставка уже обработанного события ничего не меняет.
+func (s *PaymentServiceImpl) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.paymentRepo.GetByProviderPaymentID(ctx, s.provider.Name(), event.IntentID)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventAuthorized:
+		// Платеж захватывается условным обновлением: параллельная или повторная
+		// доставка события его не получит и не спишет и не вернет средства второй раз
+		claimed, err := s.paymentRepo.CompareAndSetStatus(ctx, p.ID, string(domain.PaymentStatusPending), string(domain.PaymentStatusProcessing))
+		if err != nil || !claimed {
+			return err
+		}
+		return s.applyAuthorized(ctx, p)
+	case payment.EventFailed:
+		_, err = s.paymentRepo.CompareAndSetStatus(ctx, p.ID, string(domain.PaymentStatusPending), string(domain.PaymentStatusFailed))
+		return err
+	default:
+		log.Printf("[PaymentService] Пропущено событие %s типа %s", event.ID, event.Type)
+		return nil
+	}
+}
+
+// applyAuthorized списывает авторизованные средства по захваченному платежу и
+// переводит заказ в статус paid. Если заказ уже нельзя оплатить (оплачен другим
+// платежом, истекло удержание или он отменен), авторизация снимается или
+// списанные средства возвращаются клиенту.
+func (s *PaymentServiceImpl) applyAuthorized(ctx context.Context, p *domain.Payment) error {
+	order, err := s.orderRepo.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return s.release(ctx, p, err)
+	}
+	if status := domain.OrderStatus(order.Status); status != domain.OrderStatusPending && status != domain.OrderStatusConfirmed {
+		return s.refundPayment(ctx, p, fmt.Errorf("%w: %s", ErrOrderNotPayable, status))
+	}
+
+	if err := s.provider.Capture(ctx, p.ProviderPaymentID); err != nil {
+		return s.release(ctx, p, fmt.Errorf("ошибка при списании средств: %w", err))
+	}
+
+	// Параллельный платеж мог оплатить заказ после проверки выше
+	comment := fmt.Sprintf("оплачено через %s (%s)", p.Provider, p.ProviderPaymentID)
+	err = s.orders.MarkPaid(ctx, p.OrderID, p.ID, comment)
+	if err != nil {
+		if !errors.Is(err, ErrOrderAlreadyPaid) && !errors.Is(err, ErrOrderHoldExpired) && !errors.Is(err, ErrInvalidOrderTransition) {
+			return s.release(ctx, p, err)
+		}
+		return s.refundPayment(ctx, p, err)
+	}
+
+	return s.setStatus(ctx, p, domain.PaymentStatusSucceeded)
+}
+
+// refundPayment возвращает клиенту средства по платежу, которым нельзя оплатить
+// заказ. Платеж, которым заказ уже оплачен, не возвращается ни при каких условиях.
+func (s *PaymentServiceImpl) refundPayment(ctx context.Context, p *domain.Payment, reason error) error {
+	order, err := s.orderRepo.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return s.release(ctx, p, err)
+	}
+	if order.PaymentID != nil && *order.PaymentID == p.ID {
+		log.Printf("[PaymentService] Заказ %d уже оплачен платежом %s, возврат не выполняется", p.OrderID, p.ProviderPaymentID)
+		return s.setStatus(ctx, p, domain.PaymentStatusSucceeded)
+	}
+
+	log.Printf("[PaymentService] Заказ %d не может быть оплачен (%v), возврат платежа %s", p.OrderID, reason, p.ProviderPaymentID)
+	if err := s.provider.Refund(ctx, p.ProviderPaymentID, p.Amount); err != nil {
+		return s.release(ctx, p, fmt.Errorf("ошибка при возврате средств: %w", err))
+	}
+	return s.setStatus(ctx, p, domain.PaymentStatusRefunded)
+}
+
+// release возвращает захваченный платеж в ожидание после ошибки, чтобы
+// повторная доставка события могла обработать его заново
+func (s *PaymentServiceImpl) release(ctx context.Context, p *domain.Payment, cause error) error {
+	if _, err := s.paymentRepo.CompareAndSetStatus(ctx, p.ID, string(domain.PaymentStatusProcessing), string(domain.PaymentStatusPending)); err != nil {
+		log.Printf("[PaymentService] Не удалось вернуть платеж %s в ожидание: %v", p.ProviderPaymentID, err)
+	}
+	return cause
+}
+
+// setStatus завершает обработку захваченного платежа
+func (s *PaymentServiceImpl) setStatus(ctx context.Context, p *domain.Payment, to domain.PaymentStatus) error {
+	_, err := s.paymentRepo.CompareAndSetStatus(ctx, p.ID, string(domain.PaymentStatusProcessing), string(to))
+	return err
+}
+
+// SimulationSupported сообщает, позволяет ли провайдер имитировать оплату клиентом
+func (s *PaymentServiceImpl) SimulationSupported() bool {
+	_, ok := s.provider.(payment.Simulator)
+	return ok
+}
+
+// SimulateAuthorization имитирует подтверждение оплаты клиентом у провайдера,
+// поддерживающего работу без внешнего шлюза, и обрабатывает полученный webhook.
+// Оплатить можно только свой заказ; чужое намерение считается ненайденным.
+func (s *PaymentServiceImpl) SimulateAuthorization(ctx context.Context, userID int64, intentID string) error {
+	simulator, ok := s.provider.(payment.Simulator)
+	if !ok {
+		return ErrPaymentSimulationUnsupported
+	}
+
+	p, err := s.paymentRepo.GetByProviderPaymentID(ctx, s.provider.Name(), intentID)
+	if err != nil {
+		return err
+	}
+	order, err := s.orderRepo.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.UserID != userID {
+		return repository.ErrPaymentNotFound
+	}
+
+	payload, signature, err := simulator.SimulateAuthorization(ctx, intentID)
+	if err != nil {
+		return err
+	}
+
+	return s.HandleWebhook(ctx, payload, signature)
+}
+
+// providerRefunder возвращает средства через платежного провайдера при отмене
+// оплаченного заказа. Заказы, оплаченные вне провайдера, возвращаются вручную.
+type providerRefunder struct {
+	paymentRepo repository.PaymentRepository
+	provider    payment.PaymentProvider
+	fallback    OrderRefunder
+}
+
+// NewProviderRefunder создает OrderRefunder, работающий через платежного провайдера
+func NewProviderRefunder(paymentRepo repository.PaymentRepository, provider payment.PaymentProvider) OrderRefunder {
+	return &providerRefunder{
+		paymentRepo: paymentRepo,
+		provider:    provider,
+		fallback:    manualRefunder{},
+	}
+}
+
+func (r *providerRefunder) RefundOrder(ctx context.Context, tx repository.Tx, order *domain.Order) error {
+	p, err := r.paymentRepo.GetSucceededByOrderIDTx(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if p == nil || p.Provider != r.provider.Name() {
+		return r.fallback.RefundOrder(ctx, tx, order)
+	}
+
+	if err := r.provider.Refund(ctx, p.ProviderPaymentID, p.Amount); err != nil {
+		return err
+	}
+
+	return r.paymentRepo.UpdateStatusTx(ctx, tx, p.ID, string(domain.PaymentStatusRefunded))
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/payment"
+)
+
+// memPaymentRepo хранит платежи в памяти
+type memPaymentRepo struct {
+	repository.PaymentRepository
+	mu       sync.Mutex
+	payments []*domain.Payment
+}
+
+func (r *memPaymentRepo) Create(ctx context.Context, p *domain.Payment) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *p
+	c.ID = int64(len(r.payments) + 1)
+	r.payments = append(r.payments, &c)
+	return c.ID, nil
+}
+
+func (r *memPaymentRepo) GetByProviderPaymentID(ctx context.Context, provider, id string) (*domain.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.payments {
+		if p.Provider == provider && p.ProviderPaymentID == id {
+			c := *p
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrPaymentNotFound
+}
+
+func (r *memPaymentRepo) ListActiveByOrderID(ctx context.Context, orderID int64) ([]*domain.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var active []*domain.Payment
+	for i := len(r.payments) - 1; i >= 0; i-- {
+		p := r.payments[i]
+		if p.OrderID == orderID && p.Status != string(domain.PaymentStatusFailed) && p.Status != string(domain.PaymentStatusRefunded) {
+			c := *p
+			active = append(active, &c)
+		}
+	}
+	return active, nil
+}
+
+func (r *memPaymentRepo) CompareAndSetStatus(ctx context.Context, id int64, from, to string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.payments[id-1]
+	if p.Status != from {
+		return false, nil
+	}
+	p.Status = to
+	return true, nil
+}
+
+func (r *memPaymentRepo) status(id int64) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.payments[id-1].Status
+}
+
+// memOrders хранит один заказ и переводит его в paid так же, как OrderService:
+// повторный перевод возвращает ErrOrderAlreadyPaid
+type memOrders struct {
+	OrderService
+	mu    sync.Mutex
+	order domain.Order
+}
+
+// memOrderRepo читает заказ из memOrders
+type memOrderRepo struct {
+	repository.OrderRepository
+	orders *memOrders
+}
+
+func (r memOrderRepo) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	return r.orders.GetByID(ctx, id)
+}
+
+func (o *memOrders) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	c := o.order
+	return &c, nil
+}
+
+func (o *memOrders) MarkPaid(ctx context.Context, id, paymentID int64, comment string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.order.Status == string(domain.OrderStatusPaid) {
+		return ErrOrderAlreadyPaid
+	}
+	o.order.Status = string(domain.OrderStatusPaid)
+	o.order.PaymentID = &paymentID
+	return nil
+}
+
+func newTestPaymentService(status domain.OrderStatus) (*PaymentServiceImpl, *memPaymentRepo, *memOrders) {
+	payments := &memPaymentRepo{}
+	orders := &memOrders{order: domain.Order{
+		ID:         1,
+		UserID:     7,
+		TotalPrice: money.Amount(100000),
+		Currency:   "RUB",
+		Status:     string(status),
+	}}
+	svc := NewPaymentService(payments, memOrderRepo{orders: orders}, orders, payment.NewFakeProvider("secret")).(*PaymentServiceImpl)
+	return svc, payments, orders
+}
+
+func TestSimulateAuthorizationRequiresOrderOwner(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, _ := newTestPaymentService(domain.OrderStatusConfirmed)
+
+	intent, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SimulateAuthorization(ctx, 8, intent.ID); !errors.Is(err, repository.ErrPaymentNotFound) {
+		t.Errorf("оплата чужого заказа: %v, ожидалась ErrPaymentNotFound", err)
+	}
+	if got := payments.status(1); got != string(domain.PaymentStatusPending) {
+		t.Errorf("платеж: %s, ожидался pending", got)
+	}
+}
+
+func TestStartCheckoutReusesPendingIntent(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, _ := newTestPaymentService(domain.OrderStatusConfirmed)
+
+	first, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID != second.ID {
+		t.Errorf("второй checkout создал новое намерение %s вместо %s", second.ID, first.ID)
+	}
+	if n := len(payments.payments); n != 1 {
+		t.Errorf("сохранено платежей: %d, ожидался 1", n)
+	}
+}
+
+func TestStartCheckoutRejectsPaidOrder(t *testing.T) {
+	ctx := context.Background()
+	svc, _, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+
+	intent, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SimulateAuthorization(ctx, 7, intent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Статус заказа мог еще не обновиться, но успешный платеж уже есть
+	orders.order.Status = string(domain.OrderStatusConfirmed)
+	if _, err := svc.StartCheckout(ctx, 1); !errors.Is(err, ErrOrderAlreadyPaid) {
+		t.Errorf("повторный checkout оплаченного заказа: %v, ожидалась ErrOrderAlreadyPaid", err)
+	}
+}
+
+func TestSecondAuthorizedPaymentIsRefunded(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+
+	// Два намерения по одному заказу, например из параллельных запросов checkout
+	var ids []string
+	for i := 0; i < 2; i++ {
+		intent, err := svc.provider.CreateIntent(ctx, money.New(orders.order.TotalPrice, "RUB"), "order-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = payments.Create(ctx, &domain.Payment{
+			OrderID:           1,
+			Provider:          svc.provider.Name(),
+			ProviderPaymentID: intent.ID,
+			Amount:            intent.Amount,
+			Currency:          intent.Currency,
+			Status:            string(domain.PaymentStatusPending),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, intent.ID)
+	}
+
+	for _, id := range ids {
+		if err := svc.SimulateAuthorization(ctx, 7, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := payments.status(1); got != string(domain.PaymentStatusSucceeded) {
+		t.Errorf("первый платеж: %s, ожидался succeeded", got)
+	}
+	if got := payments.status(2); got != string(domain.PaymentStatusRefunded) {
+		t.Errorf("второй платеж: %s, ожидался refunded", got)
+	}
+	// Средства по второму намерению не списаны и не могут быть списаны
+	if err := svc.provider.Capture(ctx, ids[1]); err == nil {
+		t.Error("второе намерение осталось доступным для списания")
+	}
+}
+
+func TestPaymentRefundedWhenOrderPaidAfterCheck(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+
+	intent, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Заказ оплачен другим платежом между проверкой статуса и списанием
+	svc.orders = &paidConcurrently{memOrders: orders}
+	if err := svc.SimulateAuthorization(ctx, 7, intent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := payments.status(1); got != string(domain.PaymentStatusRefunded) {
+		t.Errorf("платеж: %s, ожидался refunded", got)
+	}
+}
+
+// paidConcurrently имитирует оплату заказа другим платежом перед MarkPaid
+type paidConcurrently struct {
+	*memOrders
+}
+
+func (o *paidConcurrently) MarkPaid(ctx context.Context, id, paymentID int64, comment string) error {
+	o.mu.Lock()
+	other := paymentID + 100
+	o.order.Status = string(domain.OrderStatusPaid)
+	o.order.PaymentID = &other
+	o.mu.Unlock()
+	return o.memOrders.MarkPaid(ctx, id, paymentID, comment)
+}
+
+// authorizedWebhook создает платеж по заказу и возвращает подписанное событие
+// об его авторизации
+func authorizedWebhook(t *testing.T, svc *PaymentServiceImpl) (string, []byte, string) {
+	t.Helper()
+	ctx := context.Background()
+	intent, err := svc.StartCheckout(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, err := svc.provider.(payment.Simulator).SimulateAuthorization(ctx, intent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return intent.ID, payload, signature
+}
+
+func TestDuplicateWebhookDoesNotRefundPayingPayment(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+	intentID, payload, signature := authorizedWebhook(t, svc)
+
+	for i := 0; i < 2; i++ {
+		if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+			t.Fatalf("доставка %d: %v", i+1, err)
+		}
+	}
+
+	if got := payments.status(1); got != string(domain.PaymentStatusSucceeded) {
+		t.Errorf("платеж: %s, ожидался succeeded", got)
+	}
+	if orders.order.Status != string(domain.OrderStatusPaid) || orders.order.PaymentID == nil || *orders.order.PaymentID != 1 {
+		t.Errorf("заказ: статус %s, платеж %v; ожидался paid платежом 1", orders.order.Status, orders.order.PaymentID)
+	}
+	// Возврат всей суммы возможен, только если средства еще не возвращались
+	if err := svc.provider.Refund(ctx, intentID, orders.order.TotalPrice); err != nil {
+		t.Errorf("средства по оплатившему платежу уже возвращены: %v", err)
+	}
+}
+
+func TestConcurrentDuplicateWebhooks(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+	intentID, payload, signature := authorizedWebhook(t, svc)
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+				t.Errorf("доставка: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := payments.status(1); got != string(domain.PaymentStatusSucceeded) {
+		t.Errorf("платеж: %s, ожидался succeeded", got)
+	}
+	if err := svc.provider.Refund(ctx, intentID, orders.order.TotalPrice); err != nil {
+		t.Errorf("средства по оплатившему платежу уже возвращены: %v", err)
+	}
+}
+
+func TestPayingPaymentIsNeverRefunded(t *testing.T) {
+	ctx := context.Background()
+	svc, payments, orders := newTestPaymentService(domain.OrderStatusConfirmed)
+	intentID, payload, signature := authorizedWebhook(t, svc)
+
+	// Заказ уже переведен в paid этим платежом, но статус платежа не успел обновиться
+	paymentID := int64(1)
+	orders.order.Status = string(domain.OrderStatusPaid)
+	orders.order.PaymentID = &paymentID
+
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := payments.status(1); got != string(domain.PaymentStatusSucceeded) {
+		t.Errorf("платеж: %s, ожидался succeeded", got)
+	}
+	if err := svc.provider.Refund(ctx, intentID, orders.order.TotalPrice); err != nil {
+		t.Errorf("средства по оплатившему платежу возвращены: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// PricingRuleServiceImpl реализация сервиса правил ценообразования
+type PricingRuleServiceImpl struct {
+	ruleRepo repository.PricingRuleRepository
+	tourRepo repository.TourRepository
+}
+
+// NewPricingRuleService создает новый сервис правил ценообразования
+func NewPricingRuleService(ruleRepo repository.PricingRuleRepository, tourRepo repository.TourRepository) PricingRuleService {
+	return &PricingRuleServiceImpl{
+		ruleRepo: ruleRepo,
+		tourRepo: tourRepo,
+	}
+}
+
+// Create создает правило ценообразования
+func (s *PricingRuleServiceImpl) Create(ctx context.Context, rule *domain.PricingRule) (int64, error) {
+	if err := normalizePricingRule(rule); err != nil {
+		return 0, err
+	}
+	return s.ruleRepo.Create(ctx, rule)
+}
+
+// GetByID получает правило ценообразования по ID
+func (s *PricingRuleServiceImpl) GetByID(ctx context.Context, id int64) (*domain.PricingRule, error) {
+	return s.ruleRepo.GetByID(ctx, id)
+}
+
+// Update обновляет правило ценообразования
+func (s *PricingRuleServiceImpl) Update(ctx context.Context, rule *domain.PricingRule) error {
+	if err := normalizePricingRule(rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Update(ctx, rule)
+}
+
+// Delete удаляет правило ценообразования
+func (s *PricingRuleServiceImpl) Delete(ctx context.Context, id int64) error {
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// List возвращает все правила ценообразования
+func (s *PricingRuleServiceImpl) List(ctx context.Context) ([]*domain.PricingRule, error) {
+	return s.ruleRepo.List(ctx)
+}
+
+// Preview рассчитывает модификаторы цены для предстоящих дат тура без их
+// сохранения. При includeInactive учитываются и выключенные правила, что
+// позволяет оценить правило до его включения.
+func (s *PricingRuleServiceImpl) Preview(ctx context.Context, tourID int64, includeInactive bool) ([]*domain.PriceModifierPreview, error) {
+	var rules []*domain.PricingRule
+	var err error
+	if includeInactive {
+		rules, err = s.ruleRepo.List(ctx)
+	} else {
+		rules, err = s.ruleRepo.ListActive(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tour, err := s.tourRepo.GetByID(ctx, tourID)
+	if err != nil {
+		return nil, err
+	}
+
+	return previewTourDates(rules, tour, time.Now()), nil
+}
+
+// Apply пересчитывает и сохраняет модификаторы цены предстоящих дат по
+// активным правилам. Если tourID не задан, обрабатываются все туры.
+// Возвращает количество дат, модификатор которых изменился.
+func (s *PricingRuleServiceImpl) Apply(ctx context.Context, tourID *int64) (int, error) {
+	rules, err := s.ruleRepo.ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	tourIDs := []int64{}
+	if tourID != nil {
+		tourIDs = append(tourIDs, *tourID)
+	} else {
+		tourIDs, err = s.tourRepo.ListUpcomingTourIDs(ctx, startOfDay(now))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	updated := 0
+	for _, id := range tourIDs {
+		tour, err := s.tourRepo.GetByID(ctx, id)
+		if err != nil {
+			return updated, err
+		}
+
+		for _, preview := range previewTourDates(rules, tour, now) {
+			if preview.Modifier == preview.CurrentModifier {
+				continue
+			}
+			if err := s.tourRepo.UpdatePriceModifier(ctx, preview.TourDateID, preview.Modifier); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+// previewTourDates рассчитывает модификаторы для дат тура, которые начинаются не раньше сегодняшнего дня
+func previewTourDates(rules []*domain.PricingRule, tour *domain.Tour, now time.Time) []*domain.PriceModifierPreview {
+	today := startOfDay(now)
+
+	previews := make([]*domain.PriceModifierPreview, 0, len(tour.TourDates))
+	for _, date := range tour.TourDates {
+		if startOfDay(date.StartDate).Before(today) {
+			continue
+		}
+
+		modifier, matches := evaluatePricingRules(rules, tour, date, today)
+		previews = append(previews, &domain.PriceModifierPreview{
+			TourDateID:      date.ID,
+			TourID:          tour.ID,
+			StartDate:       date.StartDate,
+			Capacity:        date.Capacity,
+			Availability:    date.Availability,
+			BaseModifier:    date.BaseModifier,
+			CurrentModifier: date.PriceModifier,
+			Modifier:        modifier,
+			Rules:           matches,
+		})
+	}
+
+	return previews
+}
+
+// evaluatePricingRules перемножает базовый модификатор даты с модификаторами
+// всех подходящих правил. Результат округляется до точности колонки
+// price_modifier и ограничивается ее допустимым диапазоном.
+func evaluatePricingRules(rules []*domain.PricingRule, tour *domain.Tour, date *domain.TourDate, today time.Time) (money.Ratio, []domain.PricingRuleMatch) {
+	modifier := date.BaseModifier
+	if modifier <= 0 {
+		modifier = money.One
+	}
+
+	matches := []domain.PricingRuleMatch{}
+	for _, rule := range rules {
+		if !pricingRuleInScope(rule, tour) || !pricingRuleMatches(rule, date, today) {
+			continue
+		}
+		modifier = modifier.Mul(rule.Modifier)
+		matches = append(matches, domain.PricingRuleMatch{
+			RuleID:   rule.ID,
+			Name:     rule.Name,
+			Kind:     rule.Kind,
+			Modifier: rule.Modifier,
+		})
+	}
+
+	modifier = modifier.Round(priceModifierStep)
+	switch {
+	case modifier < priceModifierStep:
+		modifier = priceModifierStep
+	case modifier >= maxPriceModifier:
+		modifier = maxPriceModifier - priceModifierStep
+	}
+
+	return modifier, matches
+}
+
+// pricingRuleInScope проверяет, что тур находится в стране и городе, заданных правилом
+func pricingRuleInScope(rule *domain.PricingRule, tour *domain.Tour) bool {
+	if rule.CityID != nil && *rule.CityID != tour.CityID {
+		return false
+	}
+	if rule.CountryID != nil {
+		if tour.City == nil || tour.City.Country == nil || tour.City.Country.ID != *rule.CountryID {
+			return false
+		}
+	}
+	return true
+}
+
+// pricingRuleMatches проверяет условие правила для даты тура
+func pricingRuleMatches(rule *domain.PricingRule, date *domain.TourDate, today time.Time) bool {
+	start := startOfDay(date.StartDate)
+
+	switch rule.Kind {
+	case domain.PricingRuleSeason:
+		return rule.SeasonStart != nil && rule.SeasonEnd != nil &&
+			!start.Before(startOfDay(*rule.SeasonStart)) && !start.After(startOfDay(*rule.SeasonEnd))
+	case domain.PricingRuleWeekend:
+		return start.Weekday() == time.Saturday || start.Weekday() == time.Sunday
+	case domain.PricingRuleOccupancy:
+		return rule.AvailabilityBelow != nil && date.Capacity > 0 &&
+			date.Availability*100 < *rule.AvailabilityBelow*date.Capacity
+	case domain.PricingRuleLastMinute:
+		// Округление сглаживает переход на летнее время
+		days := int(math.Round(start.Sub(today).Hours() / 24))
+		return rule.DaysBefore != nil && days >= 0 && days <= *rule.DaysBefore
+	default:
+		return false
+	}
+}
+
+// normalizePricingRule проверяет параметры правила и сбрасывает поля, не относящиеся к его виду
+func normalizePricingRule(rule *domain.PricingRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPricingRule)
+	}
+	if !validPriceModifier(rule.Modifier) {
+		return fmt.Errorf("%w: modifier must be in (0, 1000) with at most 2 decimal places", ErrInvalidPricingRule)
+	}
+
+	if rule.Kind != domain.PricingRuleSeason {
+		rule.SeasonStart, rule.SeasonEnd = nil, nil
+	}
+	if rule.Kind != domain.PricingRuleOccupancy {
+		rule.AvailabilityBelow = nil
+	}
+	if rule.Kind != domain.PricingRuleLastMinute {
+		rule.DaysBefore = nil
+	}
+
+	switch rule.Kind {
+	case domain.PricingRuleSeason:
+		if rule.SeasonStart == nil || rule.SeasonEnd == nil {
+			return fmt.Errorf("%w: season_start and season_end are required", ErrInvalidPricingRule)
+		}
+		if rule.SeasonEnd.Before(*rule.SeasonStart) {
+			return fmt.Errorf("%w: season_end must not be before season_start", ErrInvalidPricingRule)
+		}
+	case domain.PricingRuleWeekend:
+	case domain.PricingRuleOccupancy:
+		if rule.AvailabilityBelow == nil || *rule.AvailabilityBelow < 1 || *rule.AvailabilityBelow > 100 {
+			return fmt.Errorf("%w: availability_below must be a percentage from 1 to 100", ErrInvalidPricingRule)
+		}
+	case domain.PricingRuleLastMinute:
+		if rule.DaysBefore == nil || *rule.DaysBefore < 0 {
+			return fmt.Errorf("%w: days_before must not be negative", ErrInvalidPricingRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPricingRule, rule.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+)
+
+// TestOccupancyRuleMatchesLowAvailability проверяет, что правило occupancy
+// срабатывает, когда свободных мест осталось меньше порога, а не когда их много
+func TestOccupancyRuleMatchesLowAvailability(t *testing.T) {
+	threshold := 20
+	rule := &domain.PricingRule{Kind: domain.PricingRuleOccupancy, AvailabilityBelow: &threshold}
+	today := time.Now()
+
+	cases := []struct {
+		availability int
+		want         bool
+	}{
+		{100, false},
+		{20, false}, // ровно на пороге правило еще не действует
+		{19, true},
+		{0, true},
+	}
+	for _, tc := range cases {
+		date := &domain.TourDate{StartDate: today.AddDate(0, 1, 0), Capacity: 100, Availability: tc.availability}
+		if got := pricingRuleMatches(rule, date, today); got != tc.want {
+			t.Errorf("свободно %d из 100: %v, ожидалось %v", tc.availability, got, tc.want)
+		}
+	}
+}
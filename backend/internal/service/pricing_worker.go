@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultPricingInterval периодичность пересчета модификаторов цены, если она не задана в конфигурации
+const DefaultPricingInterval = 15 * time.Minute
+
+// PricingWorker фоновый обработчик, пересчитывающий модификаторы цены дат туров.
+// Правила по заполненности и срокам до отправления зависят от времени и
+// бронирований, поэтому модификаторы обновляются периодически.
+type PricingWorker struct {
+	pricing  PricingRuleService
+	interval time.Duration
+}
+
+// NewPricingWorker создает новый обработчик пересчета цен
+func NewPricingWorker(pricing PricingRuleService, interval time.Duration) *PricingWorker {
+	if interval <= 0 {
+		interval = DefaultPricingInterval
+	}
+
+	return &PricingWorker{
+		pricing:  pricing,
+		interval: interval,
+	}
+}
+
+// Run периодически пересчитывает модификаторы цены до отмены контекста
+func (w *PricingWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("[PricingWorker] Запущен, интервал пересчета: %s", w.interval)
+
+	for {
+		w.apply(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[PricingWorker] Остановлен")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply выполняет один пересчет модификаторов по всем турам
+func (w *PricingWorker) apply(ctx context.Context) {
+	count, err := w.pricing.Apply(ctx, nil)
+	if err != nil {
+		log.Printf("[PricingWorker] Ошибка при пересчете модификаторов цены: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("[PricingWorker] Обновлено модификаторов цены: %d", count)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// maxPromotionPercent максимальный размер процентной скидки
+var maxPromotionPercent = money.One * 100
+
+// PromotionServiceImpl реализация сервиса управления промоакциями
+type PromotionServiceImpl struct {
+	promoRepo repository.PromotionRepository
+}
+
+// NewPromotionService создает новый сервис промоакций
+func NewPromotionService(promoRepo repository.PromotionRepository) PromotionService {
+	return &PromotionServiceImpl{
+		promoRepo: promoRepo,
+	}
+}
+
+// Create создает новую промоакцию
+func (s *PromotionServiceImpl) Create(ctx context.Context, promo *domain.Promotion) (int64, error) {
+	if err := normalizePromotion(promo); err != nil {
+		return 0, err
+	}
+	return s.promoRepo.Create(ctx, promo)
+}
+
+// GetByID получает промоакцию по ID
+func (s *PromotionServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Promotion, error) {
+	return s.promoRepo.GetByID(ctx, id)
+}
+
+// Update обновляет промоакцию
+func (s *PromotionServiceImpl) Update(ctx context.Context, promo *domain.Promotion) error {
+	if err := normalizePromotion(promo); err != nil {
+		return err
+	}
+	return s.promoRepo.Update(ctx, promo)
+}
+
+// Delete удаляет промоакцию
+func (s *PromotionServiceImpl) Delete(ctx context.Context, id int64) error {
+	return s.promoRepo.Delete(ctx, id)
+}
+
+// List возвращает страницу списка промоакций и их общее количество
+func (s *PromotionServiceImpl) List(ctx context.Context, page, size int) ([]*domain.Promotion, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	promos, err := s.promoRepo.List(ctx, (page-1)*size, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, err := s.promoRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promos, totalCount, nil
+}
+
+// normalizePromotion приводит промокод к верхнему регистру и проверяет параметры скидки
+func normalizePromotion(promo *domain.Promotion) error {
+	if promo.Code != nil {
+		code := normalizePromoCode(*promo.Code)
+		if code == "" {
+			promo.Code = nil
+		} else {
+			promo.Code = &code
+		}
+	}
+	if promo.Target == "" {
+		promo.Target = domain.PromotionTargetOrder
+	}
+
+	switch {
+	case strings.TrimSpace(promo.Title) == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidPromotion)
+	case promo.Target != domain.PromotionTargetOrder && promo.Target != domain.PromotionTargetChildren:
+		return fmt.Errorf("%w: unknown target %q", ErrInvalidPromotion, promo.Target)
+	case promo.MinPeople < 0 || promo.EarlyBirdDays < 0:
+		return fmt.Errorf("%w: min_people and early_bird_days must not be negative", ErrInvalidPromotion)
+	case promo.MaxUses != nil && *promo.MaxUses < 1, promo.MaxUsesPerUser != nil && *promo.MaxUsesPerUser < 1:
+		return fmt.Errorf("%w: usage limits must be positive", ErrInvalidPromotion)
+	case promo.StartsAt != nil && promo.EndsAt != nil && !promo.EndsAt.After(*promo.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	switch promo.DiscountType {
+	case domain.PromotionTypePercent:
+		// Процент делится на 100 без потери точности только при четырех знаках после запятой
+		if promo.Percent <= 0 || promo.Percent > maxPromotionPercent || promo.Percent%100 != 0 {
+			return fmt.Errorf("%w: percent must be in (0, 100] with at most 4 decimal places", ErrInvalidPromotion)
+		}
+		promo.Amount = 0
+	case domain.PromotionTypeFixed:
+		if promo.Amount <= 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidPromotion)
+		}
+		promo.Percent = 0
+	default:
+		return fmt.Errorf("%w: unknown discount type %q", ErrInvalidPromotion, promo.DiscountType)
+	}
+
+	return nil
+}
+
+// normalizePromoCode приводит промокод к каноническому виду
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// promotionActive проверяет, что промоакция включена и действует в момент now
+func promotionActive(promo *domain.Promotion, now time.Time) bool {
+	if !promo.IsActive {
+		return false
+	}
+	if promo.StartsAt != nil && now.Before(*promo.StartsAt) {
+		return false
+	}
+	if promo.EndsAt != nil && !now.Before(*promo.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// promotionApplies проверяет условия правила скидки для заказа: тур,
+// размер группы, срок до начала тура и наличие детей
+func promotionApplies(promo *domain.Promotion, req *domain.OrderRequest, tourDate *domain.TourDate, now time.Time) bool {
+	if promo.TourID != nil && *promo.TourID != req.TourID {
+		return false
+	}
+	if promo.MinPeople > 0 && req.PeopleCount < promo.MinPeople {
+		return false
+	}
+	if promo.EarlyBirdDays > 0 && tourDate.StartDate.Sub(now) < time.Duration(promo.EarlyBirdDays)*24*time.Hour {
+		return false
+	}
+	if promo.Target == domain.PromotionTargetChildren && req.ChildrenCount == 0 {
+		return false
+	}
+	return true
+}
+
+// promotionDiscount рассчитывает размер скидки в валюте расшифровки стоимости.
+// Скидка не превышает стоимость той части заказа, к которой она применяется.
+func promotionDiscount(promo *domain.Promotion, req *domain.OrderRequest, breakdown *domain.PriceBreakdown, converter *money.Converter) (money.Amount, error) {
+	base := breakdown.TourAmount + breakdown.RoomAmount
+	units := 1
+	if promo.Target == domain.PromotionTargetChildren {
+		// Детский тариф снижает стоимость тура для каждого ребенка
+		base = breakdown.BasePrice.Mul(breakdown.PriceModifier).Times(req.ChildrenCount)
+		units = req.ChildrenCount
+	}
+
+	var discount money.Amount
+	switch promo.DiscountType {
+	case domain.PromotionTypePercent:
+		discount = base.Mul(promo.Percent / 100)
+	case domain.PromotionTypeFixed:
+		amount, err := converter.Convert(money.New(promo.Amount, promo.Currency), breakdown.Currency)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при пересчете скидки %q: %w", promo.Title, err)
+		}
+		discount = amount.Amount.Times(units)
+	}
+
+	if discount > base {
+		discount = base
+	}
+	return discount, nil
+}
+
+// promotionLimitReached проверяет общий лимит применений и лимит для пользователя
+func promotionLimitReached(promo *domain.Promotion, total, perUser int) bool {
+	if promo.MaxUses != nil && total >= *promo.MaxUses {
+		return true
+	}
+	if promo.MaxUsesPerUser != nil && perUser >= *promo.MaxUsesPerUser {
+		return true
+	}
+	return false
+}
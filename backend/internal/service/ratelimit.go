@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+)
+
+// Действия, частота которых ограничивается
+const (
+	RateLimitLogin        = "login"         // попытки входа с одного IP
+	RateLimitTwoFactor    = "two_factor"    // ввод кодов второго фактора с одного IP или пользователем
+	RateLimitRegister     = "register"      // регистрации с одного IP
+	RateLimitAccountEmail = "account_email" // письма подтверждения и сброса пароля с одного IP
+	RateLimitTicketCreate = "ticket_create" // создание тикетов одним пользователем
+	RateLimitOrderCreate  = "order_create"  // создание заказов одним пользователем
+)
+
+// defaultRateLimits лимиты действий, не заданные в конфигурации
+var defaultRateLimits = map[string]ratelimit.Rule{
+	RateLimitLogin:        {Limit: 20, Window: 5 * time.Minute},
+	RateLimitTwoFactor:    {Limit: 20, Window: 5 * time.Minute},
+	RateLimitRegister:     {Limit: 5, Window: time.Hour},
+	RateLimitAccountEmail: {Limit: 5, Window: 15 * time.Minute},
+	RateLimitTicketCreate: {Limit: 5, Window: 10 * time.Minute},
+	RateLimitOrderCreate:  {Limit: 10, Window: 10 * time.Minute},
+}
+
+// DefaultLockoutPolicy защита от подбора пароля по умолчанию
+var DefaultLockoutPolicy = ratelimit.LockoutPolicy{
+	Window:          15 * time.Minute,
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        30 * time.Second,
+	MaxFailures:     10,
+	LockoutDuration: 15 * time.Minute,
+}
+
+// RateLimitServiceImpl реализация сервиса ограничения частоты действий
+type RateLimitServiceImpl struct {
+	limiter *ratelimit.Limiter
+	rules   map[string]ratelimit.Rule
+}
+
+// NewRateLimitService создает сервис ограничения частоты с лимитами из конфигурации
+func NewRateLimitService(limiter *ratelimit.Limiter, cfg config.RateLimitConfig) RateLimitService {
+	configured := map[string]config.RateLimitRule{
+		RateLimitLogin:        cfg.Login,
+		RateLimitTwoFactor:    cfg.TwoFactor,
+		RateLimitRegister:     cfg.Register,
+		RateLimitAccountEmail: cfg.AccountEmail,
+		RateLimitTicketCreate: cfg.Tickets,
+		RateLimitOrderCreate:  cfg.Orders,
+	}
+
+	rules := make(map[string]ratelimit.Rule, len(defaultRateLimits))
+	for action, rule := range defaultRateLimits {
+		rule.Name = action
+		if c := configured[action]; c.Limit != 0 {
+			rule.Limit = c.Limit // отрицательный лимит снимает ограничение
+			if c.Window > 0 {
+				rule.Window = time.Duration(c.Window) * time.Second
+			}
+		}
+		rules[action] = rule
+	}
+
+	return &RateLimitServiceImpl{limiter: limiter, rules: rules}
+}
+
+// Allow учитывает действие по ключу, если лимит не исчерпан
+func (s *RateLimitServiceImpl) Allow(ctx context.Context, action, key string) (ratelimit.Result, error) {
+	rule, ok := s.rules[action]
+	if !ok {
+		return ratelimit.Result{}, fmt.Errorf("неизвестное действие для ограничения частоты: %s", action)
+	}
+	return s.limiter.Allow(ctx, rule, key)
+}
+
+// newLockoutPolicy применяет значения по умолчанию к настройкам блокировки из конфигурации
+func newLockoutPolicy(cfg config.LockoutConfig) ratelimit.LockoutPolicy {
+	policy := DefaultLockoutPolicy
+	if cfg.Window > 0 {
+		policy.Window = time.Duration(cfg.Window) * time.Minute
+	}
+	if cfg.FreeAttempts > 0 {
+		policy.FreeAttempts = cfg.FreeAttempts
+	}
+	if cfg.BaseDelay > 0 {
+		policy.BaseDelay = time.Duration(cfg.BaseDelay) * time.Second
+	}
+	if cfg.MaxDelay > 0 {
+		policy.MaxDelay = time.Duration(cfg.MaxDelay) * time.Second
+	}
+	if cfg.MaxFailures > 0 {
+		policy.MaxFailures = cfg.MaxFailures
+	}
+	if cfg.Duration > 0 {
+		policy.LockoutDuration = time.Duration(cfg.Duration) * time.Minute
+	}
+	return policy
+}
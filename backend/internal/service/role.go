@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+)
+
+// maxRoleNameLength максимальная длина названия роли
+const maxRoleNameLength = 50
+
+// RoleServiceImpl реализация сервиса управления ролями
+type RoleServiceImpl struct {
+	roleRepo repository.RoleRepository
+}
+
+// NewRoleService создает новый сервис ролей
+func NewRoleService(roleRepo repository.RoleRepository) RoleService {
+	return &RoleServiceImpl{roleRepo: roleRepo}
+}
+
+// List возвращает все роли с правами
+func (s *RoleServiceImpl) List(ctx context.Context) ([]*domain.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+// GetByID получает роль с правами по ID
+func (s *RoleServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Role, error) {
+	return s.roleRepo.GetByID(ctx, id)
+}
+
+// GetDefault получает роль, назначаемую при регистрации
+func (s *RoleServiceImpl) GetDefault(ctx context.Context) (*domain.Role, error) {
+	return s.roleRepo.GetDefault(ctx)
+}
+
+// Create создает роль с правами
+func (s *RoleServiceImpl) Create(ctx context.Context, role *domain.Role) (int64, error) {
+	if err := s.normalize(ctx, role); err != nil {
+		return 0, err
+	}
+	return s.roleRepo.Create(ctx, role)
+}
+
+// Update обновляет роль. Права системных ролей меняются, название - нет.
+func (s *RoleServiceImpl) Update(ctx context.Context, role *domain.Role) error {
+	current, err := s.roleRepo.GetByID(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.normalize(ctx, role); err != nil {
+		return err
+	}
+	if current.IsSystem && role.Name != current.Name {
+		return ErrSystemRole
+	}
+	return s.roleRepo.Update(ctx, role)
+}
+
+// Delete удаляет несистемную роль, не назначенную пользователям
+func (s *RoleServiceImpl) Delete(ctx context.Context, id int64) error {
+	current, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.IsSystem || current.IsDefault {
+		return ErrSystemRole
+	}
+	return s.roleRepo.Delete(ctx, id)
+}
+
+// ListPermissions возвращает справочник прав
+func (s *RoleServiceImpl) ListPermissions(ctx context.Context) ([]*domain.PermissionInfo, error) {
+	return s.roleRepo.ListPermissions(ctx)
+}
+
+// normalize проверяет название роли и сверяет права со справочником;
+// повторяющиеся права отбрасываются
+func (s *RoleServiceImpl) normalize(ctx context.Context, role *domain.Role) error {
+	role.Name = strings.TrimSpace(role.Name)
+	role.Description = strings.TrimSpace(role.Description)
+	if role.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRole)
+	}
+	if utf8.RuneCountInString(role.Name) > maxRoleNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidRole, maxRoleNameLength)
+	}
+
+	known, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	valid := make(map[domain.Permission]bool, len(known))
+	for _, p := range known {
+		valid[p.Name] = true
+	}
+
+	seen := make(map[domain.Permission]bool, len(role.Permissions))
+	permissions := make([]domain.Permission, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		if !valid[p] {
+			return fmt.Errorf("%w: unknown permission %q", ErrInvalidRole, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+	role.Permissions = permissions
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/search"
+)
+
+// Ограничения выдачи полнотекстового поиска
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	// maxSearchFilterResults сколько найденных туров учитывается при фильтрации списка туров по запросу
+	maxSearchFilterResults = 1000
+)
+
+// Поля поискового документа тура и их веса: совпадение в названии тура
+// важнее совпадения в описании или в названии отеля
+var tourSearchFields = []struct {
+	name   string
+	weight float64
+}{
+	{"name", 3},
+	{"city", 2},
+	{"country", 2},
+	{"hotels", 1.5},
+	{"description", 1},
+}
+
+// SearchServiceImpl реализация полнотекстового поиска туров поверх search.Index
+type SearchServiceImpl struct {
+	index    search.Index
+	tourRepo repository.TourRepository
+}
+
+// NewSearchService создает новый сервис поиска туров
+func NewSearchService(index search.Index, tourRepo repository.TourRepository) SearchService {
+	return &SearchServiceImpl{
+		index:    index,
+		tourRepo: tourRepo,
+	}
+}
+
+// Search ищет активные туры по запросу и возвращает их в порядке релевантности
+func (s *SearchServiceImpl) Search(ctx context.Context, query string, limit int) ([]*domain.TourSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidSearchQuery)
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	hits := s.index.Search(query, limit)
+	results := make([]*domain.TourSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		tour, err := s.tourRepo.GetByID(ctx, hit.ID)
+		if err != nil && !errors.Is(err, repository.ErrTourNotFound) {
+			return nil, err
+		}
+		// Индекс мог не успеть узнать об удалении или деактивации тура:
+		// такой документ пропускается и удаляется из индекса
+		if tour == nil || !tour.IsActive {
+			log.Printf("[SearchService] Тур %d из индекса не найден среди активных, документ удален", hit.ID)
+			s.index.Delete(hit.ID)
+			continue
+		}
+		results = append(results, &domain.TourSearchResult{
+			Tour:       tour,
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	return results, nil
+}
+
+// MatchIDs возвращает ID туров, подходящих под запрос, в порядке релевантности
+func (s *SearchServiceImpl) MatchIDs(query string) []int64 {
+	hits := s.index.Search(query, maxSearchFilterResults)
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+// Rebuild заново строит индекс по всем активным турам и возвращает число проиндексированных туров
+func (s *SearchServiceImpl) Rebuild(ctx context.Context) (int, error) {
+	sources, err := s.tourRepo.ListForSearch(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	docs := make([]search.Document, 0, len(sources))
+	for _, src := range sources {
+		docs = append(docs, tourSearchDocument(src))
+	}
+	s.index.Replace(docs)
+
+	return len(docs), nil
+}
+
+// IndexTour обновляет документ тура в индексе; неактивный тур удаляется из индекса
+func (s *SearchServiceImpl) IndexTour(ctx context.Context, tourID int64) error {
+	sources, err := s.tourRepo.ListForSearch(ctx, &tourID)
+	if err != nil {
+		return err
+	}
+
+	if len(sources) == 0 {
+		s.index.Delete(tourID)
+		return nil
+	}
+	s.index.Put(tourSearchDocument(sources[0]))
+	return nil
+}
+
+// RemoveTour удаляет тур из индекса
+func (s *SearchServiceImpl) RemoveTour(tourID int64) {
+	s.index.Delete(tourID)
+}
+
+// tourSearchDocument собирает поисковый документ тура
+func tourSearchDocument(src *domain.TourSearchSource) search.Document {
+	texts := map[string]string{
+		"name":        src.Name,
+		"city":        src.City,
+		"country":     src.Country,
+		"hotels":      src.Hotels,
+		"description": src.Description,
+	}
+
+	doc := search.Document{ID: src.TourID}
+	for _, f := range tourSearchFields {
+		doc.Fields = append(doc.Fields, search.Field{Name: f.name, Text: texts[f.name], Weight: f.weight})
+	}
+	return doc
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/search"
+)
+
+// memSearchTours отдает туры по ID; отсутствующие в карте считаются удаленными
+type memSearchTours struct {
+	repository.TourRepository
+	tours map[int64]*domain.Tour
+}
+
+func (r memSearchTours) GetByID(ctx context.Context, id int64) (*domain.Tour, error) {
+	tour, ok := r.tours[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: ID %d", repository.ErrTourNotFound, id)
+	}
+	return tour, nil
+}
+
+// TestSearchSkipsStaleHits проверяет, что удаленные и неактивные туры из
+// устаревшего индекса пропускаются и удаляются из него
+func TestSearchSkipsStaleHits(t *testing.T) {
+	index := search.NewMemoryIndex()
+	for id := int64(1); id <= 3; id++ {
+		index.Put(tourSearchDocument(&domain.TourSearchSource{TourID: id, Name: "Байкал зимой"}))
+	}
+	tours := memSearchTours{tours: map[int64]*domain.Tour{
+		1: {ID: 1, Name: "Байкал зимой", IsActive: true},
+		3: {ID: 3, Name: "Байкал зимой", IsActive: false},
+	}}
+	svc := NewSearchService(index, tours)
+
+	results, err := svc.Search(context.Background(), "байкал", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Tour.ID != 1 {
+		t.Fatalf("результаты: %+v, ожидался только тур 1", results)
+	}
+
+	hits := index.Search("байкал", 10)
+	if len(hits) != 1 || hits[0].ID != 1 {
+		t.Errorf("в индексе остались устаревшие документы: %+v", hits)
+	}
+}
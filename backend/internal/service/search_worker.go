@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultSearchRebuildInterval периодичность переиндексации туров, если она не задана в конфигурации
+const DefaultSearchRebuildInterval = 30 * time.Minute
+
+// SearchIndexWorker фоновый обработчик, перестраивающий поисковый индекс туров.
+// Первый проход заполняет индекс при запуске; последующие подхватывают
+// изменения отелей, городов и стран, которые не обновляют индекс сразу.
+type SearchIndexWorker struct {
+	search   SearchService
+	interval time.Duration
+}
+
+// NewSearchIndexWorker создает новый обработчик переиндексации
+func NewSearchIndexWorker(search SearchService, interval time.Duration) *SearchIndexWorker {
+	if interval <= 0 {
+		interval = DefaultSearchRebuildInterval
+	}
+
+	return &SearchIndexWorker{
+		search:   search,
+		interval: interval,
+	}
+}
+
+// Run периодически перестраивает индекс до отмены контекста
+func (w *SearchIndexWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("[SearchIndexWorker] Запущен, интервал переиндексации: %s", w.interval)
+
+	for {
+		w.rebuild(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[SearchIndexWorker] Остановлен")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebuild выполняет одну полную переиндексацию
+func (w *SearchIndexWorker) rebuild(ctx context.Context) {
+	count, err := w.search.Rebuild(ctx)
+	if err != nil {
+		log.Printf("[SearchIndexWorker] Ошибка при переиндексации туров: %v", err)
+		return
+	}
+
+	log.Printf("[SearchIndexWorker] Проиндексировано туров: %d", count)
+}
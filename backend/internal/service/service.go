@@ -2,10 +2,19 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
 	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/cache"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/mail"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/payment"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pricing"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/search"
 )
 
 // Service содержит все сервисы приложения
@@ -13,25 +22,79 @@ type Service struct {
 	User          UserService
 	Auth          AuthService
 	Tour          TourService
+	Search        SearchService
+	TourSchedule  TourScheduleService
+	PricingRule   PricingRuleService
 	Hotel         HotelService
 	Order         OrderService
+	Payment       PaymentService
+	Promotion     PromotionService
+	Currency      CurrencyService
 	SupportTicket SupportTicketService
 	City          CityService
 	Country       CountryService
+	Cache         CacheService
+	Role          RoleService
+	RateLimit     RateLimitService
+	TwoFactor     TwoFactorService
 }
 
 // NewService создает новый экземпляр Service
-func NewService(repos *repository.Repository, tokenManager auth.TokenManager) *Service {
+func NewService(repos *repository.Repository, tokenManager auth.TokenManager, mailer mail.Mailer, limiter *ratelimit.Limiter, paymentProvider payment.PaymentProvider, converter *money.Converter, searchIndex search.Index, caches *cache.Registry, cfg *config.Config) (*Service, error) {
+	quoteSecret := cfg.Orders.QuoteSecret
+	if quoteSecret == "" {
+		quoteSecret = cfg.JWT.Secret
+	}
+	quoteTTL := time.Duration(cfg.Orders.QuoteTTL) * time.Minute
+	if quoteTTL <= 0 {
+		quoteTTL = DefaultQuoteTTL
+	}
+	quotes := pricing.NewJWTQuoteSigner(quoteSecret, quoteTTL)
+
+	searchService := NewSearchService(searchIndex, repos.Tour)
+
+	tourCache := caches.Namespace(tourCacheNamespace, cacheTTL(cfg.Cache.TourTTL, DefaultTourCacheTTL))
+	hotelCache := caches.Namespace(hotelCacheNamespace, cacheTTL(cfg.Cache.HotelTTL, DefaultHotelCacheTTL))
+	catalogTTL := cacheTTL(cfg.Cache.CatalogTTL, DefaultCatalogCacheTTL)
+	roleService := NewCachedRoleService(NewRoleService(repos.Role), caches.Namespace(roleCacheNamespace, cacheTTL(cfg.Cache.RoleTTL, DefaultRoleCacheTTL)))
+
+	lockout := newLockoutPolicy(cfg.RateLimit.Lockout)
+	twoFactorService, err := NewTwoFactorService(repos.TwoFactor, repos.User, repos.RefreshToken, cfg.TwoFactor, cfg.JWT.Secret, limiter, lockout)
+	if err != nil {
+		return nil, err
+	}
+
+	refunder := NewProviderRefunder(repos.Payment, paymentProvider)
+	orderService := NewOrderService(repos.Order, repos.Tour, repos.User, repos.Room, repos.Promotion, quotes, converter, time.Duration(cfg.Orders.HoldTTL)*time.Minute, refunder)
+
 	return &Service{
-		User:          NewUserService(repos.User),
-		Auth:          NewAuthService(repos.User, tokenManager),
-		Tour:          NewTourService(repos.Tour),
-		Hotel:         NewHotelService(repos.Hotel, repos.Room),
-		Order:         NewOrderService(repos.Order, repos.Tour, repos.User, repos.Room),
+		User:          NewUserService(repos.User, repos.RefreshToken),
+		Auth:          NewAuthService(repos.User, repos.RefreshToken, repos.AccountToken, roleService, twoFactorService, tokenManager, mailer, cfg.Account, limiter, lockout),
+		Tour:          NewCachedTourService(NewTourService(repos.Tour, searchService), tourCache),
+		Search:        searchService,
+		TourSchedule:  NewTourScheduleService(repos.TourSchedule, repos.Tour, time.Duration(cfg.Schedule.HorizonDays)*24*time.Hour),
+		PricingRule:   NewPricingRuleService(repos.PricingRule, repos.Tour),
+		Hotel:         NewCachedHotelService(NewHotelService(repos.Hotel, repos.Room), hotelCache, tourCache),
+		Order:         orderService,
+		Payment:       NewPaymentService(repos.Payment, repos.Order, orderService, paymentProvider),
+		Promotion:     NewPromotionService(repos.Promotion),
+		Currency:      NewCurrencyService(converter),
 		SupportTicket: NewSupportTicketService(repos.SupportTicket, repos.User),
-		City:          NewCityService(repos.City),
-		Country:       NewCountryService(repos.Country),
+		City:          NewCachedCityService(NewCityService(repos.City), caches.Namespace(cityCacheNamespace, catalogTTL)),
+		Country:       NewCachedCountryService(NewCountryService(repos.Country), caches.Namespace(countryCacheNamespace, catalogTTL)),
+		Cache:         caches,
+		Role:          roleService,
+		RateLimit:     NewRateLimitService(limiter, cfg.RateLimit),
+		TwoFactor:     twoFactorService,
+	}, nil
+}
+
+// cacheTTL переводит срок жизни из конфигурации (в секундах) в time.Duration
+func cacheTTL(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
 	}
+	return time.Duration(seconds) * time.Second
 }
 
 // UserService интерфейс для работы с пользователями
@@ -40,16 +103,36 @@ type UserService interface {
 	GetByID(ctx context.Context, id int64) (*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, page, size int) ([]*domain.User, int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, page domain.Page) ([]*domain.User, *domain.PageInfo, error)
+	ListDeleted(ctx context.Context, page domain.Page) ([]*domain.User, *domain.PageInfo, error)
 }
 
 // AuthService интерфейс для аутентификации и авторизации
 type AuthService interface {
 	Register(ctx context.Context, username, email, password, firstName, lastName, fullName, phone string) (int64, error)
-	Login(ctx context.Context, usernameOrEmail, password string) (string, string, error) // Возвращает access и refresh токены
+	Login(ctx context.Context, usernameOrEmail, password string) (*domain.LoginResult, error) // Возвращает токены или токен подтверждения вторым фактором
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, error) // Обменивает токен подтверждения и код на access и refresh токены
 	ValidateToken(ctx context.Context, token string) (*domain.User, error)
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error) // Возвращает новые access и refresh токены
+	Logout(ctx context.Context, refreshToken string) error                         // Завершает сессию, к которой относится токен
+	LogoutAll(ctx context.Context, userID int64) error                             // Завершает все сессии пользователя
 	ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error
+	SendEmailVerification(ctx context.Context, userID int64) error // Повторно отправляет письмо подтверждения email
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error // Отправляет ссылку сброса пароля, если адрес зарегистрирован
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// TwoFactorService интерфейс двухфакторной аутентификации (TOTP)
+type TwoFactorService interface {
+	Status(ctx context.Context, user *domain.User) (*domain.TwoFactorStatus, error)
+	Enabled(ctx context.Context, userID int64) (bool, error)
+	Enroll(ctx context.Context, user *domain.User) (*domain.TwoFactorEnrollment, error) // Создает секрет и коды восстановления
+	Confirm(ctx context.Context, userID int64, code string) error                       // Включает двухфакторную аутентификацию по первому коду
+	Verify(ctx context.Context, userID int64, code string) error                        // Проверяет код из приложения или код восстановления
+	Disable(ctx context.Context, user *domain.User, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
 }
 
 // TourService интерфейс для работы с турами
@@ -58,12 +141,48 @@ type TourService interface {
 	GetByID(ctx context.Context, id int64) (*domain.Tour, error)
 	Update(ctx context.Context, tour *domain.Tour) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Tour, int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, filter *domain.TourFilter) ([]*domain.Tour, *domain.PageInfo, error)
+	Facets(ctx context.Context, filter *domain.TourFilter, opts *domain.TourFacetOptions) (*domain.TourFacets, error) // Количество туров по значениям атрибутов с учетом фильтра
 	AddTourDate(ctx context.Context, tourDate *domain.TourDate) (int64, error)
 	GetTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error)
 	GetTourDateByID(ctx context.Context, id int64) (*domain.TourDate, error)
 	UpdateTourDate(ctx context.Context, tourDate *domain.TourDate) error
 	DeleteTourDate(ctx context.Context, id int64) error
+	ListDeletedTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error)
+	RestoreTourDate(ctx context.Context, tourID, id int64) error
+}
+
+// SearchService интерфейс полнотекстового поиска туров
+type SearchService interface {
+	Search(ctx context.Context, query string, limit int) ([]*domain.TourSearchResult, error) // Туры в порядке релевантности с подсветкой совпадений
+	MatchIDs(query string) []int64                                                           // ID подходящих туров в порядке релевантности
+	Rebuild(ctx context.Context) (int, error)                                                // Полная переиндексация активных туров
+	IndexTour(ctx context.Context, tourID int64) error
+	RemoveTour(tourID int64)
+}
+
+// TourScheduleService интерфейс для управления расписаниями туров и генерации дат
+type TourScheduleService interface {
+	Create(ctx context.Context, schedule *domain.TourSchedule) (int64, error)
+	Update(ctx context.Context, schedule *domain.TourSchedule) error
+	Delete(ctx context.Context, tourID, id int64) error
+	ListByTourID(ctx context.Context, tourID int64) ([]*domain.TourSchedule, error)
+	// Generate создает недостающие даты по активным правилам; tourID ограничивает генерацию одним туром
+	Generate(ctx context.Context, tourID *int64) (int, error)
+}
+
+// PricingRuleService интерфейс для управления правилами ценообразования
+type PricingRuleService interface {
+	Create(ctx context.Context, rule *domain.PricingRule) (int64, error)
+	GetByID(ctx context.Context, id int64) (*domain.PricingRule, error)
+	Update(ctx context.Context, rule *domain.PricingRule) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*domain.PricingRule, error)
+	// Preview рассчитывает модификаторы предстоящих дат тура без сохранения
+	Preview(ctx context.Context, tourID int64, includeInactive bool) ([]*domain.PriceModifierPreview, error)
+	// Apply сохраняет пересчитанные модификаторы; tourID ограничивает пересчет одним туром
+	Apply(ctx context.Context, tourID *int64) (int, error)
 }
 
 // HotelService интерфейс для работы с отелями
@@ -72,24 +191,71 @@ type HotelService interface {
 	GetByID(ctx context.Context, id int64) (*domain.Hotel, error)
 	Update(ctx context.Context, hotel *domain.Hotel) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Hotel, int, error)
+	Restore(ctx context.Context, id int64) error
+	List(ctx context.Context, filter *domain.HotelFilter) ([]*domain.Hotel, *domain.PageInfo, error)
 	AddRoom(ctx context.Context, room *domain.Room) (int64, error)
 	GetRoomByID(ctx context.Context, id int64) (*domain.Room, error)
 	UpdateRoom(ctx context.Context, room *domain.Room) error
 	DeleteRoom(ctx context.Context, id int64) error
+	RestoreRoom(ctx context.Context, hotelID, id int64) error
 	ListRoomsByHotelID(ctx context.Context, hotelID int64) ([]*domain.Room, error)
+	ListDeletedRooms(ctx context.Context, hotelID int64) ([]*domain.Room, error)
+	ListAvailableRooms(ctx context.Context, hotelID int64, from, to time.Time) ([]*domain.Room, error) // Номера, свободные на все ночи периода
+	GetRoomNights(ctx context.Context, roomID int64, from, to time.Time) ([]*domain.RoomNight, error)  // Занятость номера по ночам
 }
 
 // OrderService интерфейс для работы с заказами
 type OrderService interface {
-	Create(ctx context.Context, userID, tourID, tourDateID int64, roomID *int64, peopleCount int, totalPrice float64) (int64, error)
+	Quote(ctx context.Context, req *domain.OrderRequest) (*domain.PriceQuote, error) // Рассчитывает стоимость и выдает подписанный расчет
+	Create(ctx context.Context, req *domain.OrderRequest, quoteToken string) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Order, error)
 	Update(ctx context.Context, order *domain.Order) error
 	Delete(ctx context.Context, id int64) error
 	ListByUserID(ctx context.Context, userID int64) ([]*domain.Order, error)
-	List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Order, int, error)
-	UpdateStatus(ctx context.Context, id int64, status string) error
-	CalculatePrice(ctx context.Context, tourID, tourDateID int64, roomID *int64, peopleCount int) (float64, error)
+	List(ctx context.Context, filter *domain.OrderFilter) ([]*domain.Order, *domain.PageInfo, error)
+	UpdateStatus(ctx context.Context, id int64, status string, changedBy *int64, comment string) error
+	// UpdateStatusFrom как UpdateStatus, но только из статусов from; иначе ErrOrderStatusNotAllowed
+	UpdateStatusFrom(ctx context.Context, id int64, status string, from []domain.OrderStatus, changedBy *int64, comment string) error
+	MarkPaid(ctx context.Context, id, paymentID int64, comment string) error // Переводит заказ в paid платежом paymentID; ErrOrderAlreadyPaid, если он уже оплачен
+	GetStatusHistory(ctx context.Context, orderID int64) ([]*domain.OrderStatusChange, error)
+	ExpireHolds(ctx context.Context) (int, error)                                                 // Отменяет неоплаченные заказы с истекшим удержанием мест
+	CalculatePrice(ctx context.Context, req *domain.OrderRequest) (*domain.PriceBreakdown, error) // Стоимость с учетом скидок и промокода
+}
+
+// PromotionService интерфейс для управления промоакциями
+type PromotionService interface {
+	Create(ctx context.Context, promo *domain.Promotion) (int64, error)
+	GetByID(ctx context.Context, id int64) (*domain.Promotion, error)
+	Update(ctx context.Context, promo *domain.Promotion) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, page, size int) ([]*domain.Promotion, int, error)
+}
+
+// RoleService интерфейс для управления ролями и их правами
+type RoleService interface {
+	List(ctx context.Context) ([]*domain.Role, error)
+	GetByID(ctx context.Context, id int64) (*domain.Role, error) // Роль с правами; используется при проверке доступа
+	GetDefault(ctx context.Context) (*domain.Role, error)        // Роль, назначаемая при регистрации
+	Create(ctx context.Context, role *domain.Role) (int64, error)
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, id int64) error
+	ListPermissions(ctx context.Context) ([]*domain.PermissionInfo, error)
+}
+
+// PaymentService интерфейс для приема оплаты заказов через платежного провайдера
+type PaymentService interface {
+	StartCheckout(ctx context.Context, orderID int64) (*payment.Intent, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error      // Применяет подписанное событие провайдера
+	SimulationSupported() bool                                                      // Провайдер позволяет имитировать оплату клиентом
+	SimulateAuthorization(ctx context.Context, userID int64, intentID string) error // Имитирует оплату клиентом заказа пользователя userID
+}
+
+// CurrencyService интерфейс для пересчета цен между валютами
+type CurrencyService interface {
+	Supports(currency string) bool
+	Base() string
+	ConvertTour(tour *domain.Tour, currency string) error
+	ConvertToAll(amount money.Amount, currency string) (map[string]money.Amount, error)
 }
 
 // SupportTicketService интерфейс для работы с тикетами тех-поддержки
@@ -99,10 +265,12 @@ type SupportTicketService interface {
 	Update(ctx context.Context, ticket *domain.SupportTicket) error
 	Delete(ctx context.Context, id int64) error
 	ListByUserID(ctx context.Context, userID int64) ([]*domain.SupportTicket, error)
-	List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.SupportTicket, int, error)
+	List(ctx context.Context, filter *domain.TicketFilter) ([]*domain.SupportTicket, *domain.PageInfo, error)
 	UpdateStatus(ctx context.Context, id int64, status string) error
 	AddMessage(ctx context.Context, ticketID, userID int64, message string) (int64, error)
 	GetMessages(ctx context.Context, ticketID int64) ([]*domain.TicketMessage, error)
+	ListMessages(ctx context.Context, ticketID int64, page domain.Page) ([]*domain.TicketMessage, *domain.PageInfo, error)
+	GetMessageByID(ctx context.Context, id int64) (*domain.TicketMessage, error)
 	CloseTicket(ctx context.Context, id int64) error
 }
 
@@ -113,6 +281,17 @@ type CityService interface {
 	ListByCountryID(ctx context.Context, countryID int64) ([]*domain.City, error)
 }
 
+// CacheService интерфейс статистики кэша каталога
+type CacheService interface {
+	Stats() []cache.Stats // Счетчики попаданий и промахов по группам ключей
+}
+
+// RateLimitService интерфейс ограничения частоты действий
+type RateLimitService interface {
+	// Allow учитывает действие (RateLimitLogin и т.д.) по ключу, например IP или ID пользователя
+	Allow(ctx context.Context, action, key string) (ratelimit.Result, error)
+}
+
 // CountryService интерфейс для работы со странами
 type CountryService interface {
 	GetByID(ctx context.Context, id int64) (*domain.Country, error)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
 )
 
 // SupportTicketServiceImpl реализация сервиса для работы с тикетами поддержки
@@ -72,18 +73,21 @@ func (s *SupportTicketServiceImpl) ListByUserID(ctx context.Context, userID int6
 }
 
 // List возвращает список тикетов с фильтрацией
-func (s *SupportTicketServiceImpl) List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.SupportTicket, int, error) {
-	tickets, err := s.ticketRepo.List(ctx, filters, page, size)
-	if err != nil {
-		return nil, 0, err
+func (s *SupportTicketServiceImpl) List(ctx context.Context, filter *domain.TicketFilter) ([]*domain.SupportTicket, *domain.PageInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	totalCount, err := s.ticketRepo.Count(ctx, filters)
+	tickets, err := s.ticketRepo.List(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	return tickets, totalCount, nil
+	return pageResult(filter.Page, tickets, func(t *domain.SupportTicket) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: t.CreatedAt, ID: t.ID}
+	}, func() (int, error) {
+		return s.ticketRepo.Count(ctx, filter)
+	})
 }
 
 // UpdateStatus обновляет статус тикета
@@ -120,6 +124,29 @@ func (s *SupportTicketServiceImpl) GetMessages(ctx context.Context, ticketID int
 	return s.ticketRepo.GetMessages(ctx, ticketID)
 }
 
+// ListMessages возвращает страницу сообщений тикета в хронологическом порядке
+func (s *SupportTicketServiceImpl) ListMessages(ctx context.Context, ticketID int64, page domain.Page) ([]*domain.TicketMessage, *domain.PageInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	messages, err := s.ticketRepo.ListMessages(ctx, ticketID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pageResult(page, messages, func(m *domain.TicketMessage) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	}, func() (int, error) {
+		return s.ticketRepo.CountMessages(ctx, ticketID)
+	})
+}
+
+// GetMessageByID возвращает сообщение тикета по ID
+func (s *SupportTicketServiceImpl) GetMessageByID(ctx context.Context, id int64) (*domain.TicketMessage, error) {
+	return s.ticketRepo.GetMessageByID(ctx, id)
+}
+
 // CloseTicket закрывает тикет
 func (s *SupportTicketServiceImpl) CloseTicket(ctx context.Context, id int64) error {
 	// Получаем тикет
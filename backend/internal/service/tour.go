@@ -2,27 +2,36 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"log"
 
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
 )
 
 // TourServiceImpl реализация сервиса для работы с турами
 type TourServiceImpl struct {
-	repos repository.TourRepository
+	repos  repository.TourRepository
+	search SearchService
 }
 
 // NewTourService создает новый сервис для работы с турами
-func NewTourService(repos repository.TourRepository) TourService {
+func NewTourService(repos repository.TourRepository, search SearchService) TourService {
 	return &TourServiceImpl{
-		repos: repos,
+		repos:  repos,
+		search: search,
 	}
 }
 
 // Create создает новый тур
 func (s *TourServiceImpl) Create(ctx context.Context, tour *domain.Tour) (int64, error) {
-	return s.repos.Create(ctx, tour)
+	id, err := s.repos.Create(ctx, tour)
+	if err != nil {
+		return 0, err
+	}
+	s.reindex(ctx, id)
+	return id, nil
 }
 
 // GetByID получает тур по ID
@@ -32,32 +41,79 @@ func (s *TourServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Tour,
 
 // Update обновляет данные тура
 func (s *TourServiceImpl) Update(ctx context.Context, tour *domain.Tour) error {
-	return s.repos.Update(ctx, tour)
+	if err := s.repos.Update(ctx, tour); err != nil {
+		return err
+	}
+	s.reindex(ctx, tour.ID)
+	return nil
 }
 
 // Delete удаляет тур
 func (s *TourServiceImpl) Delete(ctx context.Context, id int64) error {
-	return s.repos.Delete(ctx, id)
+	if err := s.repos.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.search.RemoveTour(id)
+	return nil
+}
+
+// Restore восстанавливает удаленный тур и возвращает его в поисковый индекс
+func (s *TourServiceImpl) Restore(ctx context.Context, id int64) error {
+	if err := s.repos.Restore(ctx, id); err != nil {
+		return err
+	}
+	s.reindex(ctx, id)
+	return nil
+}
+
+// reindex обновляет тур в поисковом индексе. Ошибка не отменяет уже
+// сохраненное изменение: индекс догонит базу при плановой переиндексации.
+func (s *TourServiceImpl) reindex(ctx context.Context, id int64) {
+	if err := s.search.IndexTour(ctx, id); err != nil {
+		log.Printf("Ошибка при обновлении тура %d в поисковом индексе: %v", id, err)
+	}
 }
 
 // List возвращает список туров с фильтрацией
-func (s *TourServiceImpl) List(ctx context.Context, filters map[string]interface{}, page, size int) ([]*domain.Tour, int, error) {
-	fmt.Println("DEBUG: Entering service.List") // Добавляем лог входа в сервис
-	tours, err := s.repos.List(ctx, filters, page, size)
-	if err != nil {
-		return nil, 0, err
+func (s *TourServiceImpl) List(ctx context.Context, filter *domain.TourFilter) ([]*domain.Tour, *domain.PageInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
 	}
+	s.resolveSearch(filter)
 
-	totalCount, err := s.repos.Count(ctx, filters)
+	tours, err := s.repos.List(ctx, filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
+	}
+
+	return pageResult(filter.Page, tours, func(t *domain.Tour) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: t.CreatedAt, ID: t.ID}
+	}, func() (int, error) {
+		return s.repos.Count(ctx, filter)
+	})
+}
+
+// Facets подсчитывает туры, подходящие под фильтр, по значениям атрибутов
+func (s *TourServiceImpl) Facets(ctx context.Context, filter *domain.TourFilter, opts *domain.TourFacetOptions) (*domain.TourFacets, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
 	}
+	s.resolveSearch(filter)
+
+	return s.repos.Facets(ctx, filter, opts)
+}
 
-	return tours, totalCount, nil
+// resolveSearch отбирает туры по текстовому запросу через поисковый индекс
+// с учетом опечаток; повторно для того же фильтра индекс не запрашивается
+func (s *TourServiceImpl) resolveSearch(filter *domain.TourFilter) {
+	if filter.Search != "" && filter.IDs == nil {
+		filter.IDs = s.search.MatchIDs(filter.Search)
+	}
 }
 
 // AddTourDate добавляет новую дату для тура
 func (s *TourServiceImpl) AddTourDate(ctx context.Context, tourDate *domain.TourDate) (int64, error) {
+	normalizeTourDate(tourDate)
 	return s.repos.AddTourDate(ctx, tourDate)
 }
 
@@ -73,6 +129,7 @@ func (s *TourServiceImpl) GetTourDateByID(ctx context.Context, id int64) (*domai
 
 // UpdateTourDate обновляет информацию о дате тура
 func (s *TourServiceImpl) UpdateTourDate(ctx context.Context, tourDate *domain.TourDate) error {
+	normalizeTourDate(tourDate)
 	return s.repos.UpdateTourDate(ctx, tourDate)
 }
 
@@ -80,3 +137,30 @@ func (s *TourServiceImpl) UpdateTourDate(ctx context.Context, tourDate *domain.T
 func (s *TourServiceImpl) DeleteTourDate(ctx context.Context, id int64) error {
 	return s.repos.DeleteTourDate(ctx, id)
 }
+
+// ListDeletedTourDates возвращает удаленные даты тура
+func (s *TourServiceImpl) ListDeletedTourDates(ctx context.Context, tourID int64) ([]*domain.TourDate, error) {
+	return s.repos.ListDeletedTourDates(ctx, tourID)
+}
+
+// RestoreTourDate восстанавливает удаленную дату тура
+func (s *TourServiceImpl) RestoreTourDate(ctx context.Context, tourID, id int64) error {
+	return s.repos.RestoreTourDate(ctx, tourID, id)
+}
+
+// normalizeTourDate заполняет вместимость и модификаторы цены даты, заданной вручную.
+// Модификатор из запроса становится базовым; итоговый пересчитывается правилами ценообразования.
+func normalizeTourDate(tourDate *domain.TourDate) {
+	if tourDate.Capacity < tourDate.Availability {
+		tourDate.Capacity = tourDate.Availability
+	}
+	if tourDate.BaseModifier <= 0 {
+		tourDate.BaseModifier = tourDate.PriceModifier
+	}
+	if tourDate.BaseModifier <= 0 {
+		tourDate.BaseModifier = money.One
+	}
+	if tourDate.PriceModifier <= 0 {
+		tourDate.PriceModifier = tourDate.BaseModifier
+	}
+}
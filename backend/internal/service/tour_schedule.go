@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// DefaultScheduleHorizon горизонт планирования дат туров, если он не задан в конфигурации
+const DefaultScheduleHorizon = 180 * 24 * time.Hour
+
+// maxPriceModifier верхняя граница модификатора цены, допустимая в колонке tour_dates.price_modifier
+var maxPriceModifier = money.One * 1000
+
+// priceModifierStep точность модификатора цены, хранимого в датах тура
+var priceModifierStep = money.One / 100
+
+// TourScheduleServiceImpl реализация сервиса расписаний туров
+type TourScheduleServiceImpl struct {
+	scheduleRepo repository.TourScheduleRepository
+	tourRepo     repository.TourRepository
+	horizon      time.Duration
+}
+
+// NewTourScheduleService создает новый сервис расписаний туров
+func NewTourScheduleService(scheduleRepo repository.TourScheduleRepository, tourRepo repository.TourRepository, horizon time.Duration) TourScheduleService {
+	if horizon <= 0 {
+		horizon = DefaultScheduleHorizon
+	}
+
+	return &TourScheduleServiceImpl{
+		scheduleRepo: scheduleRepo,
+		tourRepo:     tourRepo,
+		horizon:      horizon,
+	}
+}
+
+// Create создает правило расписания тура
+func (s *TourScheduleServiceImpl) Create(ctx context.Context, schedule *domain.TourSchedule) (int64, error) {
+	if err := validateTourSchedule(schedule); err != nil {
+		return 0, err
+	}
+	if _, err := s.tourRepo.GetByID(ctx, schedule.TourID); err != nil {
+		return 0, err
+	}
+	return s.scheduleRepo.Create(ctx, schedule)
+}
+
+// Update обновляет правило расписания тура
+func (s *TourScheduleServiceImpl) Update(ctx context.Context, schedule *domain.TourSchedule) error {
+	if err := validateTourSchedule(schedule); err != nil {
+		return err
+	}
+	return s.scheduleRepo.Update(ctx, schedule)
+}
+
+// Delete удаляет правило расписания тура
+func (s *TourScheduleServiceImpl) Delete(ctx context.Context, tourID, id int64) error {
+	return s.scheduleRepo.Delete(ctx, tourID, id)
+}
+
+// ListByTourID возвращает правила расписания тура
+func (s *TourScheduleServiceImpl) ListByTourID(ctx context.Context, tourID int64) ([]*domain.TourSchedule, error) {
+	return s.scheduleRepo.ListByTourID(ctx, tourID)
+}
+
+// Generate создает даты туров по активным правилам расписания на горизонт
+// планирования. Если задан tourID, обрабатываются только правила этого тура.
+// Существующие даты не изменяются, поэтому повторный вызов безопасен.
+// Возвращает количество добавленных дат.
+func (s *TourScheduleServiceImpl) Generate(ctx context.Context, tourID *int64) (int, error) {
+	var schedules []*domain.TourSchedule
+	var err error
+	if tourID != nil {
+		schedules, err = s.scheduleRepo.ListByTourID(ctx, *tourID)
+	} else {
+		schedules, err = s.scheduleRepo.ListActive(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Продолжительность тура нужна для расчета даты окончания
+	durations := make(map[int64]int)
+	now := time.Now()
+	created := 0
+
+	for _, schedule := range schedules {
+		if !schedule.IsActive {
+			continue
+		}
+
+		duration, ok := durations[schedule.TourID]
+		if !ok {
+			tour, err := s.tourRepo.GetByID(ctx, schedule.TourID)
+			if err != nil {
+				return created, err
+			}
+			duration = tour.Duration
+			durations[schedule.TourID] = duration
+		}
+
+		count, err := s.scheduleRepo.InsertDates(ctx, expandSchedule(schedule, duration, now, s.horizon))
+		if err != nil {
+			return created, err
+		}
+		created += count
+	}
+
+	return created, nil
+}
+
+// expandSchedule разворачивает правило в даты тура в интервале от завтрашнего
+// дня до now+horizon. Тур продолжительностью duration дней заканчивается в
+// день start+duration-1.
+func expandSchedule(schedule *domain.TourSchedule, duration int, now time.Time, horizon time.Duration) []*domain.TourDate {
+	if duration < 1 {
+		duration = 1
+	}
+
+	first := startOfDay(now).AddDate(0, 0, 1)
+	last := startOfDay(now.Add(horizon))
+	scheduleID := schedule.ID
+
+	var dates []*domain.TourDate
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if !schedule.Departs(day) {
+			continue
+		}
+
+		modifier := schedule.Modifiers.At(day)
+		dates = append(dates, &domain.TourDate{
+			TourID:        schedule.TourID,
+			StartDate:     day,
+			EndDate:       day.AddDate(0, 0, duration-1),
+			Capacity:      schedule.Capacity,
+			Availability:  schedule.Capacity,
+			BaseModifier:  modifier,
+			PriceModifier: modifier,
+			ScheduleID:    &scheduleID,
+		})
+	}
+
+	return dates
+}
+
+// startOfDay возвращает начало календарного дня t
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// validateTourSchedule проверяет параметры правила расписания
+func validateTourSchedule(schedule *domain.TourSchedule) error {
+	switch {
+	case schedule.Weekdays == 0:
+		return fmt.Errorf("%w: at least one weekday is required", ErrInvalidTourSchedule)
+	case schedule.Capacity < 1:
+		return fmt.Errorf("%w: capacity must be positive", ErrInvalidTourSchedule)
+	case schedule.SeasonStart != nil && schedule.SeasonEnd != nil && schedule.SeasonEnd.Before(*schedule.SeasonStart):
+		return fmt.Errorf("%w: season_end must not be before season_start", ErrInvalidTourSchedule)
+	}
+
+	for month, modifier := range schedule.Modifiers {
+		if month < 1 || month > 12 {
+			return fmt.Errorf("%w: unknown month %d in price_modifiers", ErrInvalidTourSchedule, month)
+		}
+		if !validPriceModifier(modifier) {
+			return fmt.Errorf("%w: price modifier for month %d must be in (0, 1000) with at most 2 decimal places", ErrInvalidTourSchedule, month)
+		}
+	}
+
+	return nil
+}
+
+// validPriceModifier проверяет, что модификатор помещается в колонку
+// DECIMAL(5, 2): положителен, меньше 1000 и имеет не больше двух знаков после запятой
+func validPriceModifier(modifier money.Ratio) bool {
+	return modifier > 0 && modifier < maxPriceModifier && modifier%priceModifierStep == 0
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultScheduleGenerationInterval периодичность генерации дат туров, если она не задана в конфигурации
+const DefaultScheduleGenerationInterval = time.Hour
+
+// TourScheduleWorker фоновый обработчик, создающий даты туров по расписаниям
+// на скользящий горизонт планирования
+type TourScheduleWorker struct {
+	schedules TourScheduleService
+	interval  time.Duration
+}
+
+// NewTourScheduleWorker создает новый обработчик расписаний
+func NewTourScheduleWorker(schedules TourScheduleService, interval time.Duration) *TourScheduleWorker {
+	if interval <= 0 {
+		interval = DefaultScheduleGenerationInterval
+	}
+
+	return &TourScheduleWorker{
+		schedules: schedules,
+		interval:  interval,
+	}
+}
+
+// Run периодически генерирует даты туров до отмены контекста
+func (w *TourScheduleWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("[TourScheduleWorker] Запущен, интервал генерации: %s", w.interval)
+
+	for {
+		w.generate(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[TourScheduleWorker] Остановлен")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// generate выполняет один проход по активным расписаниям
+func (w *TourScheduleWorker) generate(ctx context.Context) {
+	count, err := w.schedules.Generate(ctx, nil)
+	if err != nil {
+		log.Printf("[TourScheduleWorker] Ошибка при генерации дат туров: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("[TourScheduleWorker] Создано дат туров: %d", count)
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/auth"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/totp"
+)
+
+const (
+	// DefaultTwoFactorIssuer название сервиса в приложении-аутентификаторе по умолчанию
+	DefaultTwoFactorIssuer = "Tour Agency"
+	// RecoveryCodeCount количество выдаваемых кодов восстановления
+	RecoveryCodeCount = 10
+)
+
+// recoveryCodeEncoding base32 в нижнем регистре: в алфавите нет цифр 0 и 1, которые легко спутать с буквами o и l
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TwoFactorServiceImpl реализация сервиса двухфакторной аутентификации
+type TwoFactorServiceImpl struct {
+	repo          repository.TwoFactorRepository
+	users         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	box           *auth.SecretBox
+	issuer        string
+	otp           totp.Options
+	limiter       *ratelimit.Limiter
+	lockout       ratelimit.LockoutPolicy
+	now           func() time.Time
+}
+
+// NewTwoFactorService создает сервис двухфакторной аутентификации.
+// Секреты шифруются ключом из конфигурации, а если он не задан - секретом JWT.
+func NewTwoFactorService(repo repository.TwoFactorRepository, users repository.UserRepository, refreshTokens repository.RefreshTokenRepository, cfg config.TwoFactorConfig, jwtSecret string, limiter *ratelimit.Limiter, lockout ratelimit.LockoutPolicy) (TwoFactorService, error) {
+	key := cfg.EncryptionKey
+	if key == "" {
+		key = jwtSecret
+	}
+	box, err := auth.NewSecretBox(key)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = DefaultTwoFactorIssuer
+	}
+
+	return &TwoFactorServiceImpl{
+		repo:          repo,
+		users:         users,
+		refreshTokens: refreshTokens,
+		box:           box,
+		issuer:        issuer,
+		otp:           totp.DefaultOptions,
+		limiter:       limiter,
+		lockout:       lockout,
+		now:           time.Now,
+	}, nil
+}
+
+// Status возвращает состояние двухфакторной аутентификации пользователя
+func (s *TwoFactorServiceImpl) Status(ctx context.Context, user *domain.User) (*domain.TwoFactorStatus, error) {
+	status := &domain.TwoFactorStatus{
+		Required: user.Role != nil && user.Role.RequiresTwoFactor(),
+	}
+
+	enabled, err := s.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Enabled сообщает, подтверждена ли двухфакторная аутентификация пользователя
+func (s *TwoFactorServiceImpl) Enabled(ctx context.Context, userID int64) (bool, error) {
+	tf, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return tf.ConfirmedAt != nil, nil
+}
+
+// Enroll начинает подключение: создает секрет и коды восстановления.
+// Двухфакторная аутентификация включается только после Confirm.
+func (s *TwoFactorServiceImpl) Enroll(ctx context.Context, user *domain.User) (*domain.TwoFactorEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavePending(ctx, user.ID, sealed, hashes); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorConfirmed) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	log.Printf("[TwoFactorService] Начато подключение двухфакторной аутентификации пользователя ID=%d", user.ID)
+	return &domain.TwoFactorEnrollment{
+		Secret:        secret,
+		OTPAuthURI:    s.otp.URI(s.issuer, user.Email, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Confirm включает двухфакторную аутентификацию по первому коду из приложения.
+// Сессии, открытые только по паролю, завершаются.
+func (s *TwoFactorServiceImpl) Confirm(ctx context.Context, userID int64, code string) error {
+	tf, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if tf.ConfirmedAt != nil {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	key := lockoutKeyTwoFactor(userID)
+	if err := s.checkLockout(ctx, key); err != nil {
+		return err
+	}
+
+	step, ok, err := s.validateTOTP(tf, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.recordFailure(ctx, key)
+		return ErrInvalidTwoFactorCode
+	}
+	s.resetFailures(ctx, key)
+
+	if err := s.repo.Confirm(ctx, userID, step); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		return err
+	}
+
+	log.Printf("[TwoFactorService] Двухфакторная аутентификация пользователя ID=%d включена, сессии завершаются", userID)
+	if err := s.users.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, userID)
+}
+
+// Verify проверяет код из приложения или код восстановления.
+// Каждый код принимается один раз; неудачи учитываются как попытки подбора.
+func (s *TwoFactorServiceImpl) Verify(ctx context.Context, userID int64, code string) error {
+	tf, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if tf.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	key := lockoutKeyTwoFactor(userID)
+	if err := s.checkLockout(ctx, key); err != nil {
+		return err
+	}
+
+	var accepted bool
+	if s.looksLikeTOTP(code) {
+		step, ok, err := s.validateTOTP(tf, code)
+		if err != nil {
+			return err
+		}
+		if ok {
+			accepted, err = s.repo.UseStep(ctx, userID, step)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		accepted, err = s.repo.UseRecoveryCode(ctx, userID, auth.HashSecretToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+		if accepted {
+			log.Printf("[TwoFactorService] Пользователь ID=%d использовал код восстановления", userID)
+		}
+	}
+
+	if !accepted {
+		s.recordFailure(ctx, key)
+		return ErrInvalidTwoFactorCode
+	}
+	s.resetFailures(ctx, key)
+	return nil
+}
+
+// Disable отключает двухфакторную аутентификацию после проверки пароля и кода.
+// Для привилегированных ролей отключение запрещено.
+func (s *TwoFactorServiceImpl) Disable(ctx context.Context, user *domain.User, password, code string) error {
+	if user.Role != nil && user.Role.RequiresTwoFactor() {
+		return ErrTwoFactorRequired
+	}
+
+	stored, err := s.users.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !auth.CheckPassword(password, stored.Password) {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.Verify(ctx, user.ID, code); err != nil {
+		return err
+	}
+
+	log.Printf("[TwoFactorService] Двухфакторная аутентификация пользователя ID=%d отключена", user.ID)
+	return s.repo.Delete(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes заменяет коды восстановления новыми после проверки кода
+func (s *TwoFactorServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// validateTOTP расшифровывает секрет и проверяет код на текущий момент
+func (s *TwoFactorServiceImpl) validateTOTP(tf *domain.TwoFactor, code string) (int64, bool, error) {
+	secret, err := s.box.Open(tf.Secret)
+	if err != nil {
+		return 0, false, fmt.Errorf("секрет пользователя ID=%d: %w", tf.UserID, err)
+	}
+	key, err := totp.DecodeSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	step, ok := s.otp.Validate(key, code, s.now())
+	return step, ok, nil
+}
+
+// looksLikeTOTP отличает код из приложения (только цифры) от кода восстановления
+func (s *TwoFactorServiceImpl) looksLikeTOTP(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != s.otp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLockout возвращает *ratelimit.LimitError, если ввод кода нужно отложить.
+// При недоступности хранилища окон проверка не блокируется.
+func (s *TwoFactorServiceImpl) checkLockout(ctx context.Context, key string) error {
+	result, err := s.limiter.CheckLockout(ctx, s.lockout, key)
+	if err != nil {
+		log.Printf("[TwoFactorService] Ошибка проверки блокировки: %v", err)
+		return nil
+	}
+	if !result.Allowed {
+		log.Printf("[TwoFactorService] Ввод кода отложен на %s: %s", result.RetryAfter.Round(time.Second), key)
+	}
+	return result.Err()
+}
+
+func (s *TwoFactorServiceImpl) recordFailure(ctx context.Context, key string) {
+	if err := s.limiter.RecordFailure(ctx, s.lockout, key); err != nil {
+		log.Printf("[TwoFactorService] Ошибка учета неверного кода: %v", err)
+	}
+}
+
+func (s *TwoFactorServiceImpl) resetFailures(ctx context.Context, key string) {
+	if err := s.limiter.ResetFailures(ctx, key); err != nil {
+		log.Printf("[TwoFactorService] Ошибка сброса неверных кодов: %v", err)
+	}
+}
+
+// lockoutKeyTwoFactor ключ учета неверных кодов; отделен от неудачных паролей
+func lockoutKeyTwoFactor(userID int64) string {
+	return fmt.Sprintf("2fa:user:%d", userID)
+}
+
+// newRecoveryCodes генерирует коды восстановления вида xxxxx-xxxxx (50 случайных бит)
+// и их хеши. Подбор ограничен блокировкой, поэтому быстрого SHA-256 достаточно.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, RecoveryCodeCount)
+	hashes = make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("не удалось сгенерировать код восстановления: %w", err)
+		}
+		raw := recoveryCodeEncoding.EncodeToString(b)[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = auth.HashSecretToken(raw)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode приводит введенный код восстановления к виду, от которого считается хеш
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/ratelimit"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/totp"
+)
+
+// memTwoFactorRepo хранит настройку одного пользователя. UseStep и
+// UseRecoveryCode повторяют условные обновления репозитория.
+type memTwoFactorRepo struct {
+	repository.TwoFactorRepository
+	mu    sync.Mutex
+	tf    *domain.TwoFactor
+	codes map[string]bool // хеш кода -> использован
+}
+
+func (r *memTwoFactorRepo) GetByUserID(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tf == nil {
+		return nil, repository.ErrTwoFactorNotFound
+	}
+	tf := *r.tf
+	return &tf, nil
+}
+
+func (r *memTwoFactorRepo) SavePending(ctx context.Context, userID int64, secret string, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tf != nil && r.tf.ConfirmedAt != nil {
+		return repository.ErrTwoFactorConfirmed
+	}
+	r.tf = &domain.TwoFactor{UserID: userID, Secret: secret}
+	r.codes = make(map[string]bool, len(codeHashes))
+	for _, h := range codeHashes {
+		r.codes[h] = false
+	}
+	return nil
+}
+
+func (r *memTwoFactorRepo) Confirm(ctx context.Context, userID int64, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tf == nil || r.tf.ConfirmedAt != nil {
+		return repository.ErrTwoFactorNotFound
+	}
+	now := time.Now()
+	r.tf.ConfirmedAt = &now
+	r.tf.LastStep = step
+	return nil
+}
+
+func (r *memTwoFactorRepo) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tf == nil || r.tf.LastStep >= step {
+		return false, nil
+	}
+	r.tf.LastStep = step
+	return true, nil
+}
+
+func (r *memTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.codes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[codeHash] = true
+	return true, nil
+}
+
+// memSessions учитывает завершение сессий при включении второго фактора
+type memSessions struct {
+	bumped, revoked int
+}
+
+type memSessionUsers struct {
+	repository.UserRepository
+	*memSessions
+}
+
+func (s memSessionUsers) IncrementTokenVersion(ctx context.Context, userID int64) error {
+	s.bumped++
+	return nil
+}
+
+type memSessionTokens struct {
+	repository.RefreshTokenRepository
+	*memSessions
+}
+
+func (s memSessionTokens) RevokeAllForUser(ctx context.Context, userID int64) error {
+	s.revoked++
+	return nil
+}
+
+// testTwoFactor подключает второй фактор пользователю 1 на момент now
+// и возвращает сервис, ключ TOTP и коды восстановления
+func testTwoFactor(t *testing.T, now time.Time) (*TwoFactorServiceImpl, *time.Time, []byte, []string) {
+	t.Helper()
+
+	sessions := &memSessions{}
+	lockout := ratelimit.LockoutPolicy{
+		Window:          time.Hour,
+		FreeAttempts:    100,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Second,
+		MaxFailures:     100,
+		LockoutDuration: time.Hour,
+	}
+	svc, err := NewTwoFactorService(&memTwoFactorRepo{}, memSessionUsers{memSessions: sessions}, memSessionTokens{memSessions: sessions},
+		config.TwoFactorConfig{}, "jwt-secret", ratelimit.NewLimiter(ratelimit.NewMemoryStore()), lockout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impl := svc.(*TwoFactorServiceImpl)
+	clock := now
+	impl.now = func() time.Time { return clock }
+
+	ctx := context.Background()
+	enrollment, err := impl.Enroll(ctx, &domain.User{ID: 1, Email: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totp.DecodeSecret(enrollment.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := impl.Confirm(ctx, 1, totp.DefaultOptions.CodeAt(key, clock)); err != nil {
+		t.Fatalf("подтверждение: %v", err)
+	}
+	if sessions.bumped != 1 || sessions.revoked != 1 {
+		t.Fatalf("после подтверждения сессии не завершены: версия %d, отзывов %d", sessions.bumped, sessions.revoked)
+	}
+	return impl, &clock, key, enrollment.RecoveryCodes
+}
+
+func TestVerifyRejectsReplayedCode(t *testing.T) {
+	ctx := context.Background()
+	svc, clock, key, _ := testTwoFactor(t, time.Unix(1700000000, 0))
+	opts := totp.DefaultOptions
+
+	// Код, которым подтверждено подключение, повторно не принимается
+	if err := svc.Verify(ctx, 1, opts.CodeAt(key, *clock)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("повтор кода подтверждения: %v", err)
+	}
+
+	*clock = clock.Add(opts.Period)
+	code := opts.CodeAt(key, *clock)
+	if err := svc.Verify(ctx, 1, code); err != nil {
+		t.Fatalf("новый код: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("повтор кода: %v", err)
+	}
+
+	// Код следующего шага в пределах окна принимается, после него
+	// более старый код того же окна уже не проходит
+	next := opts.Code(key, opts.Step(*clock)+1)
+	if err := svc.Verify(ctx, 1, next); err != nil {
+		t.Fatalf("код следующего шага: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, opts.Code(key, opts.Step(*clock)-1)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("код предыдущего шага после более нового: %v", err)
+	}
+}
+
+func TestVerifySkewWindow(t *testing.T) {
+	ctx := context.Background()
+	svc, clock, key, _ := testTwoFactor(t, time.Unix(1700000000, 0))
+	opts := totp.DefaultOptions
+
+	*clock = clock.Add(10 * opts.Period)
+	step := opts.Step(*clock)
+
+	if err := svc.Verify(ctx, 1, opts.Code(key, step+2)); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("код через два шага: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, opts.Code(key, step-1)); err != nil {
+		t.Fatalf("код предыдущего шага: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, opts.Code(key, step+1)); err != nil {
+		t.Fatalf("код следующего шага: %v", err)
+	}
+}
+
+func TestVerifyRecoveryCodeSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, codes := testTwoFactor(t, time.Unix(1700000000, 0))
+
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("выдано %d кодов восстановления, ожидалось %d", len(codes), RecoveryCodeCount)
+	}
+
+	// Регистр, дефис и пробелы при вводе не важны
+	if err := svc.Verify(ctx, 1, strings.ToUpper(codes[0])); err != nil {
+		t.Fatalf("код восстановления: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("повтор кода восстановления: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, strings.ReplaceAll(codes[1], "-", " ")); err != nil {
+		t.Fatalf("второй код восстановления: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, "aaaaa-aaaaa"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("неизвестный код восстановления: %v", err)
+	}
+}
@@ -5,17 +5,20 @@ import (
 
 	"github.com/usedcvnt/Diplom1Project/backend/internal/domain"
 	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/pagination"
 )
 
 // UserServiceImpl реализация сервиса пользователей
 type UserServiceImpl struct {
-	repos repository.UserRepository
+	repos         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
 }
 
 // NewUserService создает новый сервис пользователей
-func NewUserService(repos repository.UserRepository) UserService {
+func NewUserService(repos repository.UserRepository, refreshTokens repository.RefreshTokenRepository) UserService {
 	return &UserServiceImpl{
-		repos: repos,
+		repos:         repos,
+		refreshTokens: refreshTokens,
 	}
 }
 
@@ -29,27 +32,66 @@ func (s *UserServiceImpl) GetByID(ctx context.Context, id int64) (*domain.User,
 	return s.repos.GetByID(ctx, id)
 }
 
-// Update обновляет данные пользователя
+// Update обновляет данные пользователя.
+// При смене роли выданные ранее access токены отзываются.
 func (s *UserServiceImpl) Update(ctx context.Context, user *domain.User) error {
-	return s.repos.Update(ctx, user)
+	current, err := s.repos.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repos.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if current.RoleID != user.RoleID {
+		return s.repos.IncrementTokenVersion(ctx, user.ID)
+	}
+	return nil
 }
 
-// Delete удаляет пользователя
+// Delete удаляет пользователя, предварительно отзывая его access токены
+// и завершая все сессии
 func (s *UserServiceImpl) Delete(ctx context.Context, id int64) error {
+	if err := s.repos.IncrementTokenVersion(ctx, id); err != nil {
+		return err
+	}
+	if err := s.refreshTokens.RevokeAllForUser(ctx, id); err != nil {
+		return err
+	}
 	return s.repos.Delete(ctx, id)
 }
 
-// List возвращает список пользователей с пагинацией
-func (s *UserServiceImpl) List(ctx context.Context, page, size int) ([]*domain.User, int, error) {
-	users, err := s.repos.List(ctx, page, size)
-	if err != nil {
-		return nil, 0, err
+// Restore восстанавливает удаленного пользователя. Токены, отозванные при
+// удалении, остаются недействительными - пользователь входит заново.
+func (s *UserServiceImpl) Restore(ctx context.Context, id int64) error {
+	return s.repos.Restore(ctx, id)
+}
+
+// List возвращает страницу списка пользователей, от новых к старым
+func (s *UserServiceImpl) List(ctx context.Context, page domain.Page) ([]*domain.User, *domain.PageInfo, error) {
+	return s.list(ctx, page, false)
+}
+
+// ListDeleted возвращает страницу списка удаленных пользователей
+func (s *UserServiceImpl) ListDeleted(ctx context.Context, page domain.Page) ([]*domain.User, *domain.PageInfo, error) {
+	return s.list(ctx, page, true)
+}
+
+// list возвращает страницу действующих или удаленных пользователей
+func (s *UserServiceImpl) list(ctx context.Context, page domain.Page, deleted bool) ([]*domain.User, *domain.PageInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	totalCount, err := s.repos.Count(ctx)
+	users, err := s.repos.List(ctx, page, deleted)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	return users, totalCount, nil
+	return pageResult(page, users, func(u *domain.User) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+	}, func() (int, error) {
+		return s.repos.Count(ctx, deleted)
+	})
 }
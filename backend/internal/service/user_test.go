@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/repository"
+)
+
+// userCalls записывает вызовы репозиториев в порядке выполнения
+type userCalls struct {
+	calls []string
+}
+
+type memDeleteUsers struct {
+	repository.UserRepository
+	*userCalls
+}
+
+func (r memDeleteUsers) IncrementTokenVersion(ctx context.Context, id int64) error {
+	r.calls = append(r.calls, "token_version")
+	return nil
+}
+
+func (r memDeleteUsers) Delete(ctx context.Context, id int64) error {
+	r.calls = append(r.calls, "delete")
+	return nil
+}
+
+type memDeleteTokens struct {
+	repository.RefreshTokenRepository
+	*userCalls
+}
+
+func (r memDeleteTokens) RevokeAllForUser(ctx context.Context, userID int64) error {
+	r.calls = append(r.calls, "revoke_sessions")
+	return nil
+}
+
+// TestDeleteUserRevokesSessions проверяет, что удаление пользователя отзывает
+// access токены и завершает сессии до удаления записи
+func TestDeleteUserRevokesSessions(t *testing.T) {
+	calls := &userCalls{}
+	svc := NewUserService(memDeleteUsers{userCalls: calls}, memDeleteTokens{userCalls: calls})
+
+	if err := svc.Delete(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"token_version", "revoke_sessions", "delete"}
+	if !reflect.DeepEqual(calls.calls, want) {
+		t.Errorf("вызовы: %v, ожидалось %v", calls.calls, want)
+	}
+}
@@ -0,0 +1,21 @@
+-- Откат исходной схемы: таблицы удаляются в порядке, обратном созданию
+
+DROP TABLE IF EXISTS ticket_messages;
+DROP TABLE IF EXISTS support_tickets;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
+DROP TABLE IF EXISTS pricing_rules;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS order_status_history;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS tour_dates;
+DROP TABLE IF EXISTS tour_schedules;
+DROP TABLE IF EXISTS tours;
+DROP TABLE IF EXISTS room_nights;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS hotels;
+DROP TABLE IF EXISTS cities;
+DROP TABLE IF EXISTS countries;
@@ -0,0 +1,282 @@
+-- Исходная схема базы данных турагентства.
+-- Применяется только к пустой базе: мигратор отказывается работать с базой,
+-- в которой есть таблицы, но нет записей о миграциях
+
+-- Страны
+CREATE TABLE IF NOT EXISTS countries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(3) NOT NULL,
+    UNIQUE KEY (code)
+);
+
+-- Города
+CREATE TABLE IF NOT EXISTS cities (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    country_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    FOREIGN KEY (country_id) REFERENCES countries(id) ON DELETE CASCADE
+);
+
+-- Отели
+CREATE TABLE IF NOT EXISTS hotels (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    city_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    address VARCHAR(255) NOT NULL,
+    category SMALLINT NOT NULL, -- Количество звезд
+    image_url VARCHAR(255),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3), -- Для ETag и Last-Modified
+    deleted_at TIMESTAMP NULL, -- Время мягкого удаления; NULL - отель действует
+    FOREIGN KEY (city_id) REFERENCES cities(id) ON DELETE CASCADE
+);
+
+-- Номера
+CREATE TABLE IF NOT EXISTS rooms (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    hotel_id INT NOT NULL,
+    description TEXT,
+    beds SMALLINT NOT NULL, -- Количество спальных мест
+    units SMALLINT NOT NULL DEFAULT 1, -- Количество номеров этого типа в отеле
+    price DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    image_url VARCHAR(255),
+    updated_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (hotel_id) REFERENCES hotels(id) ON DELETE CASCADE
+);
+
+-- Занятость номеров по ночам; ночи без строки свободны
+CREATE TABLE IF NOT EXISTS room_nights (
+    room_id INT NOT NULL,
+    night DATE NOT NULL,
+    booked SMALLINT NOT NULL DEFAULT 0, -- Сколько номеров этого типа занято в эту ночь
+    PRIMARY KEY (room_id, night),
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+-- Туры
+CREATE TABLE IF NOT EXISTS tours (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    city_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    base_price DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    image_url VARCHAR(255),
+    duration SMALLINT NOT NULL DEFAULT 1, -- Стандартная продолжительность в днях
+    is_active BOOLEAN DEFAULT TRUE,
+    version INT UNSIGNED NOT NULL DEFAULT 1, -- Увеличивается при каждом изменении; проверяется по If-Match
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (city_id) REFERENCES cities(id) ON DELETE CASCADE
+);
+
+-- Правила расписания отправлений туров
+CREATE TABLE IF NOT EXISTS tour_schedules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tour_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    weekdays TINYINT UNSIGNED NOT NULL, -- Битовая маска дней отправления: бит 0 - понедельник, бит 6 - воскресенье
+    season_start DATE NULL, -- Окно сезона; NULL - без ограничения
+    season_end DATE NULL,
+    blackout_dates JSON NULL, -- Даты, в которые отправлений нет
+    capacity INT UNSIGNED NOT NULL, -- Количество мест на каждую дату
+    price_modifiers JSON NULL, -- Сезонная кривая: модификатор цены по номеру месяца
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tour_id) REFERENCES tours(id) ON DELETE CASCADE
+);
+
+-- Доступные даты туров
+CREATE TABLE IF NOT EXISTS tour_dates (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tour_id INT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    capacity INT UNSIGNED NOT NULL DEFAULT 0, -- Общее количество мест на дату
+    availability INT UNSIGNED NOT NULL, -- Количество доступных мест, не может уйти ниже нуля
+    base_modifier DECIMAL(5, 2) DEFAULT 1.0, -- Модификатор цены, заданный вручную или расписанием
+    price_modifier DECIMAL(5, 2) DEFAULT 1.0, -- Итоговый модификатор цены с учетом правил ценообразования
+    schedule_id INT NULL, -- Правило расписания, по которому создана дата; NULL для дат, добавленных вручную
+    updated_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3), -- Меняется и при бронировании мест
+    deleted_at TIMESTAMP NULL,
+    UNIQUE KEY uq_tour_dates_start (tour_id, start_date),
+    FOREIGN KEY (tour_id) REFERENCES tours(id) ON DELETE CASCADE,
+    FOREIGN KEY (schedule_id) REFERENCES tour_schedules(id) ON DELETE SET NULL
+);
+
+-- Роли пользователей
+CREATE TABLE IF NOT EXISTS roles (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL
+);
+
+-- Пользователи
+CREATE TABLE IF NOT EXISTS users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    password VARCHAR(255) NOT NULL, -- Хешированный пароль bcrypt
+    email VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100),
+    last_name VARCHAR(155),
+    full_name VARCHAR(255),
+    phone VARCHAR(20),
+    role_id INT NOT NULL,
+    token_version INT NOT NULL DEFAULT 0, -- Увеличивается для отзыва выданных токенов
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE KEY (username),
+    UNIQUE KEY (email),
+    FOREIGN KEY (role_id) REFERENCES roles(id)
+);
+
+-- Выданные refresh токены (сессии входа)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id CHAR(32) PRIMARY KEY, -- jti токена
+    user_id INT NOT NULL,
+    family_id CHAR(32) NOT NULL, -- Семейство токенов одной сессии
+    expires_at DATETIME NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    replaced_by CHAR(32) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_refresh_tokens_family (family_id),
+    INDEX idx_refresh_tokens_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Заказы
+CREATE TABLE IF NOT EXISTS orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    tour_id INT NOT NULL,
+    tour_date_id INT NOT NULL,
+    room_id INT,
+    people_count SMALLINT NOT NULL,
+    children_count SMALLINT NOT NULL DEFAULT 0, -- сколько из people_count составляют дети
+    total_price DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    status ENUM('pending', 'confirmed', 'paid', 'cancelled', 'completed') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NULL,
+    price_breakdown JSON NULL,
+    INDEX idx_orders_status_expires_at (status, expires_at),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (tour_id) REFERENCES tours(id),
+    FOREIGN KEY (tour_date_id) REFERENCES tour_dates(id),
+    FOREIGN KEY (room_id) REFERENCES rooms(id)
+);
+
+-- История изменения статусов заказов
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    from_status ENUM('pending', 'confirmed', 'paid', 'cancelled', 'completed') NULL,
+    to_status ENUM('pending', 'confirmed', 'paid', 'cancelled', 'completed') NOT NULL,
+    changed_by INT NULL,
+    comment VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_order_status_history_order (order_id, created_at),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Платежи по заказам
+CREATE TABLE IF NOT EXISTS payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    provider_payment_id VARCHAR(64) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status ENUM('pending', 'succeeded', 'failed', 'refunded') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_payments_provider_payment (provider, provider_payment_id),
+    INDEX idx_payments_order_status (order_id, status),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+-- Правила ценообразования: модификаторы цены дат туров
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind ENUM('season', 'weekend', 'occupancy', 'last_minute') NOT NULL,
+    modifier DECIMAL(5, 2) NOT NULL, -- Множитель цены, например 1.20 или 0.85
+    country_id INT NULL, -- Область действия: страна или город; NULL - все туры
+    city_id INT NULL,
+    season_start DATE NULL, -- Период сезона для правил season
+    season_end DATE NULL,
+    occupancy_below TINYINT UNSIGNED NULL, -- Для occupancy: остаток мест ниже указанного процента вместимости
+    days_before SMALLINT UNSIGNED NULL, -- Для last_minute: до начала тура осталось не больше указанного числа дней
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (country_id) REFERENCES countries(id) ON DELETE CASCADE,
+    FOREIGN KEY (city_id) REFERENCES cities(id) ON DELETE CASCADE
+);
+
+-- Промоакции: промокоды и автоматические правила скидок
+CREATE TABLE IF NOT EXISTS promotions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(32) NULL, -- NULL для правил, применяемых автоматически
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    discount_type ENUM('percent', 'fixed') NOT NULL,
+    percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    target ENUM('order', 'children') NOT NULL DEFAULT 'order',
+    tour_id INT NULL,
+    min_people SMALLINT NOT NULL DEFAULT 0,
+    early_bird_days SMALLINT NOT NULL DEFAULT 0,
+    max_uses INT NULL,
+    max_uses_per_user INT NULL,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_promotions_code (code),
+    FOREIGN KEY (tour_id) REFERENCES tours(id) ON DELETE CASCADE
+);
+
+-- Применения промоакций к заказам
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    promotion_id INT NOT NULL,
+    order_id INT NOT NULL,
+    user_id INT NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_promotion_redemptions_order (promotion_id, order_id),
+    INDEX idx_promotion_redemptions_user (promotion_id, user_id),
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Тикеты тех-поддержки
+CREATE TABLE IF NOT EXISTS support_tickets (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    status ENUM('open', 'in_progress', 'closed') NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Сообщения в тикетах
+CREATE TABLE IF NOT EXISTS ticket_messages (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ticket_id INT NOT NULL,
+    user_id INT NOT NULL, -- Может быть и пользователь, и сотрудник тех-поддержки
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (ticket_id) REFERENCES support_tickets(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
@@ -0,0 +1,10 @@
+-- Откат прав ролей
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+
+ALTER TABLE roles
+    DROP INDEX uq_roles_name,
+    DROP COLUMN is_default,
+    DROP COLUMN is_system,
+    DROP COLUMN description;
@@ -0,0 +1,49 @@
+-- Права ролей: справочник прав и их назначение ролям вместо ID ролей в коде
+
+ALTER TABLE roles
+    ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE, -- Системную роль нельзя переименовать или удалить
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE, -- Роль, назначаемая при регистрации
+    ADD UNIQUE KEY uq_roles_name (name);
+
+-- Справочник прав; названия прав используются в коде
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL
+);
+
+-- Права, назначенные ролям
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INT NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'Просмотр пользователей'),
+    ('users:write', 'Изменение, удаление и восстановление пользователей'),
+    ('roles:manage', 'Управление ролями, их правами и назначение ролей пользователям'),
+    ('tours:write', 'Управление турами, датами и расписаниями'),
+    ('hotels:write', 'Управление отелями и номерами'),
+    ('pricing:write', 'Управление правилами ценообразования'),
+    ('promotions:write', 'Управление промоакциями'),
+    ('orders:read', 'Просмотр всех заказов и истории их статусов'),
+    ('orders:write', 'Изменение статуса заказов'),
+    ('orders:refund', 'Отмена оплаченных заказов с возвратом средств'),
+    ('tickets:read', 'Просмотр всех тикетов поддержки и ответы в них'),
+    ('tickets:assign', 'Взятие тикетов в работу и смена их статуса'),
+    ('system:manage', 'Переиндексация поиска и статистика кэша');
+
+-- Роли базы, созданной до появления прав, получают права прежних проверок в коде.
+-- В новой базе ролей еще нет: их вместе с правами создает набор начальных данных roles.
+UPDATE roles SET is_system = TRUE WHERE name IN ('admin', 'user', 'support');
+UPDATE roles SET is_default = TRUE WHERE name = 'user';
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name FROM roles r JOIN permissions p ON p.name IN ('tickets:read', 'tickets:assign')
+WHERE r.name = 'support';
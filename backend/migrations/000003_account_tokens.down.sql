@@ -0,0 +1,5 @@
+-- Откат подтверждения email и восстановления пароля
+
+DROP TABLE IF EXISTS account_tokens;
+
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,22 @@
+-- Подтверждение email и восстановление пароля
+
+-- Время подтверждения email; NULL - адрес не подтвержден
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL;
+
+-- Учетные записи, созданные до появления подтверждения, считаются подтвержденными
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Одноразовые токены подтверждения email и сброса пароля; хранится только SHA-256 токена
+CREATE TABLE IF NOT EXISTS account_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    purpose ENUM('verify_email', 'reset_password') NOT NULL,
+    email VARCHAR(255) NOT NULL, -- Адрес, на который отправлен токен
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_account_tokens_hash (token_hash),
+    INDEX idx_account_tokens_user (user_id, purpose),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Откат двухфакторной аутентификации
+
+DROP TABLE IF EXISTS recovery_codes;
+
+DROP TABLE IF EXISTS two_factor;
@@ -0,0 +1,22 @@
+-- Двухфакторная аутентификация по одноразовым кодам (TOTP)
+
+-- Секрет TOTP пользователя; хранится зашифрованным (AES-GCM)
+CREATE TABLE IF NOT EXISTS two_factor (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(255) NOT NULL,
+    confirmed_at TIMESTAMP NULL, -- NULL - подключение начато, но не подтверждено кодом
+    last_step BIGINT NOT NULL DEFAULT 0, -- Шаг времени последнего принятого кода; защита от повторного ввода
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Одноразовые коды восстановления; хранится только SHA-256 кода
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_recovery_codes (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
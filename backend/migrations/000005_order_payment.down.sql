@@ -0,0 +1,10 @@
+-- Откат платежа заказа
+
+ALTER TABLE orders
+    DROP FOREIGN KEY fk_orders_payment,
+    DROP COLUMN payment_id;
+
+UPDATE payments SET status = 'pending' WHERE status = 'processing';
+
+ALTER TABLE payments
+    MODIFY status ENUM('pending', 'succeeded', 'failed', 'refunded') NOT NULL DEFAULT 'pending';
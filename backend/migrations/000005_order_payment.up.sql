@@ -0,0 +1,11 @@
+-- Платеж, которым оплачен заказ, и статус обработки платежа
+
+-- processing: webhook об авторизации принят в обработку, повторная доставка
+-- того же события не списывает и не возвращает средства второй раз
+ALTER TABLE payments
+    MODIFY status ENUM('pending', 'processing', 'succeeded', 'failed', 'refunded') NOT NULL DEFAULT 'pending';
+
+-- Платеж, которым заказ переведен в paid; записывается в одной транзакции со статусом
+ALTER TABLE orders
+    ADD COLUMN payment_id INT NULL,
+    ADD CONSTRAINT fk_orders_payment FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Откат переименования порога правила occupancy
+
+ALTER TABLE pricing_rules
+    RENAME COLUMN availability_below TO occupancy_below;
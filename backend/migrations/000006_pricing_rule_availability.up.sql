@@ -0,0 +1,5 @@
+-- Порог правила occupancy задает остаток свободных мест, а не заполненность:
+-- колонка переименована, чтобы название совпадало с проверяемым условием
+
+ALTER TABLE pricing_rules
+    RENAME COLUMN occupancy_below TO availability_below;
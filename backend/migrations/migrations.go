@@ -0,0 +1,37 @@
+// Package migrations содержит версионированные миграции схемы базы данных
+// и наборы начальных данных, встраиваемые в исполняемые файлы.
+//
+// Файлы миграций называются <версия>_<название>.up.sql и
+// <версия>_<название>.down.sql; новую пару создает команда migrate create.
+// Наборы начальных данных лежат в подкаталогах seeds: roles - обязательные
+// роли пользователей, demo - демонстрационный каталог и учетные записи.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var schema embed.FS
+
+//go:embed seeds
+var seeds embed.FS
+
+// Schema возвращает файлы миграций схемы
+func Schema() fs.FS {
+	return schema
+}
+
+// Seeds возвращает наборы начальных данных, по одному подкаталогу на набор
+func Seeds() fs.FS {
+	sub, err := fs.Sub(seeds, "seeds")
+	if err != nil {
+		// Каталог встроен при сборке, ошибка возможна только при его переименовании
+		panic(err)
+	}
+	return sub
+}
+
+// RequiredSeeds наборы начальных данных, без которых приложение не работает
+var RequiredSeeds = []string{"roles"}
@@ -0,0 +1,195 @@
+-- Демонстрационный каталог: страны, города, отели, туры, расписания, правила цен и промоакции
+-- Повторное применение не создает дубликатов: строки с существующим ID пропускаются
+
+-- Страны
+INSERT IGNORE INTO countries (id, name, code) VALUES
+    (1, 'Россия', 'RU'),
+    (2, 'Турция', 'TR'),
+    (3, 'Египет', 'EG'),
+    (4, 'Таиланд', 'TH'),
+    (5, 'ОАЭ', 'AE'),
+    (6, 'Италия', 'IT'),
+    (7, 'Испания', 'ES'),
+    (8, 'Индонезия', 'ID'),
+    (9, 'Греция', 'GR'),
+    (10, 'Япония', 'JP'),
+    (11, 'Вьетнам', 'VN'),
+    (12, 'Мексика', 'MX'),
+    (13, 'Грузия', 'GE'),
+    (14, 'Мальдивы', 'MV'),
+    (15, 'Франция', 'FR');
+
+-- Города
+INSERT IGNORE INTO cities (id, country_id, name) VALUES
+    (1, 1, 'Москва'),
+    (2, 1, 'Санкт-Петербург'),
+    (3, 1, 'Сочи'),
+    (4, 1, 'Казань'),
+    (5, 1, 'Калининград'),
+    (6, 2, 'Анталья'),
+    (7, 2, 'Стамбул'),
+    (8, 2, 'Бодрум'),
+    (9, 3, 'Хургада'),
+    (10, 3, 'Шарм-эль-Шейх'),
+    (11, 4, 'Пхукет'),
+    (12, 4, 'Бангкок'),
+    (13, 4, 'Паттайя'),
+    (14, 5, 'Дубай'),
+    (15, 5, 'Абу-Даби'),
+    (16, 6, 'Рим'),
+    (17, 6, 'Венеция'),
+    (18, 6, 'Флоренция'),
+    (19, 7, 'Барселона'),
+    (20, 7, 'Мадрид'),
+    (21, 8, 'Бали'),
+    (22, 9, 'Афины'),
+    (23, 9, 'Санторини'),
+    (24, 10, 'Токио'),
+    (25, 10, 'Киото'),
+    (26, 11, 'Нячанг'),
+    (27, 11, 'Хошимин'),
+    (28, 12, 'Канкун'),
+    (29, 12, 'Мехико'),
+    (30, 13, 'Тбилиси'),
+    (31, 13, 'Батуми'),
+    (32, 14, 'Мале'),
+    (33, 15, 'Париж'),
+    (34, 15, 'Ницца');
+
+-- Отели
+INSERT IGNORE INTO hotels (id, city_id, name, description, address, category, image_url) VALUES
+    (1, 3, 'Сочи Марриотт Красная Поляна', 'Роскошный отель с видом на горы', 'Эсто-Садок, ул. Горная 5', 5, '/images/hotels/sochi_marriott.jpg'),
+    (2, 3, 'Radisson Blu Resort & Congress', 'Курортный отель рядом с пляжем', 'Адлер, ул. Морская 10', 4, '/images/hotels/radisson_sochi.jpg'),
+    (3, 6, 'Antalya Luxury Resort', 'Все включено на побережье Средиземного моря', 'Анталья, Пляжная улица 123', 5, '/images/hotels/turkey_deluxe.jpg'),
+    (4, 7, 'Istanbul Grand Hotel', 'В историческом центре Стамбула', 'Стамбул, ул. Султанахмет 15', 4, '/images/hotels/istanbul_grand.jpg'),
+    (5, 9, 'Red Sea Resort', 'Идеальный вариант для семейного отдыха', 'Хургада, ул. Набережная 45', 4, '/images/hotels/red_sea_resort.jpg'),
+    (6, 10, 'Sharm Premium Spa', 'Отель премиум-класса с собственным пляжем', 'Шарм-эль-Шейх, Наама Бей 28', 5, '/images/hotels/sharm_premium.jpg'),
+    (7, 11, 'Phuket Paradise', 'Бунгало на берегу моря', 'Пхукет, Пляж Патонг 30', 5, '/images/hotels/phuket_paradise.jpg'),
+    (8, 13, 'Pattaya Beach Resort', 'Отель в центре Паттайи', 'Паттайя, Бич Роуд 55', 4, '/images/hotels/pattaya_beach.jpg'),
+    (9, 14, 'Dubai Luxury Hotel', 'Роскошный отель в центре', 'Дубай, Шейх Зайед Роуд 100', 5, '/images/hotels/dubai_luxury.jpg'),
+    (10, 16, 'Roma Imperiale', 'Отель в самом сердце Рима', 'Рим, Виа дель Корсо 45', 4, '/images/hotels/roma_imperiale.jpg'),
+    (11, 19, 'Barcelona Sea View', 'С видом на Средиземное море', 'Барселона, Пасео Маритимо 28', 4, '/images/hotels/barcelona_sea.jpg'),
+    (12, 21, 'Bali Ocean Resort', 'Тропический рай на Бали', 'Бали, Кута Бич 15', 5, '/images/hotels/bali_ocean.jpg'),
+    (13, 23, 'Santorini Blue', 'Традиционный отель на утесе', 'Санторини, Ойя 22', 4, '/images/hotels/santorini_blue.jpg'),
+    (14, 24, 'Tokyo Skyline Hotel', 'Современный отель с видом на город', 'Токио, Синдзюку 78', 5, '/images/hotels/tokyo_skyline.jpg'),
+    (15, 26, 'Nha Trang Palms Resort', 'Тропический отель у океана', 'Нячанг, пр. Бич 112', 4, '/images/hotels/nhatrang_palms.jpg'),
+    (16, 28, 'Cancun Paradise Resort', 'Все включено на берегу Карибского моря', 'Канкун, Зона Отелей 45', 5, '/images/hotels/cancun_paradise.jpg'),
+    (17, 30, 'Old Tbilisi Hotel', 'Уютный отель в историческом центре', 'Тбилиси, ул. Руставели 27', 4, '/images/hotels/tbilisi_old.jpg'),
+    (18, 31, 'Batumi Sea View', 'Современный отель с видом на Черное море', 'Батуми, пр. Приморский 55', 4, '/images/hotels/batumi_sea.jpg'),
+    (19, 32, 'Maldives Water Villa', 'Виллы на воде с прямым доступом к океану', 'Южный Мале атолл', 5, '/images/hotels/maldives_villa.jpg'),
+    (20, 33, 'Paris Louvre Palace', 'Элегантный отель в сердце Парижа', 'Париж, ул. Риволи 14', 5, '/images/hotels/paris_louvre.jpg');
+
+-- Номера
+INSERT IGNORE INTO rooms (id, hotel_id, description, beds, price, image_url) VALUES
+    (1, 1, 'Стандартный номер с видом на горы', 2, 8000.00, '/images/rooms/sochi_standard.jpg'),
+    (2, 1, 'Люкс с балконом', 3, 15000.00, '/images/rooms/sochi_lux.jpg'),
+    (3, 2, 'Двухместный номер с видом на море', 2, 7500.00, '/images/rooms/radisson_double.jpg'),
+    (4, 3, 'Семейный номер с всё включено', 4, 12000.00, '/images/rooms/turkey_family.jpg'),
+    (5, 3, 'Люкс с видом на море', 2, 18000.00, '/images/rooms/turkey_lux.jpg'),
+    (6, 4, 'Стандартный номер в центре Стамбула', 2, 9000.00, '/images/rooms/istanbul_standard.jpg'),
+    (7, 5, 'Стандартный номер с видом на море', 2, 6500.00, '/images/rooms/egypt_standard.jpg'),
+    (8, 6, 'Премиум номер с джакузи', 2, 14000.00, '/images/rooms/sharm_premium.jpg'),
+    (9, 7, 'Бунгало на пляже', 2, 9000.00, '/images/rooms/phuket_bungalow.jpg'),
+    (10, 8, 'Стандартный номер в Паттайе', 2, 5000.00, '/images/rooms/pattaya_standard.jpg'),
+    (11, 9, 'Премиум с видом на город', 2, 18000.00, '/images/rooms/dubai_premium.jpg'),
+    (12, 10, 'Классический номер в Риме', 2, 12000.00, '/images/rooms/rome_classic.jpg'),
+    (13, 11, 'Номер с видом на море', 2, 13000.00, '/images/rooms/barcelona_sea.jpg'),
+    (14, 12, 'Вилла с бассейном', 4, 25000.00, '/images/rooms/bali_villa.jpg'),
+    (15, 13, 'Номер с видом на кальдеру', 2, 16000.00, '/images/rooms/santorini_view.jpg'),
+    (16, 14, 'Современный номер в Токио', 2, 15000.00, '/images/rooms/tokyo_modern.jpg'),
+    (17, 15, 'Делюкс с видом на океан', 2, 7500.00, '/images/rooms/nhatrang_deluxe.jpg'),
+    (18, 16, 'Семейный люкс с видом на океан', 4, 19000.00, '/images/rooms/cancun_family.jpg'),
+    (19, 17, 'Традиционный грузинский номер', 2, 5500.00, '/images/rooms/tbilisi_traditional.jpg'),
+    (20, 18, 'Панорамный номер с видом на море', 2, 8500.00, '/images/rooms/batumi_panorama.jpg'),
+    (21, 19, 'Водная вилла с джакузи', 2, 45000.00, '/images/rooms/maldives_water.jpg'),
+    (22, 20, 'Номер с видом на Эйфелеву башню', 2, 22000.00, '/images/rooms/paris_eiffel.jpg');
+
+-- Туры
+INSERT IGNORE INTO tours (id, city_id, name, description, base_price, image_url, duration, is_active) VALUES
+    (1, 3, 'Горнолыжный отдых в Сочи', 'Зимний отдых на склонах Красной Поляны. Катание на лыжах и сноубордах, СПА-процедуры после активного дня и вечерние развлечения в ресторанах и барах курорта.', 45000.00, '/images/tours/sochi_ski.jpg', 7, true),
+    (2, 3, 'Летний отдых в Сочи', 'Пляжный отдых на Черном море. Плавание в теплой морской воде, экскурсии по олимпийским объектам, посещение дендрария и парка развлечений.', 35000.00, '/images/tours/sochi_beach.jpg', 10, true),
+    (3, 6, 'Все включено в Турции', 'Отдых на курортах Анталии с системой все включено. Проживание в роскошном отеле на первой линии, питание в ресторанах отеля, развлечения и анимация.', 55000.00, '/images/tours/turkey_all_inclusive.jpg', 7, true),
+    (4, 7, 'Исторический Стамбул', 'Экскурсионный тур по историческим местам Стамбула. Посещение Голубой мечети, дворца Топкапы, Гранд Базара и круиз по Босфору.', 45000.00, '/images/tours/istanbul_historic.jpg', 5, true),
+    (5, 9, 'Древний Египет', 'Экскурсионный тур с посещением древних пирамид, Сфинкса, храмов Луксора и Карнакского храма. Комбинированный с пляжным отдыхом на Красном море.', 60000.00, '/images/tours/egypt_ancient.jpg', 8, true),
+    (6, 10, 'Отдых в Шарм-эль-Шейхе', 'Пляжный отдых в одном из лучших курортов Египта. Снорклинг и дайвинг на коралловых рифах, сафари в пустыне и вечерние развлечения.', 50000.00, '/images/tours/sharm_beach.jpg', 9, true),
+    (7, 11, 'Райский Таиланд', 'Отдых на лучших пляжах Пхукета. Экскурсии на острова Пхи-Пхи, поездка в тропический лес, посещение этнической деревни и храмов.', 85000.00, '/images/tours/thailand_paradise.jpg', 12, true),
+    (8, 13, 'Паттайя - город развлечений', 'Активный отдых в Паттайе. Водные виды спорта, посещение шоу трансвеститов, парков развлечений и тропического сада Нонг Нуч.', 75000.00, '/images/tours/pattaya_fun.jpg', 10, true),
+    (9, 14, 'Роскошный Дубай', 'Шоппинг и достопримечательности Эмиратов. Посещение Бурдж-Халифа, шоппинг в Dubai Mall, сафари в пустыне и круиз по бухте Дубая.', 90000.00, '/images/tours/dubai_luxury_tour.jpg', 5, true),
+    (10, 16, 'Вечный город Рим', 'Экскурсионный тур по Риму с посещением Колизея, Форума, Ватикана, фонтана Треви и других исторических мест.', 90000.00, '/images/tours/rome_eternal.jpg', 7, true),
+    (11, 19, 'Солнечная Барселона', 'Знакомство с творениями Гауди, прогулки по Рамбле, посещение Готического квартала и отдых на пляжах Барселоны.', 80000.00, '/images/tours/barcelona_sun.jpg', 6, true),
+    (12, 21, 'Экзотический Бали', 'Отдых на лучших пляжах Бали, посещение храмов, рисовых террас, вулкана Батур и обезьяньего леса.', 120000.00, '/images/tours/bali_exotic.jpg', 14, true),
+    (13, 23, 'Романтика Санторини', 'Отдых на вулканическом острове с белоснежными домиками и голубыми куполами церквей. Посещение древних руин, виноделен и купание в Эгейском море.', 110000.00, '/images/tours/santorini_romance.jpg', 8, true),
+    (14, 24, 'Технологичный Токио', 'Экскурсии по современному Токио, посещение Акихабары, храма Сенсо-дзи, императорского дворца и смотровой площадки Tokyo Skytree.', 130000.00, '/images/tours/tokyo_tech.jpg', 9, true),
+    (15, 26, 'Тропический Вьетнам', 'Пляжный отдых в Нячанге с экскурсиями на острова, грязевые ванны и дегустацией местной кухни', 65000.00, '/images/tours/vietnam_tropical.jpg', 12, true),
+    (16, 28, 'Карибское побережье Мексики', 'Отдых на белоснежных пляжах Канкуна, посещение древних городов майя и заповедников', 115000.00, '/images/tours/mexico_caribean.jpg', 10, true),
+    (17, 30, 'Гостеприимная Грузия', 'Культурный тур с посещением Тбилиси, Мцхеты и дегустацией вин в Кахетии', 42000.00, '/images/tours/georgia_wine.jpg', 8, true),
+    (18, 31, 'Черное море в Батуми', 'Пляжный отдых и экскурсии по Батуми и окрестностям, знаменитая набережная и ботанический сад', 38000.00, '/images/tours/batumi_relax.jpg', 7, true),
+    (19, 32, 'Мальдивский рай', 'Отдых на роскошном курорте на частном острове, снорклинг в лазурных водах, SPA-процедуры', 180000.00, '/images/tours/maldives_paradise.jpg', 10, true),
+    (20, 33, 'Романтичный Париж', 'Классический тур по столице Франции с посещением Лувра, Монмартра, Эйфелевой башни и круизом по Сене', 95000.00, '/images/tours/paris_romantic.jpg', 6, true);
+
+-- Доступные даты туров
+INSERT IGNORE INTO tour_dates (id, tour_id, start_date, end_date, capacity, availability, base_modifier, price_modifier) VALUES
+    (1, 1, '2024-01-15', '2024-01-22', 20, 20, 1.0, 1.0),
+    (2, 1, '2024-01-25', '2024-02-01', 15, 15, 1.5, 1.5),
+    (3, 1, '2024-02-10', '2024-02-17', 10, 10, 1.2, 1.2),
+    (4, 2, '2024-06-01', '2024-06-11', 30, 30, 1.0, 1.0),
+    (5, 2, '2024-07-01', '2024-07-11', 25, 25, 1.2, 1.2),
+    (6, 2, '2024-08-05', '2024-08-15', 20, 20, 1.3, 1.3),
+    (7, 3, '2024-05-10', '2024-05-17', 40, 40, 1.0, 1.0),
+    (8, 3, '2024-06-15', '2024-06-22', 35, 35, 1.1, 1.1),
+    (9, 3, '2024-07-20', '2024-07-27', 30, 30, 1.2, 1.2),
+    (10, 4, '2024-04-05', '2024-04-10', 25, 25, 1.0, 1.0),
+    (11, 4, '2024-05-15', '2024-05-20', 20, 20, 1.1, 1.1),
+    (12, 5, '2024-09-05', '2024-09-13', 25, 25, 1.0, 1.0),
+    (13, 5, '2024-10-10', '2024-10-18', 20, 20, 0.9, 0.9),
+    (14, 6, '2024-06-01', '2024-06-10', 30, 30, 1.0, 1.0),
+    (15, 6, '2024-07-15', '2024-07-24', 25, 25, 1.1, 1.1),
+    (16, 7, '2024-11-01', '2024-11-13', 20, 20, 1.0, 1.0),
+    (17, 7, '2024-12-05', '2024-12-17', 15, 15, 1.2, 1.2),
+    (18, 8, '2024-03-10', '2024-03-20', 30, 30, 0.9, 0.9),
+    (19, 8, '2024-04-15', '2024-04-25', 25, 25, 1.0, 1.0),
+    (20, 9, '2024-09-05', '2024-09-10', 15, 15, 1.0, 1.0),
+    (21, 9, '2024-10-15', '2024-10-20', 10, 10, 1.1, 1.1),
+    (22, 10, '2024-05-01', '2024-05-08', 20, 20, 1.0, 1.0),
+    (23, 10, '2024-06-10', '2024-06-17', 15, 15, 1.1, 1.1),
+    (24, 11, '2024-07-05', '2024-07-11', 25, 25, 1.0, 1.0),
+    (25, 11, '2024-08-20', '2024-08-26', 20, 20, 1.1, 1.1),
+    (26, 12, '2024-06-01', '2024-06-15', 15, 15, 1.0, 1.0),
+    (27, 12, '2024-07-10', '2024-07-24', 10, 10, 1.2, 1.2),
+    (28, 13, '2024-05-15', '2024-05-23', 20, 20, 1.0, 1.0),
+    (29, 13, '2024-06-20', '2024-06-28', 15, 15, 1.1, 1.1),
+    (30, 14, '2024-04-10', '2024-04-19', 20, 20, 1.0, 1.0),
+    (31, 14, '2024-05-20', '2024-05-29', 15, 15, 1.1, 1.1),
+    (32, 15, '2024-05-05', '2024-05-17', 25, 25, 1.0, 1.0),
+    (33, 15, '2024-06-15', '2024-06-27', 20, 20, 1.1, 1.1),
+    (34, 16, '2024-07-10', '2024-07-20', 20, 20, 1.0, 1.0),
+    (35, 16, '2024-08-15', '2024-08-25', 15, 15, 1.2, 1.2),
+    (36, 17, '2024-04-10', '2024-04-18', 30, 30, 0.9, 0.9),
+    (37, 17, '2024-05-15', '2024-05-23', 25, 25, 1.0, 1.0),
+    (38, 18, '2024-06-05', '2024-06-12', 25, 25, 1.0, 1.0),
+    (39, 18, '2024-08-10', '2024-08-17', 20, 20, 1.1, 1.1),
+    (40, 19, '2024-09-15', '2024-09-25', 15, 15, 1.0, 1.0),
+    (41, 19, '2024-10-20', '2024-10-30', 10, 10, 0.9, 0.9),
+    (42, 20, '2024-04-10', '2024-04-16', 25, 25, 1.0, 1.0),
+    (43, 20, '2024-05-15', '2024-05-21', 20, 20, 1.1, 1.1);
+
+-- Правила расписания отправлений (weekdays: 1 - понедельник, 16 - пятница, 32 - суббота)
+INSERT IGNORE INTO tour_schedules (id, tour_id, name, weekdays, season_start, season_end, blackout_dates, capacity, price_modifiers) VALUES
+    (1, 1, 'Зимний сезон, заезды по субботам', 32, '2024-12-01', '2025-04-15', '["2024-12-28", "2025-01-04"]', 20, '{"1": 1.5, "2": 1.2, "3": 1.1}'),
+    (2, 2, 'Летний сезон, заезды по понедельникам и пятницам', 17, '2024-05-15', '2024-09-30', '[]', 30, '{"7": 1.2, "8": 1.3}'),
+    (3, 3, 'Круглый год, заезды по субботам', 32, NULL, NULL, '[]', 40, '{"6": 1.1, "7": 1.2, "8": 1.2}');
+
+-- Правила ценообразования
+INSERT IGNORE INTO pricing_rules (id, name, kind, modifier, country_id, city_id, season_start, season_end, availability_below, days_before) VALUES
+    (1, 'Новогодние праздники', 'season', 1.30, NULL, NULL, '2024-12-28', '2025-01-08', NULL, NULL),
+    (2, 'Низкий сезон в Египте', 'season', 0.85, 3, NULL, '2024-06-01', '2024-08-31', NULL, NULL),
+    (3, 'Заезд в выходные', 'weekend', 1.05, NULL, NULL, NULL, NULL, NULL, NULL),
+    (4, 'Мало свободных мест', 'occupancy', 1.10, NULL, NULL, NULL, NULL, 20, NULL),
+    (5, 'Горящий тур', 'last_minute', 0.80, NULL, NULL, NULL, NULL, NULL, 7);
+
+-- Промоакции
+INSERT IGNORE INTO promotions (id, code, title, discount_type, percent, amount, target, min_people, early_bird_days, max_uses, max_uses_per_user) VALUES
+    (1, NULL, 'Раннее бронирование', 'percent', 10.00, 0, 'order', 0, 60, NULL, NULL),
+    (2, NULL, 'Групповая скидка от 5 человек', 'percent', 7.00, 0, 'order', 5, 0, NULL, NULL),
+    (3, NULL, 'Детский тариф', 'percent', 50.00, 0, 'children', 0, 0, NULL, NULL),
+    (4, 'WELCOME', 'Скидка на первое путешествие', 'fixed', 0, 3000.00, 'order', 0, 0, 1000, 1);
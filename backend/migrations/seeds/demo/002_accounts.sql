@@ -0,0 +1,22 @@
+-- Демонстрационные пользователи, заказы и обращения в поддержку; требует набора roles
+-- Повторное применение не создает дубликатов: строки с существующим ID пропускаются
+
+-- Пользователи (пароль: password), email подтвержден
+INSERT IGNORE INTO users (id, username, password, email, first_name, last_name, full_name, phone, role_id, email_verified_at) VALUES
+    (1, 'admin', '$2a$10$VnSofjaTgaF8SwigfzYseuxTDmD0MCXOE3qn70NrNnlAt2Zgk3J8a', 'admin@example.com', 'Администратор', '', 'Администратор', '+7 (999) 123-45-67', 1, CURRENT_TIMESTAMP),
+    (2, 'user1', '$2a$10$VnSofjaTgaF8SwigfzYseuxTDmD0MCXOE3qn70NrNnlAt2Zgk3J8a', 'user1@example.com', 'Иван', 'Иванов', 'Иван Иванов', '+7 (999) 765-43-21', 2, CURRENT_TIMESTAMP),
+    (3, 'support1', '$2a$10$VnSofjaTgaF8SwigfzYseuxTDmD0MCXOE3qn70NrNnlAt2Zgk3J8a', 'support1@example.com', 'Сотрудник', 'Поддержки', 'Сотрудник Поддержки', '+7 (999) 111-22-33', 3, CURRENT_TIMESTAMP);
+
+-- Заказы
+INSERT IGNORE INTO orders (id, user_id, tour_id, tour_date_id, room_id, people_count, total_price, status) VALUES
+    (1, 2, 1, 1, 1, 2, 98000.00, 'confirmed'),
+    (2, 2, 3, 7, 4, 3, 165000.00, 'paid');
+
+-- Тикеты поддержки
+INSERT IGNORE INTO support_tickets (id, user_id, subject, status) VALUES
+    (1, 2, 'Вопрос по бронированию', 'open');
+
+-- Сообщения в тикетах
+INSERT IGNORE INTO ticket_messages (id, ticket_id, user_id, message) VALUES
+    (1, 1, 2, 'Здравствуйте! Я хотел бы уточнить детали моего бронирования.'),
+    (2, 1, 3, 'Добрый день! Какие именно детали вас интересуют?');
@@ -1,4 +1,4 @@
--- Скрипт для добавления двух отелей-заполнителей в каждый город, где есть туры
+-- Два отеля-заполнителя в каждом городе, где есть туры; уже добавленные отели пропускаются
 
 -- Установка переменных для данных первого отеля
 SET @hotel1_name = 'Placeholder Hotel Alpha';
@@ -0,0 +1,18 @@
+-- Обязательные системные роли и их права по умолчанию
+-- Повторное применение не создает дубликатов: строки с существующим ID пропускаются
+
+-- Роли пользователей
+INSERT IGNORE INTO roles (id, name, description, is_system, is_default) VALUES
+    (1, 'admin', 'Администратор', TRUE, FALSE),
+    (2, 'user', 'Клиент турагентства', TRUE, TRUE),
+    (3, 'support', 'Сотрудник тех-поддержки', TRUE, FALSE);
+
+-- Права назначаются только роли без прав, чтобы не отменять изменения администратора
+INSERT IGNORE INTO role_permissions (role_id, permission)
+SELECT 1, p.name FROM permissions p
+WHERE NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = 1);
+
+INSERT IGNORE INTO role_permissions (role_id, permission)
+SELECT 3, p.name FROM permissions p
+WHERE p.name IN ('tickets:read', 'tickets:assign')
+  AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = 3);
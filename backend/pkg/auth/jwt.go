@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -10,18 +12,43 @@ import (
 	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
 )
 
+// Типы токенов, записываемые в claim "typ"
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeChallenge токен подтверждения входа, обмениваемый на пару токенов по коду второго фактора
+	TokenTypeChallenge = "2fa"
+)
+
+// DefaultChallengeTTL срок действия токена подтверждения входа по умолчанию
+const DefaultChallengeTTL = 5 * time.Minute
+
+// ErrWrongTokenType возвращается, когда токен одного типа используется вместо другого
+var ErrWrongTokenType = errors.New("неверный тип токена")
+
 // TokenClaims структура для хранения данных в JWT токене
 type TokenClaims struct {
 	UserID int64  `json:"user_id"`
 	Role   string `json:"role"`
+	Type   string `json:"typ"`
+	// Version версия токенов пользователя на момент выдачи
+	Version int `json:"ver"`
+	// Family идентификатор семейства refresh токенов (одна сессия входа)
+	Family string `json:"fam,omitempty"`
+	// MFA сессия подтверждена вторым фактором
+	MFA bool `json:"mfa,omitempty"`
 	jwt.StandardClaims
 }
 
 // TokenManager интерфейс для работы с JWT токенами
 type TokenManager interface {
-	GenerateAccessToken(userID int64, role string) (string, error)
-	GenerateRefreshToken(userID int64) (string, error)
+	GenerateAccessToken(userID int64, role string, version int, mfa bool) (string, error)
+	GenerateRefreshToken(userID int64, tokenID, familyID string, mfa bool) (string, time.Time, error)
+	GenerateChallengeToken(userID int64, version int) (string, error)
 	ParseToken(token string) (*TokenClaims, error)
+	ParseAccessToken(token string) (*TokenClaims, error)
+	ParseRefreshToken(token string) (*TokenClaims, error)
+	ParseChallengeToken(token string) (*TokenClaims, error)
 }
 
 // JWTManager реализация TokenManager с использованием JWT
@@ -29,22 +56,32 @@ type JWTManager struct {
 	signingKey      string
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	challengeTTL    time.Duration
 }
 
 // NewJWTManager создает новый экземпляр JWTManager
 func NewJWTManager(cfg config.JWTConfig) *JWTManager {
+	challengeTTL := time.Duration(cfg.ChallengeExpiration) * time.Minute
+	if challengeTTL <= 0 {
+		challengeTTL = DefaultChallengeTTL
+	}
+
 	return &JWTManager{
 		signingKey:      cfg.Secret,
 		accessTokenTTL:  time.Duration(cfg.AccessExpiration) * time.Minute,
 		refreshTokenTTL: time.Duration(cfg.RefreshExpiration) * time.Hour,
+		challengeTTL:    challengeTTL,
 	}
 }
 
-// GenerateAccessToken генерирует JWT access токен
-func (m *JWTManager) GenerateAccessToken(userID int64, role string) (string, error) {
+// GenerateAccessToken генерирует JWT access токен; mfa - вход подтвержден вторым фактором
+func (m *JWTManager) GenerateAccessToken(userID int64, role string, version int, mfa bool) (string, error) {
 	claims := TokenClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:  userID,
+		Role:    role,
+		Type:    TokenTypeAccess,
+		Version: version,
+		MFA:     mfa,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(m.accessTokenTTL).Unix(),
 			IssuedAt:  time.Now().Unix(),
@@ -55,19 +92,25 @@ func (m *JWTManager) GenerateAccessToken(userID int64, role string) (string, err
 	return token.SignedString([]byte(m.signingKey))
 }
 
-// GenerateRefreshToken генерирует JWT refresh токен
-func (m *JWTManager) GenerateRefreshToken(userID int64) (string, error) {
+// GenerateRefreshToken генерирует JWT refresh токен с идентификатором tokenID (jti)
+// в семействе familyID. Признак mfa переносится в access токены, выпущенные по нему.
+// Возвращает токен и время его истечения.
+func (m *JWTManager) GenerateRefreshToken(userID int64, tokenID, familyID string, mfa bool) (string, time.Time, error) {
 	log.Printf("[JWT] Генерация refresh токена для пользователя ID: %d", userID)
 
+	expiresAt := time.Now().Add(m.refreshTokenTTL)
 	claims := TokenClaims{
 		UserID: userID,
+		Type:   TokenTypeRefresh,
+		Family: familyID,
+		MFA:    mfa,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(m.refreshTokenTTL).Unix(),
+			Id:        tokenID,
+			ExpiresAt: expiresAt.Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
 	}
 
-	expiresAt := time.Unix(claims.ExpiresAt, 0)
 	log.Printf("[JWT] Refresh токен истекает: %v (через %v)", expiresAt, m.refreshTokenTTL)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -75,11 +118,28 @@ func (m *JWTManager) GenerateRefreshToken(userID int64) (string, error) {
 
 	if err != nil {
 		log.Printf("[JWT] Ошибка подписи refresh токена: %v", err)
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	log.Printf("[JWT] Refresh токен успешно создан, длина: %d", len(tokenString))
-	return tokenString, nil
+	return tokenString, expiresAt, nil
+}
+
+// GenerateChallengeToken генерирует короткоживущий токен подтверждения входа.
+// Версия токенов пользователя делает его недействительным после смены пароля.
+func (m *JWTManager) GenerateChallengeToken(userID int64, version int) (string, error) {
+	claims := TokenClaims{
+		UserID:  userID,
+		Type:    TokenTypeChallenge,
+		Version: version,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(m.challengeTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(m.signingKey))
 }
 
 // ParseToken разбирает JWT токен и возвращает данные из него
@@ -132,3 +192,47 @@ func (m *JWTManager) ParseToken(tokenString string) (*TokenClaims, error) {
 
 	return claims, nil
 }
+
+// ParseAccessToken разбирает токен и проверяет, что это access токен
+func (m *JWTManager) ParseAccessToken(tokenString string) (*TokenClaims, error) {
+	return m.parseTyped(tokenString, TokenTypeAccess)
+}
+
+// ParseRefreshToken разбирает токен и проверяет, что это refresh токен с jti и семейством
+func (m *JWTManager) ParseRefreshToken(tokenString string) (*TokenClaims, error) {
+	claims, err := m.parseTyped(tokenString, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Id == "" || claims.Family == "" {
+		return nil, errors.New("refresh токен не содержит идентификатора")
+	}
+	return claims, nil
+}
+
+// ParseChallengeToken разбирает токен и проверяет, что это токен подтверждения входа
+func (m *JWTManager) ParseChallengeToken(tokenString string) (*TokenClaims, error) {
+	return m.parseTyped(tokenString, TokenTypeChallenge)
+}
+
+// parseTyped разбирает токен и сверяет его тип
+func (m *JWTManager) parseTyped(tokenString, tokenType string) (*TokenClaims, error) {
+	claims, err := m.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != tokenType {
+		log.Printf("[JWT] Ожидался токен типа %q, получен %q", tokenType, claims.Type)
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// NewTokenID генерирует случайный идентификатор для jti и семейств токенов
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать идентификатор токена: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
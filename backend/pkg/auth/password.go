@@ -11,7 +11,6 @@ const bcryptCost = 12
 
 // HashPassword хеширует пароль используя bcrypt
 func HashPassword(password string) (string, error) {
-	log.Printf("[Auth] Хеширование пароля (длина: %d)", len(password))
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	if err != nil {
 		log.Printf("[Auth] Ошибка хеширования пароля: %v", err)
@@ -22,8 +21,6 @@ func HashPassword(password string) (string, error) {
 
 // CheckPassword проверяет соответствие пароля и хеша
 func CheckPassword(password, hash string) bool {
-	log.Printf("[Auth] Проверка пароля (длина пароля: %d, длина хеша: %d)", len(password), len(hash))
-
 	if len(hash) == 0 {
 		log.Printf("[Auth] Ошибка: хеш пароля пустой")
 		return false
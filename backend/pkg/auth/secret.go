@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewSecretToken генерирует одноразовый токен для ссылок в письмах и его хеш.
+// Пользователю отправляется токен, в базе хранится только хеш.
+func NewSecretToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("не удалось сгенерировать токен: %w", err)
+	}
+	token = hex.EncodeToString(b)
+	return token, HashSecretToken(token), nil
+}
+
+// HashSecretToken возвращает хеш токена для поиска в базе. Токен содержит
+// 256 случайных бит, поэтому быстрого SHA-256 без соли достаточно.
+func HashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
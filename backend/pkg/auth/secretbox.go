@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrSecretBoxOpen шифротекст поврежден или зашифрован другим ключом
+var ErrSecretBoxOpen = errors.New("не удалось расшифровать секрет")
+
+// SecretBox шифрует секреты для хранения в базе (AES-256-GCM).
+// Ключ выводится из строки конфигурации через SHA-256.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox создает SecretBox с ключом, выведенным из key
+func NewSecretBox(key string) (*SecretBox, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать шифр: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать шифр: %w", err)
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal шифрует строку; результат - nonce и шифротекст в base64
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open расшифровывает строку, полученную от Seal
+func (b *SecretBox) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrSecretBoxOpen
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrSecretBoxOpen
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache хранилище байтовых значений со сроком жизни
+type Cache interface {
+	// Get возвращает значение по ключу; false, если ключа нет или срок его жизни истек
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set сохраняет значение на ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete удаляет ключи
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix удаляет все ключи с префиксом
+	DeletePrefix(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memorySweepEvery через сколько записей в кэш удаляются просроченные значения
+const memorySweepEvery = 1000
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// memoryCache кэш в памяти процесса; используется, когда Redis недоступен
+type memoryCache struct {
+	mu     sync.RWMutex
+	items  map[string]memoryEntry
+	writes int
+}
+
+// NewMemoryCache создает кэш в памяти процесса
+func NewMemoryCache() Cache {
+	return &memoryCache{items: make(map[string]memoryEntry)}
+}
+
+// Get возвращает значение по ключу
+func (m *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	entry, ok := m.items[key]
+	m.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set сохраняет значение на ttl
+func (m *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items[key] = memoryEntry{value: value, expires: time.Now().Add(ttl)}
+
+	// Просроченные значения удаляются при записи, отдельная горутина не нужна
+	m.writes++
+	if m.writes >= memorySweepEvery {
+		m.writes = 0
+		now := time.Now()
+		for k, e := range m.items {
+			if now.After(e.expires) {
+				delete(m.items, k)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete удаляет ключи
+func (m *memoryCache) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	return nil
+}
+
+// DeletePrefix удаляет все ключи с префиксом
+func (m *memoryCache) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.items, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats счетчики обращений к группе ключей
+type Stats struct {
+	Namespace string  `json:"namespace"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+// Namespace группа ключей с общим префиксом, сроком жизни и счетчиками
+// попаданий. Значения хранятся в JSON.
+type Namespace struct {
+	cache  Cache
+	name   string
+	ttl    time.Duration
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Key возвращает полный ключ значения в группе
+func (n *Namespace) Key(key string) string {
+	return n.name + ":" + key
+}
+
+// Invalidate удаляет значения по ключам группы. Ошибка хранилища только
+// логируется: значение в любом случае устареет по сроку жизни.
+func (n *Namespace) Invalidate(ctx context.Context, keys ...string) {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = n.Key(key)
+	}
+	if err := n.cache.Delete(ctx, full...); err != nil {
+		log.Printf("[Cache] Ошибка при удалении ключей %s: %v", n.name, err)
+	}
+}
+
+// InvalidateAll удаляет все значения группы
+func (n *Namespace) InvalidateAll(ctx context.Context) {
+	if err := n.cache.DeletePrefix(ctx, n.name+":"); err != nil {
+		log.Printf("[Cache] Ошибка при очистке %s: %v", n.name, err)
+	}
+}
+
+// Stats возвращает счетчики обращений группы
+func (n *Namespace) Stats() Stats {
+	stats := Stats{Namespace: n.name, Hits: n.hits.Load(), Misses: n.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Fetch возвращает значение из кэша или загружает его через load и сохраняет.
+// Недоступность хранилища не мешает чтению: значение загружается напрямую.
+func Fetch[T any](ctx context.Context, n *Namespace, key string, load func() (T, error)) (T, error) {
+	full := n.Key(key)
+
+	data, ok, err := n.cache.Get(ctx, full)
+	if err != nil {
+		log.Printf("[Cache] Ошибка при чтении %s: %v", full, err)
+	}
+	if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			n.hits.Add(1)
+			return value, nil
+		}
+		log.Printf("[Cache] Поврежденное значение %s: %v", full, err)
+	}
+	n.misses.Add(1)
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	if data, err := json.Marshal(value); err != nil {
+		log.Printf("[Cache] Ошибка при сериализации %s: %v", full, err)
+	} else if err := n.cache.Set(ctx, full, data, n.ttl); err != nil {
+		log.Printf("[Cache] Ошибка при записи %s: %v", full, err)
+	}
+	return value, nil
+}
+
+// Registry создает группы ключей поверх общего хранилища и собирает их статистику
+type Registry struct {
+	cache      Cache
+	mu         sync.Mutex
+	namespaces map[string]*Namespace
+}
+
+// NewRegistry создает реестр групп ключей
+func NewRegistry(cache Cache) *Registry {
+	return &Registry{cache: cache, namespaces: make(map[string]*Namespace)}
+}
+
+// Namespace возвращает группу ключей с именем name; ttl задается при первом обращении
+func (r *Registry) Namespace(name string, ttl time.Duration) *Namespace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n, ok := r.namespaces[name]; ok {
+		return n
+	}
+	n := &Namespace{cache: r.cache, name: name, ttl: ttl}
+	r.namespaces[name] = n
+	return n
+}
+
+// Stats возвращает счетчики всех групп, упорядоченные по имени
+func (r *Registry) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]Stats, 0, len(r.namespaces))
+	for _, n := range r.namespaces {
+		stats = append(stats, n.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Namespace < stats[j].Namespace })
+	return stats
+}
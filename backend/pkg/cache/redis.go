@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisScanBatch сколько ключей запрашивается за один шаг SCAN при удалении по префиксу
+const redisScanBatch = 500
+
+// redisCache кэш в Redis. Ключи получают общий префикс, чтобы не пересекаться
+// с другими данными в той же базе Redis.
+type redisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache создает кэш поверх клиента Redis
+func NewRedisCache(client *redis.Client, prefix string) Cache {
+	return &redisCache{client: client, prefix: prefix}
+}
+
+// Get возвращает значение по ключу
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set сохраняет значение на ttl
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete удаляет ключи
+func (r *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// DeletePrefix удаляет все ключи с префиксом. Используется SCAN, а не KEYS,
+// чтобы не блокировать Redis на больших базах.
+func (r *redisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, r.prefix+prefix+"*", redisScanBatch).Iterator()
+
+	batch := make([]string, 0, redisScanBatch)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == redisScanBatch {
+			if err := r.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return r.client.Del(ctx, batch...).Err()
+	}
+	return nil
+}
@@ -12,7 +12,7 @@ import (
 func NewRedisClient(cfg config.RedisConfig) (*redis.Client, error) {
 	// Создание клиента Redis
 	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.DB,
 	})
@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+)
+
+// Идентификаторы способов доставки писем
+const (
+	LogDriverName  = "log"  // письма выводятся в журнал приложения
+	FileDriverName = "file" // письма сохраняются в каталог outbox в формате .eml
+	SMTPDriverName = "smtp" // письма отправляются через SMTP сервер
+)
+
+// DefaultOutboxDir каталог писем для способа доставки file по умолчанию
+const DefaultOutboxDir = "outbox"
+
+// Message письмо с текстовым телом
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer интерфейс доставки писем
+type Mailer interface {
+	// Send доставляет письмо; возвращает ошибку, если письмо не принято к доставке
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer создает способ доставки писем согласно конфигурации
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "", LogDriverName:
+		return NewLogMailer(cfg.From), nil
+	case FileDriverName:
+		dir := cfg.OutboxDir
+		if dir == "" {
+			dir = DefaultOutboxDir
+		}
+		return NewFileMailer(cfg.From, dir)
+	case SMTPDriverName:
+		if cfg.Host == "" || cfg.From == "" {
+			return nil, fmt.Errorf("для доставки через SMTP нужны host и from")
+		}
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("неизвестный способ доставки писем: %s", cfg.Driver)
+	}
+}
+
+// compose формирует письмо в формате RFC 5322 с телом в UTF-8
+func compose(from string, msg Message, now time.Time) []byte {
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	writeHeader("From", formatAddress(from))
+	writeHeader("To", msg.To)
+	writeHeader("Subject", mime.BEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	// Строки base64 ограничены 76 символами (RFC 2045)
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(body) > 76 {
+		buf.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	buf.WriteString(body + "\r\n")
+
+	return buf.Bytes()
+}
+
+// formatAddress кодирует имя отправителя в заголовке по RFC 2047;
+// адрес, который не удалось разобрать, используется как есть
+func formatAddress(addr string) string {
+	parsed, err := netmail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return parsed.String()
+}
+
+// envelopeAddress возвращает адрес без имени для команды SMTP MAIL FROM
+func envelopeAddress(addr string) string {
+	parsed, err := netmail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return parsed.Address
+}
+
+// validateAddress отклоняет адреса с переводами строк, которые позволили бы
+// дописать в письмо произвольные заголовки
+func validateAddress(addr string) error {
+	if addr == "" || strings.ContainsAny(addr, "\r\n") {
+		return fmt.Errorf("некорректный адрес получателя %q", addr)
+	}
+	return nil
+}
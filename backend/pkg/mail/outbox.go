@@ -0,0 +1,69 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer выводит письма в журнал приложения; используется в разработке
+// без почтового сервера, ссылки из писем можно взять из журнала
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer создает доставку писем в журнал
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+// Send выводит письмо в журнал
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := validateAddress(msg.To); err != nil {
+		return err
+	}
+	log.Printf("[Mail] Письмо от %s для %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer сохраняет письма в каталог outbox файлами .eml,
+// которые открываются почтовым клиентом
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+// NewFileMailer создает доставку писем в каталог, создавая его при необходимости
+func NewFileMailer(from, dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога писем %s: %w", dir, err)
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+// Send сохраняет письмо в отдельный файл
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := validateAddress(msg.To); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("ошибка формирования имени письма: %w", err)
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s_%s.eml", now.Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, compose(m.from, msg, now), 0o644); err != nil {
+		return fmt.Errorf("ошибка сохранения письма: %w", err)
+	}
+
+	log.Printf("[Mail] Письмо для %s сохранено в %s", msg.To, path)
+	return nil
+}
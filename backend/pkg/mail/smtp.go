@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+)
+
+// defaultSMTPTimeout ограничение на отправку одного письма, если в контексте нет срока
+const defaultSMTPTimeout = 30 * time.Second
+
+// SMTPMailer отправляет письма через SMTP сервер. STARTTLS используется,
+// если сервер его поддерживает; авторизация - если задано имя пользователя.
+type SMTPMailer struct {
+	from     string
+	addr     string
+	host     string
+	username string
+	password string
+}
+
+// NewSMTPMailer создает доставку писем через SMTP
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	port := cfg.Port
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPMailer{
+		from:     cfg.From,
+		addr:     net.JoinHostPort(cfg.Host, port),
+		host:     cfg.Host,
+		username: cfg.Username,
+		password: cfg.Password,
+	}
+}
+
+// Send отправляет письмо
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := validateAddress(msg.To); err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultSMTPTimeout)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к SMTP серверу: %w", err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("ошибка подключения к SMTP серверу: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("ошибка STARTTLS: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("ошибка авторизации на SMTP сервере: %w", err)
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(m.from)); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	if _, err := w.Write(compose(m.from, msg, time.Now())); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+
+	return client.Quit()
+}
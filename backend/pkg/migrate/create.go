@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// namePattern допустимое название новой миграции
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create создает в каталоге dir пустые файлы up и down миграции name
+// со следующим номером версии и возвращает их пути
+func Create(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("название миграции %q может содержать только латинские буквы, цифры и _", name)
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version uint64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	files := map[string]string{
+		up:   fmt.Sprintf("-- Миграция %d: %s\n", version, name),
+		down: fmt.Sprintf("-- Откат миграции %d: %s\n", version, name),
+	}
+	for file, header := range files {
+		// O_EXCL не дает перезаписать существующий файл
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", fmt.Errorf("ошибка создания %s: %w", file, err)
+		}
+		_, err = f.WriteString(header)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("ошибка записи %s: %w", file, err)
+		}
+	}
+
+	return up, down, nil
+}
@@ -0,0 +1,307 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Table таблица с примененными версиями схемы
+const Table = "schema_migrations"
+
+// ErrSchemaMismatch версия схемы в базе не совпадает с ожидаемой
+var ErrSchemaMismatch = errors.New("версия схемы базы данных не совпадает с ожидаемой")
+
+// ErrUnmanagedSchema в базе есть таблицы, но нет ни одной записи о миграциях:
+// схема создана в обход миграций и может не совпадать ни с одной версией
+var ErrUnmanagedSchema = errors.New("база данных содержит таблицы, созданные без миграций")
+
+// fileNamePattern имя файла миграции: <версия>_<название>.<up|down>.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration версия схемы с SQL применения и отката
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string // пусто, если откат не предусмотрен
+}
+
+// Status состояние миграции в базе
+type Status struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // nil - миграция не применена
+}
+
+// Load читает миграции из корня fsys, упорядоченные по версии
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога миграций: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("некорректная версия миграции %s", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения миграции %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("версия %d задана у миграций %s и %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("у миграции %d_%s нет файла up", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции в базе данных
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []*Migration
+}
+
+// New создает мигратор для миграций из fsys
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest возвращает последнюю известную версию схемы
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version возвращает текущую версию схемы в базе; 0, если миграции не применялись
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, err
+	}
+
+	var version uint64
+	err := m.db.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) FROM "+Table)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения версии схемы: %w", err)
+	}
+	return version, nil
+}
+
+// Check проверяет, что схема в базе находится на последней известной версии
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		if err := m.checkEmpty(ctx); err != nil {
+			return err
+		}
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("%w: в базе %d, ожидается %d", ErrSchemaMismatch, version, m.Latest())
+	}
+	return nil
+}
+
+// Status возвращает состояние всех известных миграций
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if at, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up применяет все непримененные миграции по возрастанию версии
+// и возвращает примененные
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Первая миграция создает схему с нуля; поверх чужих таблиц она записала бы
+	// версию 1, не добавив недостающих столбцов
+	if len(applied) == 0 {
+		if err := m.checkEmpty(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []*Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.exec(ctx, migration, migration.Up, func(tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO "+Table+" (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down откатывает steps последних примененных миграций и возвращает откаченные
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return done, fmt.Errorf("у миграции %d_%s нет файла down", migration.Version, migration.Name)
+		}
+		err := m.exec(ctx, migration, migration.Down, func(tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM "+Table+" WHERE version = ?", migration.Version)
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// exec выполняет SQL миграции и запись о ней в одной транзакции.
+// MySQL неявно фиксирует DDL, поэтому при ошибке посреди миграции
+// уже выполненные выражения схемы не откатываются.
+func (m *Migrator) exec(ctx context.Context, migration *Migration, body string, record func(tx *sqlx.Tx) error) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, stmt := range SplitStatements(body) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("миграция %d_%s, выражение %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("ошибка записи версии %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// applied возвращает время применения каждой примененной версии
+func (m *Migrator) applied(ctx context.Context) (map[uint64]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Version   uint64    `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	err := m.db.SelectContext(ctx, &rows, "SELECT version, applied_at FROM "+Table)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения примененных миграций: %w", err)
+	}
+
+	applied := make(map[uint64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+// checkEmpty возвращает ErrUnmanagedSchema, если в базе без примененных
+// миграций уже есть таблицы
+func (m *Migrator) checkEmpty(ctx context.Context) error {
+	var tables []string
+	err := m.db.SelectContext(ctx, &tables, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name <> ?
+		ORDER BY table_name
+	`, Table)
+	if err != nil {
+		return fmt.Errorf("ошибка получения списка таблиц: %w", err)
+	}
+	if len(tables) > 0 {
+		return fmt.Errorf("%w (%s): примените миграции к пустой базе и перенесите в нее данные",
+			ErrUnmanagedSchema, strings.Join(tables, ", "))
+	}
+	return nil
+}
+
+// ensureTable создает таблицу версий схемы, если ее нет
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+Table+` (
+			version BIGINT UNSIGNED PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка создания таблицы %s: %w", Table, err)
+	}
+	return nil
+}
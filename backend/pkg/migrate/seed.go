@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Seed заполняет базу набором начальных данных set: выполняет файлы *.sql
+// каталога set в порядке имен, каждый в отдельной транзакции.
+// Скрипты наборов должны быть идемпотентными, набор можно применять повторно.
+func Seed(ctx context.Context, db *sqlx.DB, fsys fs.FS, set string) error {
+	entries, err := fs.ReadDir(fsys, set)
+	if err != nil {
+		return fmt.Errorf("набор начальных данных %q не найден: %w", set, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, path.Join(set, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения %s: %w", file, err)
+		}
+		if err := seedFile(ctx, db, file, string(body)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedFile выполняет один скрипт начальных данных. Транзакция держит одно
+// соединение, поэтому пользовательские переменные (SET @x) доступны до конца файла.
+func seedFile(ctx context.Context, db *sqlx.DB, file, body string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, stmt := range SplitStatements(body) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%s, выражение %d: %w", file, i+1, err)
+		}
+	}
+
+	return tx.Commit()
+}
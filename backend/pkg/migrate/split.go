@@ -0,0 +1,81 @@
+package migrate
+
+import "strings"
+
+// SplitStatements разбивает SQL-скрипт на отдельные выражения по точке с запятой.
+// Точки с запятой внутри строк, идентификаторов в обратных кавычках
+// и комментариев разделителями не считаются. DELIMITER не поддерживается.
+func SplitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte // открытая кавычка: ', " или `
+	)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+
+		if quote != 0 {
+			current.WriteByte(ch)
+			switch {
+			case ch == '\\' && quote != '`' && i+1 < len(script):
+				i++
+				current.WriteByte(script[i])
+			case ch == quote && i+1 < len(script) && script[i+1] == quote:
+				// Удвоенная кавычка внутри строки
+				i++
+				current.WriteByte(script[i])
+			case ch == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+			current.WriteByte(ch)
+		case ch == '#', ch == '-' && isLineComment(script[i:]):
+			// Однострочный комментарий пропускается до конца строки
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end
+				current.WriteByte('\n')
+			}
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+		case ch == ';':
+			flush()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// isLineComment сообщает, начинается ли s с комментария "--": в MySQL после
+// двух дефисов обязателен пробельный символ или конец строки
+func isLineComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+	return len(s) == 2 || strings.ContainsRune(" \t\r\n", rune(s[2]))
+}
@@ -0,0 +1,97 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Converter пересчитывает суммы между валютами по фиксированной таблице курсов
+type Converter struct {
+	base  string
+	rates map[string]Ratio // количество единиц валюты за одну единицу базовой валюты
+}
+
+// NewConverter создает конвертер с базовой валютой base. rates задает курс
+// каждой валюты к базовой; курс базовой валюты всегда равен единице.
+func NewConverter(base string, rates map[string]float64) (*Converter, error) {
+	base = strings.ToUpper(base)
+	c := &Converter{
+		base:  base,
+		rates: map[string]Ratio{base: One},
+	}
+
+	for code, rate := range rates {
+		code = strings.ToUpper(code)
+		if code == base {
+			continue
+		}
+
+		r, err := RatioFromFloat(rate)
+		if err != nil {
+			return nil, fmt.Errorf("курс %s: %w", code, err)
+		}
+		if r <= 0 {
+			return nil, fmt.Errorf("курс %s должен быть положительным", code)
+		}
+		c.rates[code] = r
+	}
+
+	return c, nil
+}
+
+// Base возвращает базовую валюту
+func (c *Converter) Base() string {
+	return c.base
+}
+
+// Currencies возвращает коды поддерживаемых валют в алфавитном порядке
+func (c *Converter) Currencies() []string {
+	codes := make([]string, 0, len(c.rates))
+	for code := range c.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Supports проверяет, известна ли валюта конвертеру
+func (c *Converter) Supports(currency string) bool {
+	_, ok := c.rates[strings.ToUpper(currency)]
+	return ok
+}
+
+// Convert пересчитывает сумму в валюту to с округлением до минимальной единицы
+func (c *Converter) Convert(m Money, to string) (Money, error) {
+	to = strings.ToUpper(to)
+	if m.Currency == to {
+		return m, nil
+	}
+
+	fromRate, ok := c.rates[m.Currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, m.Currency)
+	}
+	toRate, ok := c.rates[to]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+
+	return Money{
+		Amount:   Amount(mulDiv(int64(m.Amount), int64(toRate), int64(fromRate))),
+		Currency: to,
+	}, nil
+}
+
+// ConvertToAll пересчитывает сумму во все поддерживаемые валюты
+func (c *Converter) ConvertToAll(m Money) (map[string]Amount, error) {
+	result := make(map[string]Amount, len(c.rates))
+	for code := range c.rates {
+		converted, err := c.Convert(m, code)
+		if err != nil {
+			return nil, err
+		}
+		result[code] = converted.Amount
+	}
+	return result, nil
+}
@@ -0,0 +1,309 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// minorUnits количество минимальных единиц в основной единице валюты;
+// все поддерживаемые валюты имеют два знака после запятой
+const minorUnits = 100
+
+// RatioScale множитель, с которым хранится Ratio (шесть знаков после запятой)
+const RatioScale = 1000000
+
+var (
+	// ErrCurrencyMismatch операция над суммами в разных валютах
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrUnknownCurrency валюта отсутствует в таблице курсов
+	ErrUnknownCurrency = errors.New("unknown currency")
+)
+
+// Amount денежная сумма в минимальных единицах валюты (копейках, центах).
+// В JSON и БД представляется десятичным числом с двумя знаками после запятой.
+type Amount int64
+
+// ParseAmount разбирает десятичную запись суммы, например "1234.50"
+func ParseAmount(s string) (Amount, error) {
+	v, err := parseDecimal(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная сумма %q: %w", s, err)
+	}
+	return Amount(v), nil
+}
+
+// FromMajor создает сумму из целого числа основных единиц
+func FromMajor(units int64) Amount {
+	return Amount(units * minorUnits)
+}
+
+// String возвращает десятичную запись суммы
+func (a Amount) String() string {
+	return formatDecimal(int64(a), 2)
+}
+
+// Float64 возвращает приближенное значение суммы в основных единицах;
+// используется только для отображения и логирования
+func (a Amount) Float64() float64 {
+	return float64(a) / minorUnits
+}
+
+// Times умножает сумму на целое число
+func (a Amount) Times(n int) Amount {
+	return a * Amount(n)
+}
+
+// Mul умножает сумму на коэффициент с округлением до минимальной единицы
+func (a Amount) Mul(r Ratio) Amount {
+	return Amount(mulDiv(int64(a), int64(r), RatioScale))
+}
+
+// MarshalJSON представляет сумму JSON-числом без потери точности
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON принимает число или строку с десятичной записью суммы
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	v, err := ParseAmount(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value сохраняет сумму в DECIMAL колонку
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan читает сумму из DECIMAL колонки
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		return a.UnmarshalJSON(v)
+	case string:
+		return a.UnmarshalJSON([]byte(v))
+	case int64:
+		*a = FromMajor(v)
+		return nil
+	case float64:
+		*a = Amount(math.Round(v * minorUnits))
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип суммы: %T", src)
+	}
+}
+
+// Ratio десятичный коэффициент (модификатор цены, курс валюты),
+// хранимый с точностью до шести знаков после запятой
+type Ratio int64
+
+// One коэффициент, равный единице
+const One Ratio = RatioScale
+
+// ParseRatio разбирает десятичную запись коэффициента, например "1.15"
+func ParseRatio(s string) (Ratio, error) {
+	v, err := parseDecimal(s, 6)
+	if err != nil {
+		return 0, fmt.Errorf("некорректный коэффициент %q: %w", s, err)
+	}
+	return Ratio(v), nil
+}
+
+// RatioFromFloat преобразует число с плавающей точкой в коэффициент
+// по его кратчайшей десятичной записи
+func RatioFromFloat(f float64) (Ratio, error) {
+	return ParseRatio(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Mul перемножает коэффициенты с округлением до шестого знака
+func (r Ratio) Mul(other Ratio) Ratio {
+	return Ratio(mulDiv(int64(r), int64(other), RatioScale))
+}
+
+// Round округляет коэффициент до кратного step
+func (r Ratio) Round(step Ratio) Ratio {
+	return Ratio(mulDiv(int64(r), 1, int64(step))) * step
+}
+
+// String возвращает десятичную запись коэффициента без незначащих нулей
+func (r Ratio) String() string {
+	s := formatDecimal(int64(r), 6)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MarshalJSON представляет коэффициент JSON-числом
+func (r Ratio) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON принимает число или строку с десятичной записью коэффициента
+func (r *Ratio) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	v, err := ParseRatio(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Value сохраняет коэффициент в DECIMAL колонку
+func (r Ratio) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan читает коэффициент из DECIMAL колонки; NULL соответствует единице
+func (r *Ratio) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = One
+		return nil
+	case []byte:
+		return r.UnmarshalJSON(v)
+	case string:
+		return r.UnmarshalJSON([]byte(v))
+	case int64:
+		*r = Ratio(v * RatioScale)
+		return nil
+	case float64:
+		*r = Ratio(math.Round(v * RatioScale))
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип коэффициента: %T", src)
+	}
+}
+
+// Money сумма в определенной валюте
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"` // код валюты ISO 4217
+}
+
+// New создает сумму в валюте currency
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Add складывает суммы в одной валюте
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s и %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// String возвращает сумму с кодом валюты, например "1234.50 RUB"
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// parseDecimal разбирает десятичную запись в целое число с scale знаками после запятой
+func parseDecimal(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("пустое значение")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return 0, errors.New("нет цифр")
+	}
+	if len(fracPart) > scale {
+		// Лишние знаки допускаются только нулевыми, иначе значение теряет точность
+		if strings.Trim(fracPart[scale:], "0") != "" {
+			return 0, fmt.Errorf("больше %d знаков после запятой", scale)
+		}
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	digits := intPart + fracPart
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, errors.New("недопустимый символ")
+		}
+	}
+
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseUint(digits, 10, 63)
+	if err != nil {
+		return 0, errors.New("значение вне допустимого диапазона")
+	}
+
+	if negative {
+		return -int64(v), nil
+	}
+	return int64(v), nil
+}
+
+// formatDecimal форматирует целое число с scale знаками после запятой
+func formatDecimal(v int64, scale int) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+
+	s := strconv.FormatUint(u, 10)
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// mulDiv вычисляет a*b/c с округлением половины от нуля без переполнения
+func mulDiv(a, b, c int64) int64 {
+	num := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	den := big.NewInt(c)
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	// |2r| >= |c| - округляем от нуля
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	if r2.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q.Int64()
+}
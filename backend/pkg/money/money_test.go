@@ -0,0 +1,165 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{"1234.50", 123450, false},
+		{"0.1", 10, false},
+		{"5", 500, false},
+		{"+5", 500, false},
+		{" 7.25 ", 725, false},
+		{".5", 50, false},
+		{"1.230", 123, false}, // лишние нули после запятой не теряют точность
+		{"-12.34", -1234, false},
+		{"-0.01", -1, false},
+		{"92233720368547758.07", math.MaxInt64, false},
+		{"-92233720368547758.07", -math.MaxInt64, false},
+
+		{"1.234", 0, true}, // третий значащий знак после запятой
+		{"0.001", 0, true},
+		{"92233720368547758.08", 0, true}, // переполнение int64
+		{"100000000000000000000", 0, true},
+		{"", 0, true},
+		{".", 0, true},
+		{"-", 0, true},
+		{"1.2.3", 0, true},
+		{"1e3", 0, true},
+		{"--1", 0, true},
+		{"12,50", 0, true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseAmount(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %d, ожидалась ошибка", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseAmount(%q) = %d, ожидалось %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseRatio(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Ratio
+		wantErr bool
+	}{
+		{"1.15", 1150000, false},
+		{"1", One, false},
+		{"0.000001", 1, false},
+		{"1.1234560", 1123456, false},
+		{"-0.5", -500000, false},
+
+		{"1.1234567", 0, true}, // седьмой знак после запятой
+		{"9223372036854.775808", 0, true},
+		{"x", 0, true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseRatio(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseRatio(%q) = %d, ожидалась ошибка", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRatio(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseRatio(%q) = %d, ожидалось %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	cases := map[Amount]string{
+		0:             "0.00",
+		5:             "0.05",
+		-5:            "-0.05",
+		123450:        "1234.50",
+		-123450:       "-1234.50",
+		math.MaxInt64: "92233720368547758.07",
+	}
+	for in, want := range cases {
+		if got := in.String(); got != want {
+			t.Errorf("Amount(%d).String() = %q, ожидалось %q", in, got, want)
+		}
+	}
+}
+
+// TestAmountMulRoundsHalfUp проверяет округление произведения суммы на
+// шестизначный коэффициент: половина минимальной единицы округляется от нуля
+func TestAmountMulRoundsHalfUp(t *testing.T) {
+	cases := []struct {
+		amount Amount
+		ratio  Ratio
+		want   Amount
+	}{
+		{100, 1005000, 101},   // 1.005 -> 1.01
+		{100, 1004999, 100},   // 1.004999 -> 1.00
+		{-100, 1005000, -101}, // отрицательная половина - тоже от нуля
+		{-100, 1004999, -100},
+		{1, 500000, 1}, // ровно половина копейки
+		{1, 499999, 0},
+		{-1, 500000, -1},
+		{123450, 850000, 104933}, // 1049.325 -> 1049.33
+		{999999, One, 999999},
+		{0, 1234567, 0},
+	}
+
+	for _, tc := range cases {
+		if got := tc.amount.Mul(tc.ratio); got != tc.want {
+			t.Errorf("%s * %s = %s, ожидалось %s", tc.amount, tc.ratio, got, tc.want)
+		}
+	}
+}
+
+func TestRatioMulAndRound(t *testing.T) {
+	if got := Ratio(1000001).Mul(500000); got != 500001 { // 0.5000005 -> 0.500001
+		t.Errorf("1.000001 * 0.5 = %s, ожидалось 0.500001", got)
+	}
+	if got := Ratio(-1000001).Mul(500000); got != -500001 {
+		t.Errorf("-1.000001 * 0.5 = %s, ожидалось -0.500001", got)
+	}
+
+	step := Ratio(10000) // 0.01
+	rounds := map[Ratio]Ratio{
+		1234567: 1230000,
+		1235000: 1240000, // половина шага - вверх
+		1234999: 1230000,
+	}
+	for in, want := range rounds {
+		if got := in.Round(step); got != want {
+			t.Errorf("Round(%s) = %s, ожидалось %s", in, got, want)
+		}
+	}
+}
+
+// TestMulDivDoesNotOverflow проверяет, что промежуточное произведение
+// вычисляется без переполнения int64
+func TestMulDivDoesNotOverflow(t *testing.T) {
+	if got := mulDiv(math.MaxInt64, RatioScale, RatioScale); got != math.MaxInt64 {
+		t.Errorf("mulDiv(MaxInt64, scale, scale) = %d", got)
+	}
+	if got := mulDiv(1<<62, 3, 4); got != 3<<60 {
+		t.Errorf("mulDiv(2^62, 3, 4) = %d, ожидалось %d", got, int64(3)<<60)
+	}
+}
@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cursorVersion префикс формата курсора; позволяет изменить формат, не ломая разбор старых курсоров
+const cursorVersion = "v1"
+
+// ErrInvalidCursor курсор поврежден или выдан в другом формате
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor позиция последней записи полученной страницы в списке, упорядоченном
+// по времени создания и ID. В отличие от смещения, курсор не сдвигается при
+// добавлении и удалении записей.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// IsZero сообщает, что курсор указывает на начало списка
+func (c Cursor) IsZero() bool {
+	return c.ID == 0 && c.CreatedAt.IsZero()
+}
+
+// Encode возвращает непрозрачное строковое представление курсора
+func (c Cursor) Encode() string {
+	raw := cursorVersion + ":" + strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode разбирает курсор, полученный от клиента. Пустая строка означает начало списка.
+func Decode(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != cursorVersion {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || id < 1 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// Trim обрезает выборку до limit записей. Репозиторий выбирает на одну запись
+// больше страницы: если она есть, возвращается курсор следующей страницы,
+// построенный по последней записи; иначе пустая строка.
+func Trim[T any](items []T, limit int, key func(T) Cursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	return items, key(items[limit-1]).Encode()
+}
@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{CreatedAt: time.Date(2024, 5, 17, 10, 30, 0, 123456789, time.UTC), ID: 42},
+		{CreatedAt: time.Unix(0, 0), ID: 1},
+		{CreatedAt: time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC), ID: 7}, // до начала эпохи
+		{CreatedAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), ID: 1<<62 + 1},
+	}
+
+	for _, c := range cursors {
+		got, err := Decode(c.Encode())
+		if err != nil {
+			t.Errorf("Decode(%v): %v", c, err)
+			continue
+		}
+		if got.ID != c.ID || !got.CreatedAt.Equal(c.CreatedAt) {
+			t.Errorf("после разбора %v, ожидалось %v", got, c)
+		}
+	}
+}
+
+func TestDecodeEmptyCursor(t *testing.T) {
+	c, err := Decode("")
+	if err != nil || !c.IsZero() {
+		t.Errorf("пустой курсор: %v, %v", c, err)
+	}
+}
+
+func TestDecodeRejectsMalformedCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	cases := map[string]string{
+		"не base64":             "!!!",
+		"base64 с дополнением":  base64.URLEncoding.EncodeToString([]byte("v1:1:12")),
+		"другая версия":         encode("v2:1700000000000000000:5"),
+		"без версии":            encode("1700000000000000000:5"),
+		"лишняя часть":          encode("v1:1700000000000000000:5:6"),
+		"время не число":        encode("v1:abc:5"),
+		"ID не число":           encode("v1:1700000000000000000:x"),
+		"нулевой ID":            encode("v1:1700000000000000000:0"),
+		"отрицательный ID":      encode("v1:1700000000000000000:-3"),
+		"переполнение времени":  encode("v1:99999999999999999999:5"),
+		"пустые части":          encode("v1::"),
+		"только префикс версии": encode("v1"),
+	}
+
+	for name, s := range cases {
+		if c, err := Decode(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: %v, %v, ожидалась ErrInvalidCursor", name, c, err)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	key := func(id int64) Cursor {
+		return Cursor{CreatedAt: time.Unix(id, 0), ID: id}
+	}
+
+	items, next := Trim([]int64{1, 2, 3}, 3, key)
+	if len(items) != 3 || next != "" {
+		t.Errorf("полная последняя страница: %v, курсор %q", items, next)
+	}
+
+	items, next = Trim([]int64{1, 2, 3, 4}, 3, key)
+	if len(items) != 3 {
+		t.Fatalf("записей на странице: %d, ожидалось 3", len(items))
+	}
+	c, err := Decode(next)
+	if err != nil || c.ID != 3 {
+		t.Errorf("курсор следующей страницы указывает на %v (%v), ожидалась запись 3", c, err)
+	}
+}
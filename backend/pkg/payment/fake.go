@@ -0,0 +1,197 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// FakeProviderName идентификатор встроенного тестового провайдера
+const FakeProviderName = "fake"
+
+// Состояния намерения в тестовом провайдере
+const (
+	fakeIntentCreated    = "created"
+	fakeIntentAuthorized = "authorized"
+	fakeIntentCaptured   = "captured"
+	fakeIntentRefunded   = "refunded"
+)
+
+type fakeIntent struct {
+	intent   Intent
+	status   string
+	refunded money.Amount
+}
+
+// FakeProvider платежный провайдер для локальной разработки: хранит намерения
+// в памяти и подписывает webhook по HMAC-SHA256 общим секретом
+type FakeProvider struct {
+	secret  []byte
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+}
+
+// NewFakeProvider создает тестовый платежный провайдер
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:  []byte(webhookSecret),
+		intents: make(map[string]*fakeIntent),
+	}
+}
+
+// Name возвращает идентификатор провайдера
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+// CreateIntent создает платежное намерение
+func (p *FakeProvider) CreateIntent(ctx context.Context, amount money.Money, reference string) (*Intent, error) {
+	id, err := newFakeID("pi_")
+	if err != nil {
+		return nil, err
+	}
+
+	intent := Intent{
+		ID:          id,
+		Amount:      amount.Amount,
+		Currency:    amount.Currency,
+		CheckoutURL: fmt.Sprintf("/api/payments/fake/%s/authorize", id),
+	}
+
+	p.mu.Lock()
+	p.intents[intent.ID] = &fakeIntent{intent: intent, status: fakeIntentCreated}
+	p.mu.Unlock()
+
+	return &intent, nil
+}
+
+// GetIntent возвращает ранее созданное намерение
+func (p *FakeProvider) GetIntent(ctx context.Context, intentID string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fi, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	intent := fi.intent
+	return &intent, nil
+}
+
+// Capture списывает авторизованные средства
+func (p *FakeProvider) Capture(ctx context.Context, intentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fi, ok := p.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+
+	switch fi.status {
+	case fakeIntentCaptured:
+		return nil
+	case fakeIntentAuthorized:
+		fi.status = fakeIntentCaptured
+		return nil
+	default:
+		return fmt.Errorf("невозможно списать средства по намерению в статусе %s", fi.status)
+	}
+}
+
+// Refund возвращает средства; для авторизованного, но не списанного намерения
+// снимает авторизацию
+func (p *FakeProvider) Refund(ctx context.Context, intentID string, amount money.Amount) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fi, ok := p.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+
+	if fi.status != fakeIntentAuthorized && fi.status != fakeIntentCaptured {
+		return fmt.Errorf("невозможно вернуть средства по намерению в статусе %s", fi.status)
+	}
+	if fi.refunded+amount > fi.intent.Amount {
+		return fmt.Errorf("сумма возврата превышает сумму платежа")
+	}
+
+	fi.refunded += amount
+	if fi.refunded >= fi.intent.Amount {
+		fi.status = fakeIntentRefunded
+	}
+
+	return nil
+}
+
+// VerifyWebhook проверяет подпись и разбирает событие
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе события: %w", err)
+	}
+
+	return &event, nil
+}
+
+// SimulateAuthorization имитирует подтверждение оплаты клиентом и возвращает
+// подписанный webhook, который отправил бы настоящий шлюз
+func (p *FakeProvider) SimulateAuthorization(ctx context.Context, intentID string) ([]byte, string, error) {
+	p.mu.Lock()
+	fi, ok := p.intents[intentID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, "", ErrIntentNotFound
+	}
+	if fi.status == fakeIntentCreated {
+		fi.status = fakeIntentAuthorized
+	}
+	amount := fi.intent.Amount
+	p.mu.Unlock()
+
+	eventID, err := newFakeID("evt_")
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err := json.Marshal(Event{
+		ID:       eventID,
+		Type:     EventAuthorized,
+		IntentID: intentID,
+		Amount:   amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, hex.EncodeToString(p.sign(payload)), nil
+}
+
+func (p *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// newFakeID генерирует случайный идентификатор с префиксом
+func newFakeID(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать идентификатор: %w", err)
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// Типы событий, которые платежный провайдер присылает в webhook
+const (
+	EventAuthorized = "payment.authorized" // клиент подтвердил оплату, средства можно списать
+	EventFailed     = "payment.failed"     // оплата отклонена
+)
+
+var (
+	// ErrInvalidSignature подпись webhook не совпадает с ожидаемой
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrIntentNotFound платежное намерение неизвестно провайдеру
+	ErrIntentNotFound = errors.New("payment intent not found")
+)
+
+// Intent платежное намерение, созданное у провайдера
+type Intent struct {
+	ID          string       `json:"id"`
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+	CheckoutURL string       `json:"checkout_url"` // адрес, по которому клиент завершает оплату
+}
+
+// Event событие провайдера, полученное через webhook
+type Event struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	IntentID string       `json:"intent_id"`
+	Amount   money.Amount `json:"amount"`
+}
+
+// PaymentProvider интерфейс платежного шлюза
+type PaymentProvider interface {
+	// Name возвращает идентификатор провайдера, сохраняемый вместе с платежом
+	Name() string
+	// CreateIntent создает платежное намерение на сумму заказа
+	CreateIntent(ctx context.Context, amount money.Money, reference string) (*Intent, error)
+	// GetIntent возвращает ранее созданное намерение или ErrIntentNotFound
+	GetIntent(ctx context.Context, intentID string) (*Intent, error)
+	// Capture списывает авторизованные средства
+	Capture(ctx context.Context, intentID string) error
+	// Refund возвращает средства клиенту
+	Refund(ctx context.Context, intentID string, amount money.Amount) error
+	// VerifyWebhook проверяет подпись webhook и разбирает событие
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// Simulator реализуется провайдерами, позволяющими имитировать действия
+// клиента без внешнего шлюза
+type Simulator interface {
+	// SimulateAuthorization возвращает подписанный webhook об авторизации оплаты
+	SimulateAuthorization(ctx context.Context, intentID string) (payload []byte, signature string, err error)
+}
+
+// NewProvider создает платежного провайдера согласно конфигурации
+func NewProvider(cfg config.PaymentConfig) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "", FakeProviderName:
+		return NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("неизвестный платежный провайдер: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,101 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/usedcvnt/Diplom1Project/backend/pkg/money"
+)
+
+// tokenTypeQuote значение claim "typ" для токенов расчета стоимости
+const tokenTypeQuote = "quote"
+
+var (
+	// ErrInvalidQuote токен расчета стоимости поврежден или подписан другим ключом
+	ErrInvalidQuote = errors.New("invalid price quote")
+	// ErrQuoteExpired срок действия расчета стоимости истек
+	ErrQuoteExpired = errors.New("price quote has expired")
+)
+
+// QuoteClaims параметры заказа и рассчитанная сервером стоимость,
+// зафиксированные в подписанном токене
+type QuoteClaims struct {
+	UserID        int64        `json:"user_id"`
+	TourID        int64        `json:"tour_id"`
+	TourDateID    int64        `json:"tour_date_id"`
+	RoomID        *int64       `json:"room_id,omitempty"`
+	PeopleCount   int          `json:"people_count"`
+	ChildrenCount int          `json:"children_count,omitempty"`
+	PromoCode     string       `json:"promo_code,omitempty"`
+	Total         money.Amount `json:"total"`
+	Currency      string       `json:"currency"`
+	Type          string       `json:"typ"`
+	jwt.StandardClaims
+}
+
+// QuoteSigner выдает и проверяет токены расчета стоимости
+type QuoteSigner interface {
+	// Sign подписывает расчет и возвращает токен и время его истечения
+	Sign(claims QuoteClaims) (string, time.Time, error)
+	// Parse проверяет подпись и срок действия токена
+	Parse(token string) (*QuoteClaims, error)
+}
+
+// JWTQuoteSigner реализация QuoteSigner на основе JWT
+type JWTQuoteSigner struct {
+	signingKey []byte
+	ttl        time.Duration
+}
+
+// NewJWTQuoteSigner создает подписчик расчетов стоимости со сроком действия ttl
+func NewJWTQuoteSigner(secret string, ttl time.Duration) *JWTQuoteSigner {
+	return &JWTQuoteSigner{
+		signingKey: []byte(secret),
+		ttl:        ttl,
+	}
+}
+
+// Sign подписывает расчет стоимости
+func (s *JWTQuoteSigner) Sign(claims QuoteClaims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	claims.Type = tokenTypeQuote
+	claims.StandardClaims = jwt.StandardClaims{
+		ExpiresAt: expiresAt.Unix(),
+		IssuedAt:  now.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.signingKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка подписи расчета стоимости: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// Parse разбирает токен расчета стоимости
+func (s *JWTQuoteSigner) Parse(tokenString string) (*QuoteClaims, error) {
+	claims := &QuoteClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
+		}
+		return s.signingKey, nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+			return nil, ErrQuoteExpired
+		}
+		return nil, ErrInvalidQuote
+	}
+
+	if !token.Valid || claims.Type != tokenTypeQuote {
+		return nil, ErrInvalidQuote
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimited лимит исчерпан; конкретная ошибка - *LimitError со сроком ожидания
+var ErrLimited = errors.New("rate limit exceeded")
+
+// LimitError сообщает, через сколько можно повторить действие
+type LimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLimited, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrLimited)
+func (e *LimitError) Unwrap() error {
+	return ErrLimited
+}
+
+// Rule лимит действий в скользящем окне
+type Rule struct {
+	Name   string // группа ключей, например login_ip
+	Limit  int    // допустимое число действий в окне; 0 - без ограничения
+	Window time.Duration
+}
+
+// Result решение по одному действию
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // сколько действий еще доступно в окне
+	RetryAfter time.Duration // через сколько освободится место, если действие отклонено
+}
+
+// Err возвращает *LimitError для отклоненного действия и nil для разрешенного
+func (r Result) Err() error {
+	if r.Allowed {
+		return nil
+	}
+	return &LimitError{RetryAfter: r.RetryAfter}
+}
+
+// LockoutPolicy правила защиты от подбора пароля: после FreeAttempts неудач
+// каждая следующая попытка возможна не раньше, чем через удваивающуюся задержку,
+// а после MaxFailures неудач учетная запись блокируется на LockoutDuration
+type LockoutPolicy struct {
+	Window          time.Duration // окно учета неудачных попыток
+	FreeAttempts    int           // неудачи без задержки
+	BaseDelay       time.Duration // задержка после первой неудачи сверх FreeAttempts
+	MaxDelay        time.Duration // предел задержки
+	MaxFailures     int           // число неудач в окне, после которого включается блокировка
+	LockoutDuration time.Duration
+}
+
+// retention сколько хранить неудачи, чтобы блокировка продержалась весь срок
+func (p LockoutPolicy) retention() time.Duration {
+	if p.LockoutDuration > p.Window {
+		return p.LockoutDuration
+	}
+	return p.Window
+}
+
+// Limiter ограничивает частоту действий по скользящим окнам
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewLimiter создает ограничитель поверх хранилища окон
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Allow учитывает действие по ключу, если лимит правила не исчерпан.
+// Отклоненные действия не учитываются: клиент, выждавший RetryAfter, будет пропущен.
+func (l *Limiter) Allow(ctx context.Context, rule Rule, key string) (Result, error) {
+	if rule.Limit <= 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	now := l.now()
+	allowed, events, err := l.store.Take(ctx, windowKey(rule.Name, key), rule.Limit, rule.Window, now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Allowed: allowed, Limit: rule.Limit, Remaining: rule.Limit - len(events)}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		// Место освободится, когда из окна выйдет событие, превысившее лимит
+		result.RetryAfter = events[len(events)-rule.Limit].Add(rule.Window).Sub(now)
+	}
+	return result, nil
+}
+
+// CheckLockout проверяет, можно ли сейчас сделать попытку входа по ключу учетной записи
+func (l *Limiter) CheckLockout(ctx context.Context, policy LockoutPolicy, key string) (Result, error) {
+	now := l.now()
+	failures, err := l.store.Events(ctx, windowKey("lockout", key), policy.retention(), now)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(failures) == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	last := failures[len(failures)-1]
+	wait := time.Duration(0)
+
+	// Блокировка отсчитывается от последней неудачи, а неудачи считаются в окне до нее
+	if policy.MaxFailures > 0 && countSince(failures, last.Add(-policy.Window)) >= policy.MaxFailures {
+		wait = last.Add(policy.LockoutDuration).Sub(now)
+	}
+	if wait <= 0 {
+		wait = last.Add(policy.delay(countSince(failures, now.Add(-policy.Window)))).Sub(now)
+	}
+
+	if wait > 0 {
+		return Result{Allowed: false, RetryAfter: wait}, nil
+	}
+	return Result{Allowed: true}, nil
+}
+
+// RecordFailure учитывает неудачную попытку входа
+func (l *Limiter) RecordFailure(ctx context.Context, policy LockoutPolicy, key string) error {
+	_, _, err := l.store.Take(ctx, windowKey("lockout", key), 0, policy.retention(), l.now())
+	return err
+}
+
+// ResetFailures сбрасывает неудачные попытки после успешного входа
+func (l *Limiter) ResetFailures(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, windowKey("lockout", key))
+}
+
+// delay задержка перед следующей попыткой после failures неудач
+func (p LockoutPolicy) delay(failures int) time.Duration {
+	extra := failures - p.FreeAttempts
+	if extra <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < extra; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// countSince число событий позже since
+func countSince(events []time.Time, since time.Time) int {
+	return len(trim(events, since))
+}
+
+// windowKey ключ окна в хранилище
+func windowKey(name, key string) string {
+	return name + ":" + key
+}
@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testClock управляемые часы ограничителя
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time { return c.t }
+
+func (c *testClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestLimiter возвращает ограничитель в памяти с управляемыми часами
+func newTestLimiter() (*Limiter, *testClock) {
+	clock := &testClock{t: time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)}
+	l := NewLimiter(NewMemoryStore())
+	l.now = clock.now
+	return l, clock
+}
+
+func TestAllowSlidingWindow(t *testing.T) {
+	l, clock := newTestLimiter()
+	ctx := context.Background()
+	rule := Rule{Name: "test", Limit: 3, Window: time.Minute}
+
+	allow := func() Result {
+		t.Helper()
+		res, err := l.Allow(ctx, rule, "1.2.3.4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// t0, t0+10s, t0+20s
+	for i := 0; i < 3; i++ {
+		res := allow()
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("действие %d: %+v", i+1, res)
+		}
+		clock.advance(10 * time.Second)
+	}
+
+	// t0+30s: окно заполнено, место освободится, когда выйдет событие t0
+	res := allow()
+	if res.Allowed || res.RetryAfter != 30*time.Second {
+		t.Fatalf("сверх лимита: %+v, ожидался отказ с ожиданием 30s", res)
+	}
+	if err := res.Err(); !errors.Is(err, ErrLimited) {
+		t.Errorf("ошибка отказа: %v", err)
+	}
+
+	// t0+59s: событие t0 еще в окне
+	clock.advance(29 * time.Second)
+	if res := allow(); res.Allowed || res.RetryAfter != time.Second {
+		t.Fatalf("за секунду до границы окна: %+v", res)
+	}
+
+	// t0+60s: событие t0 ровно на границе уже не учитывается; отказы не
+	// заняли места в окне
+	clock.advance(time.Second)
+	if res := allow(); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("на границе окна: %+v", res)
+	}
+
+	// t0+65s: в окне события t0+10s, t0+20s, t0+60s
+	clock.advance(5 * time.Second)
+	if res := allow(); res.Allowed || res.RetryAfter != 5*time.Second {
+		t.Fatalf("после сдвига окна: %+v, ожидался отказ с ожиданием 5s", res)
+	}
+}
+
+func TestAllowKeysAreIndependent(t *testing.T) {
+	l, _ := newTestLimiter()
+	ctx := context.Background()
+	rule := Rule{Name: "test", Limit: 1, Window: time.Minute}
+
+	if res, _ := l.Allow(ctx, rule, "a"); !res.Allowed {
+		t.Fatal("первое действие ключа a отклонено")
+	}
+	if res, _ := l.Allow(ctx, rule, "a"); res.Allowed {
+		t.Fatal("лимит ключа a не сработал")
+	}
+	if res, _ := l.Allow(ctx, rule, "b"); !res.Allowed {
+		t.Error("лимит ключа a повлиял на ключ b")
+	}
+	if res, _ := l.Allow(ctx, Rule{Name: "other", Limit: 1, Window: time.Minute}, "a"); !res.Allowed {
+		t.Error("лимит правила test повлиял на правило other")
+	}
+}
+
+func TestAllowWithoutLimit(t *testing.T) {
+	l, _ := newTestLimiter()
+	rule := Rule{Name: "test", Limit: 0, Window: time.Minute}
+
+	for i := 0; i < 100; i++ {
+		if res, err := l.Allow(context.Background(), rule, "k"); err != nil || !res.Allowed {
+			t.Fatalf("действие %d без лимита отклонено: %+v, %v", i+1, res, err)
+		}
+	}
+}
+
+var testLockout = LockoutPolicy{
+	Window:          15 * time.Minute,
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        8 * time.Second,
+	MaxFailures:     5,
+	LockoutDuration: 30 * time.Minute,
+}
+
+func TestLockoutDelay(t *testing.T) {
+	want := map[int]time.Duration{
+		0:  0,
+		3:  0,
+		4:  time.Second,
+		5:  2 * time.Second,
+		6:  4 * time.Second,
+		7:  8 * time.Second,
+		8:  8 * time.Second, // предел MaxDelay
+		40: 8 * time.Second,
+	}
+	for failures, d := range want {
+		if got := testLockout.delay(failures); got != d {
+			t.Errorf("задержка после %d неудач: %s, ожидалось %s", failures, got, d)
+		}
+	}
+}
+
+// checkLockout проверяет решение CheckLockout
+func checkLockout(t *testing.T, l *Limiter, allowed bool, retryAfter time.Duration) {
+	t.Helper()
+	res, err := l.CheckLockout(context.Background(), testLockout, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed != allowed || res.RetryAfter != retryAfter {
+		t.Fatalf("CheckLockout: %+v, ожидалось allowed=%v retry=%s", res, allowed, retryAfter)
+	}
+}
+
+func recordFailures(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := l.RecordFailure(context.Background(), testLockout, "user"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLockoutThreshold(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	checkLockout(t, l, true, 0)
+
+	// Бесплатные попытки не задерживают следующую
+	recordFailures(t, l, 3)
+	checkLockout(t, l, true, 0)
+
+	// Четвертая неудача - задержка BaseDelay
+	recordFailures(t, l, 1)
+	checkLockout(t, l, false, time.Second)
+	clock.advance(time.Second)
+	checkLockout(t, l, true, 0)
+
+	// Пятая неудача достигает MaxFailures - блокировка от последней неудачи
+	recordFailures(t, l, 1)
+	checkLockout(t, l, false, 30*time.Minute)
+	clock.advance(29 * time.Minute)
+	checkLockout(t, l, false, time.Minute)
+
+	// По истечении блокировки неудачи уже вне окна, задержки нет
+	clock.advance(time.Minute)
+	checkLockout(t, l, true, 0)
+}
+
+// TestLockoutResetsAfterWindow проверяет, что неудачи, вышедшие из окна,
+// не приближают блокировку
+func TestLockoutResetsAfterWindow(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	recordFailures(t, l, 4)
+	checkLockout(t, l, false, time.Second)
+
+	clock.advance(testLockout.Window)
+	checkLockout(t, l, true, 0)
+
+	// Учитываются только четыре новые неудачи, а не восемь: до блокировки далеко
+	recordFailures(t, l, 4)
+	checkLockout(t, l, false, time.Second)
+}
+
+func TestLockoutResetFailures(t *testing.T) {
+	l, _ := newTestLimiter()
+
+	recordFailures(t, l, 5)
+	checkLockout(t, l, false, 30*time.Minute)
+
+	if err := l.ResetFailures(context.Background(), "user"); err != nil {
+		t.Fatal(err)
+	}
+	checkLockout(t, l, true, 0)
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepEvery через сколько записей удаляются ключи без событий в окне
+const memorySweepEvery = 1000
+
+type memoryWindow struct {
+	events []time.Time
+	window time.Duration
+}
+
+// memoryStore хранилище окон в памяти процесса; лимиты действуют
+// в пределах одного экземпляра API
+type memoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	writes  int
+}
+
+// NewMemoryStore создает хранилище окон в памяти процесса
+func NewMemoryStore() Store {
+	return &memoryStore{windows: make(map[string]*memoryWindow)}
+}
+
+// Take записывает событие, если в окне есть место
+func (m *memoryStore) Take(_ context.Context, key string, limit int, window time.Duration, now time.Time) (bool, []time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := m.windows[key]
+	if w == nil {
+		w = &memoryWindow{}
+		m.windows[key] = w
+	}
+	w.window = window
+	w.events = trim(w.events, now.Add(-window))
+
+	allowed := limit <= 0 || len(w.events) < limit
+	if allowed {
+		w.events = append(w.events, now)
+		m.sweep(now)
+	}
+	return allowed, copyEvents(w.events), nil
+}
+
+// Events возвращает события окна
+func (m *memoryStore) Events(_ context.Context, key string, window time.Duration, now time.Time) ([]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := m.windows[key]
+	if w == nil {
+		return nil, nil
+	}
+	return copyEvents(trim(w.events, now.Add(-window))), nil
+}
+
+// Reset удаляет события ключа
+func (m *memoryStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.windows, key)
+	m.mu.Unlock()
+	return nil
+}
+
+// sweep периодически удаляет ключи, все события которых вышли из окна;
+// вызывается под блокировкой
+func (m *memoryStore) sweep(now time.Time) {
+	m.writes++
+	if m.writes < memorySweepEvery {
+		return
+	}
+	m.writes = 0
+	for key, w := range m.windows {
+		if len(trim(w.events, now.Add(-w.window))) == 0 {
+			delete(m.windows, key)
+		}
+	}
+}
+
+// trim отбрасывает события не позже since; события упорядочены по времени
+func trim(events []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(events) && !events[i].After(since) {
+		i++
+	}
+	return events[i:]
+}
+
+func copyEvents(events []time.Time) []time.Time {
+	return append([]time.Time(nil), events...)
+}
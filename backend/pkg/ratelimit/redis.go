@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript атомарно очищает окно, проверяет лимит и записывает событие.
+// Окно хранится в sorted set: score - время события в микросекундах. Время
+// передается строками: Lua печатает числа с 14 значащими цифрами и теряет точность.
+var takeScript = redis.NewScript(`
+local key = KEYS[1]
+redis.call('ZREMRANGEBYSCORE', key, '-inf', ARGV[2])
+local limit = tonumber(ARGV[3])
+local allowed = 0
+if limit <= 0 or redis.call('ZCARD', key) < limit then
+	redis.call('ZADD', key, ARGV[1], ARGV[4])
+	redis.call('PEXPIRE', key, ARGV[5])
+	allowed = 1
+end
+return {allowed, redis.call('ZRANGE', key, 0, -1, 'WITHSCORES')}
+`)
+
+// redisStore хранилище окон в Redis; лимиты общие для всех экземпляров API
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore создает хранилище окон поверх клиента Redis
+func NewRedisStore(client *redis.Client, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+// Take записывает событие, если в окне есть место
+func (r *redisStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (bool, []time.Time, error) {
+	// Несколько событий в одну микросекунду не должны схлопнуться в один элемент множества
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return false, nil, err
+	}
+	member := strconv.FormatInt(now.UnixMicro(), 10) + "-" + hex.EncodeToString(suffix)
+
+	res, err := takeScript.Run(ctx, r.client, []string{r.prefix + key},
+		strconv.FormatInt(now.UnixMicro(), 10),
+		strconv.FormatInt(now.Add(-window).UnixMicro(), 10),
+		limit, member, window.Milliseconds()+1).Slice()
+	if err != nil {
+		return false, nil, err
+	}
+
+	allowed, _ := res[0].(int64)
+	pairs, _ := res[1].([]interface{})
+	return allowed == 1, parseScores(pairs), nil
+}
+
+// Events возвращает события окна
+func (r *redisStore) Events(ctx context.Context, key string, window time.Duration, now time.Time) ([]time.Time, error) {
+	min := strconv.FormatInt(now.Add(-window).UnixMicro(), 10)
+	values, err := r.client.ZRangeByScoreWithScores(ctx, r.prefix+key, &redis.ZRangeBy{
+		Min: "(" + min,
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]time.Time, len(values))
+	for i, v := range values {
+		events[i] = time.UnixMicro(int64(v.Score))
+	}
+	return events, nil
+}
+
+// Reset удаляет события ключа
+func (r *redisStore) Reset(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// parseScores разбирает ответ ZRANGE WITHSCORES: пары элемент, score
+func parseScores(pairs []interface{}) []time.Time {
+	events := make([]time.Time, 0, len(pairs)/2)
+	for i := 1; i < len(pairs); i += 2 {
+		s, _ := pairs[i].(string)
+		score, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			continue
+		}
+		events = append(events, time.UnixMicro(int64(score)))
+	}
+	return events
+}
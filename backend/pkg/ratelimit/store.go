@@ -0,0 +1,18 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store хранилище событий скользящих окон. Операции над одним ключом атомарны,
+// поэтому параллельные запросы не превышают лимит.
+type Store interface {
+	// Take записывает событие, если в окне меньше limit событий; limit <= 0 - без ограничения.
+	// Возвращает, записано ли событие, и события окна по возрастанию времени.
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (bool, []time.Time, error)
+	// Events возвращает события окна по возрастанию времени
+	Events(ctx context.Context, key string, window time.Duration, now time.Time) ([]time.Time, error)
+	// Reset удаляет события ключа
+	Reset(ctx context.Context, key string) error
+}
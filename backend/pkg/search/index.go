@@ -0,0 +1,53 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/usedcvnt/Diplom1Project/backend/internal/config"
+)
+
+// MemoryBackendName идентификатор встроенного индекса в памяти процесса
+const MemoryBackendName = "memory"
+
+// Field текстовое поле документа. Вес определяет вклад совпадений в поле в релевантность.
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Document индексируемый документ
+type Document struct {
+	ID     int64
+	Fields []Field
+}
+
+// Hit найденный документ
+type Hit struct {
+	ID    int64
+	Score float64
+	// Highlights фрагменты полей с совпадениями, выделенными тегом <mark>; текст экранирован для HTML
+	Highlights map[string]string
+}
+
+// Index полнотекстовый индекс документов
+type Index interface {
+	// Put добавляет документ или заменяет документ с тем же ID
+	Put(doc Document)
+	// Delete удаляет документ из индекса
+	Delete(id int64)
+	// Replace атомарно заменяет содержимое индекса
+	Replace(docs []Document)
+	// Search возвращает до limit документов, отсортированных по убыванию релевантности
+	Search(query string, limit int) []Hit
+}
+
+// NewIndex создает индекс выбранного в конфигурации типа
+func NewIndex(cfg config.SearchConfig) (Index, error) {
+	switch cfg.Backend {
+	case "", MemoryBackendName:
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный поисковый индекс: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,280 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Параметры ранжирования
+const (
+	// bm25K1 и bm25B параметры насыщения частоты слова и нормализации по длине поля (BM25)
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// prefixQuality вес совпадения слова запроса с началом слова документа
+	prefixQuality = 0.7
+	// minPrefixLength минимальная длина слова запроса для поиска по префиксу
+	minPrefixLength = 3
+	// editPenalty снижение веса совпадения за каждую опечатку
+	editPenalty = 0.25
+)
+
+// indexedField поле документа после разбора на слова
+type indexedField struct {
+	Field
+	tokens []token
+	freq   map[string]int
+}
+
+// indexedDoc документ после разбора на слова
+type indexedDoc struct {
+	id     int64
+	fields []indexedField
+}
+
+// memoryIndex индекс в памяти процесса. Подходит для каталогов из тысяч
+// документов: поиск перебирает словарь, а не использует специальные структуры.
+type memoryIndex struct {
+	mu    sync.RWMutex
+	docs  map[int64]*indexedDoc
+	terms map[string]int // число документов, содержащих слово
+}
+
+// NewMemoryIndex создает пустой индекс в памяти процесса
+func NewMemoryIndex() Index {
+	return &memoryIndex{
+		docs:  make(map[int64]*indexedDoc),
+		terms: make(map[string]int),
+	}
+}
+
+// Put добавляет документ или заменяет документ с тем же ID
+func (m *memoryIndex) Put(doc Document) {
+	indexed := indexDocument(doc)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(doc.ID)
+	m.add(indexed)
+}
+
+// Delete удаляет документ из индекса
+func (m *memoryIndex) Delete(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(id)
+}
+
+// Replace атомарно заменяет содержимое индекса
+func (m *memoryIndex) Replace(docs []Document) {
+	indexed := make([]*indexedDoc, 0, len(docs))
+	for _, doc := range docs {
+		indexed = append(indexed, indexDocument(doc))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.docs = make(map[int64]*indexedDoc, len(indexed))
+	m.terms = make(map[string]int)
+	for _, doc := range indexed {
+		m.remove(doc.id)
+		m.add(doc)
+	}
+}
+
+// Search возвращает до limit документов, отсортированных по убыванию релевантности
+func (m *memoryIndex) Search(query string, limit int) []Hit {
+	queryTerms := uniqueTerms(tokenize(query))
+	if len(queryTerms) == 0 || limit <= 0 {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Для каждого слова запроса подбираем слова словаря с качеством совпадения
+	expansions := make([]map[string]float64, len(queryTerms))
+	for i, q := range queryTerms {
+		expansions[i] = m.expand(q)
+	}
+
+	total := float64(len(m.docs))
+	avgLen := m.averageFieldLengths()
+
+	hits := make([]Hit, 0)
+	for _, doc := range m.docs {
+		var score float64
+		matchedTerms := 0
+		marked := make(map[string]map[string]bool)
+
+		for _, expansion := range expansions {
+			best := 0.0
+			for _, f := range doc.fields {
+				for term, quality := range expansion {
+					tf := f.freq[term]
+					if tf == 0 {
+						continue
+					}
+					if marked[f.Name] == nil {
+						marked[f.Name] = make(map[string]bool)
+					}
+					marked[f.Name][term] = true
+
+					df := float64(m.terms[term])
+					idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+					norm := 1 - bm25B + bm25B*float64(len(f.tokens))/avgLen[f.Name]
+					sat := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+					if s := f.Weight * quality * idf * sat; s > best {
+						best = s
+					}
+				}
+			}
+			if best > 0 {
+				score += best
+				matchedTerms++
+			}
+		}
+		if matchedTerms == 0 {
+			continue
+		}
+
+		// Документы, в которых нашлись все слова запроса, ранжируются выше частичных совпадений
+		score *= float64(matchedTerms) / float64(len(queryTerms))
+
+		highlights := make(map[string]string)
+		for _, f := range doc.fields {
+			if snippet, ok := highlight(f.Text, f.tokens, marked[f.Name]); ok {
+				highlights[f.Name] = snippet
+			}
+		}
+
+		hits = append(hits, Hit{ID: doc.id, Score: score, Highlights: highlights})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits
+}
+
+// expand подбирает слова словаря, совпадающие со словом запроса точно,
+// по префиксу или с опечатками, и качество каждого совпадения
+func (m *memoryIndex) expand(q string) map[string]float64 {
+	result := make(map[string]float64)
+	edits := maxEdits(q)
+
+	for term := range m.terms {
+		quality := 0.0
+		switch {
+		case term == q:
+			quality = 1
+		case len([]rune(q)) >= minPrefixLength && strings.HasPrefix(term, q):
+			quality = prefixQuality
+		}
+		if edits > 0 && quality < 1 {
+			if d := editDistance(q, term, edits); d <= edits {
+				if fuzzy := 1 - editPenalty*float64(d); fuzzy > quality {
+					quality = fuzzy
+				}
+			}
+		}
+		if quality > 0 {
+			result[term] = quality
+		}
+	}
+
+	return result
+}
+
+// averageFieldLengths возвращает среднюю длину каждого поля в словах
+func (m *memoryIndex) averageFieldLengths() map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]float64)
+	for _, doc := range m.docs {
+		for _, f := range doc.fields {
+			sums[f.Name] += float64(len(f.tokens))
+			counts[f.Name]++
+		}
+	}
+
+	avg := make(map[string]float64, len(sums))
+	for name, sum := range sums {
+		avg[name] = math.Max(sum/counts[name], 1)
+	}
+	return avg
+}
+
+// add добавляет разобранный документ; вызывается под блокировкой
+func (m *memoryIndex) add(doc *indexedDoc) {
+	m.docs[doc.id] = doc
+	for term := range docTerms(doc) {
+		m.terms[term]++
+	}
+}
+
+// remove удаляет документ; вызывается под блокировкой
+func (m *memoryIndex) remove(id int64) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+
+	delete(m.docs, id)
+	for term := range docTerms(doc) {
+		if m.terms[term]--; m.terms[term] <= 0 {
+			delete(m.terms, term)
+		}
+	}
+}
+
+// indexDocument разбирает поля документа на слова
+func indexDocument(doc Document) *indexedDoc {
+	indexed := &indexedDoc{id: doc.ID}
+	for _, f := range doc.Fields {
+		if f.Weight <= 0 {
+			f.Weight = 1
+		}
+		tokens := tokenize(f.Text)
+		freq := make(map[string]int, len(tokens))
+		for _, t := range tokens {
+			freq[t.term]++
+		}
+		indexed.fields = append(indexed.fields, indexedField{Field: f, tokens: tokens, freq: freq})
+	}
+	return indexed
+}
+
+// docTerms возвращает множество слов документа
+func docTerms(doc *indexedDoc) map[string]struct{} {
+	terms := make(map[string]struct{})
+	for _, f := range doc.fields {
+		for term := range f.freq {
+			terms[term] = struct{}{}
+		}
+	}
+	return terms
+}
+
+// uniqueTerms возвращает слова без повторов в порядке появления
+func uniqueTerms(tokens []token) []string {
+	seen := make(map[string]bool, len(tokens))
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !seen[t.term] {
+			seen[t.term] = true
+			terms = append(terms, t.term)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,209 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minTermLength минимальная длина индексируемого слова
+const minTermLength = 2
+
+// translit транслитерация кириллицы. Слова индексируются в латинице, поэтому
+// запрос "Antalya" находит "Анталия", а опечатки сравниваются в одном алфавите.
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// token слово текста и его положение в исходной строке (в байтах)
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize разбивает текст на слова и нормализует их
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if term := normalize(text[start:end]); utf8.RuneCountInString(term) >= minTermLength {
+			tokens = append(tokens, token{term: term, start: start, end: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// normalize приводит слово к нижнему регистру и латинице
+func normalize(word string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if latin, ok := translit[r]; ok {
+			sb.WriteString(latin)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// maxEdits допустимое число опечаток для слова запроса: короткие слова
+// должны совпадать точно, иначе почти любое слово находит что-нибудь
+func maxEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance расстояние Дамерау-Левенштейна (с перестановкой соседних
+// символов) между a и b. Если расстояние больше limit, возвращает limit+1.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	if prev[len(rb)] > limit {
+		return limit + 1
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Параметры фрагментов с подсветкой
+const (
+	snippetLength = 160 // максимальная длина фрагмента в символах
+	snippetBefore = 50  // сколько символов оставлять перед первым совпадением
+	markOpen      = "<mark>"
+	markClose     = "</mark>"
+)
+
+// highlight возвращает фрагмент текста, в котором слова из matched выделены
+// тегом <mark>. Длинный текст обрезается вокруг первого совпадения.
+func highlight(text string, tokens []token, matched map[string]bool) (string, bool) {
+	var marks []token
+	for _, t := range tokens {
+		if matched[t.term] {
+			marks = append(marks, t)
+		}
+	}
+	if len(marks) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(text)
+	if utf8.RuneCountInString(text) > snippetLength {
+		from = moveRunes(text, marks[0].start, -snippetBefore)
+		to = moveRunes(text, from, snippetLength)
+		// Не разрезаем слова на границах фрагмента
+		if from > 0 {
+			if i := strings.IndexByte(text[from:marks[0].start], ' '); i >= 0 {
+				from += i + 1
+			}
+		}
+		if to < len(text) {
+			if i := strings.LastIndexByte(text[from:to], ' '); i > 0 && from+i > marks[0].end {
+				to = from + i
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	pos := from
+	for _, m := range marks {
+		if m.start < from || m.end > to {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[pos:m.start]))
+		sb.WriteString(markOpen)
+		sb.WriteString(html.EscapeString(text[m.start:m.end]))
+		sb.WriteString(markClose)
+		pos = m.end
+	}
+	sb.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+
+	return sb.String(), true
+}
+
+// moveRunes сдвигает байтовую позицию pos на n символов вперед или назад
+func moveRunes(text string, pos, n int) int {
+	for ; n < 0 && pos > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
@@ -0,0 +1,140 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm хеш-функция HMAC
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+// SecretSize длина генерируемого секрета в байтах; RFC 4226 рекомендует 160 бит
+const SecretSize = 20
+
+// encoding base32 без выравнивания, как в приложениях-аутентификаторах
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Options параметры одноразовых паролей (RFC 6238)
+type Options struct {
+	Digits    int           // длина кода
+	Period    time.Duration // шаг времени
+	Algorithm Algorithm
+	Skew      int // сколько соседних шагов принимается из-за расхождения часов
+}
+
+// DefaultOptions параметры, которые поддерживают все распространенные аутентификаторы
+var DefaultOptions = Options{Digits: 6, Period: 30 * time.Second, Algorithm: SHA1, Skew: 1}
+
+// GenerateSecret создает случайный секрет в base32
+func GenerateSecret() (string, error) {
+	b := make([]byte, SecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать секрет: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// DecodeSecret разбирает секрет в base32; регистр, пробелы и выравнивание игнорируются
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный секрет: %w", err)
+	}
+	return key, nil
+}
+
+// Step возвращает номер шага времени для момента t
+func (o Options) Step(t time.Time) int64 {
+	return t.Unix() / int64(o.Period/time.Second)
+}
+
+// Code вычисляет код HOTP (RFC 4226) для счетчика
+func (o Options) Code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(o.hash(), key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Динамическое усечение: 31 бит начиная со смещения из последнего полубайта
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < o.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", o.Digits, value%mod)
+}
+
+// CodeAt вычисляет код TOTP для момента t
+func (o Options) CodeAt(key []byte, t time.Time) string {
+	return o.Code(key, o.Step(t))
+}
+
+// Validate проверяет код для момента t с учетом Skew соседних шагов
+// и возвращает шаг, которому код соответствует. Шаг нужно сохранить и не
+// принимать коды с шагом не больше сохраненного, иначе перехваченный код
+// можно использовать повторно.
+func (o Options) Validate(key []byte, code string, t time.Time) (int64, bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != o.Digits {
+		return 0, false
+	}
+
+	current := o.Step(t)
+	for delta := -o.Skew; delta <= o.Skew; delta++ {
+		step := current + int64(delta)
+		if subtle.ConstantTimeCompare([]byte(o.Code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI возвращает ссылку otpauth:// для QR-кода приложения-аутентификатора
+func (o Options) URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", string(o.Algorithm))
+	query.Set("digits", strconv.Itoa(o.Digits))
+	query.Set("period", strconv.Itoa(int(o.Period/time.Second)))
+
+	// Часть приложений не декодирует «+» как пробел
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func (o Options) hash() func() hash.Hash {
+	switch o.Algorithm {
+	case SHA256:
+		return sha256.New
+	case SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,121 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ключи из RFC 6238, приложение B: для каждой хеш-функции - своя длина
+var rfcKeys = map[Algorithm][]byte{
+	SHA1:   []byte("12345678901234567890"),
+	SHA256: []byte("12345678901234567890123456789012"),
+	SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+}
+
+func TestCodeAtRFC6238(t *testing.T) {
+	vectors := []struct {
+		unix      int64
+		algorithm Algorithm
+		want      string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+
+	for _, v := range vectors {
+		opts := Options{Digits: 8, Period: 30 * time.Second, Algorithm: v.algorithm}
+		if got := opts.CodeAt(rfcKeys[v.algorithm], time.Unix(v.unix, 0)); got != v.want {
+			t.Errorf("T=%d %s: %s, ожидалось %s", v.unix, v.algorithm, got, v.want)
+		}
+	}
+}
+
+func TestCodeRFC4226(t *testing.T) {
+	// RFC 4226, приложение D: HOTP для счетчиков 0-9
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := DefaultOptions.Code(rfcKeys[SHA1], int64(counter)); got != code {
+			t.Errorf("счетчик %d: %s, ожидалось %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	key := rfcKeys[SHA1]
+	now := time.Unix(1234567890, 0)
+	step := DefaultOptions.Step(now)
+
+	for delta := int64(-1); delta <= 1; delta++ {
+		code := DefaultOptions.Code(key, step+delta)
+		got, ok := DefaultOptions.Validate(key, code, now)
+		if !ok || got != step+delta {
+			t.Errorf("сдвиг %d: шаг %d, ok=%v; ожидался шаг %d", delta, got, ok, step+delta)
+		}
+	}
+
+	for _, delta := range []int64{-2, 2} {
+		if _, ok := DefaultOptions.Validate(key, DefaultOptions.Code(key, step+delta), now); ok {
+			t.Errorf("код со сдвигом %d принят вне окна", delta)
+		}
+	}
+
+	strict := DefaultOptions
+	strict.Skew = 0
+	if _, ok := strict.Validate(key, DefaultOptions.Code(key, step-1), now); ok {
+		t.Error("при Skew=0 принят код предыдущего шага")
+	}
+}
+
+func TestValidateRejectsMalformed(t *testing.T) {
+	key := rfcKeys[SHA1]
+	now := time.Unix(59, 0)
+	code := DefaultOptions.CodeAt(key, now)
+
+	for _, input := range []string{"", code[:5], code + "0", "abcdef"} {
+		if _, ok := DefaultOptions.Validate(key, input, now); ok {
+			t.Errorf("принят некорректный код %q", input)
+		}
+	}
+	if _, ok := DefaultOptions.Validate(key, code[:3]+" "+code[3:], now); !ok {
+		t.Error("не принят код с пробелом")
+	}
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DecodeSecret(strings.ToLower(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != SecretSize {
+		t.Errorf("длина ключа: %d, ожидалось %d", len(key), SecretSize)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := DefaultOptions.URI("Tour Agency", "user@example.com", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/Tour%20Agency:user@example.com?algorithm=SHA1&digits=6&issuer=Tour%20Agency&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != want {
+		t.Errorf("URI:\n%s\nожидалось\n%s", uri, want)
+	}
+}
@@ -27,6 +27,10 @@ type Message struct {
 	Content interface{} `json:"content"`
 }
 
+// MessageHandler обрабатывает входящее сообщение клиента.
+// Обработчик сам решает, что и кому рассылать через хаб.
+type MessageHandler func(c *Client, msg Message)
+
 // Client представляет клиента WebSocket
 type Client struct {
 	Hub      *Hub
@@ -34,6 +38,8 @@ type Client struct {
 	Send     chan Message
 	TicketID int64
 	UserID   int64
+	// OnMessage если задан, вызывается вместо прямой рассылки сообщения
+	OnMessage MessageHandler
 }
 
 // ReadPump обрабатывает сообщения от клиента
@@ -60,6 +66,11 @@ func (c *Client) ReadPump() {
 			break
 		}
 
+		if c.OnMessage != nil {
+			c.OnMessage(c, msg)
+			continue
+		}
+
 		// Добавляем сообщение в хаб
 		c.Hub.Broadcast <- BroadcastMessage{
 			Message:  msg,
@@ -68,6 +79,14 @@ func (c *Client) ReadPump() {
 	}
 }
 
+// Write записывает сообщение в соединение в обход канала Send и ждет окончания
+// записи, поэтому медленный клиент не переполняет буфер. У соединения может быть
+// только один писатель: вызывать Write можно лишь до запуска WritePump.
+func (c *Client) Write(message Message) error {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteJSON(message)
+}
+
 // WritePump отправляет сообщения клиенту
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(pingPeriod)
@@ -4,6 +4,8 @@ package websocket
 type BroadcastMessage struct {
 	Message  Message
 	TicketID int64
+	// Target если задан, сообщение доставляется только этому клиенту
+	Target *Client
 }
 
 // Hub центральный компонент для управления всеми клиентами WebSocket
@@ -61,6 +63,9 @@ func (h *Hub) Run() {
 			// Отправляем сообщение всем клиентам, связанным с данным тикетом
 			if clients, ok := h.Clients[message.TicketID]; ok {
 				for client := range clients {
+					if message.Target != nil && message.Target != client {
+						continue
+					}
 					select {
 					case client.Send <- message.Message:
 					default:
@@ -72,3 +77,13 @@ func (h *Hub) Run() {
 		}
 	}
 }
+
+// SendTo отправляет сообщение одному клиенту через хаб.
+// Доставка идет через цикл Run, поэтому не гонится с закрытием канала Send.
+func (h *Hub) SendTo(client *Client, message Message) {
+	h.Broadcast <- BroadcastMessage{
+		Message:  message,
+		TicketID: client.TicketID,
+		Target:   client,
+	}
+}
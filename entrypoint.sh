@@ -77,9 +77,16 @@ else
     echo "Nginx health-check отвечает кодом 200 OK"
 fi
 
+# Применяем миграции схемы и обязательные начальные данные, если это включено
+cd /app
+if [ "${RUN_MIGRATIONS:-false}" = "true" ]; then
+    echo "Применение миграций базы данных..."
+    /app/migrate up || { echo "ОШИБКА: миграции не применены"; exit 1; }
+    /app/migrate seed || { echo "ОШИБКА: начальные данные не загружены"; exit 1; }
+fi
+
 # Запускаем бэкенд
 echo "Запуск бэкенда..."
-cd /app
 /app/api &
 BACKEND_PID=$!
 
@@ -221,7 +221,7 @@ const OrderForm: React.FC<OrderFormProps> = ({ orderData, onChange, onSubmit, lo
       
       <div className="form-actions">
         <button type="submit" className="form-submit-button" disabled={loading}>
-          {loading ? 'Оформляем заказ...' : 'Перейти к подтверждению'}
+          {loading ? 'Рассчитываем стоимость...' : 'Перейти к подтверждению'}
         </button>
       </div>
     </form>
@@ -37,8 +37,31 @@ interface Room {
   imageUrl: string;
 }
 
+// Расшифровка стоимости, рассчитанная сервером (PriceBreakdown)
+export interface PriceBreakdown {
+  currency: string;
+  base_price: number;
+  price_modifier: number;
+  people_count: number;
+  children_count: number;
+  tour_amount: number;
+  room_price: number;
+  room_nights: number;
+  room_amount: number;
+  discounts: {
+    promotion_id: number;
+    code?: string;
+    title: string;
+    amount: number;
+  }[] | null;
+  discount_amount: number;
+  total: number;
+}
+
 interface OrderSummaryProps {
   tour: Tour;
+  // Если передана, стоимость выводится по расчету сервера, а не оценивается на клиенте
+  breakdown?: PriceBreakdown | null;
   tourDate?: TourDate;
   selectedRoom?: Room | null;
   orderData: {
@@ -63,6 +86,7 @@ const OrderSummary: React.FC<OrderSummaryProps> = ({
   tour, 
   tourDate, 
   selectedRoom,
+  breakdown,
   orderData,
   startDate,
   endDate,
@@ -167,6 +191,26 @@ const OrderSummary: React.FC<OrderSummaryProps> = ({
     }
   };
   
+  // Функция для склонения слова "ночь"
+  const getNightsText = (count: number): string => {
+    if (count % 10 === 1 && count % 100 !== 11) {
+      return 'ночь';
+    } else if ([2, 3, 4].includes(count % 10) && ![12, 13, 14].includes(count % 100)) {
+      return 'ночи';
+    } else {
+      return 'ночей';
+    }
+  };
+  
+  // Форматирует сумму из расчета сервера в его валюте
+  const formatAmount = (amount: number, currency: string): string => {
+    try {
+      return new Intl.NumberFormat('ru-RU', { style: 'currency', currency }).format(amount);
+    } catch (error) {
+      return `${amount.toLocaleString('ru-RU')} ${currency}`;
+    }
+  };
+  
   console.log('OrderSummary - Исходные данные:', {
     tour,
     tourDate,
@@ -259,10 +303,12 @@ const OrderSummary: React.FC<OrderSummaryProps> = ({
             <span className="summary-label">Тип номера:</span>
             <span className="summary-value">{selectedRoom.description}</span>
           </div>
-          <div className="summary-item">
-            <span className="summary-label">Стоимость за ночь:</span>
-            <span className="summary-value">{selectedRoom.price.toLocaleString()} ₽</span>
-          </div>
+          {!breakdown && (
+            <div className="summary-item">
+              <span className="summary-label">Стоимость за ночь:</span>
+              <span className="summary-value">{selectedRoom.price.toLocaleString()} ₽</span>
+            </div>
+          )}
         </div>
       )}
       
@@ -289,6 +335,37 @@ const OrderSummary: React.FC<OrderSummaryProps> = ({
         </div>
       )}
       
+      {breakdown ? (
+      <div className="summary-section price-breakdown">
+        <h4>Детализация стоимости</h4>
+        <div className="summary-item">
+          <span className="summary-label">Базовая стоимость тура:</span>
+          <span className="summary-value">{formatAmount(breakdown.base_price, breakdown.currency)} × {breakdown.price_modifier} × {breakdown.people_count} {getPeopleText(breakdown.people_count)}</span>
+        </div>
+        <div className="summary-item">
+          <span className="summary-label">Общая стоимость тура:</span>
+          <span className="summary-value">{formatAmount(breakdown.tour_amount, breakdown.currency)}</span>
+        </div>
+        {breakdown.room_nights > 0 && (
+          <>
+            <div className="summary-item">
+              <span className="summary-label">Проживание:</span>
+              <span className="summary-value">{formatAmount(breakdown.room_price, breakdown.currency)} за ночь × {breakdown.room_nights} {getNightsText(breakdown.room_nights)}</span>
+            </div>
+            <div className="summary-item">
+              <span className="summary-label">Общая стоимость проживания:</span>
+              <span className="summary-value">{formatAmount(breakdown.room_amount, breakdown.currency)}</span>
+            </div>
+          </>
+        )}
+        {(breakdown.discounts || []).map((d) => (
+          <div className="summary-item discount-item" key={d.promotion_id}>
+            <span className="summary-label">{d.title}{d.code ? ` (${d.code})` : ''}:</span>
+            <span className="summary-value discount-value">- {formatAmount(d.amount, breakdown.currency)}</span>
+          </div>
+        ))}
+      </div>
+      ) : (
       <div className="summary-section price-breakdown">
         <h4>Детализация стоимости</h4>
         <div className="summary-item">
@@ -327,10 +404,15 @@ const OrderSummary: React.FC<OrderSummaryProps> = ({
           </div>
         )}
       </div>
+      )}
       
       <div className="total-price">
         <span className="total-label">Итого:</span>
-        <span className="total-value">{isNaN(finalPrice) ? '0' : finalPrice.toLocaleString()} ₽</span>
+        <span className="total-value">
+          {breakdown
+            ? formatAmount(breakdown.total, breakdown.currency)
+            : `${isNaN(finalPrice) ? '0' : finalPrice.toLocaleString()} ₽`}
+        </span>
       </div>
       
       {(onConfirm || onEdit) && (
@@ -22,6 +22,8 @@ const Chat = ({ ticketId }) => {
         message: data.content.message,
         timestamp: data.content.timestamp || new Date().toISOString()
       }]);
+    } else if (data.type === 'error') {
+      console.error('Ошибка чата:', data.content?.message);
     } else if (data.type === 'history') {
       // Добавляем историческое сообщение
       setMessages(prevMessages => {
@@ -40,9 +42,12 @@ const Chat = ({ ticketId }) => {
     }
   };
   
+  // Токен передается в query, так как WebSocket API не поддерживает заголовки
+  const token = localStorage.getItem('accessToken') || localStorage.getItem('token') || '';
+
   // Инициализируем WebSocket соединение
   const { isConnected, error, sendMessage, reconnect } = useWebSocket(
-    `${WS_URL}/ws/chat/${ticketId}`,
+    `${WS_URL}/ws/chat/${ticketId}?token=${encodeURIComponent(token)}`,
     handleMessage
   );
   
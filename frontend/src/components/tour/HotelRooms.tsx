@@ -43,35 +43,17 @@ const HotelRooms: React.FC<HotelRoomsProps> = ({
     try {
       setLoading(true);
       setError(null);
-      // Получаем список номеров отеля
-      const response = await hotelService.getHotelRooms(hotelId);
-      const roomsData = response.data;
-      
-      // Проверяем доступность номеров на выбранные даты
-      const roomsWithAvailability = await Promise.all(
-        roomsData.map(async (room: Room) => {
-          try {
-            // Запрашиваем доступность номера на выбранные даты
-            const availabilityResponse = await hotelService.getRoomAvailability(
-              room.id.toString(),
-              startDate,
-              endDate
-            );
-            return {
-              ...room,
-              // isAvailable: availabilityResponse.data.available // Оригинальная проверка доступности - ЗАКОММЕНТИРОВАНО
-              isAvailable: true // Временно отображаем все номера как доступные - РАСКОММЕНТИРОВАНО
-            };
-          } catch (err) {
-            console.error(`Error checking availability for room ${room.id}:`, err);
-            return {
-              ...room,
-              // isAvailable: false // Оригинальная логика при ошибке - ЗАКОММЕНТИРОВАНО
-              isAvailable: true // Временно отображаем как доступные даже при ошибке API - РАСКОММЕНТИРОВАНО
-            };
-          }
-        })
-      );
+      // Получаем все номера отеля и номера, свободные на все ночи тура
+      const [allResponse, freeResponse] = await Promise.all([
+        hotelService.getHotelRooms(hotelId),
+        hotelService.getHotelRooms(hotelId, startDate, endDate)
+      ]);
+      const freeIds = new Set((freeResponse.data || []).map((room: Room) => room.id));
+
+      const roomsWithAvailability = (allResponse.data || []).map((room: Room) => ({
+        ...room,
+        isAvailable: freeIds.has(room.id)
+      }));
       
       setRooms(roomsWithAvailability);
     } catch (err) {
@@ -6,7 +6,7 @@ import { useAppDispatch } from '../store/hooks';
 import OrderForm from '../components/order/OrderForm';
 import OrderSummary from '../components/order/OrderSummary';
 import Spinner from '../components/ui/Spinner';
-import { createOrder, resetCreateOrderSuccess } from '../store/order/orderSlice';
+import { createOrder, quoteOrder, clearQuote, resetCreateOrderSuccess } from '../store/order/orderSlice';
 import { hotelService } from '../services/api';
 import './BookingPage.css';
 
@@ -56,7 +56,7 @@ const BookingPage: React.FC = () => {
 
   // Деструктуризация
   const { tour, loading: tourLoading, error: tourError } = tourData || {}; 
-  const { loading: orderLoading, error: orderError, createOrderSuccess } = orderState || {};
+  const { loading: orderLoading, error: orderError, createOrderSuccess, quote, quoteLoading, quoteError } = orderState || {};
   const { isAuthenticated, user } = authState || {};
 
   console.log('BookingPage - isAuthenticated:', isAuthenticated);
@@ -84,6 +84,7 @@ const BookingPage: React.FC = () => {
   
   useEffect(() => {
     dispatch(resetCreateOrderSuccess());
+    dispatch(clearQuote());
     
     if (!isAuthenticated) {
       console.log('BookingPage - пользователь не авторизован, перенаправление на /login');
@@ -138,141 +139,68 @@ const BookingPage: React.FC = () => {
     }));
   };
   
-  const calculatePrice = () => {
-    if (!tour) return 0;
-    
-    const selectedDate = tour.dates?.find((date: any) => date.id === orderData.tourDateId);
-    const priceModifier = selectedDate?.priceModifier || 1;
-    
-    // Используем basePrice или base_price, в зависимости от того, что доступно
-    const basePrice = tour.basePrice || tour.base_price || 0;
-    console.log('BookingPage - Базовая цена тура:', basePrice);
-    
-    // Базовая стоимость тура с учетом количества людей
-    const tourPrice = basePrice * priceModifier * (orderData.peopleCount || 1);
+  // Параметры заказа в формате API; по ним же запрашивается расчет стоимости
+  const buildOrderRequest = () => {
+    const request: any = {
+      tour_id: parseInt(orderData.tourId),
+      tour_date_id: orderData.tourDateId,
+      people_count: orderData.peopleCount
+    };
     
-    // Стоимость номера в отеле (если выбран)
-    let roomPrice = 0;
-    let days = 0;
-    let nights = 0;
-    if (orderData.roomId && selectedRoom) {
-      days = selectedDate ? calculateDaysFromDates(selectedDate.startDate, selectedDate.endDate) : tour.duration || 1;
-      nights = days - 1; // Рассчитываем количество ночей (всегда на 1 меньше, чем дней тура)
-      
-      // Стоимость за все ночи проживания
-      roomPrice = selectedRoom.price * nights;
-      
-      console.log('BookingPage - Данные для расчета стоимости номера:', {
-        roomId: orderData.roomId,
-        roomPricePerNight: selectedRoom.price,
-        days,
-        nights,
-        roomPriceCalculation: `${selectedRoom.price} × ${nights} = ${roomPrice}`,
-        totalRoomPrice: roomPrice
-      });
+    if (orderData.roomId) {
+      request.room_id = orderData.roomId;
     }
     
-    // Общая стоимость = стоимость тура + стоимость номера
-    const calculatedPrice = tourPrice + roomPrice;
-    console.log('BookingPage - Расчет полной стоимости:', {
-      basePrice,
-      priceModifier,
-      peopleCount: orderData.peopleCount,
-      tourPriceCalculation: `${basePrice} × ${priceModifier} × ${orderData.peopleCount || 1} = ${tourPrice}`,
-      tourPrice,
-      roomPricePerNight: selectedRoom?.price,
-      days,
-      nights,
-      roomPrice,
-      finalCalculation: `${tourPrice} + ${roomPrice} = ${calculatedPrice}`,
-      calculatedPrice
-    });
+    if (orderData.contactPhone) {
+      request.contact_phone = orderData.contactPhone;
+    }
     
-    // Округляем до целого числа рублей
-    return Math.round(calculatedPrice);
-  };
-  
-  // Вспомогательная функция для расчета количества дней
-  const calculateDaysFromDates = (startDateStr: string, endDateStr: string): number => {
-    try {
-      const start = new Date(startDateStr);
-      const end = new Date(endDateStr);
-      
-      if (isNaN(start.getTime()) || isNaN(end.getTime())) {
-        return tour.duration || 1;
-      }
-      
-      const diffTime = Math.abs(end.getTime() - start.getTime());
-      return Math.ceil(diffTime / (1000 * 60 * 60 * 24)) + 1; // +1 чтобы учесть день отъезда
-    } catch (error) {
-      console.error("Ошибка при расчете длительности:", error);
-      return tour.duration || 1;
+    if (orderData.specialRequests) {
+      request.special_requests = orderData.specialRequests;
     }
+    
+    return request;
   };
   
-  useEffect(() => {
-    const price = calculatePrice();
-    setOrderData(prev => ({
-      ...prev,
-      totalPrice: price
-    }));
-    console.log('BookingPage - Рассчитанная стоимость с учетом номера:', price);
-  }, [selectedRoom, orderData.peopleCount, tour, orderData.tourDateId]);
-  
+  // Шаг 1: стоимость рассчитывает сервер, подтверждение показывает его расчет
   const handleNext = () => {
-    setStep(2);
+    const request = buildOrderRequest();
+    if (!request.tour_id || !request.tour_date_id) {
+      console.error('BookingPage - Ошибка: Недостаточно данных для расчета стоимости.', request);
+      return;
+    }
+    
+    dispatch(quoteOrder(request)).then((result) => {
+      if (result.meta.requestStatus === 'fulfilled') {
+        setOrderData(prev => ({
+          ...prev,
+          totalPrice: result.payload.total_price
+        }));
+        setStep(2);
+      }
+    });
   };
   
   const handleBack = () => {
+    // Данные заказа могут измениться, поэтому расчет придется запросить заново
+    dispatch(clearQuote());
     setStep(1);
   };
   
+  // Шаг 2: заказ оформляется по токену показанного пользователю расчета
   const handleSubmit = () => {
-    // Пересчитываем итоговую стоимость для отправки на сервер
-    const totalPrice = calculatePrice();
-    
-    // Используем данные из state (orderData), который инициализировался из параметров
-    const submitData: any = {
-      tour_id: parseInt(orderData.tourId),
-      tour_date_id: orderData.tourDateId,
-      people_count: orderData.peopleCount,
-      total_price: totalPrice // Добавляем рассчитанную стоимость
-    };
-    
-    if (orderData.roomId) {
-      submitData.room_id = orderData.roomId;
-    }
-    
-    if (orderData.contactPhone) {
-      submitData.contact_phone = orderData.contactPhone;
-    }
-    
-    if (orderData.specialRequests) {
-      submitData.special_requests = orderData.specialRequests;
+    if (!quote?.quote_token) {
+      setStep(1);
+      return;
     }
     
-    console.log('Отправка заказа на сервер с указанием полной стоимости:', submitData);
-    // Проверяем, что dispatch вызывается с правильными данными
-    if (submitData.tour_id && submitData.tour_date_id) {
-      // Очищаем возможные предыдущие флаги успеха
-      dispatch(resetCreateOrderSuccess());
-      
-      // Отправляем запрос на создание заказа
-      dispatch(createOrder(submitData))
-        .then((result) => {
-          // После успешного создания заказа
-          if (result.meta.requestStatus === 'fulfilled') {
-            console.log('BookingPage - Заказ успешно создан, результат:', result.payload);
-            // Принудительное перенаправление на страницу заказов
-            navigate('/orders');
-          }
-        })
-        .catch((error) => {
-          console.error('BookingPage - Ошибка при создании заказа:', error);
-        });
-    } else {
-      console.error('BookingPage - Ошибка: Недостаточно данных для отправки заказа.', submitData);
-    }
+    dispatch(resetCreateOrderSuccess());
+    dispatch(createOrder({ ...buildOrderRequest(), quote_token: quote.quote_token }))
+      .then((result) => {
+        if (result.meta.requestStatus === 'fulfilled') {
+          navigate('/orders');
+        }
+      });
   };
   
   // Убираем дублирование кода
@@ -430,11 +358,11 @@ const BookingPage: React.FC = () => {
               orderData={orderData}
               onChange={handleInputChange}
               onSubmit={handleNext}
-              loading={orderLoading}
+              loading={quoteLoading}
             />
-            {orderError && (
+            {quoteError && (
               <div className="error-message booking-error-message">
-                {orderError}
+                {quoteError}
               </div>
             )}
           </div>
@@ -444,6 +372,7 @@ const BookingPage: React.FC = () => {
               tour={preparedTour}
               tourDate={selectedDate} // Передаем найденный объект даты
               selectedRoom={selectedRoom} // Передаем информацию о выбранном номере
+              breakdown={quote?.breakdown} // Стоимость по расчету сервера
               orderData={orderData}
               // startDate и endDate больше не нужны здесь, т.к. есть tourDate
             />
@@ -455,7 +384,7 @@ const BookingPage: React.FC = () => {
               <button 
                 className="booking-submit-button" 
                 onClick={handleSubmit}
-                disabled={orderLoading}
+                disabled={orderLoading || !quote}
               >
                 {orderLoading ? 'Оформление...' : 'Подтвердить бронирование'}
               </button>
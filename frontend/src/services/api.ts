@@ -122,8 +122,10 @@ export const hotelService = {
   getHotelById: (id: string) => {
     return api.get(`/hotels/${id}`);
   },
-  getHotelRooms: (hotelId: number) => {
-    return api.get(`/hotels/${hotelId}/rooms`);
+  getHotelRooms: (hotelId: number, startDate?: string, endDate?: string) => {
+    // С датами заезда и выезда сервер возвращает только свободные номера
+    const params = startDate && endDate ? { startDate, endDate } : {};
+    return api.get(`/hotels/${hotelId}/rooms`, { params });
   },
   // Добавлено из api.js
   getRoomById: (id: string | number) => { // Уточнил тип id
@@ -160,6 +162,11 @@ export const authService = {
     return axios.post(`${API_URL}/auth/refresh`, { refreshToken });
   },
   logout: () => {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (refreshToken) {
+      // Отзываем сессию на сервере; ошибка не мешает локальному выходу
+      axios.post(`${API_URL}/auth/logout`, { refreshToken }).catch(() => {});
+    }
     localStorage.removeItem('token');
     localStorage.removeItem('accessToken');
     localStorage.removeItem('refreshToken');
@@ -206,6 +213,10 @@ export const authService = {
 
 // Сервис для работы с заказами
 export const orderService = {
+  quoteOrder: (orderData: any) => {
+    // Стоимость рассчитывается только на сервере; ответ содержит подписанный quote_token
+    return api.post('/orders/quote', orderData);
+  },
   createOrder: (orderData: any) => {
     console.log('API: Отправка запроса на создание заказа', orderData); // Лог из api.js
     return api.post('/orders', orderData)
@@ -8,6 +8,10 @@ const initialState = {
   loading: false,
   error: null,
   createOrderSuccess: false,
+  // Расчет стоимости, полученный с сервера перед подтверждением заказа
+  quote: null,
+  quoteLoading: false,
+  quoteError: null,
   pagination: {
     page: 1,
     size: 10,
@@ -17,24 +21,40 @@ const initialState = {
 
 // Асинхронные action creators
 /**
- * Создает новый заказ
+ * Запрашивает у сервера расчет стоимости заказа
  * @param {Object} orderData - Данные заказа
  * @param {number} orderData.tour_id - ID тура
  * @param {number} orderData.tour_date_id - ID даты тура
  * @param {number|null} [orderData.room_id] - ID номера (опционально)
  * @param {number} orderData.people_count - Количество человек
- * @param {number} [orderData.total_price] - Общая стоимость заказа (опционально)
+ *
+ * Ответ содержит итоговую сумму, расшифровку стоимости и подписанный
+ * quote_token, по которому затем оформляется заказ.
+ */
+export const quoteOrder = createAsyncThunk(
+  'order/quoteOrder',
+  async (orderData, { rejectWithValue }) => {
+    try {
+      const response = await orderService.quoteOrder(orderData);
+      return response.data;
+    } catch (error) {
+      return rejectWithValue(error.response?.data?.error || 'Ошибка при расчете стоимости');
+    }
+  }
+);
+
+/**
+ * Создает новый заказ по ранее полученному расчету стоимости
+ * @param {Object} orderData - Данные заказа, по которым был получен расчет
+ * @param {string} orderData.quote_token - Подписанный токен расчета из quoteOrder
  */
 export const createOrder = createAsyncThunk(
   'order/createOrder',
   async (orderData, { rejectWithValue }) => {
     try {
-      console.log('[OrderSlice] Отправка данных заказа:', orderData);
       const response = await orderService.createOrder(orderData);
-      console.log('[OrderSlice] Успешный ответ от сервера:', response.data);
       return response.data;
     } catch (error) {
-      console.error('[OrderSlice] Ошибка при создании заказа:', error.response?.data);
       return rejectWithValue(error.response?.data?.error || 'Ошибка при создании заказа');
     }
   }
@@ -94,10 +114,29 @@ const orderSlice = createSlice({
     },
     clearError: (state) => {
       state.error = null;
+    },
+    clearQuote: (state) => {
+      state.quote = null;
+      state.quoteError = null;
     }
   },
   extraReducers: (builder) => {
     builder
+      // Обработка quoteOrder
+      .addCase(quoteOrder.pending, (state) => {
+        state.quoteLoading = true;
+        state.quoteError = null;
+        state.quote = null;
+      })
+      .addCase(quoteOrder.fulfilled, (state, action) => {
+        state.quoteLoading = false;
+        state.quote = action.payload;
+      })
+      .addCase(quoteOrder.rejected, (state, action) => {
+        state.quoteLoading = false;
+        state.quoteError = action.payload;
+      })
+
       // Обработка createOrder
       .addCase(createOrder.pending, (state) => {
         state.loading = true;
@@ -109,6 +148,7 @@ const orderSlice = createSlice({
         state.loading = false;
         state.order = action.payload;
         state.createOrderSuccess = true;
+        state.quote = null;
         console.log('[OrderSlice] createOrder.fulfilled - createOrderSuccess установлен в true, должно быть перенаправление');
         // Добавляем новый заказ в начало списка, если он уже был загружен
         if (state.orders.length > 0) {
@@ -180,7 +220,7 @@ const orderSlice = createSlice({
   }
 });
 
-export const { resetCreateOrderSuccess, setPage, setSize, clearError } = orderSlice.actions;
+export const { resetCreateOrderSuccess, setPage, setSize, clearError, clearQuote } = orderSlice.actions;
 
 // Добавляем функцию для отладки состояния редьюсера
 export const debugOrderState = (state) => {